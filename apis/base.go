@@ -39,6 +39,11 @@ func NewRouter(app core.App) (*router.Router[*core.RequestEvent], error) {
 	bindCollectionApi(app, apiGroup)
 	bindRecordCrudApi(app, apiGroup)
 	bindRecordAuthApi(app, apiGroup)
+	bindRecordExportApi(app, apiGroup)
+	bindRecordImportApi(app, apiGroup)
+	bindRecordAggregateApi(app, apiGroup)
+	bindRecordHistoryApi(app, apiGroup)
+	bindRecordSoftDeleteApi(app, apiGroup)
 	bindLogsApi(app, apiGroup)
 	bindBackupApi(app, apiGroup)
 	bindCronApi(app, apiGroup)
@@ -46,6 +51,9 @@ func NewRouter(app core.App) (*router.Router[*core.RequestEvent], error) {
 	bindBatchApi(app, apiGroup)
 	bindRealtimeApi(app, apiGroup)
 	bindHealthApi(app, apiGroup)
+	bindCertsApi(app, apiGroup)
+	bindWebhooksApi(app, apiGroup)
+	bindOpenAPIApi(app, pbRouter, apiGroup)
 
 	return pbRouter, nil
 }
@@ -112,10 +112,17 @@ func batchTransaction(e *core.RequestEvent) error {
 		return err
 	}
 
+	// note: the idempotency check is inlined because it requires the body limit to be applied first
+	return handleIdempotentRequest(e, func() error {
+		return processBatchRequest(e, maxRequests, txTimeout)
+	})
+}
+
+func processBatchRequest(e *core.RequestEvent, maxRequests int, txTimeout time.Duration) error {
 	form := &batchRequestsForm{max: maxRequests}
 
 	// load base requests data
-	err = e.BindBody(form)
+	err := e.BindBody(form)
 	if err != nil {
 		return e.BadRequestError("Failed to read the submitted batch data.", err)
 	}
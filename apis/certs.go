@@ -0,0 +1,52 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/certstore"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+const certsServeEventStoreKey = "pbCertsServeEvent"
+
+// bindCertsApi registers the SNI certificates api endpoints.
+func bindCertsApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	// keep a reference to the serve event so that the certificates
+	// are listed from its store (even if it was replaced by an OnServe hook)
+	app.OnServe().Bind(&hook.Handler[*core.ServeEvent]{
+		Id: "pbCertsServeEvent",
+		Func: func(e *core.ServeEvent) error {
+			e.App.Store().Set(certsServeEventStoreKey, e)
+
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	sub := rg.Group("/certs").Bind(RequireSuperuserAuth())
+	sub.GET("", certsList)
+}
+
+// ServeCertStore returns the SNI certificates store of the app server
+// that is running in the current process.
+//
+// Returns nil if the app is not serving (or the server has no certificates store).
+func ServeCertStore(app core.App) *certstore.Store {
+	e, _ := app.Store().Get(certsServeEventStoreKey).(*core.ServeEvent)
+	if e == nil {
+		return nil
+	}
+
+	return e.CertStore
+}
+
+func certsList(e *core.RequestEvent) error {
+	store := ServeCertStore(e.App)
+	if store == nil {
+		return e.JSON(http.StatusOK, []certstore.Certificate{})
+	}
+
+	return e.JSON(http.StatusOK, store.List())
+}
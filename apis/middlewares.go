@@ -1,9 +1,11 @@
 package apis
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"runtime"
@@ -60,7 +62,6 @@ func RequireGuestOnly() *hook.Handler[*core.RequestEvent] {
 	return &hook.Handler[*core.RequestEvent]{
 		Id: DefaultRequireGuestOnlyMiddlewareId,
 		Func: func(e *core.RequestEvent) error {
-			e.TraceMiddleware(DefaultRequireGuestOnlyMiddlewareId, 0)
 			if e.Auth != nil {
 				return router.NewBadRequestError("The request can be accessed only by guests.", nil)
 			}
@@ -184,7 +185,6 @@ func loadAuthToken() *hook.Handler[*core.RequestEvent] {
 		Id:       DefaultLoadAuthTokenMiddlewareId,
 		Priority: DefaultLoadAuthTokenMiddlewarePriority,
 		Func: func(e *core.RequestEvent) error {
-			e.TraceMiddleware(DefaultLoadAuthTokenMiddlewareId, DefaultLoadAuthTokenMiddlewarePriority)
 			// already loaded by another middleware
 			if e.Auth != nil {
 				return e.Next()
@@ -226,7 +226,6 @@ func wwwRedirect(redirectHosts []string) *hook.Handler[*core.RequestEvent] {
 		Id:       DefaultWWWRedirectMiddlewareId,
 		Priority: DefaultWWWRedirectMiddlewarePriority,
 		Func: func(e *core.RequestEvent) error {
-			e.TraceMiddleware(DefaultWWWRedirectMiddlewareId, DefaultWWWRedirectMiddlewarePriority)
 			host := e.Request.Host
 
 			if strings.HasPrefix(host, "www.") && list.ExistInSlice(host, redirectHosts) {
@@ -253,7 +252,6 @@ func panicRecover() *hook.Handler[*core.RequestEvent] {
 		Id:       DefaultPanicRecoverMiddlewareId,
 		Priority: DefaultPanicRecoverMiddlewarePriority,
 		Func: func(e *core.RequestEvent) (err error) {
-			e.TraceMiddleware(DefaultPanicRecoverMiddlewareId, DefaultPanicRecoverMiddlewarePriority)
 			// panic-recover
 			defer func() {
 				recoverResult := recover()
@@ -289,7 +287,6 @@ func securityHeaders() *hook.Handler[*core.RequestEvent] {
 		Id:       DefaultSecurityHeadersMiddlewareId,
 		Priority: DefaultSecurityHeadersMiddlewarePriority,
 		Func: func(e *core.RequestEvent) error {
-			e.TraceMiddleware(DefaultSecurityHeadersMiddlewareId, DefaultSecurityHeadersMiddlewarePriority)
 			e.Response.Header().Set("X-XSS-Protection", "1; mode=block")
 			e.Response.Header().Set("X-Content-Type-Options", "nosniff")
 			e.Response.Header().Set("X-Frame-Options", "SAMEORIGIN")
@@ -324,14 +321,21 @@ func SkipSuccessActivityLog() *hook.Handler[*core.RequestEvent] {
 //
 // Users can attach the [apis.SkipSuccessActivityLog()] middleware if
 // you want to log only the failed requests.
+//
+// The request middlewares and handler execution trace is stored with the log entry
+// and in dev mode it is also sent to the client as Server-Timing header.
 func activityLogger() *hook.Handler[*core.RequestEvent] {
 	return &hook.Handler[*core.RequestEvent]{
 		Id:       DefaultActivityLoggerMiddlewareId,
 		Priority: DefaultActivityLoggerMiddlewarePriority,
 		Func: func(e *core.RequestEvent) error {
-			e.TraceMiddleware(DefaultActivityLoggerMiddlewareId, DefaultActivityLoggerMiddlewarePriority)
 			e.Set(requestEventKeyExecStart, time.Now())
 
+			// expose the middlewares trace as Server-Timing header in dev mode
+			if e.App.IsDev() {
+				e.Response = &serverTimingResponseWriter{ResponseWriter: e.Response, trace: e.Trace()}
+			}
+
 			err := e.Next()
 
 			logRequest(e, err)
@@ -402,7 +406,7 @@ func logRequest(event *core.RequestEvent, err error) {
 
 	attrs = append(
 		attrs,
-		slog.String("mw_trace", event.GetTrace()),
+		slog.Any("trace", event.Trace().Spans()),
 		slog.String("url", requestUri),
 		slog.String("host", host),
 		slog.String("proto", proto),
@@ -455,3 +459,51 @@ func cutStr(str string, max int) string {
 	}
 	return str
 }
+
+// -------------------------------------------------------------------
+
+// serverTimingResponseWriter wraps a http.ResponseWriter and sets the
+// Server-Timing header with the current trace spans right before
+// the response headers are sent.
+type serverTimingResponseWriter struct {
+	http.ResponseWriter
+
+	trace         *router.Trace
+	headerWritten bool
+}
+
+func (w *serverTimingResponseWriter) writeServerTiming() {
+	if w.headerWritten {
+		return
+	}
+
+	w.headerWritten = true
+
+	if timing := w.trace.ServerTiming(); timing != "" {
+		w.ResponseWriter.Header().Add("Server-Timing", timing)
+	}
+}
+
+func (w *serverTimingResponseWriter) WriteHeader(status int) {
+	w.writeServerTiming()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *serverTimingResponseWriter) Write(b []byte) (int, error) {
+	w.writeServerTiming()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *serverTimingResponseWriter) FlushError() error {
+	w.writeServerTiming()
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *serverTimingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying ResponseWritter instance (usually used by [http.ResponseController]).
+func (w *serverTimingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,311 @@
+package apis
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	DefaultIdempotencyMiddlewareId       = "pbIdempotency"
+	DefaultIdempotencyMiddlewarePriority = DefaultBodyLimitMiddlewarePriority + 10
+)
+
+const (
+	// IdempotencyKeyHeader is the request header with the client generated idempotency key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotencyReplayedHeader is the response header that is set
+	// when the response was replayed from a previous request.
+	IdempotencyReplayedHeader = "Idempotency-Replayed"
+)
+
+// idempotencyKeyMaxLength is the max allowed Idempotency-Key header value length.
+const idempotencyKeyMaxLength = 255
+
+// idempotencyHopHeaders lists the hop-by-hop (and the transport specific)
+// response headers that are not stored with the idempotent response.
+var idempotencyHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Content-Length",
+	IdempotencyReplayedHeader,
+}
+
+// idempotencyPendingTimeout is the max duration after which a pending
+// (aka. not completed) idempotency key is considered abandoned and
+// could be reclaimed by a new request (eg. in case of a server crash).
+const idempotencyPendingTimeout = 5 * time.Minute
+
+// Idempotency returns a middleware that honours the "Idempotency-Key" request header.
+//
+// The first request with a specific key is executed as usual and its response
+// is stored for the duration of the configured settings idempotency window.
+// Subsequent requests with the same key and payload replay the stored response
+// without executing the handler, while requests that reuse the key with
+// a different payload are rejected with 409 Conflict error.
+//
+// Error responses are not stored and requests that end with an error
+// or with 5xx status code could be retried with the same key.
+//
+// Note that the middleware reads the entire request body so make sure
+// to register it after the body limit middleware.
+func Idempotency() *hook.Handler[*core.RequestEvent] {
+	return &hook.Handler[*core.RequestEvent]{
+		Id:       DefaultIdempotencyMiddlewareId,
+		Priority: DefaultIdempotencyMiddlewarePriority,
+		Func: func(e *core.RequestEvent) error {
+			return handleIdempotentRequest(e, e.Next)
+		},
+	}
+}
+
+func handleIdempotentRequest(e *core.RequestEvent, next func() error) error {
+	key := e.Request.Header.Get(IdempotencyKeyHeader)
+	window := e.App.Settings().Idempotency.Window
+	if key == "" || window <= 0 {
+		return next()
+	}
+
+	if len(key) > idempotencyKeyMaxLength {
+		return e.BadRequestError("Invalid "+IdempotencyKeyHeader+" header value.", nil)
+	}
+
+	fingerprint, err := idempotencyFingerprint(e.Request)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to read the request body.", err))
+	}
+
+	var auth string
+	if e.Auth != nil {
+		auth = e.Auth.Collection().Id + "/" + e.Auth.Id
+	}
+
+	entry, existing, err := reserveIdempotencyKey(e.App, auth, key, fingerprint, time.Duration(window)*time.Second)
+	if err != nil {
+		return e.InternalServerError("Failed to store the "+IdempotencyKeyHeader+" request.", err)
+	}
+
+	if existing != nil {
+		return replayIdempotentResponse(e, existing, fingerprint)
+	}
+
+	original := e.Response
+	recorder := &idempotencyResponseWriter{ResponseWriter: original}
+	e.Response = recorder
+
+	err = next()
+
+	e.Response = original
+
+	// release the key to allow retrying the failed request
+	if err != nil || recorder.status == 0 || recorder.status >= 500 {
+		if deleteErr := e.App.AuxDelete(entry); deleteErr != nil {
+			e.App.Logger().Warn("Failed to release the idempotency key", "error", deleteErr, "key", key)
+		}
+
+		return err
+	}
+
+	entry.Status = core.IdempotencyKeyStatusCompleted
+	entry.ResponseStatus = recorder.status
+	entry.ResponseHeaders = idempotencyResponseHeaders(recorder.header)
+	entry.ResponseBody = recorder.body.String()
+	entry.Updated = types.NowDateTime()
+	if saveErr := e.App.AuxSaveNoValidate(entry); saveErr != nil {
+		e.App.Logger().Warn("Failed to store the idempotency key response", "error", saveErr, "key", key)
+	}
+
+	return nil
+}
+
+// reserveIdempotencyKey inserts a new pending idempotency key entry.
+//
+// If a non-expired entry with the same auth and key already exists,
+// it is returned as second result.
+func reserveIdempotencyKey(
+	app core.App,
+	auth string,
+	key string,
+	fingerprint string,
+	window time.Duration,
+) (*core.IdempotencyKey, *core.IdempotencyKey, error) {
+	var insertErr error
+
+	// note: retry once in case an expired or abandoned entry was deleted
+	for range 2 {
+		now := types.NowDateTime()
+
+		existing, err := app.FindIdempotencyKey(auth, key)
+		if err == nil {
+			isExpired := existing.Created.Time().Before(now.Time().Add(-window))
+			isAbandoned := existing.Status == core.IdempotencyKeyStatusPending &&
+				existing.Updated.Time().Before(now.Time().Add(-idempotencyPendingTimeout))
+
+			if !isExpired && !isAbandoned {
+				return nil, existing, nil
+			}
+
+			if err := app.AuxDelete(existing); err != nil {
+				return nil, nil, err
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, err
+		}
+
+		entry := &core.IdempotencyKey{}
+		entry.Id = core.GenerateDefaultRandomId()
+		entry.Key = key
+		entry.Auth = auth
+		entry.Fingerprint = fingerprint
+		entry.Status = core.IdempotencyKeyStatusPending
+		entry.Created = now
+		entry.Updated = now
+
+		// note: could fail with unique constraint error in case of a concurrent request with the same key
+		insertErr = app.AuxSaveNoValidate(entry)
+		if insertErr == nil {
+			return entry, nil, nil
+		}
+	}
+
+	return nil, nil, insertErr
+}
+
+func replayIdempotentResponse(e *core.RequestEvent, existing *core.IdempotencyKey, fingerprint string) error {
+	if existing.Fingerprint != fingerprint {
+		return router.NewApiError(
+			http.StatusConflict,
+			"The "+IdempotencyKeyHeader+" has been already used with a different request.",
+			nil,
+		)
+	}
+
+	if existing.Status != core.IdempotencyKeyStatusCompleted {
+		return router.NewApiError(
+			http.StatusConflict,
+			"A request with the same "+IdempotencyKeyHeader+" is still being processed.",
+			nil,
+		)
+	}
+
+	// note: the headers that are already set by the previous middlewares
+	// for the current request (eg. CORS) are left untouched
+	header := e.Response.Header()
+	for name, values := range existing.ResponseHeaders {
+		if _, ok := header[name]; !ok {
+			header[name] = values
+		}
+	}
+	header.Set(IdempotencyReplayedHeader, "true")
+
+	e.Response.WriteHeader(existing.ResponseStatus)
+	_, err := io.WriteString(e.Response, existing.ResponseBody)
+
+	return err
+}
+
+// idempotencyResponseHeaders returns a copy of the provided response
+// headers without the hop-by-hop ones.
+func idempotencyResponseHeaders(header http.Header) types.JSONMap[[]string] {
+	result := make(types.JSONMap[[]string], len(header))
+
+	for name, values := range header {
+		result[name] = slices.Clone(values)
+	}
+
+	// remove the headers listed in the Connection header
+	for _, v := range header.Values("Connection") {
+		for name := range strings.SplitSeq(v, ",") {
+			delete(result, http.CanonicalHeaderKey(strings.TrimSpace(name)))
+		}
+	}
+
+	for _, name := range idempotencyHopHeaders {
+		delete(result, name)
+	}
+
+	return result
+}
+
+// idempotencyFingerprint returns a hash of the request method, uri and body.
+//
+// The multipart/form-data boundary is excluded from the hash because
+// it is usually randomly generated by the client on each submit.
+func idempotencyFingerprint(r *http.Request) (string, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// rewind the body for the next handlers
+	if rr, ok := r.Body.(router.Rereader); ok {
+		rr.Reread()
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if boundary := params["boundary"]; boundary != "" {
+		body = bytes.ReplaceAll(body, []byte(boundary), nil)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n" + mediaType + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+var _ router.RWUnwrapper = (*idempotencyResponseWriter)(nil)
+
+// idempotencyResponseWriter is a response writer that keeps
+// a copy of the written response status, headers and body.
+type idempotencyResponseWriter struct {
+	http.ResponseWriter
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func (w *idempotencyResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+		w.header = w.Header().Clone()
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+		w.header = w.Header().Clone()
+	}
+
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
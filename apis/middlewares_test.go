@@ -1,12 +1,17 @@
 package apis_test
 
 import (
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
 )
 
 func TestPanicRecover(t *testing.T) {
@@ -537,3 +542,127 @@ func TestRequireSameCollectionContextAuth(t *testing.T) {
 		scenario.Test(t)
 	}
 }
+
+func TestActivityLoggerServerTiming(t *testing.T) {
+	t.Parallel()
+
+	beforeTestFunc := func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		e.Router.GET("/my/test", func(e *core.RequestEvent) error {
+			return e.String(http.StatusOK, "test")
+		}).Bind(&hook.Handler[*core.RequestEvent]{
+			Id:       "myMiddleware",
+			Priority: -2000,
+			Func: func(e *core.RequestEvent) error {
+				return e.Next()
+			},
+		})
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "non-dev mode",
+			Method:          http.MethodGet,
+			URL:             "/my/test",
+			BeforeTestFunc:  beforeTestFunc,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test"},
+			ExpectedEvents:  map[string]int{"*": 0},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if v := res.Header.Get("Server-Timing"); v != "" {
+					t.Fatalf("Expected no Server-Timing header, got %q", v)
+				}
+			},
+		},
+		{
+			Name:   "dev mode",
+			Method: http.MethodGet,
+			URL:    "/my/test",
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app, err := tests.NewTestAppWithConfig(core.BaseAppConfig{IsDev: true})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return app
+			},
+			BeforeTestFunc:  beforeTestFunc,
+			ExpectedStatus:  200,
+			ExpectedContent: []string{"test"},
+			ExpectedEvents:  map[string]int{"*": 0},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				timing := res.Header.Get("Server-Timing")
+
+				expected := []string{
+					apis.DefaultActivityLoggerMiddlewareId + `;desc="priority -65536"`,
+					apis.DefaultRateLimitMiddlewareId + `;desc="priority -1000"`,
+					`myMiddleware;desc="priority -2000"`,
+					router.TraceHandlerId + `;desc="priority 0"`,
+				}
+				for _, v := range expected {
+					if !strings.Contains(timing, v) {
+						t.Fatalf("Missing %q in Server-Timing header %q", v, timing)
+					}
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestActivityLoggerServerTimingHijack(t *testing.T) {
+	t.Parallel()
+
+	app, err := tests.NewTestAppWithConfig(core.BaseAppConfig{IsDev: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Cleanup()
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pbRouter.GET("/my/test", func(e *core.RequestEvent) error {
+		// most websocket and proxy libraries check directly for the interface
+		hijacker, ok := e.Response.(http.Hijacker)
+		if !ok {
+			return e.InternalServerError("The response writer doesn't implement http.Hijacker.", nil)
+		}
+
+		conn, buf, err := hijacker.Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+
+		return buf.Flush()
+	})
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/my/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusOK || string(body) != "hijacked" {
+		t.Fatalf("Expected the hijacked response, got %d %q", res.StatusCode, body)
+	}
+}
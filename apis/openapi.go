@@ -0,0 +1,1127 @@
+package apis
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// OpenAPIVersion is the OpenAPI specification version of the generated document.
+const OpenAPIVersion = "3.1.0"
+
+// bindOpenAPIApi registers the OpenAPI document api endpoint.
+//
+// The document is generated on request from the current router routes
+// (including the ones registered with OnServe) and the app collections.
+func bindOpenAPIApi(app core.App, r *router.Router[*core.RequestEvent], rg *router.RouterGroup[*core.RequestEvent]) {
+	rg.GET("/openapi.json", func(e *core.RequestEvent) error {
+		doc, err := NewOpenAPIDocument(e.App, r)
+		if err != nil {
+			return e.InternalServerError("Failed to generate the OpenAPI document.", err)
+		}
+
+		return e.JSON(http.StatusOK, doc)
+	}).Bind(RequireSuperuserAuth())
+}
+
+// NewOpenAPIDocument generates an OpenAPI 3.1 document from the
+// provided router routes and the app collections.
+//
+// The collection specific routes (records crud, auth, etc.) are expanded
+// for each collection with their request and response schemas.
+// All other routes, including the custom user defined ones, are listed
+// with their path parameters and a generic response.
+func NewOpenAPIDocument(app core.App, r *router.Router[*core.RequestEvent]) (map[string]any, error) {
+	collections, err := app.FindAllCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := map[string]any{
+		"Error":               openAPIErrorSchema,
+		"AuthMethodsResponse": openAPIAuthMethodsSchema,
+	}
+	for _, c := range collections {
+		schemas[openAPISchemaName(c, "Record")] = openAPIRecordSchema(c, collections)
+		if !c.IsView() {
+			schemas[openAPISchemaName(c, "CreateRequest")] = openAPIRecordRequestSchema(c, true)
+			schemas[openAPISchemaName(c, "UpdateRequest")] = openAPIRecordRequestSchema(c, false)
+		}
+		if c.IsAuth() {
+			schemas[openAPISchemaName(c, "AuthResponse")] = map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"token":  map[string]any{"type": "string"},
+					"record": openAPIRef(openAPISchemaName(c, "Record")),
+					"meta":   map[string]any{"type": "object"},
+				},
+				"required": []string{"token", "record"},
+			}
+		}
+	}
+
+	paths := map[string]any{}
+	tags := []string{}
+
+	addOperation := func(path string, method string, op map[string]any) {
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(method)] = op
+
+		if opTags, _ := op["tags"].([]string); len(opTags) > 0 && !slices.Contains(tags, opTags[0]) {
+			tags = append(tags, opTags[0])
+		}
+	}
+
+	err = r.Walk(func(route *router.Route[*core.RequestEvent], path string, middlewares []*hook.Handler[*core.RequestEvent]) {
+		// skip the catch-all, CONNECT and host specific routes
+		// since they cannot be described as regular OpenAPI operations
+		if route.Method == "" || route.Method == http.MethodConnect || !strings.HasPrefix(path, "/") {
+			return
+		}
+
+		if template, ok := openAPICollectionOperations[route.Method+" "+path]; ok {
+			for _, c := range collections {
+				op := template(c)
+				if op == nil {
+					continue
+				}
+
+				op["tags"] = []string{c.Name}
+
+				addOperation(strings.ReplaceAll(openAPIPath(path), "{collection}", c.Name), route.Method, op)
+			}
+			return
+		}
+
+		addOperation(openAPIPath(path), route.Method, openAPIGenericOperation(route.Method, path, middlewares))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	docTags := make([]map[string]any, len(tags))
+	for i, t := range tags {
+		docTags[i] = map[string]any{"name": t}
+	}
+
+	doc := map[string]any{
+		"openapi": OpenAPIVersion,
+		"info": map[string]any{
+			"title":   app.Settings().Meta.AppName + " API",
+			"version": "1.0.0",
+		},
+		"tags":  docTags,
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"authToken": map[string]any{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "The auth record token (optionally prefixed with \"Bearer \").",
+				},
+			},
+		},
+	}
+
+	if appURL := app.Settings().Meta.AppURL; appURL != "" {
+		doc["servers"] = []map[string]any{{"url": appURL}}
+	}
+
+	return doc, nil
+}
+
+// -------------------------------------------------------------------
+// Routes
+// -------------------------------------------------------------------
+
+// openAPICollectionOperations holds the collection specific route
+// patterns and their related operation factories.
+//
+// The factory must return nil if the operation is not applicable for the collection.
+var openAPICollectionOperations = map[string]func(c *core.Collection) map[string]any{
+	"GET /api/collections/{collection}/records":         openAPIRecordsListOperation,
+	"GET /api/collections/{collection}/aggregate":       openAPIRecordsAggregateOperation,
+	"GET /api/collections/{collection}/records/{id}":    openAPIRecordViewOperation,
+	"POST /api/collections/{collection}/records":        openAPIRecordCreateOperation,
+	"PATCH /api/collections/{collection}/records/{id}":  openAPIRecordUpdateOperation,
+	"DELETE /api/collections/{collection}/records/{id}": openAPIRecordDeleteOperation,
+
+	"POST /api/collections/{collection}/records/{id}/restore": openAPIRecordRestoreOperation,
+	"DELETE /api/collections/{collection}/records/{id}/purge": openAPIRecordPurgeOperation,
+
+	"GET /api/collections/{collection}/records/{id}/history":                    openAPIRecordHistoryListOperation,
+	"GET /api/collections/{collection}/records/{id}/history/diff":               openAPIRecordHistoryDiffOperation,
+	"POST /api/collections/{collection}/records/{id}/history/{version}/restore": openAPIRecordHistoryRestoreOperation,
+
+	"GET /api/collections/{collection}/auth-methods": openAPIAuthOperation("authMethods", "List the available auth methods",
+		"", nil, openAPIJSONResponse("Auth methods", openAPIRef("AuthMethodsResponse"))),
+	"POST /api/collections/{collection}/auth-refresh": openAPIAuthOperation("authRefresh", "Refresh the auth token",
+		"Requires a valid auth token from the same collection.", nil, nil),
+	"POST /api/collections/{collection}/auth-with-password": openAPIAuthOperation("authWithPassword", "Authenticate with identity and password",
+		"", openAPIObject([]string{"identity", "password"}, map[string]any{
+			"identity":      map[string]any{"type": "string"},
+			"password":      map[string]any{"type": "string", "format": "password"},
+			"identityField": map[string]any{"type": "string"},
+		}), nil),
+	"POST /api/collections/{collection}/auth-with-oauth2": openAPIAuthOperation("authWithOAuth2", "Authenticate with an OAuth2 provider",
+		"", openAPIObject([]string{"provider", "code", "redirectURL"}, map[string]any{
+			"provider":     map[string]any{"type": "string"},
+			"code":         map[string]any{"type": "string"},
+			"codeVerifier": map[string]any{"type": "string"},
+			"redirectURL":  map[string]any{"type": "string"},
+			"createData":   map[string]any{"type": "object"},
+		}), nil),
+	"POST /api/collections/{collection}/request-otp": openAPIAuthOperation("requestOTP", "Request a one-time password",
+		"", openAPIObject([]string{"email"}, map[string]any{
+			"email": map[string]any{"type": "string", "format": "email"},
+		}), openAPIJSONResponse("The OTP request id", openAPIObject([]string{"otpId"}, map[string]any{
+			"otpId": map[string]any{"type": "string"},
+		}))),
+	"POST /api/collections/{collection}/auth-with-otp": openAPIAuthOperation("authWithOTP", "Authenticate with a one-time password",
+		"", openAPIObject([]string{"otpId", "password"}, map[string]any{
+			"otpId":    map[string]any{"type": "string"},
+			"password": map[string]any{"type": "string", "format": "password"},
+		}), nil),
+	"POST /api/collections/{collection}/request-password-reset": openAPIAuthOperation("requestPasswordReset", "Send a password reset email",
+		"", openAPIObject([]string{"email"}, map[string]any{
+			"email": map[string]any{"type": "string", "format": "email"},
+		}), openAPINoContentResponse),
+	"POST /api/collections/{collection}/confirm-password-reset": openAPIAuthOperation("confirmPasswordReset", "Confirm a password reset",
+		"", openAPIObject([]string{"token", "password", "passwordConfirm"}, map[string]any{
+			"token":           map[string]any{"type": "string"},
+			"password":        map[string]any{"type": "string", "format": "password"},
+			"passwordConfirm": map[string]any{"type": "string", "format": "password"},
+		}), openAPINoContentResponse),
+	"POST /api/collections/{collection}/request-verification": openAPIAuthOperation("requestVerification", "Send a verification email",
+		"", openAPIObject([]string{"email"}, map[string]any{
+			"email": map[string]any{"type": "string", "format": "email"},
+		}), openAPINoContentResponse),
+	"POST /api/collections/{collection}/confirm-verification": openAPIAuthOperation("confirmVerification", "Confirm an email verification",
+		"", openAPIObject([]string{"token"}, map[string]any{
+			"token": map[string]any{"type": "string"},
+		}), openAPINoContentResponse),
+	"POST /api/collections/{collection}/request-email-change": openAPIAuthOperation("requestEmailChange", "Send an email change confirmation",
+		"Requires a valid auth token from the same collection.", openAPIObject([]string{"newEmail"}, map[string]any{
+			"newEmail": map[string]any{"type": "string", "format": "email"},
+		}), openAPINoContentResponse),
+	"POST /api/collections/{collection}/confirm-email-change": openAPIAuthOperation("confirmEmailChange", "Confirm an email change",
+		"", openAPIObject([]string{"token", "password"}, map[string]any{
+			"token":    map[string]any{"type": "string"},
+			"password": map[string]any{"type": "string", "format": "password"},
+		}), openAPINoContentResponse),
+	"POST /api/collections/{collection}/impersonate/{id}": openAPIAuthOperation("impersonate", "Impersonate an auth record",
+		"Superusers only.", openAPIObject(nil, map[string]any{
+			"duration": map[string]any{"type": "integer", "description": "Optional custom token duration in seconds."},
+		}), nil),
+}
+
+var openAPIPathWildcardRegex = regexp.MustCompile(`\{(\w+)(\.\.\.)?\}`)
+
+// openAPIPath normalizes a std ServeMux path pattern to an OpenAPI path template.
+func openAPIPath(path string) string {
+	path = strings.TrimSuffix(path, "{$}")
+
+	return openAPIPathWildcardRegex.ReplaceAllString(path, "{$1}")
+}
+
+func openAPIPathParams(path string) []map[string]any {
+	matches := openAPIPathWildcardRegex.FindAllStringSubmatch(path, -1)
+
+	params := make([]map[string]any, 0, len(matches))
+	for _, m := range matches {
+		params = append(params, map[string]any{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "string"},
+		})
+	}
+
+	return params
+}
+
+var openAPINonWordRegex = regexp.MustCompile(`\W+`)
+
+func openAPIGenericOperation(method string, path string, middlewares []*hook.Handler[*core.RequestEvent]) map[string]any {
+	// use the first non-api path segment as tag (eg. /api/logs/{id} -> logs)
+	tag := strings.Trim(openAPIPathWildcardRegex.ReplaceAllString(path, ""), "/")
+	tag = strings.TrimPrefix(tag, "api/")
+	tag, _, _ = strings.Cut(tag, "/")
+	if tag == "" {
+		tag = "default"
+	}
+
+	op := map[string]any{
+		"operationId": strings.ToLower(method) + "_" + strings.Trim(openAPINonWordRegex.ReplaceAllString(path, "_"), "_"),
+		"summary":     method + " " + path,
+		"tags":        []string{tag},
+		"responses": map[string]any{
+			"default": map[string]any{"description": "Response"},
+		},
+	}
+
+	if params := openAPIPathParams(path); len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	for _, m := range middlewares {
+		switch m.Id {
+		case DefaultRequireSuperuserAuthMiddlewareId:
+			op["description"] = "Superusers only."
+			op["security"] = []map[string]any{{"authToken": []string{}}}
+		case DefaultRequireAuthMiddlewareId,
+			DefaultRequireSuperuserOrOwnerAuthMiddlewareId,
+			DefaultRequireSameCollectionContextAuthMiddlewareId:
+			op["description"] = "Requires a valid auth token."
+			op["security"] = []map[string]any{{"authToken": []string{}}}
+		case DefaultRequireGuestOnlyMiddlewareId:
+			op["description"] = "Guests only."
+			op["security"] = []map[string]any{}
+		}
+	}
+
+	return op
+}
+
+// openAPIRuleSecurity sets the operation security and description
+// based on the provided collection API rule.
+func openAPIRuleSecurity(op map[string]any, rule *string) {
+	var note string
+
+	switch {
+	case rule == nil:
+		note = "Superusers only."
+		op["security"] = []map[string]any{{"authToken": []string{}}}
+	case *rule == "":
+		note = "Public (no auth required)."
+		op["security"] = []map[string]any{{}, {"authToken": []string{}}}
+	default:
+		note = fmt.Sprintf("Requires the API rule `%s` to be satisfied (superusers are always allowed).", *rule)
+		op["security"] = []map[string]any{{}, {"authToken": []string{}}}
+	}
+
+	op["x-pocketbase-rule"] = rule
+
+	if desc, _ := op["description"].(string); desc != "" {
+		op["description"] = desc + "\n\n" + note
+	} else {
+		op["description"] = note
+	}
+}
+
+var openAPIIdParam = map[string]any{
+	"name":     "id",
+	"in":       "path",
+	"required": true,
+	"schema":   map[string]any{"type": "string"},
+}
+
+var openAPIExpandParam = map[string]any{
+	"name":        expandQueryParam,
+	"in":          "query",
+	"description": "Comma separated list of relation fields to auto expand (supports up to 6 levels nesting, eg. `rel1,rel2.subRel`).",
+	"schema":      map[string]any{"type": "string"},
+}
+
+var openAPIFieldsParam = map[string]any{
+	"name":        fieldsQueryParam,
+	"in":          "query",
+	"description": "Comma separated list of the fields to return in the JSON response (eg. `*,expand.rel.name,description:excerpt(200,true)`).",
+	"schema":      map[string]any{"type": "string"},
+}
+
+var openAPIIdempotencyKeyParam = map[string]any{
+	"name":        IdempotencyKeyHeader,
+	"in":          "header",
+	"description": "Optional unique client key used to safely retry the request without executing it twice.",
+	"schema":      map[string]any{"type": "string", "maxLength": idempotencyKeyMaxLength},
+}
+
+var openAPIErrorSchema = openAPIObject([]string{"status", "message", "data"}, map[string]any{
+	"status":  map[string]any{"type": "integer"},
+	"message": map[string]any{"type": "string"},
+	"data":    map[string]any{"type": "object"},
+})
+
+var openAPIAuthMethodsSchema = openAPIObject(nil, map[string]any{
+	"password": openAPIObject(nil, map[string]any{
+		"enabled":        map[string]any{"type": "boolean"},
+		"identityFields": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	}),
+	"oauth2": openAPIObject(nil, map[string]any{
+		"enabled": map[string]any{"type": "boolean"},
+		"providers": map[string]any{"type": "array", "items": openAPIObject(nil, map[string]any{
+			"name":                map[string]any{"type": "string"},
+			"displayName":         map[string]any{"type": "string"},
+			"state":               map[string]any{"type": "string"},
+			"authURL":             map[string]any{"type": "string"},
+			"codeVerifier":        map[string]any{"type": "string"},
+			"codeChallenge":       map[string]any{"type": "string"},
+			"codeChallengeMethod": map[string]any{"type": "string"},
+		})},
+	}),
+	"mfa": openAPIObject(nil, map[string]any{
+		"enabled":  map[string]any{"type": "boolean"},
+		"duration": map[string]any{"type": "integer"},
+	}),
+	"otp": openAPIObject(nil, map[string]any{
+		"enabled":  map[string]any{"type": "boolean"},
+		"duration": map[string]any{"type": "integer"},
+	}),
+})
+
+var openAPINoContentResponse = map[string]any{
+	"204": map[string]any{"description": "No content"},
+}
+
+func openAPIErrorResponses(statuses ...int) map[string]any {
+	result := make(map[string]any, len(statuses))
+
+	for _, s := range statuses {
+		result[fmt.Sprint(s)] = map[string]any{
+			"description": http.StatusText(s),
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": openAPIRef("Error")},
+			},
+		}
+	}
+
+	return result
+}
+
+func openAPIJSONResponse(description string, schema any) map[string]any {
+	return map[string]any{
+		"200": map[string]any{
+			"description": description,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schema},
+			},
+		},
+	}
+}
+
+func openAPIRecordsListOperation(c *core.Collection) map[string]any {
+	op := map[string]any{
+		"operationId": c.Name + ".list",
+		"summary":     "List " + c.Name + " records",
+		"parameters": []map[string]any{
+			{"name": "page", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 1, "default": 1}},
+			{"name": "perPage", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 1, "default": 30}},
+			{"name": "sort", "in": "query", "description": "Comma separated list of fields to sort by (eg. `-created,id`). Use `@rank` to sort by the `ftsMatch(field, query)` filter full-text search relevance.", "schema": map[string]any{"type": "string"}},
+			{"name": "filter", "in": "query", "description": "Filter expression to further narrow the returned records (eg. `(title~'abc' && created>'2022-01-01')`).", "schema": map[string]any{"type": "string"}},
+			{"name": "skipTotal", "in": "query", "description": "Skip the total counts query.", "schema": map[string]any{"type": "boolean"}},
+			{"name": "cursor", "in": "query", "description": "Opaque keyset pagination cursor (`nextCursor` or `prevCursor` from a previous response). Pass an empty value to fetch the first page. The `page` parameter is ignored when set.", "schema": map[string]any{"type": "string"}},
+			openAPIExpandParam,
+			openAPIFieldsParam,
+		},
+		"responses": openAPIJSONResponse("Paginated records list", openAPIObject(
+			[]string{"page", "perPage", "totalItems", "totalPages", "items"},
+			map[string]any{
+				"page":       map[string]any{"type": "integer"},
+				"perPage":    map[string]any{"type": "integer"},
+				"totalItems": map[string]any{"type": "integer", "description": "-1 when skipTotal is set."},
+				"totalPages": map[string]any{"type": "integer", "description": "-1 when skipTotal is set."},
+				"items":      map[string]any{"type": "array", "items": openAPIRef(openAPISchemaName(c, "Record"))},
+				"nextCursor": map[string]any{"type": "string", "description": "Cursor of the next page (only with cursor pagination)."},
+				"prevCursor": map[string]any{"type": "string", "description": "Cursor of the previous page (only with cursor pagination)."},
+			},
+		)),
+	}
+
+	if c.SoftDelete {
+		op["parameters"] = append(op["parameters"].([]map[string]any), openAPIWithDeletedParam)
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden))
+	openAPIRuleSecurity(op, c.ListRule)
+
+	return op
+}
+
+func openAPIRecordsAggregateOperation(c *core.Collection) map[string]any {
+	op := map[string]any{
+		"operationId": c.Name + ".aggregate",
+		"summary":     "Aggregate " + c.Name + " records",
+		"parameters": []map[string]any{
+			{"name": "groupBy", "in": "query", "description": "Comma separated list of fields to group by. Date fields could be bucketed with `:hour`, `:day`, `:week`, `:month` or `:year` (eg. `status,created:month`).", "schema": map[string]any{"type": "string"}},
+			{"name": "agg", "in": "query", "description": "Comma separated list of aggregate functions - `count()`, `count(field)`, `sum(field)`, `avg(field)`, `min(field)`, `max(field)`. Default to `count()`.", "schema": map[string]any{"type": "string"}},
+			{"name": "filter", "in": "query", "description": "Filter expression to narrow the aggregated records.", "schema": map[string]any{"type": "string"}},
+			{"name": "sort", "in": "query", "description": "Comma separated list of groupBy or agg expressions to sort by (eg. `-count()`).", "schema": map[string]any{"type": "string"}},
+			{"name": "limit", "in": "query", "description": "Max number of returned groups.", "schema": map[string]any{"type": "integer", "minimum": 1, "maximum": core.MaxRecordsAggregateLimit, "default": core.DefaultRecordsAggregateLimit}},
+		},
+		"responses": openAPIJSONResponse("Aggregated groups", openAPIObject(
+			[]string{"items"},
+			map[string]any{
+				"items": map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+			},
+		)),
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden))
+	openAPIRuleSecurity(op, c.ListRule)
+
+	return op
+}
+
+func openAPIRecordViewOperation(c *core.Collection) map[string]any {
+	op := map[string]any{
+		"operationId": c.Name + ".view",
+		"summary":     "View a single " + c.Name + " record",
+		"parameters":  []map[string]any{openAPIIdParam, openAPIExpandParam, openAPIFieldsParam},
+		"responses":   openAPIJSONResponse("The record", openAPIRef(openAPISchemaName(c, "Record"))),
+	}
+
+	if c.SoftDelete {
+		op["parameters"] = append(op["parameters"].([]map[string]any), openAPIWithDeletedParam)
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, c.ViewRule)
+
+	return op
+}
+
+func openAPIRecordCreateOperation(c *core.Collection) map[string]any {
+	if c.IsView() {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".create",
+		"summary":     "Create a new " + c.Name + " record",
+		"parameters":  []map[string]any{openAPIExpandParam, openAPIFieldsParam, openAPIIdempotencyKeyParam},
+		"requestBody": openAPIRecordRequestBody(c, true),
+		"responses":   openAPIJSONResponse("The created record", openAPIRef(openAPISchemaName(c, "Record"))),
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusConflict))
+	openAPIRuleSecurity(op, c.CreateRule)
+
+	return op
+}
+
+func openAPIRecordUpdateOperation(c *core.Collection) map[string]any {
+	if c.IsView() {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".update",
+		"summary":     "Update a single " + c.Name + " record",
+		"parameters":  []map[string]any{openAPIIdParam, openAPIExpandParam, openAPIFieldsParam},
+		"requestBody": openAPIRecordRequestBody(c, false),
+		"responses":   openAPIJSONResponse("The updated record", openAPIRef(openAPISchemaName(c, "Record"))),
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, c.UpdateRule)
+
+	return op
+}
+
+func openAPIRecordDeleteOperation(c *core.Collection) map[string]any {
+	if c.IsView() {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".delete",
+		"summary":     "Delete a single " + c.Name + " record",
+		"parameters":  []map[string]any{openAPIIdParam},
+		"responses":   openAPINoContentResponse,
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, c.DeleteRule)
+
+	return op
+}
+
+var openAPIWithDeletedParam = map[string]any{
+	"name":        "withDeleted",
+	"in":          "query",
+	"description": "Include the soft deleted records (superusers only).",
+	"schema":      map[string]any{"type": "boolean"},
+}
+
+func openAPIRecordRestoreOperation(c *core.Collection) map[string]any {
+	if !c.SoftDelete || c.IsView() {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".restore",
+		"summary":     "Restore a single soft deleted " + c.Name + " record",
+		"parameters":  []map[string]any{openAPIIdParam},
+		"responses":   openAPIJSONResponse("The restored record", openAPIRef(openAPISchemaName(c, "Record"))),
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, c.DeleteRule)
+
+	return op
+}
+
+func openAPIRecordPurgeOperation(c *core.Collection) map[string]any {
+	if !c.SoftDelete || c.IsView() {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".purge",
+		"summary":     "Permanently delete a single " + c.Name + " record",
+		"parameters":  []map[string]any{openAPIIdParam},
+		"responses":   openAPINoContentResponse,
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, nil)
+
+	return op
+}
+
+var openAPIRecordHistoryEntrySchema = openAPIObject(
+	[]string{"id", "version", "action", "data", "changes", "created"},
+	map[string]any{
+		"id":                map[string]any{"type": "string"},
+		"version":           map[string]any{"type": "integer"},
+		"action":            map[string]any{"type": "string", "enum": []string{core.RecordHistoryActionCreate, core.RecordHistoryActionUpdate, core.RecordHistoryActionDelete}},
+		"data":              map[string]any{"type": "object", "description": "The record fields snapshot (hidden fields are returned only to superusers)."},
+		"changes":           map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"collectionRef":     map[string]any{"type": "string"},
+		"recordRef":         map[string]any{"type": "string"},
+		"authCollectionRef": map[string]any{"type": "string"},
+		"authRef":           map[string]any{"type": "string"},
+		"ip":                map[string]any{"type": "string", "description": "Returned only to superusers."},
+		"created":           map[string]any{"type": "string"},
+	},
+)
+
+func openAPIRecordHistoryListOperation(c *core.Collection) map[string]any {
+	if !c.History {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".historyList",
+		"summary":     "List the version history of a single " + c.Name + " record",
+		"parameters": []map[string]any{
+			openAPIIdParam,
+			{"name": "page", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 1, "default": 1}},
+			{"name": "perPage", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 1, "default": 30}},
+			{"name": "sort", "in": "query", "description": "Comma separated list of fields to sort by (eg. `-version`).", "schema": map[string]any{"type": "string"}},
+			{"name": "filter", "in": "query", "description": "Filter expression to further narrow the returned history entries (eg. `action='update'`).", "schema": map[string]any{"type": "string"}},
+		},
+		"responses": openAPIJSONResponse("Paginated record history list", openAPIObject(
+			[]string{"page", "perPage", "totalItems", "totalPages", "items"},
+			map[string]any{
+				"page":       map[string]any{"type": "integer"},
+				"perPage":    map[string]any{"type": "integer"},
+				"totalItems": map[string]any{"type": "integer"},
+				"totalPages": map[string]any{"type": "integer"},
+				"items":      map[string]any{"type": "array", "items": openAPIRecordHistoryEntrySchema},
+			},
+		)),
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, c.HistoryRule)
+
+	return op
+}
+
+func openAPIRecordHistoryDiffOperation(c *core.Collection) map[string]any {
+	if !c.History {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".historyDiff",
+		"summary":     "Diff 2 versions of a single " + c.Name + " record",
+		"parameters": []map[string]any{
+			openAPIIdParam,
+			{"name": "from", "in": "query", "required": true, "schema": map[string]any{"type": "integer"}},
+			{"name": "to", "in": "query", "required": true, "schema": map[string]any{"type": "integer"}},
+		},
+		"responses": openAPIJSONResponse("The changed fields", openAPIObject(
+			[]string{"from", "to", "changes"},
+			map[string]any{
+				"from": map[string]any{"type": "integer"},
+				"to":   map[string]any{"type": "integer"},
+				"changes": map[string]any{
+					"type": "object",
+					"additionalProperties": openAPIObject([]string{"from", "to"}, map[string]any{
+						"from": map[string]any{},
+						"to":   map[string]any{},
+					}),
+				},
+			},
+		)),
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, c.HistoryRule)
+
+	return op
+}
+
+func openAPIRecordHistoryRestoreOperation(c *core.Collection) map[string]any {
+	if !c.History {
+		return nil
+	}
+
+	op := map[string]any{
+		"operationId": c.Name + ".historyRestore",
+		"summary":     "Restore a single " + c.Name + " record to a previous version",
+		"parameters": []map[string]any{
+			openAPIIdParam,
+			{"name": "version", "in": "path", "required": true, "schema": map[string]any{"type": "integer"}},
+		},
+		"responses": openAPIJSONResponse("The restored record", openAPIRef(openAPISchemaName(c, "Record"))),
+	}
+
+	openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound))
+	openAPIRuleSecurity(op, c.HistoryRule)
+
+	return op
+}
+
+// openAPIAuthOperation returns an auth collection operation factory.
+//
+// If description is set, the operation is marked as requiring an auth token.
+// If responses is nil, it fallbacks to the collection auth response.
+func openAPIAuthOperation(name string, summary string, description string, body map[string]any, responses map[string]any) func(c *core.Collection) map[string]any {
+	return func(c *core.Collection) map[string]any {
+		if !c.IsAuth() {
+			return nil
+		}
+
+		op := map[string]any{
+			"operationId": c.Name + "." + name,
+			"summary":     summary,
+		}
+
+		if description != "" {
+			op["description"] = description
+
+			// all described auth operations require an auth token
+			op["security"] = []map[string]any{{"authToken": []string{}}}
+		}
+
+		if name == "impersonate" {
+			op["parameters"] = []map[string]any{openAPIIdParam}
+		}
+
+		if body != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json":    map[string]any{"schema": body},
+					"multipart/form-data": map[string]any{"schema": body},
+				},
+			}
+		}
+
+		if responses != nil {
+			op["responses"] = responses
+		} else {
+			op["responses"] = openAPIJSONResponse("Auth token and record", openAPIRef(openAPISchemaName(c, "AuthResponse")))
+		}
+
+		openAPIMergeResponses(op, openAPIErrorResponses(http.StatusBadRequest))
+
+		return op
+	}
+}
+
+func openAPIMergeResponses(op map[string]any, responses map[string]any) {
+	existing, _ := op["responses"].(map[string]any)
+
+	merged := make(map[string]any, len(existing)+len(responses))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range responses {
+		merged[k] = v
+	}
+
+	op["responses"] = merged
+}
+
+// -------------------------------------------------------------------
+// Schemas
+// -------------------------------------------------------------------
+
+var openAPISchemaNameRegex = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// openAPISchemaName returns a component schema name for the collection
+// (eg. "posts" and "Record" -> "PostsRecord").
+func openAPISchemaName(c *core.Collection, suffix string) string {
+	var name strings.Builder
+
+	for _, part := range openAPISchemaNameRegex.Split(c.Name, -1) {
+		if part == "" {
+			continue
+		}
+		name.WriteString(strings.ToUpper(part[:1]))
+		name.WriteString(part[1:])
+	}
+
+	name.WriteString(suffix)
+
+	return name.String()
+}
+
+func openAPIRef(schemaName string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + schemaName}
+}
+
+func openAPIObject(required []string, properties map[string]any) map[string]any {
+	obj := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+
+	return obj
+}
+
+// openAPIRecordSchema returns the JSON schema of a single collection record API response.
+func openAPIRecordSchema(c *core.Collection, collections []*core.Collection) map[string]any {
+	properties := map[string]any{
+		core.FieldNameCollectionId:   map[string]any{"type": "string", "const": c.Id},
+		core.FieldNameCollectionName: map[string]any{"type": "string", "const": c.Name},
+	}
+	required := []string{core.FieldNameCollectionId, core.FieldNameCollectionName}
+
+	expand := map[string]any{}
+
+	for _, f := range c.Fields {
+		if f.GetHidden() {
+			continue
+		}
+
+		properties[f.GetName()] = openAPIFieldSchema(f, false)
+		if f.GetName() != core.FieldNameEmail || !c.IsAuth() {
+			// the auth email is returned only to the owner or if emailVisibility is set
+			required = append(required, f.GetName())
+		}
+
+		if relation, ok := f.(*core.RelationField); ok {
+			var relSchema map[string]any
+			for _, rc := range collections {
+				if rc.Id == relation.CollectionId {
+					relSchema = openAPIRef(openAPISchemaName(rc, "Record"))
+					break
+				}
+			}
+			if relSchema == nil {
+				relSchema = map[string]any{"type": "object"}
+			}
+
+			if relation.IsMultiple() {
+				expand[f.GetName()] = map[string]any{"type": "array", "items": relSchema}
+			} else {
+				expand[f.GetName()] = relSchema
+			}
+		}
+	}
+
+	properties[core.FieldNameExpand] = map[string]any{
+		"type":                 "object",
+		"description":          "The expanded relations (available only when the expand query parameter is set). Back-relations are also allowed in the format `collection_via_field`.",
+		"properties":           expand,
+		"additionalProperties": true,
+	}
+
+	return map[string]any{
+		"type":        "object",
+		"title":       c.Name,
+		"description": fmt.Sprintf("A single %q %s collection record.", c.Name, c.Type),
+		"properties":  properties,
+		"required":    required,
+	}
+}
+
+// openAPIRecordRequestSchema returns the JSON schema of a single collection record create or update request body.
+func openAPIRecordRequestSchema(c *core.Collection, isCreate bool) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for _, f := range c.Fields {
+		// autodate fields are always autopopulated
+		if f.Type() == core.FieldTypeAutodate {
+			continue
+		}
+
+		properties[f.GetName()] = openAPIFieldSchema(f, true)
+
+		if isCreate && openAPIIsFieldRequired(f) {
+			required = append(required, f.GetName())
+		}
+	}
+
+	if c.IsAuth() {
+		properties["passwordConfirm"] = map[string]any{"type": "string", "format": "password", "writeOnly": true}
+		if isCreate {
+			required = append(required, "passwordConfirm")
+		} else {
+			properties["oldPassword"] = map[string]any{
+				"type":        "string",
+				"format":      "password",
+				"writeOnly":   true,
+				"description": "Required when changing the password of a non-superuser auth record.",
+			}
+		}
+	}
+
+	schema := map[string]any{
+		"type":        "object",
+		"properties":  properties,
+		"description": "Besides the listed fields, multi-valued fields also support the `field+` (append) and `field-` (remove) modifiers.",
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func openAPIRecordRequestBody(c *core.Collection, isCreate bool) map[string]any {
+	suffix := "UpdateRequest"
+	if isCreate {
+		suffix = "CreateRequest"
+	}
+
+	return map[string]any{
+		"required": isCreate,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": openAPIRef(openAPISchemaName(c, suffix)),
+			},
+			"multipart/form-data": map[string]any{
+				"schema": openAPIRef(openAPISchemaName(c, suffix)),
+			},
+		},
+	}
+}
+
+func openAPIIsFieldRequired(f core.Field) bool {
+	switch v := f.(type) {
+	case *core.TextField:
+		return v.Required && v.AutogeneratePattern == ""
+	case *core.PasswordField:
+		return v.Required
+	case *core.EmailField:
+		return v.Required
+	case *core.URLField:
+		return v.Required
+	case *core.EditorField:
+		return v.Required
+	case *core.NumberField:
+		return v.Required
+	case *core.BoolField:
+		return v.Required
+	case *core.DateField:
+		return v.Required
+	case *core.SelectField:
+		return v.Required
+	case *core.FileField:
+		return v.Required
+	case *core.RelationField:
+		return v.Required
+	case *core.JSONField:
+		return v.Required
+	case *core.GeoPointField:
+		return v.Required
+	case *core.GeoShapeField:
+		return v.Required
+	case *core.EncryptedField:
+		return v.Required
+	case *core.VectorField:
+		return v.Required
+	}
+
+	return false
+}
+
+// openAPIFieldSchema returns the JSON schema of a single collection field value.
+//
+// isRequest indicates whether the schema is for a request body
+// (eg. file fields are described as binary uploads).
+func openAPIFieldSchema(f core.Field, isRequest bool) map[string]any {
+	var schema map[string]any
+
+	switch v := f.(type) {
+	case *core.TextField:
+		schema = map[string]any{"type": "string"}
+		if v.Min > 0 {
+			schema["minLength"] = v.Min
+		}
+		if v.Max > 0 {
+			schema["maxLength"] = v.Max
+		} else {
+			schema["maxLength"] = 5000
+		}
+		if v.Pattern != "" {
+			schema["pattern"] = v.Pattern
+		}
+		if v.PrimaryKey && isRequest {
+			schema["description"] = "Autogenerated if not set."
+		}
+	case *core.EditorField:
+		schema = map[string]any{"type": "string", "contentMediaType": "text/html"}
+	case *core.NumberField:
+		if v.OnlyInt {
+			schema = map[string]any{"type": "integer"}
+		} else {
+			schema = map[string]any{"type": "number"}
+		}
+		if v.Min != nil {
+			schema["minimum"] = *v.Min
+		}
+		if v.Max != nil {
+			schema["maximum"] = *v.Max
+		}
+	case *core.BoolField:
+		schema = map[string]any{"type": "boolean"}
+	case *core.EmailField:
+		schema = map[string]any{
+			"type":  "string",
+			"anyOf": []map[string]any{{"format": "email"}, {"const": ""}},
+		}
+	case *core.URLField:
+		schema = map[string]any{
+			"type":  "string",
+			"anyOf": []map[string]any{{"format": "uri"}, {"const": ""}},
+		}
+	case *core.DateField, *core.AutodateField:
+		schema = map[string]any{
+			"type":        "string",
+			"description": "UTC datetime string in the format `Y-m-d H:i:s.uZ` (or empty string if not set).",
+			"examples":    []string{"2024-01-01 10:00:00.123Z"},
+		}
+		if _, ok := v.(*core.AutodateField); ok {
+			schema["readOnly"] = true
+		}
+	case *core.PasswordField:
+		schema = map[string]any{"type": "string", "format": "password", "writeOnly": true}
+		if v.Min > 0 {
+			schema["minLength"] = v.Min
+		}
+		if v.Max > 0 {
+			schema["maxLength"] = v.Max
+		}
+		if v.Pattern != "" {
+			schema["pattern"] = v.Pattern
+		}
+	case *core.SelectField:
+		values := make([]any, 0, len(v.Values)+1)
+		for _, val := range v.Values {
+			values = append(values, val)
+		}
+
+		if v.IsMultiple() {
+			schema = map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string", "enum": values},
+				"uniqueItems": true,
+				"maxItems":    v.MaxSelect,
+			}
+		} else {
+			if !v.Required {
+				values = append(values, "")
+			}
+			schema = map[string]any{"type": "string", "enum": values}
+		}
+	case *core.FileField:
+		item := map[string]any{"type": "string", "description": "The stored file name."}
+		if isRequest {
+			item = map[string]any{"type": "string", "contentMediaType": "application/octet-stream", "description": "New file upload (multipart/form-data only) or an existing file name."}
+		}
+
+		if v.IsMultiple() {
+			maxSelect := v.MaxSelect
+			if maxSelect <= 0 {
+				maxSelect = 1
+			}
+			schema = map[string]any{"type": "array", "items": item, "maxItems": maxSelect}
+		} else {
+			schema = item
+		}
+	case *core.RelationField:
+		item := map[string]any{"type": "string", "description": "The related record id."}
+
+		if v.IsMultiple() {
+			schema = map[string]any{"type": "array", "items": item, "uniqueItems": true}
+			if v.MinSelect > 0 {
+				schema["minItems"] = v.MinSelect
+			}
+			if v.MaxSelect > 0 {
+				schema["maxItems"] = v.MaxSelect
+			}
+		} else {
+			schema = item
+		}
+		schema["x-collection-id"] = v.CollectionId
+	case *core.JSONField:
+		schema = map[string]any{"description": "Any serialized JSON value."}
+	case *core.GeoPointField:
+		schema = openAPIObject([]string{"lon", "lat"}, map[string]any{
+			"lon": map[string]any{"type": "number", "minimum": -180, "maximum": 180},
+			"lat": map[string]any{"type": "number", "minimum": -90, "maximum": 90},
+		})
+	case *core.GeoShapeField:
+		schema = openAPIObject([]string{"type", "coordinates"}, map[string]any{
+			"type": map[string]any{
+				"type": "string",
+				"enum": []string{types.GeoShapeTypeLineString, types.GeoShapeTypePolygon, types.GeoShapeTypeMultiPolygon},
+			},
+			"coordinates": map[string]any{"type": "array", "description": "GeoJSON geometry [lon, lat] positions."},
+			"bbox": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "number"},
+				"description": "The cached [minLon, minLat, maxLon, maxLat] bounding box (ignored on save).",
+			},
+		})
+	case *core.EncryptedField:
+		if v.IsJSON() {
+			schema = map[string]any{"description": "Any serialized JSON value (encrypted at rest)."}
+		} else {
+			schema = map[string]any{"type": "string", "description": "Plain text value (encrypted at rest)."}
+		}
+	case *core.VectorField:
+		schema = map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "number"},
+			"maxItems":    v.Dimensions,
+			"description": fmt.Sprintf("Embedding vector with exactly %d numbers (or empty array).", v.Dimensions),
+		}
+		if v.Required {
+			schema["minItems"] = v.Dimensions
+		}
+	default:
+		schema = map[string]any{}
+	}
+
+	schema["x-field-type"] = f.Type()
+
+	return schema
+}
@@ -8,17 +8,19 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/picker"
 	"github.com/pocketbase/pocketbase/tools/router"
-	"github.com/pocketbase/pocketbase/tools/routine"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/spf13/cast"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -33,6 +35,10 @@ func bindRealtimeApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	sub := rg.Group("/realtime")
 	sub.GET("", realtimeConnect).Bind(SkipSuccessActivityLog())
 	sub.POST("", realtimeSetSubscriptions)
+	sub.GET("/ws", realtimeConnectWS).Bind(SkipSuccessActivityLog())
+	sub.POST("/publish", realtimePublish)
+	sub.GET("/presence", realtimePresence)
+	sub.GET("/stats", realtimeStats).Bind(RequireSuperuserAuth())
 
 	bindRealtimeEvents(app)
 }
@@ -70,9 +76,14 @@ func realtimeConnect(e *core.RequestEvent) error {
 		// register new subscription client
 		ce.App.SubscriptionsBroker().Register(ce.Client)
 		defer func() {
+			realtimeSaveClientHistory(e.App, ce.Client)
 			e.App.SubscriptionsBroker().Unregister(ce.Client.Id())
+			realtimeLeaveClientPresence(e.App, ce.Client)
 		}()
 
+		// check for reconnect
+		realtimeInitClientHistory(ce.App, ce.Client, ce.Request)
+
 		ce.App.Logger().Debug("Realtime connection established.", slog.String("clientId", ce.Client.Id()))
 
 		// signalize established connection (aka. fire "connect" message)
@@ -81,7 +92,7 @@ func realtimeConnect(e *core.RequestEvent) error {
 		connectMsgEvent.Client = ce.Client
 		connectMsgEvent.Message = &subscriptions.Message{
 			Name: "PB_CONNECT",
-			Data: []byte(`{"clientId":"` + ce.Client.Id() + `"}`),
+			Data: []byte(`{"clientId":"` + ce.Client.Id() + `","memberId":"` + realtimeClientMemberId(ce.Client) + `"}`),
 		}
 		connectMsgErr := ce.App.OnRealtimeMessageSend().Trigger(connectMsgEvent, func(me *core.RealtimeMessageEvent) error {
 			err := me.Message.WriteSSE(me.Response, me.Client.Id())
@@ -117,12 +128,23 @@ func realtimeConnect(e *core.RequestEvent) error {
 					return nil
 				}
 
+				// skip already replayed events
+				if !realtimeTrackClientMessage(ce.Client, &msg) {
+					continue
+				}
+
 				msgEvent := new(core.RealtimeMessageEvent)
 				msgEvent.RequestEvent = ce.RequestEvent
 				msgEvent.Client = ce.Client
 				msgEvent.Message = &msg
 				msgErr := ce.App.OnRealtimeMessageSend().Trigger(msgEvent, func(me *core.RealtimeMessageEvent) error {
-					err := me.Message.WriteSSE(me.Response, me.Client.Id())
+					// fallback to the client id for backward compatibility
+					eventId := me.Message.Id
+					if eventId == "" {
+						eventId = me.Client.Id()
+					}
+
+					err := me.Message.WriteSSE(me.Response, eventId)
 					if err != nil {
 						return err
 					}
@@ -167,6 +189,7 @@ func (form *realtimeSubscribeForm) validate() error {
 }
 
 // note: in case of reconnect, clients will have to resubmit all subscriptions again
+// (the missed events since the "Last-Event-ID" are replayed after the first resubmit)
 func realtimeSetSubscriptions(e *core.RequestEvent) error {
 	form := new(realtimeSubscribeForm)
 
@@ -192,6 +215,11 @@ func realtimeSetSubscriptions(e *core.RequestEvent) error {
 		return e.ForbiddenError("The current and the previous request authorization don't match.", nil)
 	}
 
+	err = realtimeCheckChannelSubscriptions(e.App, e.Auth, form.Subscriptions)
+	if err != nil {
+		return err
+	}
+
 	event := new(core.RealtimeSubscribeRequestEvent)
 	event.RequestEvent = e
 	event.Client = client
@@ -207,12 +235,18 @@ func realtimeSetSubscriptions(e *core.RequestEvent) error {
 		// subscribe to the new subscriptions
 		e.Client.Subscribe(e.Subscriptions...)
 
+		// join or leave the custom presence channels
+		realtimeSyncClientPresence(e.App, e.Client)
+
 		e.App.Logger().Debug(
 			"Realtime subscriptions updated.",
 			slog.String("clientId", e.Client.Id()),
 			slog.Any("subscriptions", e.Subscriptions),
 		)
 
+		// send the missed events (if reconnecting)
+		realtimeReplayClientHistory(e.App, e.Client)
+
 		return execAfterSuccessTx(true, e.App, func() error {
 			return e.NoContent(http.StatusNoContent)
 		})
@@ -313,7 +347,9 @@ func bindRealtimeEvents(app core.App) {
 		Func: func(e *core.ModelEvent) error {
 			record := realtimeResolveRecord(e.App, e.Model, "")
 			if record != nil {
-				err := realtimeBroadcastRecord(e.App, "create", record, false)
+				eventId := realtimeHistoryAdd(app, "create", record)
+
+				err := realtimeBroadcastRecord(e.App, "create", record, eventId, false)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to broadcast record create",
@@ -333,7 +369,9 @@ func bindRealtimeEvents(app core.App) {
 		Func: func(e *core.ModelEvent) error {
 			record := realtimeResolveRecord(e.App, e.Model, "")
 			if record != nil {
-				err := realtimeBroadcastRecord(e.App, "update", record, false)
+				eventId := realtimeHistoryAdd(app, "update", record)
+
+				err := realtimeBroadcastRecord(e.App, "update", record, eventId, false)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to broadcast record update",
@@ -357,7 +395,9 @@ func bindRealtimeEvents(app core.App) {
 				// note: use the outside scoped app instance for the access checks so that the API rules
 				// are performed out of the delete transaction ensuring that they would still work even if
 				// a cascade-deleted record's API rule relies on an already deleted parent record
-				err := realtimeBroadcastRecord(e.App, "delete", record, true, app)
+				realtimeHistorySetPendingDelete(app, getDryCacheKey("delete", record), record)
+
+				err := realtimeBroadcastRecord(e.App, "delete", record, "", true, app)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to dry cache record delete",
@@ -381,7 +421,14 @@ func bindRealtimeEvents(app core.App) {
 			// custom model it'll fail to resolve since the record is already deleted
 			collection := realtimeResolveRecordCollection(e.App, e.Model)
 			if collection != nil {
-				err := realtimeBroadcastDryCacheKey(e.App, getDryCacheKey("delete", e.Model))
+				key := getDryCacheKey("delete", e.Model)
+
+				var eventId string
+				if record := realtimeHistoryUnsetPendingDelete(app, key); record != nil {
+					eventId = realtimeHistoryAdd(app, "delete", record)
+				}
+
+				err := realtimeBroadcastDryCacheKey(e.App, key, eventId)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to broadcast record delete",
@@ -402,7 +449,11 @@ func bindRealtimeEvents(app core.App) {
 		Func: func(e *core.ModelErrorEvent) error {
 			record := realtimeResolveRecord(e.App, e.Model, "")
 			if record != nil {
-				err := realtimeUnsetDryCacheKey(e.App, getDryCacheKey("delete", record))
+				key := getDryCacheKey("delete", record)
+
+				realtimeHistoryUnsetPendingDelete(app, key)
+
+				err := realtimeUnsetDryCacheKey(e.App, key)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to cleanup after broadcast record delete failure",
@@ -474,172 +525,156 @@ func realtimeResolveRecordCollection(app core.App, model core.Model) (collection
 	return nil
 }
 
+// deltaQueryParam is the subscription options query parameter
+// for sending only the changed record fields on update.
+//
+// Note that the changes are resolved against [core.Record.Original]
+// so for a record instance saved multiple times without refetching,
+// the delta could contain also some of the unchanged fields.
+const deltaQueryParam = "delta"
+
 // recordData represents the broadcasted record subscrition message data.
 type recordData struct {
 	Record any    `json:"record"` /* map or core.Record */
 	Action string `json:"action"`
+	Delta  bool   `json:"delta,omitempty"`
+}
+
+// realtimeDeltaRecordData returns a map with only the changed fields
+// of the already serializable record data (+ its id and updated fields).
+//
+// The hidden fields and the ones excluded with the "fields" option are
+// not exported by the record data and therefore they are never part of the result.
+//
+// The expanded relations are kept only for the changed relation fields.
+func realtimeDeltaRecordData(recordData any, changedFields []string) (map[string]any, error) {
+	raw, err := json.Marshal(recordData)
+	if err != nil {
+		return nil, err
+	}
+
+	full := map[string]any{}
+	if err := json.Unmarshal(raw, &full); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any, len(changedFields)+2)
+
+	for _, name := range append([]string{core.FieldNameId, "updated"}, changedFields...) {
+		if v, ok := full[name]; ok {
+			result[name] = v
+		}
+	}
+
+	if expand, ok := full[core.FieldNameExpand].(map[string]any); ok {
+		changedExpand := map[string]any{}
+		for _, name := range changedFields {
+			if v, ok := expand[name]; ok {
+				changedExpand[name] = v
+			}
+		}
+		if len(changedExpand) > 0 {
+			result[core.FieldNameExpand] = changedExpand
+		}
+	}
+
+	return result, nil
 }
 
 // Note: the optAccessCheckApp is there in case you want the access check
 // to be performed against different db app context (e.g. out of a transaction).
 // If set, it is expected that optAccessCheckApp instance is used for read-only operations to avoid deadlocks.
 // If not set, it fallbacks to app.
-func realtimeBroadcastRecord(app core.App, action string, record *core.Record, dryCache bool, optAccessCheckApp ...core.App) error {
+//
+// eventId is the optional realtime history event id of the broadcasted messages.
+//
+// The client subscriptions with the same access rule, auth and options are
+// grouped and the access check and the message data are resolved once per group
+// (the auth is not taken into account if the subscription doesn't depend on it).
+func realtimeBroadcastRecord(app core.App, action string, record *core.Record, eventId string, dryCache bool, optAccessCheckApp ...core.App) error {
 	collection := record.Collection()
 	if collection == nil {
 		return errors.New("[broadcastRecord] Record collection not set")
 	}
 
-	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
-	if len(chunks) == 0 {
+	clients := app.SubscriptionsBroker().Clients()
+	if len(clients) == 0 {
 		return nil // no subscribers
 	}
 
-	subscriptionRuleMap := map[string]*string{
-		(collection.Name + "/" + record.Id + "?"): collection.ViewRule,
-		(collection.Id + "/" + record.Id + "?"):   collection.ViewRule,
-		(collection.Name + "/*?"):                 collection.ListRule,
-		(collection.Id + "/*?"):                   collection.ListRule,
+	start := time.Now()
 
-		// @deprecated: the same as the wildcard topic but kept for backward compatibility
-		(collection.Name + "?"): collection.ListRule,
-		(collection.Id + "?"):   collection.ListRule,
+	subscriptionRuleMap := realtimeSubscriptionRuleMap(record)
+
+	groups := realtimeGroupRecordSubscriptions(collection, clients, subscriptionRuleMap)
+	if len(groups) == 0 {
+		return nil // no record subscriptions
 	}
 
 	dryCacheKey := getDryCacheKey(action, record)
 
-	group := new(errgroup.Group)
+	// the changed fields are used only for the opt-in delta update payloads
+	var changedFields []string
+	if action == "update" {
+		var err error
+		changedFields, err = record.ChangedFields()
+		if err != nil {
+			app.Logger().Debug(
+				"[broadcastRecord] failed to resolve the record changed fields",
+				slog.String("id", record.Id),
+				slog.String("collectionName", collection.Name),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
 
 	accessCheckApp := app
 	if len(optAccessCheckApp) > 0 {
 		accessCheckApp = optAccessCheckApp[0]
 	}
 
-	for _, chunk := range chunks {
-		group.Go(func() error {
-			var clientAuth *core.Record
+	stats := realtimeGetStats(app)
 
-			for _, client := range chunk {
+	sender := newRealtimeSender(app)
+
+	// rules that depend only on the request info are evaluated in-process
+	staticRules := map[*string]*realtimeStaticRule{
+		collection.ViewRule: realtimeCompileStaticRule(collection, collection.ViewRule),
+		collection.ListRule: realtimeCompileStaticRule(collection, collection.ListRule),
+	}
+
+	var cacheMu sync.Mutex
+	cache := map[subscriptions.Client][]subscriptions.Message{}
+
+	errGroup := new(errgroup.Group)
+
+	for _, chunk := range list.ToChunks(groups, clientsChunkSize) {
+		errGroup.Go(func() error {
+			for _, group := range chunk {
 				// note: not executed concurrently to avoid races and to ensure
 				// that the access checks are applied for the current record db state
-				for prefix, rule := range subscriptionRuleMap {
-					subs := client.Subscriptions(prefix)
-					if len(subs) == 0 {
+				membersData, ok := realtimeRecordGroupData(app, accessCheckApp, group, action, record, changedFields, staticRules[group.rule], stats)
+				if !ok {
+					continue
+				}
+
+				for i, member := range group.members {
+					if membersData[i] == nil {
 						continue
 					}
 
-					clientAuth, _ = client.Get(RealtimeClientAuthKey).(*core.Record)
-
-					for sub, options := range subs {
-						// mock request data
-						requestInfo := &core.RequestInfo{
-							Context: core.RequestInfoContextRealtime,
-							Method:  "GET",
-							Query:   options.Query,
-							Headers: options.Headers,
-							Auth:    clientAuth,
-						}
-
-						if !realtimeCanAccessRecord(accessCheckApp, record, requestInfo, rule) {
-							continue
-						}
-
-						// create a clean record copy without expand and unknown fields because we don't know yet
-						// which exact fields the client subscription requested or has permissions to access
-						cleanRecord := record.Fresh()
-
-						// trigger the enrich hooks
-						enrichErr := triggerRecordEnrichHooks(app, requestInfo, []*core.Record{cleanRecord}, func() error {
-							// apply expand
-							rawExpand := options.Query[expandQueryParam]
-							if rawExpand != "" {
-								expandErrs := app.ExpandRecord(cleanRecord, strings.Split(rawExpand, ","), expandFetch(app, requestInfo))
-								if len(expandErrs) > 0 {
-									app.Logger().Debug(
-										"[broadcastRecord] expand errors",
-										slog.String("id", cleanRecord.Id),
-										slog.String("collectionName", cleanRecord.Collection().Name),
-										slog.String("sub", sub),
-										slog.String("expand", rawExpand),
-										slog.Any("errors", expandErrs),
-									)
-								}
-							}
-
-							// ignore the auth record email visibility checks
-							// for auth owner, superuser or manager
-							if collection.IsAuth() {
-								if isSameAuth(clientAuth, cleanRecord) ||
-									realtimeCanAccessRecord(accessCheckApp, cleanRecord, requestInfo, collection.ManageRule) {
-									cleanRecord.IgnoreEmailVisibility(true)
-								}
-							}
-
-							return nil
-						})
-						if enrichErr != nil {
-							app.Logger().Debug(
-								"[broadcastRecord] record enrich error",
-								slog.String("id", cleanRecord.Id),
-								slog.String("collectionName", cleanRecord.Collection().Name),
-								slog.String("sub", sub),
-								slog.Any("error", enrichErr),
-							)
-							continue
-						}
-
-						data := &recordData{
-							Action: action,
-							Record: cleanRecord,
-						}
-
-						// check fields
-						rawFields := options.Query[fieldsQueryParam]
-						if rawFields != "" {
-							decoded, err := picker.Pick(cleanRecord, rawFields)
-							if err == nil {
-								data.Record = decoded
-							} else {
-								app.Logger().Debug(
-									"[broadcastRecord] pick fields error",
-									slog.String("id", cleanRecord.Id),
-									slog.String("collectionName", cleanRecord.Collection().Name),
-									slog.String("sub", sub),
-									slog.String("fields", rawFields),
-									slog.String("error", err.Error()),
-								)
-							}
-						}
-
-						dataBytes, err := json.Marshal(data)
-						if err != nil {
-							app.Logger().Debug(
-								"[broadcastRecord] data marshal error",
-								slog.String("id", cleanRecord.Id),
-								slog.String("collectionName", cleanRecord.Collection().Name),
-								slog.String("error", err.Error()),
-							)
-							continue
-						}
-
-						msg := subscriptions.Message{
-							Name: sub,
-							Data: dataBytes,
-						}
-
-						if dryCache {
-							messages, ok := client.Get(dryCacheKey).([]subscriptions.Message)
-							if !ok {
-								messages = []subscriptions.Message{msg}
-							} else {
-								messages = append(messages, msg)
-							}
-							client.Set(dryCacheKey, messages)
-						} else {
-							routine.FireAndForget(func() {
-								client.Send(msg)
-							})
-						}
+					msg := subscriptions.Message{
+						Name: member.sub,
+						Data: membersData[i],
+						Id:   eventId,
+					}
+
+					if dryCache {
+						cacheMu.Lock()
+						cache[member.client] = append(cache[member.client], msg)
+						cacheMu.Unlock()
+					} else {
+						sender.send(member.client, msg)
 					}
 				}
 			}
@@ -648,16 +683,265 @@ func realtimeBroadcastRecord(app core.App, action string, record *core.Record, d
 		})
 	}
 
-	return group.Wait()
+	err := errGroup.Wait()
+
+	for client, messages := range cache {
+		cached, _ := client.Get(dryCacheKey).([]subscriptions.Message)
+		client.Set(dryCacheKey, append(cached, messages...))
+	}
+
+	stats.addBroadcast(time.Since(start), len(groups))
+
+	return err
+}
+
+// realtimeSubscriptionRuleMap returns the record related subscription
+// topic prefixes with their corresponding access rule.
+func realtimeSubscriptionRuleMap(record *core.Record) map[string]*string {
+	collection := record.Collection()
+
+	return map[string]*string{
+		(collection.Name + "/" + record.Id + "?"): collection.ViewRule,
+		(collection.Id + "/" + record.Id + "?"):   collection.ViewRule,
+		(collection.Name + "/*?"):                 collection.ListRule,
+		(collection.Id + "/*?"):                   collection.ListRule,
+
+		// @deprecated: the same as the wildcard topic but kept for backward compatibility
+		(collection.Name + "?"): collection.ListRule,
+		(collection.Id + "?"):   collection.ListRule,
+	}
+}
+
+// realtimeClientRecordMessages returns the record event messages
+// for each client subscription that has access to the record.
+//
+// The access is checked against the provided record data (aka. the
+// history event snapshot) because the record db row could have been
+// changed or deleted since the event was registered.
+//
+// changedFields is the optional list of the record changed fields used for the
+// opt-in "delta" subscriptions (if nil, the full record data is always sent).
+func realtimeClientRecordMessages(
+	app core.App,
+	accessCheckApp core.App,
+	client subscriptions.Client,
+	subscriptionRuleMap map[string]*string,
+	action string,
+	record *core.Record,
+	eventId string,
+	changedFields []string,
+) []subscriptions.Message {
+	var result []subscriptions.Message
+
+	groups := realtimeGroupRecordSubscriptions(record.Collection(), map[string]subscriptions.Client{client.Id(): client}, subscriptionRuleMap)
+
+	for _, group := range groups {
+		group.snapshot = true
+
+		membersData, ok := realtimeRecordGroupData(app, accessCheckApp, group, action, record, changedFields, nil, nil)
+		if !ok {
+			continue
+		}
+
+		for i, member := range group.members {
+			if membersData[i] == nil {
+				continue
+			}
+
+			result = append(result, subscriptions.Message{
+				Name: member.sub,
+				Data: membersData[i],
+				Id:   eventId,
+			})
+		}
+	}
+
+	return result
+}
+
+// realtimeRecordGroupData checks whether the subscriptions group has access
+// to the record and returns the serialized record event data of each group member.
+//
+// The access is checked once for the whole group but the record data is
+// enriched for each distinct member auth because the OnRecordEnrich
+// handlers could depend on it (even if the access rule doesn't).
+//
+// A nil member data means that the event shouldn't be sent to the member.
+//
+// staticRule and stats are optional.
+func realtimeRecordGroupData(
+	app core.App,
+	accessCheckApp core.App,
+	group *realtimeRecordGroup,
+	action string,
+	record *core.Record,
+	changedFields []string,
+	staticRule *realtimeStaticRule,
+	stats *realtimeStatsCollector,
+) ([][]byte, bool) {
+	if !realtimeCanAccessRecordGroup(accessCheckApp, record, group, staticRule, stats) {
+		return nil, false
+	}
+
+	result := make([][]byte, len(group.members))
+
+	cache := map[string][]byte{}
+
+	for i, member := range group.members {
+		authKey := realtimeAuthKey(member.auth)
+
+		data, ok := cache[authKey]
+		if !ok {
+			requestInfo := group.requestInfo
+			if member.auth != requestInfo.Auth {
+				memberInfo := *requestInfo
+				memberInfo.Auth = member.auth
+				requestInfo = &memberInfo
+			}
+
+			data, _ = realtimeRecordData(app, accessCheckApp, group, requestInfo, member.sub, action, record, changedFields)
+
+			cache[authKey] = data
+		}
+
+		result[i] = data
+	}
+
+	return result, true
+}
+
+// realtimeRecordData enriches the record for the provided request info
+// and returns the serialized record event data.
+//
+// sub is the subscription the data is for and it is used only for logging purposes.
+func realtimeRecordData(
+	app core.App,
+	accessCheckApp core.App,
+	group *realtimeRecordGroup,
+	requestInfo *core.RequestInfo,
+	sub string,
+	action string,
+	record *core.Record,
+	changedFields []string,
+) ([]byte, bool) {
+	collection := record.Collection()
+
+	options := group.options
+
+	// create a clean record copy without expand and unknown fields because we don't know yet
+	// which exact fields the client subscription requested or has permissions to access
+	cleanRecord := record.Fresh()
+
+	// trigger the enrich hooks
+	enrichErr := triggerRecordEnrichHooks(app, requestInfo, []*core.Record{cleanRecord}, func() error {
+		// apply expand
+		rawExpand := options.Query[expandQueryParam]
+		if rawExpand != "" {
+			expandErrs := app.ExpandRecord(cleanRecord, strings.Split(rawExpand, ","), expandFetch(app, requestInfo))
+			if len(expandErrs) > 0 {
+				app.Logger().Debug(
+					"[broadcastRecord] expand errors",
+					slog.String("id", cleanRecord.Id),
+					slog.String("collectionName", cleanRecord.Collection().Name),
+					slog.String("sub", sub),
+					slog.String("expand", rawExpand),
+					slog.Any("errors", expandErrs),
+				)
+			}
+		}
+
+		// ignore the auth record email visibility checks
+		// for auth owner, superuser or manager
+		if collection.IsAuth() {
+			canManage := realtimeCanAccessRecord
+			if group.snapshot {
+				canManage = realtimeCanAccessRecordSnapshot
+			}
+
+			if isSameAuth(requestInfo.Auth, cleanRecord) ||
+				canManage(accessCheckApp, cleanRecord, requestInfo, collection.ManageRule) {
+				cleanRecord.IgnoreEmailVisibility(true)
+			}
+		}
+
+		return nil
+	})
+	if enrichErr != nil {
+		app.Logger().Debug(
+			"[broadcastRecord] record enrich error",
+			slog.String("id", cleanRecord.Id),
+			slog.String("collectionName", cleanRecord.Collection().Name),
+			slog.String("sub", sub),
+			slog.Any("error", enrichErr),
+		)
+		return nil, false
+	}
+
+	data := &recordData{
+		Action: action,
+		Record: cleanRecord,
+	}
+
+	// check fields
+	rawFields := options.Query[fieldsQueryParam]
+	if rawFields != "" {
+		decoded, err := picker.Pick(cleanRecord, rawFields)
+		if err == nil {
+			data.Record = decoded
+		} else {
+			app.Logger().Debug(
+				"[broadcastRecord] pick fields error",
+				slog.String("id", cleanRecord.Id),
+				slog.String("collectionName", cleanRecord.Collection().Name),
+				slog.String("sub", sub),
+				slog.String("fields", rawFields),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	// send only the changed fields
+	if changedFields != nil && cast.ToBool(options.Query[deltaQueryParam]) {
+		delta, err := realtimeDeltaRecordData(data.Record, changedFields)
+		if err == nil {
+			data.Record = delta
+			data.Delta = true
+		} else {
+			app.Logger().Debug(
+				"[broadcastRecord] delta record data error",
+				slog.String("id", cleanRecord.Id),
+				slog.String("collectionName", cleanRecord.Collection().Name),
+				slog.String("sub", sub),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		app.Logger().Debug(
+			"[broadcastRecord] data marshal error",
+			slog.String("id", cleanRecord.Id),
+			slog.String("collectionName", cleanRecord.Collection().Name),
+			slog.String("error", err.Error()),
+		)
+		return nil, false
+	}
+
+	return dataBytes, true
 }
 
 // realtimeBroadcastDryCacheKey broadcasts the dry cached key related messages.
-func realtimeBroadcastDryCacheKey(app core.App, key string) error {
+//
+// eventId is the optional realtime history event id of the broadcasted messages.
+func realtimeBroadcastDryCacheKey(app core.App, key string, eventId string) error {
 	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
 	if len(chunks) == 0 {
 		return nil // no subscribers
 	}
 
+	sender := newRealtimeSender(app)
+
 	group := new(errgroup.Group)
 
 	for _, chunk := range chunks {
@@ -670,13 +954,13 @@ func realtimeBroadcastDryCacheKey(app core.App, key string) error {
 
 				client.Unset(key)
 
-				client := client
-
-				routine.FireAndForget(func() {
-					for _, msg := range messages {
-						client.Send(msg)
+				if eventId != "" {
+					for i := range messages {
+						messages[i].Id = eventId
 					}
-				})
+				}
+
+				sender.send(client, messages...)
 			}
 
 			return nil
@@ -0,0 +1,619 @@
+package apis
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+const realtimeStatsStoreKey = "pbRealtimeStats"
+
+// RealtimeStats defines the realtime record broadcasts metrics.
+type RealtimeStats struct {
+	// Broadcasts is the total number of the broadcasted record events.
+	Broadcasts int64 `json:"broadcasts"`
+
+	// Groups is the total number of the resolved subscription groups
+	// (aka. client subscriptions with the same access rule, auth and options).
+	Groups int64 `json:"groups"`
+
+	// Messages is the total number of the dispatched record event messages.
+	Messages int64 `json:"messages"`
+
+	// DBChecks is the total number of the access rule checks that required a db query
+	// (the optional subscription filter checks are not counted).
+	DBChecks int64 `json:"dbChecks"`
+
+	// InProcessChecks is the total number of the access rule checks
+	// that were evaluated in-process without a db query.
+	InProcessChecks int64 `json:"inProcessChecks"`
+
+	// Dropped is the total number of the messages that were dropped
+	// because of the "drop" back-pressure policy.
+	Dropped int64 `json:"dropped"`
+
+	// Disconnected is the total number of the slow clients that were
+	// disconnected because of the "disconnect" back-pressure policy.
+	Disconnected int64 `json:"disconnected"`
+
+	// LastLatency, AvgLatency and MaxLatency are the record broadcasts
+	// durations in milliseconds (from the start of the access checks until
+	// all messages are dispatched, aka. without the clients delivery time).
+	LastLatency float64 `json:"lastLatency"`
+	AvgLatency  float64 `json:"avgLatency"`
+	MaxLatency  float64 `json:"maxLatency"`
+}
+
+// RealtimeBroadcastStats returns a snapshot of the app realtime record broadcasts metrics.
+//
+// The metrics are kept in memory and they are reset on app restart.
+func RealtimeBroadcastStats(app core.App) RealtimeStats {
+	return realtimeGetStats(app).snapshot()
+}
+
+// realtimeStats handles the realtime broadcasts metrics request.
+func realtimeStats(e *core.RequestEvent) error {
+	return e.JSON(http.StatusOK, RealtimeBroadcastStats(e.App))
+}
+
+type realtimeStatsCollector struct {
+	broadcasts      atomic.Int64
+	groups          atomic.Int64
+	messages        atomic.Int64
+	dbChecks        atomic.Int64
+	inProcessChecks atomic.Int64
+	dropped         atomic.Int64
+	disconnected    atomic.Int64
+
+	mu           sync.Mutex
+	lastLatency  time.Duration
+	maxLatency   time.Duration
+	totalLatency time.Duration
+}
+
+func realtimeGetStats(app core.App) *realtimeStatsCollector {
+	return app.Store().GetOrSet(realtimeStatsStoreKey, func() any {
+		return &realtimeStatsCollector{}
+	}).(*realtimeStatsCollector)
+}
+
+func (c *realtimeStatsCollector) addBroadcast(latency time.Duration, groups int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.broadcasts.Add(1)
+	c.groups.Add(int64(groups))
+
+	c.lastLatency = latency
+	c.totalLatency += latency
+	if latency > c.maxLatency {
+		c.maxLatency = latency
+	}
+}
+
+func (c *realtimeStatsCollector) snapshot() RealtimeStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := RealtimeStats{
+		Broadcasts:      c.broadcasts.Load(),
+		Groups:          c.groups.Load(),
+		Messages:        c.messages.Load(),
+		DBChecks:        c.dbChecks.Load(),
+		InProcessChecks: c.inProcessChecks.Load(),
+		Dropped:         c.dropped.Load(),
+		Disconnected:    c.disconnected.Load(),
+		LastLatency:     realtimeMilliseconds(c.lastLatency),
+		MaxLatency:      realtimeMilliseconds(c.maxLatency),
+	}
+
+	if result.Broadcasts > 0 {
+		result.AvgLatency = realtimeMilliseconds(c.totalLatency / time.Duration(result.Broadcasts))
+	}
+
+	return result
+}
+
+func realtimeMilliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// -------------------------------------------------------------------
+
+// realtimeRecordGroup represents a group of client subscriptions with
+// the same access rule, auth and options, meaning that they
+// share the same access check result and message data.
+type realtimeRecordGroup struct {
+	rule        *string
+	options     subscriptions.SubscriptionOptions
+	requestInfo *core.RequestInfo
+	members     []realtimeRecordGroupMember
+
+	// snapshot reports whether the access should be checked against
+	// the record own data instead of its current db row
+	// (eg. for the replayed history events).
+	snapshot bool
+}
+
+type realtimeRecordGroupMember struct {
+	client subscriptions.Client
+	sub    string
+
+	// auth is the member client auth record
+	// (could differ from the group request info auth).
+	auth *core.Record
+}
+
+// realtimeGroupRecordSubscriptions groups the clients subscriptions
+// matching the subscription rule map prefixes.
+//
+// The non-superuser subscriptions that don't depend on the client auth
+// (see [realtimeIsAuthDependent]) are grouped and their access is checked
+// as guest ones (the members still keep their own auth for the record enrich).
+func realtimeGroupRecordSubscriptions(
+	collection *core.Collection,
+	clients map[string]subscriptions.Client,
+	subscriptionRuleMap map[string]*string,
+) []*realtimeRecordGroup {
+	var result []*realtimeRecordGroup
+
+	index := map[string]*realtimeRecordGroup{}
+
+	for _, client := range clients {
+		var clientAuth *core.Record
+		var clientAuthLoaded bool
+
+		for prefix, rule := range subscriptionRuleMap {
+			subs := client.Subscriptions(prefix)
+			if len(subs) == 0 {
+				continue
+			}
+
+			if !clientAuthLoaded {
+				clientAuth, _ = client.Get(RealtimeClientAuthKey).(*core.Record)
+				clientAuthLoaded = true
+			}
+
+			for sub, options := range subs {
+				groupAuth := clientAuth
+				if groupAuth != nil && !groupAuth.IsSuperuser() && !realtimeIsAuthDependent(collection, rule, options) {
+					groupAuth = nil
+				}
+
+				key := realtimeRecordGroupKey(rule, groupAuth, options)
+
+				group, ok := index[key]
+				if !ok {
+					group = &realtimeRecordGroup{
+						rule:    rule,
+						options: options,
+						// mock request data
+						requestInfo: &core.RequestInfo{
+							Context: core.RequestInfoContextRealtime,
+							Method:  "GET",
+							Query:   options.Query,
+							Headers: options.Headers,
+							Auth:    groupAuth,
+						},
+					}
+					index[key] = group
+					result = append(result, group)
+				}
+
+				group.members = append(group.members, realtimeRecordGroupMember{client: client, sub: sub, auth: clientAuth})
+			}
+		}
+	}
+
+	return result
+}
+
+// realtimeIsAuthDependent reports whether the access to the collection
+// records or their subscription data could differ between the client auth records.
+//
+// Besides the rule and subscription options that reference @request.auth.*,
+// the auth collections (because of the email visibility) and the
+// subscriptions with expand (because the expanded records are checked
+// against their own collection rules) are also considered auth dependent.
+func realtimeIsAuthDependent(collection *core.Collection, rule *string, options subscriptions.SubscriptionOptions) bool {
+	if collection.IsAuth() || options.Query[expandQueryParam] != "" {
+		return true
+	}
+
+	if rule != nil && strings.Contains(*rule, "@request.auth") {
+		return true
+	}
+
+	rawOptions, _ := json.Marshal(options)
+
+	return strings.Contains(string(rawOptions), "@request.auth")
+}
+
+func realtimeRecordGroupKey(rule *string, auth *core.Record, options subscriptions.SubscriptionOptions) string {
+	var key strings.Builder
+
+	if rule == nil {
+		key.WriteString("-")
+	} else {
+		key.WriteString("+" + *rule)
+	}
+	key.WriteString("\n")
+
+	key.WriteString(realtimeAuthKey(auth))
+	key.WriteString("\n")
+
+	// note: the map keys are serialized sorted
+	rawOptions, _ := json.Marshal(options)
+	key.Write(rawOptions)
+
+	return key.String()
+}
+
+// realtimeAuthKey returns a unique key of the provided auth record
+// (empty string for guests).
+func realtimeAuthKey(auth *core.Record) string {
+	if auth == nil {
+		return ""
+	}
+
+	return auth.Collection().Id + "/" + auth.Id
+}
+
+// realtimeCanAccessRecordGroup checks if the subscriptions group has access to the specified record model.
+//
+// If staticRule is set, the access rule is evaluated in-process.
+func realtimeCanAccessRecordGroup(
+	app core.App,
+	record *core.Record,
+	group *realtimeRecordGroup,
+	staticRule *realtimeStaticRule,
+	stats *realtimeStatsCollector,
+) bool {
+	requestInfo := group.requestInfo
+
+	rule := group.rule
+
+	if staticRule != nil && !requestInfo.HasSuperuserAuth() {
+		if stats != nil {
+			stats.inProcessChecks.Add(1)
+		}
+
+		if !staticRule.eval(requestInfo) {
+			return false
+		}
+
+		// the rule is already satisfied and only the filter (if any) remains to be checked
+		rule = types.Pointer("")
+	} else if stats != nil && rule != nil && *rule != "" && !requestInfo.HasSuperuserAuth() {
+		stats.dbChecks.Add(1)
+	}
+
+	if group.snapshot {
+		return realtimeCanAccessRecordSnapshot(app, record, requestInfo, rule)
+	}
+
+	return realtimeCanAccessRecord(app, record, requestInfo, rule)
+}
+
+// -------------------------------------------------------------------
+
+// realtimeStaticRuleFields lists the request info fields that are
+// resolved statically (aka. without db join) by the record field resolver.
+var realtimeStaticRuleFields = map[string]func(requestInfo *core.RequestInfo) any{
+	"@request.context": func(requestInfo *core.RequestInfo) any {
+		return requestInfo.Context
+	},
+	"@request.method": func(requestInfo *core.RequestInfo) any {
+		return requestInfo.Method
+	},
+	"@request.auth." + core.FieldNameId: realtimeStaticAuthField(func(auth *core.Record) any {
+		return auth.Id
+	}),
+	"@request.auth." + core.FieldNameCollectionId: realtimeStaticAuthField(func(auth *core.Record) any {
+		return auth.Collection().Id
+	}),
+	"@request.auth." + core.FieldNameCollectionName: realtimeStaticAuthField(func(auth *core.Record) any {
+		return auth.Collection().Name
+	}),
+	"@request.auth." + core.FieldNameEmail: realtimeStaticAuthField(func(auth *core.Record) any {
+		return auth.Email()
+	}),
+	"@request.auth." + core.FieldNameEmailVisibility: realtimeStaticAuthField(func(auth *core.Record) any {
+		return auth.EmailVisibility()
+	}),
+	"@request.auth." + core.FieldNameVerified: realtimeStaticAuthField(func(auth *core.Record) any {
+		return auth.Verified()
+	}),
+}
+
+func realtimeStaticAuthField(fn func(auth *core.Record) any) func(requestInfo *core.RequestInfo) any {
+	return func(requestInfo *core.RequestInfo) any {
+		if requestInfo.Auth == nil || requestInfo.Auth.Collection() == nil {
+			return nil
+		}
+
+		return fn(requestInfo.Auth)
+	}
+}
+
+// realtimeStaticRule is an access rule that depends only on the static
+// request info fields (eg. `@request.auth.id != ""`) and therefore
+// it could be evaluated in-process without a db query.
+type realtimeStaticRule struct {
+	data []fexpr.ExprGroup
+}
+
+// realtimeCompileStaticRule parses the specified collection access rule
+// and returns nil if it can't be evaluated in-process.
+//
+// Only the "=" and "!=" (and their "any" variants) comparisons of
+// plain literals and realtimeStaticRuleFields are currently supported.
+func realtimeCompileStaticRule(collection *core.Collection, rule *string) *realtimeStaticRule {
+	if rule == nil || *rule == "" {
+		return nil
+	}
+
+	data, err := fexpr.Parse(*rule)
+	if err != nil || !realtimeIsStaticExprGroups(collection, data) {
+		return nil
+	}
+
+	return &realtimeStaticRule{data: data}
+}
+
+func realtimeIsStaticExprGroups(collection *core.Collection, data []fexpr.ExprGroup) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	for _, group := range data {
+		switch item := group.Item.(type) {
+		case fexpr.Expr:
+			switch item.Op {
+			case fexpr.SignEq, fexpr.SignAnyEq, fexpr.SignNeq, fexpr.SignAnyNeq:
+			default:
+				return false
+			}
+
+			if !realtimeIsStaticToken(collection, item.Left) || !realtimeIsStaticToken(collection, item.Right) {
+				return false
+			}
+		case fexpr.ExprGroup:
+			if !realtimeIsStaticExprGroups(collection, []fexpr.ExprGroup{item}) {
+				return false
+			}
+		case []fexpr.ExprGroup:
+			if !realtimeIsStaticExprGroups(collection, item) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func realtimeIsStaticToken(collection *core.Collection, token fexpr.Token) bool {
+	switch token.Type {
+	case fexpr.TokenText, fexpr.TokenNumber:
+		return true
+	case fexpr.TokenIdentifier:
+		// the identifiers are resolved first as collection fields
+		if collection.Fields.GetByName(token.Literal) != nil {
+			return false
+		}
+
+		if _, ok := realtimeStaticRuleFields[token.Literal]; ok {
+			// the record field resolver casts the numeric strings
+			// if the collection has a number field with the same name
+			lastProp := token.Literal[strings.LastIndex(token.Literal, ".")+1:]
+			field := collection.Fields.GetByName(lastProp)
+
+			return field == nil || field.Type() != core.FieldTypeNumber
+		}
+
+		switch strings.ToLower(token.Literal) {
+		case "null", "true", "false":
+			return true
+		}
+	}
+
+	return false
+}
+
+// eval evaluates the static rule against the specified request info.
+//
+// Similar to the SQL operators precedence, "&&" has higher priority than "||".
+func (r *realtimeStaticRule) eval(requestInfo *core.RequestInfo) bool {
+	return realtimeEvalStaticExprGroups(r.data, requestInfo)
+}
+
+func realtimeEvalStaticExprGroups(data []fexpr.ExprGroup, requestInfo *core.RequestInfo) bool {
+	var result bool
+
+	current := true
+
+	for i, group := range data {
+		var v bool
+
+		switch item := group.Item.(type) {
+		case fexpr.Expr:
+			v = realtimeStaticValuesEqual(
+				realtimeStaticTokenValue(item.Left, requestInfo),
+				realtimeStaticTokenValue(item.Right, requestInfo),
+			)
+			if item.Op == fexpr.SignNeq || item.Op == fexpr.SignAnyNeq {
+				v = !v
+			}
+		case fexpr.ExprGroup:
+			v = realtimeEvalStaticExprGroups([]fexpr.ExprGroup{item}, requestInfo)
+		case []fexpr.ExprGroup:
+			v = realtimeEvalStaticExprGroups(item, requestInfo)
+		}
+
+		if i > 0 && group.Join == fexpr.JoinOr {
+			result = result || current
+			current = v
+		} else {
+			current = current && v
+		}
+	}
+
+	return result || current
+}
+
+func realtimeStaticTokenValue(token fexpr.Token, requestInfo *core.RequestInfo) any {
+	switch token.Type {
+	case fexpr.TokenText:
+		return token.Literal
+	case fexpr.TokenNumber:
+		return cast.ToFloat64(token.Literal)
+	}
+
+	if fn, ok := realtimeStaticRuleFields[token.Literal]; ok {
+		return fn(requestInfo)
+	}
+
+	switch strings.ToLower(token.Literal) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	return nil // null
+}
+
+// realtimeStaticValuesEqual compares the static values in the same
+// way as the equal filter expressions in the db query, aka.:
+//   - NULL and empty string are considered equal
+//   - bool and number values are compared numerically
+//   - text and numeric values are never equal
+func realtimeStaticValuesEqual(a, b any) bool {
+	a = realtimeNormalizeStaticValue(a)
+	b = realtimeNormalizeStaticValue(b)
+
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		return ok && av == bv
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	}
+
+	return false
+}
+
+func realtimeNormalizeStaticValue(v any) any {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case bool:
+		if val {
+			return float64(1)
+		}
+		return float64(0)
+	case float64:
+		return val
+	default:
+		if f, err := cast.ToFloat64E(val); err == nil {
+			return f
+		}
+		return cast.ToString(val)
+	}
+}
+
+// -------------------------------------------------------------------
+
+// realtimeSender sends the realtime messages to the clients
+// applying the configured back-pressure policy.
+type realtimeSender struct {
+	app     core.App
+	stats   *realtimeStatsCollector
+	policy  string
+	timeout time.Duration
+}
+
+func newRealtimeSender(app core.App) *realtimeSender {
+	config := app.Settings().Realtime
+
+	return &realtimeSender{
+		app:     app,
+		stats:   realtimeGetStats(app),
+		policy:  config.BackpressurePolicy,
+		timeout: time.Duration(config.BackpressureTimeout) * time.Second,
+	}
+}
+
+// send sends the messages to the client sequentially in a separate goroutine.
+func (s *realtimeSender) send(client subscriptions.Client, messages ...subscriptions.Message) {
+	s.stats.messages.Add(int64(len(messages)))
+
+	timeoutClient, ok := client.(interface {
+		SendTimeout(m subscriptions.Message, timeout time.Duration) bool
+	})
+
+	// wait for the client without a timeout
+	if s.policy == "" || s.timeout <= 0 || !ok {
+		routine.FireAndForget(func() {
+			for _, msg := range messages {
+				client.Send(msg)
+			}
+		})
+		return
+	}
+
+	routine.FireAndForget(func() {
+		for _, msg := range messages {
+			if timeoutClient.SendTimeout(msg, s.timeout) || client.IsDiscarded() {
+				continue
+			}
+
+			if s.policy == core.RealtimeBackpressureDisconnect {
+				s.disconnect(client)
+				return
+			}
+
+			s.stats.dropped.Add(1)
+
+			s.app.Logger().Debug(
+				"Dropped realtime message of a slow client",
+				slog.String("clientId", client.Id()),
+				slog.String("message", msg.Name),
+			)
+		}
+	})
+}
+
+func (s *realtimeSender) disconnect(client subscriptions.Client) {
+	broker := s.app.SubscriptionsBroker()
+
+	// already disconnected
+	if _, err := broker.ClientById(client.Id()); err != nil {
+		return
+	}
+
+	broker.Unregister(client.Id())
+
+	s.stats.disconnected.Add(1)
+
+	s.app.Logger().Debug(
+		"Disconnected slow realtime client",
+		slog.String("clientId", client.Id()),
+	)
+}
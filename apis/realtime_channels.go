@@ -0,0 +1,371 @@
+package apis
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+// The custom realtime channel message actions.
+const (
+	RealtimeChannelActionPublish = "publish"
+	RealtimeChannelActionJoin    = "join"
+	RealtimeChannelActionLeave   = "leave"
+)
+
+const (
+	realtimeClientPresenceKey = "@pbRealtimePresence"
+	realtimeClientMemberIdKey = "@pbRealtimeMemberId"
+)
+
+// RealtimePublish publishes data to all clients subscribed
+// to the specified custom realtime channel.
+//
+// Note that the channel PublishRule is not checked and it is expected
+// to be used only by trusted server-side code (eg. in hooks):
+//
+//	app.OnRecordAfterUpdateSuccess("docs").BindFunc(func(e *core.RecordEvent) error {
+//		err := apis.RealtimePublish(e.App, "docs/"+e.Record.Id, map[string]any{"saved": true})
+//		if err != nil {
+//			return err
+//		}
+//		return e.Next()
+//	})
+func RealtimePublish(app core.App, channel string, data any) error {
+	return realtimePublishChannelData(app, &realtimeChannelData{
+		Action:  RealtimeChannelActionPublish,
+		Channel: channel,
+		Data:    data,
+	})
+}
+
+// realtimeChannelMember represents a single custom channel presence member
+// (or the publisher of a channel message).
+//
+// Note that the MemberId is a random per client identifier that is
+// safe to be shared with the other clients, unlike the client id
+// which must be known only to its own client because it is used
+// to manage the client subscriptions and to resume its history.
+type realtimeChannelMember struct {
+	MemberId           string `json:"memberId"`
+	AuthId             string `json:"authId"`
+	AuthCollectionName string `json:"authCollectionName"`
+}
+
+// realtimeChannelData represents the custom channel subscription message data.
+type realtimeChannelData struct {
+	realtimeChannelMember
+	Action  string `json:"action"`
+	Channel string `json:"channel"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func newRealtimeChannelMember(memberId string, auth *core.Record) realtimeChannelMember {
+	member := realtimeChannelMember{MemberId: memberId}
+
+	if auth != nil {
+		member.AuthId = auth.Id
+		member.AuthCollectionName = auth.Collection().Name
+	}
+
+	return member
+}
+
+var realtimeMemberIdMu sync.Mutex
+
+// realtimeClientMemberId returns the channels member id of the provided client
+// (a new one is generated on first call).
+func realtimeClientMemberId(client subscriptions.Client) string {
+	realtimeMemberIdMu.Lock()
+	defer realtimeMemberIdMu.Unlock()
+
+	id, _ := client.Get(realtimeClientMemberIdKey).(string)
+	if id == "" {
+		id = security.RandomString(40)
+		client.Set(realtimeClientMemberIdKey, id)
+	}
+
+	return id
+}
+
+func realtimePublishChannelData(app core.App, data *realtimeChannelData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	app.SubscriptionsBroker().Publish(data.Channel, raw)
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+type realtimePublishForm struct {
+	ClientId string `form:"clientId" json:"clientId"`
+	Channel  string `form:"channel" json:"channel"`
+	Data     any    `form:"data" json:"data"`
+}
+
+func (form *realtimePublishForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.ClientId, validation.Length(0, 255)),
+		validation.Field(&form.Channel, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+// realtimePublish handles the custom realtime channel publish request.
+//
+// The optional clientId is used only to identify the publisher (with its
+// member id) in the sent messages and it must have the same auth state as the request.
+func realtimePublish(e *core.RequestEvent) error {
+	form := new(realtimePublishForm)
+
+	err := e.BindBody(form)
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	err = form.validate()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	publisher := newRealtimeChannelMember("", e.Auth)
+
+	if form.ClientId != "" {
+		client, err := e.App.SubscriptionsBroker().ClientById(form.ClientId)
+		if err != nil {
+			return e.NotFoundError("Missing or invalid client id.", err)
+		}
+
+		clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+		if !isSameAuth(clientAuth, e.Auth) {
+			return e.ForbiddenError("The client and the request authorization don't match.", nil)
+		}
+
+		publisher.MemberId = realtimeClientMemberId(client)
+	}
+
+	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextRealtime)
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	config, ok := e.App.Settings().Realtime.FindChannel(form.Channel)
+	if !ok || !realtimeCanAccessChannel(e.App, requestInfo, config.PublishRule) {
+		return e.ForbiddenError("You are not allowed to publish to the realtime channel.", nil)
+	}
+
+	err = realtimePublishChannelData(e.App, &realtimeChannelData{
+		realtimeChannelMember: publisher,
+		Action:                RealtimeChannelActionPublish,
+		Channel:               form.Channel,
+		Data:                  form.Data,
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to publish the realtime channel message.", err)
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// realtimePresence returns the presence members of the ?channel query parameter.
+func realtimePresence(e *core.RequestEvent) error {
+	channel := e.Request.URL.Query().Get("channel")
+	if channel == "" {
+		return e.BadRequestError("Missing required channel query parameter.", nil)
+	}
+
+	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextRealtime)
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	// expose the channel name in the same way as for the subscriptions
+	requestInfo = requestInfo.Clone()
+	requestInfo.Body = map[string]any{"channel": channel}
+
+	members, err := realtimeChannelMembers(e.App, channel, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	return e.JSON(http.StatusOK, members)
+}
+
+// realtimeChannelMembers returns the sorted presence members of the specified channel.
+func realtimeChannelMembers(app core.App, channel string, requestInfo *core.RequestInfo) ([]realtimeChannelMember, error) {
+	config, ok := app.Settings().Realtime.FindChannel(channel)
+	if !ok || !config.Presence || !realtimeCanAccessChannel(app, requestInfo, config.SubscribeRule) {
+		return nil, router.NewForbiddenError("The realtime channel doesn't support presence or you are not allowed to access it.", nil)
+	}
+
+	members := app.SubscriptionsBroker().Members(channel)
+
+	result := make([]realtimeChannelMember, 0, len(members))
+	for _, data := range members {
+		if member, ok := data.(realtimeChannelMember); ok {
+			result = append(result, member)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b realtimeChannelMember) int {
+		return strings.Compare(a.MemberId, b.MemberId)
+	})
+
+	return result, nil
+}
+
+// realtimeCheckChannelSubscriptions checks whether auth is allowed
+// to subscribe to all custom channels from the subscriptions list.
+func realtimeCheckChannelSubscriptions(app core.App, auth *core.Record, subs []string) error {
+	for _, sub := range subs {
+		topic, options := subscriptions.ParseSubscription(sub)
+
+		channel, ok := strings.CutPrefix(topic, subscriptions.ChannelTopicPrefix)
+		if !ok {
+			continue
+		}
+
+		requestInfo := &core.RequestInfo{
+			Context: core.RequestInfoContextRealtime,
+			Method:  "GET",
+			Query:   options.Query,
+			Headers: options.Headers,
+			Body:    map[string]any{"channel": channel},
+			Auth:    auth,
+		}
+
+		config, ok := app.Settings().Realtime.FindChannel(channel)
+		if !ok || !realtimeCanAccessChannel(app, requestInfo, config.SubscribeRule) {
+			return router.NewForbiddenError("You are not allowed to subscribe to the realtime channel "+channel+".", nil)
+		}
+	}
+
+	return nil
+}
+
+// realtimeCanAccessChannel checks whether the request info satisfies the specified channel rule.
+//
+// Similar to the collection API rules, nil rule means superusers only
+// and empty string means everyone.
+func realtimeCanAccessChannel(app core.App, requestInfo *core.RequestInfo, rule *string) bool {
+	if requestInfo.HasSuperuserAuth() {
+		return true
+	}
+
+	if rule == nil {
+		return false
+	}
+
+	if *rule == "" {
+		return true
+	}
+
+	// the channels don't have fields of their own so only the
+	// @request.* and @collection.* identifiers are allowed
+	resolver := core.NewRecordFieldResolver(app, core.NewBaseCollection(""), requestInfo, true)
+	resolver.SetAllowedFields(slices.DeleteFunc(resolver.AllowedFields(), func(pattern string) bool {
+		return !strings.HasPrefix(pattern, `^\@`)
+	}))
+
+	expr, err := search.FilterData(*rule).BuildExpr(resolver)
+	if err != nil {
+		app.Logger().Debug(
+			"Invalid realtime channel rule",
+			slog.String("rule", *rule),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+
+	query := app.ConcurrentDB().Select("(1)").From("(SELECT 1)").AndWhere(expr)
+	resolver.UpdateQuery(query)
+
+	var exists int
+
+	err = query.Limit(1).Row(&exists)
+
+	return err == nil && exists > 0
+}
+
+// realtimeSyncClientPresence joins or leaves the presence channels
+// based on the current client custom channel subscriptions.
+func realtimeSyncClientPresence(app core.App, client subscriptions.Client) {
+	broker := app.SubscriptionsBroker()
+
+	auth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+	member := newRealtimeChannelMember(realtimeClientMemberId(client), auth)
+
+	var current []string
+	for sub := range client.Subscriptions(subscriptions.ChannelTopicPrefix) {
+		topic, _, _ := strings.Cut(sub, "?")
+		channel := strings.TrimPrefix(topic, subscriptions.ChannelTopicPrefix)
+
+		config, ok := app.Settings().Realtime.FindChannel(channel)
+		if ok && config.Presence && !slices.Contains(current, channel) {
+			current = append(current, channel)
+		}
+	}
+	slices.Sort(current)
+
+	joined, _ := client.Get(realtimeClientPresenceKey).([]string)
+
+	for _, channel := range joined {
+		if !slices.Contains(current, channel) && broker.Leave(channel, client.Id()) {
+			realtimePublishPresenceEvent(app, RealtimeChannelActionLeave, channel, member)
+		}
+	}
+
+	for _, channel := range current {
+		if broker.Join(channel, client.Id(), member) {
+			realtimePublishPresenceEvent(app, RealtimeChannelActionJoin, channel, member)
+		}
+	}
+
+	client.Set(realtimeClientPresenceKey, current)
+}
+
+// realtimeLeaveClientPresence removes the client from all presence channels.
+func realtimeLeaveClientPresence(app core.App, client subscriptions.Client) {
+	auth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+	member := newRealtimeChannelMember(realtimeClientMemberId(client), auth)
+
+	for _, channel := range app.SubscriptionsBroker().LeaveAll(client.Id()) {
+		realtimePublishPresenceEvent(app, RealtimeChannelActionLeave, channel, member)
+	}
+
+	client.Unset(realtimeClientPresenceKey)
+}
+
+func realtimePublishPresenceEvent(app core.App, action string, channel string, member realtimeChannelMember) {
+	err := realtimePublishChannelData(app, &realtimeChannelData{
+		realtimeChannelMember: member,
+		Action:                action,
+		Channel:               channel,
+	})
+	if err != nil {
+		app.Logger().Debug(
+			"Failed to publish realtime presence event",
+			slog.String("memberId", member.MemberId),
+			slog.String("channel", channel),
+			slog.String("action", action),
+			slog.String("error", err.Error()),
+		)
+	}
+}
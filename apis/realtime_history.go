@@ -0,0 +1,863 @@
+package apis
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+// RealtimeResetMessageName is the name of the realtime message that is sent
+// to the reconnecting clients when the missed events can't be replayed
+// (eg. because of too large gap), meaning that the client should
+// refetch its data.
+const RealtimeResetMessageName = "PB_RESET"
+
+// RealtimeLastEventIdQueryParam is the realtime connect query parameter
+// alternative of the "Last-Event-ID" header (eg. for the WebSocket connections).
+const RealtimeLastEventIdQueryParam = "lastEventId"
+
+const (
+	realtimeHistoryStoreKey          = "pbRealtimeHistory"
+	realtimeClientPositionsStoreKey  = "pbRealtimeClientPositions"
+	realtimePendingDeleteStorePrefix = "pbRealtimePendingDelete/"
+	realtimeClientLastEventKey       = "@pbRealtimeLastEventId"
+	realtimeClientResumeKey          = "@pbRealtimeResumeId"
+	realtimeClientSentEventsKey      = "@pbRealtimeSentEvents"
+	realtimeEventsTable              = "_realtimeEvents"
+	realtimeMaxClientPositions       = 10000
+	realtimeMaxClientSentEvents      = 1000
+)
+
+// realtimeEvent defines a single realtime record event history entry.
+type realtimeEvent struct {
+	record *core.Record
+	action string
+	id     int64
+}
+
+// realtimeHistory defines a bounded per collection store
+// with the most recent realtime record events.
+type realtimeHistory interface {
+	// add registers a new record event and returns its id.
+	add(action string, record *core.Record) (int64, error)
+
+	// lastId returns the id of the last registered event.
+	lastId() (int64, error)
+
+	// since returns the events of the specified collections registered after afterId
+	// (in ascending order).
+	//
+	// complete reports whether the history covers all events after afterId,
+	// aka. whether no events were evicted or missed.
+	since(collectionIds []string, afterId int64) (events []*realtimeEvent, complete bool, err error)
+}
+
+// realtimeHistoryStoreEntry is the app store wrapper of the current realtime
+// history used to detect settings changes.
+type realtimeHistoryStoreEntry struct {
+	history realtimeHistory
+	size    int
+	persist bool
+}
+
+var realtimeHistoryMu sync.Mutex
+
+// realtimeAppHistory returns the realtime history instance of the app
+// based on its current settings.
+//
+// Returns nil if the realtime history is disabled.
+func realtimeAppHistory(app core.App) realtimeHistory {
+	config := app.Settings().Realtime
+	if config.HistorySize <= 0 {
+		app.Store().Remove(realtimeHistoryStoreKey)
+		return nil
+	}
+
+	realtimeHistoryMu.Lock()
+	defer realtimeHistoryMu.Unlock()
+
+	entry, _ := app.Store().Get(realtimeHistoryStoreKey).(*realtimeHistoryStoreEntry)
+	if entry != nil && entry.size == config.HistorySize && entry.persist == config.HistoryPersist {
+		return entry.history
+	}
+
+	// (re)initialize on settings change
+	// (clients with older event ids will receive reset message)
+	entry = &realtimeHistoryStoreEntry{size: config.HistorySize, persist: config.HistoryPersist}
+	if config.HistoryPersist {
+		entry.history = newRealtimeDBHistory(app, config.HistorySize)
+	} else {
+		entry.history = newRealtimeMemoryHistory(config.HistorySize)
+	}
+	app.Store().Set(realtimeHistoryStoreKey, entry)
+
+	return entry.history
+}
+
+// realtimeHistoryAdd registers a new record event in the app realtime history
+// and returns its id as string.
+//
+// Returns empty string if the realtime history is disabled or failed to register the event.
+func realtimeHistoryAdd(app core.App, action string, record *core.Record) string {
+	history := realtimeAppHistory(app)
+	if history == nil {
+		return ""
+	}
+
+	id, err := history.add(action, record)
+	if err != nil {
+		app.Logger().Debug(
+			"Failed to register realtime history event",
+			slog.String("id", record.Id),
+			slog.String("collectionName", record.Collection().Name),
+			slog.String("action", action),
+			slog.String("error", err.Error()),
+		)
+		return ""
+	}
+
+	return strconv.FormatInt(id, 10)
+}
+
+// realtimeHistorySetPendingDelete stores the record that is about
+// to be deleted until the delete transaction completes.
+func realtimeHistorySetPendingDelete(app core.App, key string, record *core.Record) {
+	if realtimeAppHistory(app) == nil {
+		return
+	}
+
+	app.Store().Set(realtimePendingDeleteStorePrefix+key, record.Fresh())
+}
+
+// realtimeHistoryUnsetPendingDelete removes the pending delete record
+// associated with the specified key and returns it (if any).
+func realtimeHistoryUnsetPendingDelete(app core.App, key string) *core.Record {
+	storeKey := realtimePendingDeleteStorePrefix + key
+
+	record, _ := app.Store().Get(storeKey).(*core.Record)
+	if record != nil {
+		app.Store().Remove(storeKey)
+	}
+
+	return record
+}
+
+// realtimeInitClientHistory initializes the realtime history state of a new client.
+//
+// If the request has "Last-Event-ID" header or lastEventId query parameter
+// the client is marked as reconnecting and its missed events
+// will be replayed on the first subscriptions update.
+//
+// The last event id could be either a record event id or the id
+// of a previous client (aka. the PB_CONNECT message id).
+func realtimeInitClientHistory(app core.App, client subscriptions.Client, r *http.Request) {
+	history := realtimeAppHistory(app)
+	if history == nil {
+		return
+	}
+
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get(RealtimeLastEventIdQueryParam)
+	}
+
+	if raw == "" {
+		lastId, err := history.lastId()
+		if err != nil {
+			app.Logger().Debug(
+				"Failed to load the last realtime history event id",
+				slog.String("clientId", client.Id()),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+
+		client.Set(realtimeClientLastEventKey, lastId)
+		return
+	}
+
+	resumeId := parseRealtimeEventId(raw)
+	if resumeId < 0 {
+		resumeId = realtimeAppClientPositions(app).pop(raw)
+	}
+
+	client.Set(realtimeClientResumeKey, resumeId)
+	client.Set(realtimeClientSentEventsKey, newRealtimeSentEvents(realtimeMaxClientSentEvents))
+	if resumeId >= 0 {
+		client.Set(realtimeClientLastEventKey, resumeId)
+	}
+}
+
+// realtimeSaveClientHistory remembers the last received event id
+// of the disconnected client so that it could be resolved later
+// on reconnect by its client id.
+func realtimeSaveClientHistory(app core.App, client subscriptions.Client) {
+	lastId, ok := client.Get(realtimeClientLastEventKey).(int64)
+	if !ok {
+		return
+	}
+
+	realtimeAppClientPositions(app).set(client.Id(), lastId)
+}
+
+// realtimeTrackClientMessage updates the client realtime history
+// position and reports whether the message should be delivered,
+// aka. whether it wasn't already sent to the client (eg. as replayed event).
+func realtimeTrackClientMessage(client subscriptions.Client, msg *subscriptions.Message) bool {
+	if msg.Id == "" {
+		return true
+	}
+
+	id := parseRealtimeEventId(msg.Id)
+	if id < 0 {
+		return true
+	}
+
+	if sent, ok := client.Get(realtimeClientSentEventsKey).(*realtimeSentEvents); ok && !sent.add(id) {
+		return false
+	}
+
+	if lastId, _ := client.Get(realtimeClientLastEventKey).(int64); id > lastId {
+		client.Set(realtimeClientLastEventKey, id)
+	}
+
+	return true
+}
+
+// realtimeReplayClientHistory sends to the reconnecting client the
+// missed record events of its current subscriptions (if any).
+//
+// The replayed events pass through the same access checks as the regular broadcasts.
+// If the missed events can't be replayed (eg. because of too large gap),
+// a single [RealtimeResetMessageName] message is sent instead.
+func realtimeReplayClientHistory(app core.App, client subscriptions.Client) {
+	resumeId, ok := client.Get(realtimeClientResumeKey).(int64)
+	if !ok {
+		return // not a reconnecting client or already replayed
+	}
+	client.Unset(realtimeClientResumeKey)
+
+	messages, err := realtimeHistoryMessages(app, client, resumeId)
+	if err != nil {
+		app.Logger().Debug(
+			"Failed to replay the realtime history events",
+			slog.String("clientId", client.Id()),
+			slog.String("error", err.Error()),
+		)
+
+		messages = []subscriptions.Message{realtimeResetMessage(app)}
+	}
+
+	if len(messages) == 0 {
+		return
+	}
+
+	routine.FireAndForget(func() {
+		for _, msg := range messages {
+			client.Send(msg)
+		}
+	})
+}
+
+// realtimeHistoryMessages returns the client messages of the history
+// events registered after afterId.
+func realtimeHistoryMessages(app core.App, client subscriptions.Client, afterId int64) ([]subscriptions.Message, error) {
+	history := realtimeAppHistory(app)
+	if history == nil || afterId < 0 {
+		return []subscriptions.Message{realtimeResetMessage(app)}, nil
+	}
+
+	collectionIds := realtimeClientCollectionIds(app, client)
+	if len(collectionIds) == 0 {
+		return nil, nil
+	}
+
+	events, complete, err := history.since(collectionIds, afterId)
+	if err != nil {
+		return nil, err
+	}
+	if !complete {
+		return []subscriptions.Message{realtimeResetMessage(app)}, nil
+	}
+
+	var result []subscriptions.Message
+
+	for _, event := range events {
+		ruleMap := realtimeSubscriptionRuleMap(event.record)
+
+		// the deleted records can't be checked against db
+		if event.action == "delete" && realtimeRequireDBAccessCheck(client, ruleMap) {
+			return []subscriptions.Message{realtimeResetMessage(app)}, nil
+		}
+
+		eventId := strconv.FormatInt(event.id, 10)
+
+		result = append(result, realtimeClientRecordMessages(app, app, client, ruleMap, event.action, event.record, eventId, nil)...)
+	}
+
+	return result, nil
+}
+
+// realtimeResetMessage returns a new [RealtimeResetMessageName] message
+// with the current last history event id.
+func realtimeResetMessage(app core.App) subscriptions.Message {
+	msg := subscriptions.Message{
+		Name: RealtimeResetMessageName,
+		Data: []byte("{}"),
+	}
+
+	if history := realtimeAppHistory(app); history != nil {
+		if lastId, err := history.lastId(); err == nil {
+			msg.Id = strconv.FormatInt(lastId, 10)
+		}
+	}
+
+	return msg
+}
+
+// realtimeClientCollectionIds returns the ids of the collections
+// that the client is subscribed to.
+func realtimeClientCollectionIds(app core.App, client subscriptions.Client) []string {
+	var result []string
+
+	for sub := range client.Subscriptions() {
+		topic, _, _ := strings.Cut(sub, "?")
+		name, _, _ := strings.Cut(topic, "/")
+
+		collection, err := app.FindCachedCollectionByNameOrId(name)
+		if err != nil || slices.Contains(result, collection.Id) {
+			continue
+		}
+
+		result = append(result, collection.Id)
+	}
+
+	return result
+}
+
+// realtimeRequireDBAccessCheck reports whether any of the client
+// subscriptions require a db query to check the record access.
+func realtimeRequireDBAccessCheck(client subscriptions.Client, ruleMap map[string]*string) bool {
+	clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+	isSuperuser := clientAuth != nil && clientAuth.IsSuperuser()
+
+	for prefix, rule := range ruleMap {
+		for _, options := range client.Subscriptions(prefix) {
+			if options.Query[search.FilterQueryParam] != "" {
+				return true
+			}
+
+			if !isSuperuser && rule != nil && *rule != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// realtimeCanAccessRecordSnapshot is similar to [realtimeCanAccessRecord]
+// but checks the access rule and the subscription filter against the
+// provided record data instead of the record current db row.
+//
+// The record data is loaded as a single row CTE table with the same
+// columns as the record collection table.
+func realtimeCanAccessRecordSnapshot(
+	app core.App,
+	record *core.Record,
+	requestInfo *core.RequestInfo,
+	accessRule *string,
+) bool {
+	isSuperuser := requestInfo.HasSuperuserAuth()
+
+	if !isSuperuser && accessRule == nil {
+		return false
+	}
+
+	filter := requestInfo.Query[search.FilterQueryParam]
+	if filter != "" && checkForSuperuserOnlyRuleFields(requestInfo) != nil {
+		return false
+	}
+
+	if (isSuperuser || *accessRule == "") && filter == "" {
+		return true // no further checks needed
+	}
+
+	export, err := record.DBExport(app)
+	if err != nil {
+		return false
+	}
+
+	params := make(dbx.Params, len(export))
+	selects := make([]string, 0, len(export))
+	for k, v := range export {
+		k = inflector.Columnify(k)
+		param := "__pb_snapshot__" + k
+		params[param] = v
+		selects = append(selects, "{:"+param+"} AS [["+k+"]]")
+	}
+
+	// shallow clone the record collection with a unique table name
+	// (the id is kept so that the self and back relations are resolved as usual)
+	snapshotCollection := *record.Collection()
+	snapshotCollection.Name += "__pb_snapshot__" + security.PseudorandomString(6)
+
+	withFrom := fmt.Sprintf("WITH {{%s}} AS (SELECT %s)", snapshotCollection.Name, strings.Join(selects, ","))
+
+	exists := func(filter string, allowHiddenFields bool) bool {
+		q := app.ConcurrentDB().Select("(1)").
+			PreFragment(withFrom).
+			From(snapshotCollection.Name).
+			AndBind(params)
+
+		resolver := core.NewRecordFieldResolver(app, &snapshotCollection, requestInfo, allowHiddenFields)
+		expr, err := search.FilterData(filter).BuildExpr(resolver)
+		if err != nil {
+			return false
+		}
+
+		q.AndWhere(expr)
+		resolver.UpdateQuery(q)
+
+		var result int
+		err = q.Limit(1).Row(&result)
+
+		return err == nil && result > 0
+	}
+
+	// check the access rule
+	if !isSuperuser && *accessRule != "" && !exists(*accessRule, true) {
+		return false
+	}
+
+	// check the subscription client-side filter (if any)
+	return filter == "" || exists(filter, false)
+}
+
+// -------------------------------------------------------------------
+
+// realtimeClientPositions keeps the last received event ids
+// of the recently disconnected clients.
+type realtimeClientPositions struct {
+	store *store.Store[string, int64]
+}
+
+func realtimeAppClientPositions(app core.App) *realtimeClientPositions {
+	return app.Store().GetOrSet(realtimeClientPositionsStoreKey, func() any {
+		return &realtimeClientPositions{store: store.New[string, int64](nil)}
+	}).(*realtimeClientPositions)
+}
+
+func (p *realtimeClientPositions) set(clientId string, lastId int64) {
+	if !p.store.SetIfLessThanLimit(clientId, lastId, realtimeMaxClientPositions) {
+		// forget the older positions
+		// (the related clients will receive reset message on reconnect)
+		p.store.RemoveAll()
+		p.store.Set(clientId, lastId)
+	}
+}
+
+// pop returns and removes the last event id of the specified client.
+//
+// Returns -1 if there is no such client.
+func (p *realtimeClientPositions) pop(clientId string) int64 {
+	lastId, ok := p.store.GetOk(clientId)
+	if !ok {
+		return -1
+	}
+
+	p.store.Remove(clientId)
+
+	return lastId
+}
+
+// realtimeSentEvents is a bounded set with the most recent event ids sent to a client.
+type realtimeSentEvents struct {
+	ids   map[int64]struct{}
+	queue []int64
+	limit int
+	mu    sync.Mutex
+}
+
+func newRealtimeSentEvents(limit int) *realtimeSentEvents {
+	return &realtimeSentEvents{
+		ids:   map[int64]struct{}{},
+		limit: limit,
+	}
+}
+
+// add registers the specified event id and reports whether it wasn't already registered.
+func (s *realtimeSentEvents) add(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+
+	if len(s.queue) >= s.limit {
+		delete(s.ids, s.queue[0])
+		s.queue = s.queue[1:]
+	}
+
+	s.ids[id] = struct{}{}
+	s.queue = append(s.queue, id)
+
+	return true
+}
+
+// -------------------------------------------------------------------
+
+var _ realtimeHistory = (*realtimeMemoryHistory)(nil)
+
+// realtimeMemoryHistory is an in-memory realtimeHistory implementation
+// with a ring buffer per collection.
+//
+// The event ids start from the current unix time in microseconds so
+// that they continue to increase after app restart and the events
+// missed during the restart could be detected.
+type realtimeMemoryHistory struct {
+	buffers map[string]*realtimeEventsRing
+	size    int
+	startId int64
+	nextId  int64
+	mu      sync.RWMutex
+}
+
+type realtimeEventsRing struct {
+	events    []*realtimeEvent
+	head      int   // index of the oldest event
+	evictedId int64 // id of the last evicted event
+}
+
+func newRealtimeMemoryHistory(size int) *realtimeMemoryHistory {
+	startId := time.Now().UnixMicro()
+
+	return &realtimeMemoryHistory{
+		buffers: map[string]*realtimeEventsRing{},
+		size:    size,
+		startId: startId,
+		nextId:  startId + 1,
+	}
+}
+
+func (h *realtimeMemoryHistory) add(action string, record *core.Record) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	event := &realtimeEvent{
+		id:     h.nextId,
+		action: action,
+		record: record.Fresh(),
+	}
+	h.nextId++
+
+	collectionId := record.Collection().Id
+
+	ring, ok := h.buffers[collectionId]
+	if !ok {
+		ring = &realtimeEventsRing{events: make([]*realtimeEvent, 0, min(h.size, 16))}
+		h.buffers[collectionId] = ring
+	}
+
+	if len(ring.events) < h.size {
+		ring.events = append(ring.events, event)
+	} else {
+		ring.evictedId = ring.events[ring.head].id
+		ring.events[ring.head] = event
+		ring.head = (ring.head + 1) % len(ring.events)
+	}
+
+	return event.id, nil
+}
+
+func (h *realtimeMemoryHistory) lastId() (int64, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.nextId - 1, nil
+}
+
+func (h *realtimeMemoryHistory) since(collectionIds []string, afterId int64) ([]*realtimeEvent, bool, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if afterId < h.startId || afterId >= h.nextId {
+		return nil, false, nil
+	}
+
+	var result []*realtimeEvent
+
+	for _, id := range collectionIds {
+		ring, ok := h.buffers[id]
+		if !ok {
+			continue
+		}
+
+		if ring.evictedId > afterId {
+			return nil, false, nil
+		}
+
+		for i := range ring.events {
+			event := ring.events[(ring.head+i)%len(ring.events)]
+			if event.id > afterId {
+				result = append(result, event)
+			}
+		}
+	}
+
+	sortRealtimeEvents(result)
+
+	return result, true, nil
+}
+
+// -------------------------------------------------------------------
+
+var _ realtimeHistory = (*realtimeDBHistory)(nil)
+
+// realtimeDBHistory is an auxiliary database realtimeHistory implementation.
+//
+// Each collection keeps up to size+1 events where the extra (oldest)
+// one is the last evicted event used only for the gap detection.
+//
+// The records are stored as json snapshot without the password
+// and tokenKey fields and with the encrypted fields in their stored form.
+type realtimeDBHistory struct {
+	app  core.App
+	size int
+}
+
+type realtimeEventRow struct {
+	Id         int64  `db:"id"`
+	Collection string `db:"collection"`
+	Action     string `db:"action"`
+	Record     string `db:"record"`
+}
+
+func newRealtimeDBHistory(app core.App, size int) *realtimeDBHistory {
+	return &realtimeDBHistory{app: app, size: size}
+}
+
+func (h *realtimeDBHistory) add(action string, record *core.Record) (int64, error) {
+	snapshot, err := realtimeRecordSnapshot(record)
+	if err != nil {
+		return 0, err
+	}
+
+	collectionId := record.Collection().Id
+
+	result, err := h.app.AuxNonconcurrentDB().Insert(realtimeEventsTable, dbx.Params{
+		"collection": collectionId,
+		"action":     action,
+		"record":     string(snapshot),
+	}).Execute()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// evict the events outside of the history size
+	// (the newest evicted event is kept as boundary marker for the gap detection)
+	_, err = h.app.AuxNonconcurrentDB().NewQuery(`
+		DELETE FROM {{` + realtimeEventsTable + `}}
+		WHERE [[collection]] = {:collection} AND [[id]] <= COALESCE((
+			SELECT [[id]] FROM {{` + realtimeEventsTable + `}}
+			WHERE [[collection]] = {:collection}
+			ORDER BY [[id]] DESC
+			LIMIT 1 OFFSET {:offset}
+		), 0)
+	`).Bind(dbx.Params{"collection": collectionId, "offset": h.size + 1}).Execute()
+
+	return id, err
+}
+
+func (h *realtimeDBHistory) lastId() (int64, error) {
+	var id int64
+
+	// note: the autoincrement sequence is used so that the last id
+	// is available even after all events have been deleted
+	err := h.app.AuxConcurrentDB().
+		NewQuery("SELECT COALESCE((SELECT [[seq]] FROM sqlite_sequence WHERE [[name]] = {:table}), 0)").
+		Bind(dbx.Params{"table": realtimeEventsTable}).
+		Row(&id)
+
+	return id, err
+}
+
+func (h *realtimeDBHistory) since(collectionIds []string, afterId int64) ([]*realtimeEvent, bool, error) {
+	lastId, err := h.lastId()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if afterId < 0 || afterId > lastId {
+		return nil, false, nil
+	}
+
+	if len(collectionIds) == 0 {
+		return nil, true, nil
+	}
+
+	ids := make([]any, len(collectionIds))
+	for i, id := range collectionIds {
+		ids[i] = id
+	}
+
+	// check for evicted events
+	// (if the history is full, its oldest event is the last evicted one)
+	for _, id := range collectionIds {
+		var total int
+		var boundaryId int64
+		err := h.app.AuxConcurrentDB().Select("count(*)", "COALESCE(min([[id]]), 0)").
+			From(realtimeEventsTable).
+			AndWhere(dbx.HashExp{"collection": id}).
+			Row(&total, &boundaryId)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if total > h.size && boundaryId > afterId {
+			return nil, false, nil
+		}
+	}
+
+	rows := []*realtimeEventRow{}
+	err = h.app.AuxConcurrentDB().Select("id", "collection", "action", "record").
+		From(realtimeEventsTable).
+		AndWhere(dbx.In("collection", ids...)).
+		AndWhere(dbx.NewExp("[[id]] > {:afterId}", dbx.Params{"afterId": afterId})).
+		OrderBy("id ASC").
+		All(&rows)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := make([]*realtimeEvent, 0, len(rows))
+	for _, row := range rows {
+		collection, err := h.app.FindCachedCollectionByNameOrId(row.Collection)
+		if err != nil {
+			continue // deleted collection
+		}
+
+		record, err := realtimeRecordFromSnapshot(h.app, collection, []byte(row.Record))
+		if err != nil {
+			return nil, false, err
+		}
+
+		result = append(result, &realtimeEvent{
+			id:     row.Id,
+			action: row.Action,
+			record: record,
+		})
+	}
+
+	return result, true, nil
+}
+
+// realtimeRecordSnapshot serializes the record fields data for
+// the realtime history persistence.
+func realtimeRecordSnapshot(record *core.Record) ([]byte, error) {
+	data := make(map[string]any, len(record.Collection().Fields))
+
+	for _, field := range record.Collection().Fields {
+		name := field.GetName()
+
+		switch f := field.(type) {
+		case *core.PasswordField:
+			continue
+		case *core.EncryptedField:
+			v, err := f.DriverValue(record)
+			if err != nil {
+				return nil, err
+			}
+			data[name] = v
+			continue
+		}
+
+		if name == core.FieldNameTokenKey && record.Collection().IsAuth() {
+			continue
+		}
+
+		data[name] = record.Get(name)
+	}
+
+	return json.Marshal(data)
+}
+
+// realtimeRecordFromSnapshot loads a realtimeRecordSnapshot serialized record.
+func realtimeRecordFromSnapshot(app core.App, collection *core.Collection, raw []byte) (*core.Record, error) {
+	data := map[string]any{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+
+	record := core.NewRecord(collection)
+
+	for _, field := range collection.Fields {
+		name := field.GetName()
+
+		v, ok := data[name]
+		if !ok {
+			continue
+		}
+
+		if f, ok := field.(*core.EncryptedField); ok {
+			prepared, err := f.PrepareValue(record, v)
+			if err != nil {
+				return nil, err
+			}
+			record.SetRaw(name, prepared)
+			continue
+		}
+
+		record.Set(name, v)
+	}
+
+	// the encrypted values are decrypted with the app key on access
+	core.BindEncryptedFields(app, record)
+
+	record.PostScan()
+
+	return record, nil
+}
+
+// -------------------------------------------------------------------
+
+// sortRealtimeEvents sorts in place the events by their id.
+func sortRealtimeEvents(events []*realtimeEvent) {
+	slices.SortFunc(events, func(a, b *realtimeEvent) int {
+		return cmp.Compare(a.id, b.id)
+	})
+}
+
+// parseRealtimeEventId parses a realtime event id string.
+//
+// Returns -1 for an invalid event id.
+func parseRealtimeEventId(raw string) int64 {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return -1
+	}
+
+	return id
+}
@@ -0,0 +1,510 @@
+package apis_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"golang.org/x/net/websocket"
+)
+
+func TestRealtimeHistoryResumeWS(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Realtime.HistorySize = 3
+	app.Settings().Realtime.HistoryPersist = false
+
+	collection := core.NewBaseCollection("history_test")
+	collection.ListRule = types.Pointer("")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	server := startRealtimeWSTestServer(t, app)
+	defer server.Close()
+
+	// initial connection
+	// ---
+	conn1, clientId1 := dialRealtimeHistoryWS(t, server, "")
+
+	if reply := sendRealtimeWSMessage(t, conn1, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	saveRealtimeHistoryTestRecord(t, app, collection, "a")
+
+	frameA := readRealtimeHistoryWSFrame(t, conn1)
+	if frameA.Event != "history_test/*" || frameA.Id == "" || !strings.Contains(string(frameA.Data), `"title":"a"`) {
+		t.Fatalf("Expected record event with id, got %v", frameA)
+	}
+
+	conn1.Close()
+	waitRealtimeClientsDisconnect(t, app)
+
+	// missed events
+	// ---
+	recordB := saveRealtimeHistoryTestRecord(t, app, collection, "b")
+	recordB.Set("title", "b2")
+	if err := app.Save(recordB); err != nil {
+		t.Fatal(err)
+	}
+
+	// resume from the last received event id
+	// ---
+	conn2, clientId2 := dialRealtimeHistoryWS(t, server, frameA.Id)
+
+	if reply := sendRealtimeWSMessage(t, conn2, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	replayed := []*realtimeHistoryWSFrame{
+		readRealtimeHistoryWSFrame(t, conn2),
+		readRealtimeHistoryWSFrame(t, conn2),
+	}
+	if !strings.Contains(string(replayed[0].Data), `"action":"create"`) || !strings.Contains(string(replayed[0].Data), `"title":"b"`) {
+		t.Fatalf("Expected replayed create event, got %v", replayed[0])
+	}
+	if !strings.Contains(string(replayed[1].Data), `"action":"update"`) || !strings.Contains(string(replayed[1].Data), `"title":"b2"`) {
+		t.Fatalf("Expected replayed update event, got %v", replayed[1])
+	}
+	if parseTestEventId(t, frameA.Id) >= parseTestEventId(t, replayed[0].Id) ||
+		parseTestEventId(t, replayed[0].Id) >= parseTestEventId(t, replayed[1].Id) {
+		t.Fatalf("Expected increasing event ids, got %q, %q, %q", frameA.Id, replayed[0].Id, replayed[1].Id)
+	}
+	if frame, ok := readRealtimeWSFrame(t, conn2, 300*time.Millisecond); ok {
+		t.Fatalf("Expected no more events, got %s %s", frame.Event, frame.Data)
+	}
+
+	// the replay is performed only once
+	if reply := sendRealtimeWSMessage(t, conn2, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/abc"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+	if frame, ok := readRealtimeWSFrame(t, conn2, 300*time.Millisecond); ok {
+		t.Fatalf("Expected no more events, got %s %s", frame.Event, frame.Data)
+	}
+
+	conn2.Close()
+	waitRealtimeClientsDisconnect(t, app)
+
+	// resume with the previous client id
+	// ---
+	saveRealtimeHistoryTestRecord(t, app, collection, "c")
+
+	conn3, _ := dialRealtimeHistoryWS(t, server, clientId2)
+
+	if reply := sendRealtimeWSMessage(t, conn3, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	if frame := readRealtimeHistoryWSFrame(t, conn3); !strings.Contains(string(frame.Data), `"title":"c"`) {
+		t.Fatalf("Expected replayed c create event, got %v", frame)
+	}
+	if frame, ok := readRealtimeWSFrame(t, conn3, 300*time.Millisecond); ok {
+		t.Fatalf("Expected no more events, got %s %s", frame.Event, frame.Data)
+	}
+
+	conn3.Close()
+	waitRealtimeClientsDisconnect(t, app)
+
+	// reset because of evicted events
+	// ---
+	for i := 0; i < 3; i++ {
+		saveRealtimeHistoryTestRecord(t, app, collection, "d"+strconv.Itoa(i))
+	}
+
+	for _, lastEventId := range []string{frameA.Id, "invalid", clientId1} {
+		conn, _ := dialRealtimeHistoryWS(t, server, lastEventId)
+
+		if reply := sendRealtimeWSMessage(t, conn, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+			t.Fatalf("[%s] Expected PB_ACK reply, got %s %s", lastEventId, reply.Event, reply.Data)
+		}
+
+		if frame := readRealtimeHistoryWSFrame(t, conn); frame.Event != apis.RealtimeResetMessageName || frame.Id == "" {
+			t.Fatalf("[%s] Expected %s event, got %v", lastEventId, apis.RealtimeResetMessageName, frame)
+		}
+
+		conn.Close()
+		waitRealtimeClientsDisconnect(t, app)
+	}
+}
+
+func TestRealtimeHistoryResumeDeleteWithRule(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Realtime.HistorySize = 10
+
+	collection := core.NewBaseCollection("history_test")
+	collection.ListRule = types.Pointer("title != 'hidden'")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	server := startRealtimeWSTestServer(t, app)
+	defer server.Close()
+
+	conn1, clientId1 := dialRealtimeHistoryWS(t, server, "")
+	if reply := sendRealtimeWSMessage(t, conn1, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+	conn1.Close()
+	waitRealtimeClientsDisconnect(t, app)
+
+	// the update is replayed after the current rule check
+	visible := saveRealtimeHistoryTestRecord(t, app, collection, "visible")
+	saveRealtimeHistoryTestRecord(t, app, collection, "hidden")
+
+	conn2, clientId2 := dialRealtimeHistoryWS(t, server, clientId1)
+	if reply := sendRealtimeWSMessage(t, conn2, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+	if frame := readRealtimeHistoryWSFrame(t, conn2); !strings.Contains(string(frame.Data), `"title":"visible"`) {
+		t.Fatalf("Expected replayed visible create event, got %v", frame)
+	}
+	if frame, ok := readRealtimeWSFrame(t, conn2, 300*time.Millisecond); ok {
+		t.Fatalf("Expected no more events, got %s %s", frame.Event, frame.Data)
+	}
+	conn2.Close()
+	waitRealtimeClientsDisconnect(t, app)
+
+	// the deleted record can't be checked against the rule
+	if err := app.Delete(visible); err != nil {
+		t.Fatal(err)
+	}
+
+	conn3, _ := dialRealtimeHistoryWS(t, server, clientId2)
+	if reply := sendRealtimeWSMessage(t, conn3, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+	if frame := readRealtimeHistoryWSFrame(t, conn3); frame.Event != apis.RealtimeResetMessageName {
+		t.Fatalf("Expected %s event, got %v", apis.RealtimeResetMessageName, frame)
+	}
+}
+
+func TestRealtimeHistoryResumeSnapshotRule(t *testing.T) {
+	for _, persist := range []bool{false, true} {
+		t.Run("persist_"+strconv.FormatBool(persist), func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			app.Settings().Realtime.HistorySize = 10
+			app.Settings().Realtime.HistoryPersist = persist
+
+			collection := core.NewBaseCollection("history_test")
+			collection.ListRule = types.Pointer("title = 'published'")
+			collection.Fields.Add(
+				&core.TextField{Name: "title"},
+				&core.TextField{Name: "content"},
+			)
+			if err := app.Save(collection); err != nil {
+				t.Fatal(err)
+			}
+
+			server := startRealtimeWSTestServer(t, app)
+			defer server.Close()
+
+			conn1, clientId1 := dialRealtimeHistoryWS(t, server, "")
+			if reply := sendRealtimeWSMessage(t, conn1, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+				t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+			}
+			conn1.Close()
+			waitRealtimeClientsDisconnect(t, app)
+
+			// the draft is published while the client is disconnected
+			record := core.NewRecord(collection)
+			record.Set("title", "draft")
+			record.Set("content", "SECRET-DRAFT")
+			if err := app.Save(record); err != nil {
+				t.Fatal(err)
+			}
+			record.Set("title", "published")
+			record.Set("content", "public")
+			if err := app.Save(record); err != nil {
+				t.Fatal(err)
+			}
+
+			conn2, _ := dialRealtimeHistoryWS(t, server, clientId1)
+			if reply := sendRealtimeWSMessage(t, conn2, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+				t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+			}
+
+			frame := readRealtimeHistoryWSFrame(t, conn2)
+			if strings.Contains(string(frame.Data), "SECRET-DRAFT") {
+				t.Fatalf("Expected the draft event to not be replayed, got %s %s", frame.Event, frame.Data)
+			}
+			if !strings.Contains(string(frame.Data), `"action":"update"`) || !strings.Contains(string(frame.Data), `"content":"public"`) {
+				t.Fatalf("Expected replayed published update event, got %s %s", frame.Event, frame.Data)
+			}
+			if frame, ok := readRealtimeWSFrame(t, conn2, 300*time.Millisecond); ok {
+				t.Fatalf("Expected no more events, got %s %s", frame.Event, frame.Data)
+			}
+		})
+	}
+}
+
+func TestRealtimeHistoryResumeSSE(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Realtime.HistorySize = 5
+	app.Settings().Realtime.HistoryPersist = true
+
+	collection := core.NewBaseCollection("history_test")
+	collection.ListRule = types.Pointer("")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	collection.Fields.Add(&core.PasswordField{Name: "secret"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	server := startRealtimeWSTestServer(t, app)
+	defer server.Close()
+
+	// initial connection
+	// ---
+	events1, clientId1, close1 := connectRealtimeHistorySSE(t, server, "")
+
+	subscribeRealtimeHistorySSE(t, server, clientId1, "history_test/*")
+
+	recordA := saveRealtimeHistoryTestRecord(t, app, collection, "a")
+
+	eventA := readRealtimeHistorySSEEvent(t, events1)
+	if eventA.name != "history_test/*" || parseTestEventId(t, eventA.id) <= 0 {
+		t.Fatalf("Expected record event with numeric id, got %v", eventA)
+	}
+
+	close1()
+	waitRealtimeClientsDisconnect(t, app)
+
+	// missed events
+	// ---
+	recordA.Set("title", "a2")
+	recordA.Set("secret", "1234567890")
+	if err := app.Save(recordA); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Delete(recordA); err != nil {
+		t.Fatal(err)
+	}
+
+	// resume
+	// ---
+	events2, clientId2, close2 := connectRealtimeHistorySSE(t, server, eventA.id)
+	defer close2()
+
+	subscribeRealtimeHistorySSE(t, server, clientId2, "history_test/*")
+
+	update := readRealtimeHistorySSEEvent(t, events2)
+	if !strings.Contains(update.data, `"action":"update"`) || !strings.Contains(update.data, `"title":"a2"`) || !strings.Contains(update.data, `"secret":""`) {
+		t.Fatalf("Expected replayed update event, got %v", update)
+	}
+
+	del := readRealtimeHistorySSEEvent(t, events2)
+	if !strings.Contains(del.data, `"action":"delete"`) || !strings.Contains(del.data, `"id":"`+recordA.Id+`"`) {
+		t.Fatalf("Expected replayed delete event, got %v", del)
+	}
+
+	if parseTestEventId(t, eventA.id) >= parseTestEventId(t, update.id) || parseTestEventId(t, update.id) >= parseTestEventId(t, del.id) {
+		t.Fatalf("Expected increasing event ids, got %q, %q, %q", eventA.id, update.id, del.id)
+	}
+}
+
+func TestRealtimeHistoryDisabled(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Realtime.HistorySize = 0
+
+	collection := core.NewBaseCollection("history_test")
+	collection.ListRule = types.Pointer("")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	server := startRealtimeWSTestServer(t, app)
+	defer server.Close()
+
+	conn, _ := dialRealtimeHistoryWS(t, server, "123")
+	defer conn.Close()
+
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"type": "subscribe", "subscriptions": []string{"history_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	saveRealtimeHistoryTestRecord(t, app, collection, "a")
+
+	frame := readRealtimeHistoryWSFrame(t, conn)
+	if frame.Event != "history_test/*" || frame.Id != "" {
+		t.Fatalf("Expected record event without id, got %v", frame)
+	}
+}
+
+// -------------------------------------------------------------------
+
+type realtimeHistoryWSFrame struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+	Id    string          `json:"id"`
+}
+
+func dialRealtimeHistoryWS(t *testing.T, server *httptest.Server, lastEventId string) (*websocket.Conn, string) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/realtime/ws"
+	if lastEventId != "" {
+		url += "?" + apis.RealtimeLastEventIdQueryParam + "=" + lastEventId
+	}
+
+	conn, err := websocket.Dial(url, "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connect := readRealtimeHistoryWSFrame(t, conn)
+	if connect.Event != "PB_CONNECT" {
+		t.Fatalf("Expected PB_CONNECT frame, got %v", connect)
+	}
+
+	data := struct {
+		ClientId string `json:"clientId"`
+	}{}
+	if err := json.Unmarshal(connect.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	return conn, data.ClientId
+}
+
+func readRealtimeHistoryWSFrame(t *testing.T, conn *websocket.Conn) *realtimeHistoryWSFrame {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	frame := new(realtimeHistoryWSFrame)
+	if err := websocket.JSON.Receive(conn, frame); err != nil {
+		t.Fatalf("Failed to read WebSocket frame: %v", err)
+	}
+
+	return frame
+}
+
+type realtimeHistorySSEEvent struct {
+	id   string
+	name string
+	data string
+}
+
+func connectRealtimeHistorySSE(t *testing.T, server *httptest.Server, lastEventId string) (chan *realtimeHistorySSEEvent, string, func()) {
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/realtime", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan *realtimeHistorySSEEvent, 10)
+
+	go func() {
+		defer close(events)
+
+		event := new(realtimeHistorySSEEvent)
+
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				events <- event
+				event = new(realtimeHistorySSEEvent)
+			case strings.HasPrefix(line, "id:"):
+				event.id = strings.TrimPrefix(line, "id:")
+			case strings.HasPrefix(line, "event:"):
+				event.name = strings.TrimPrefix(line, "event:")
+			case strings.HasPrefix(line, "data:"):
+				event.data = strings.TrimPrefix(line, "data:")
+			}
+		}
+	}()
+
+	connect := readRealtimeHistorySSEEvent(t, events)
+	if connect.name != "PB_CONNECT" || connect.id == "" {
+		t.Fatalf("Expected PB_CONNECT event with the client id, got %v", connect)
+	}
+
+	return events, connect.id, func() { res.Body.Close() }
+}
+
+func subscribeRealtimeHistorySSE(t *testing.T, server *httptest.Server, clientId string, subs ...string) {
+	body, err := json.Marshal(map[string]any{"clientId": clientId, "subscriptions": subs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := http.Post(server.URL+"/api/realtime", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected 204 subscribe response, got %d", res.StatusCode)
+	}
+}
+
+func readRealtimeHistorySSEEvent(t *testing.T, events chan *realtimeHistorySSEEvent) *realtimeHistorySSEEvent {
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("The SSE stream was closed")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout while waiting for SSE event")
+	}
+
+	return nil
+}
+
+func saveRealtimeHistoryTestRecord(t *testing.T, app core.App, collection *core.Collection, title string) *core.Record {
+	record := core.NewRecord(collection)
+	record.Set("title", title)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	return record
+}
+
+func waitRealtimeClientsDisconnect(t *testing.T, app core.App) {
+	for i := 0; i < 40 && len(app.SubscriptionsBroker().Clients()) > 0; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if total := len(app.SubscriptionsBroker().Clients()); total != 0 {
+		t.Fatalf("Expected all clients to be disconnected, found %d", total)
+	}
+}
+
+func parseTestEventId(t *testing.T, raw string) int64 {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		t.Fatalf("Invalid event id %q: %v", raw, err)
+	}
+
+	return id
+}
@@ -883,3 +883,117 @@ func TestRealtimeRecordResolve(t *testing.T) {
 		})
 	}
 }
+
+func TestRealtimeRecordDeltaUpdate(t *testing.T) {
+	t.Parallel()
+
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// init realtime handlers
+	apis.NewRouter(testApp)
+
+	collection := core.NewBaseCollection("delta_test")
+	collection.ListRule = types.Pointer("")
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.NumberField{Name: "counter"},
+		&core.TextField{Name: "secret", Hidden: true},
+		&core.JSONField{Name: "content"},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	if err := testApp.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	fullClient := subscriptions.NewDefaultClient()
+	fullClient.Subscribe("delta_test/*")
+
+	deltaClient := subscriptions.NewDefaultClient()
+	deltaClient.Subscribe(`delta_test/*?options={"query":{"delta":true}}`)
+
+	deltaFieldsClient := subscriptions.NewDefaultClient()
+	deltaFieldsClient.Subscribe(`delta_test/*?options={"query":{"delta":"true","fields":"id,title,secret"}}`)
+
+	for _, client := range []subscriptions.Client{fullClient, deltaClient, deltaFieldsClient} {
+		testApp.SubscriptionsBroker().Register(client)
+	}
+
+	readEvent := func(t *testing.T, client subscriptions.Client) map[string]any {
+		select {
+		case msg := <-client.Channel():
+			data := map[string]any{}
+			if err := json.Unmarshal(msg.Data, &data); err != nil {
+				t.Fatal(err)
+			}
+			return data
+		case <-time.After(2 * time.Second):
+			t.Fatalf("[%s] Missing realtime event", client.Id())
+		}
+		return nil
+	}
+
+	// sort the record keys for easier comparison
+	recordKeys := func(data map[string]any) []string {
+		record, _ := data["record"].(map[string]any)
+		keys := make([]string, 0, len(record))
+		for k := range record {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "test")
+	record.Set("secret", "abc")
+	record.Set("content", map[string]any{"large": strings.Repeat("a", 100)})
+	if err := testApp.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// create events are always sent in full
+	for _, client := range []subscriptions.Client{fullClient, deltaClient, deltaFieldsClient} {
+		data := readEvent(t, client)
+		if data["action"] != "create" || data["delta"] != nil {
+			t.Fatalf("[%s] Expected full create event, got %v", client.Id(), data)
+		}
+	}
+
+	// refetch to have up-to-date Original() state (the same as in the record update API)
+	record, err := testApp.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Set("counter", 1)
+	record.Set("secret", "def")
+	if err := testApp.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		client       subscriptions.Client
+		expectedKeys []string
+		expectDelta  bool
+	}{
+		{fullClient, []string{"collectionId", "collectionName", "content", "counter", "id", "title", "updated"}, false},
+		{deltaClient, []string{"counter", "id", "updated"}, true},
+		{deltaFieldsClient, []string{"id"}, true},
+	}
+
+	for i, s := range scenarios {
+		data := readEvent(t, s.client)
+
+		if data["action"] != "update" {
+			t.Fatalf("[%d] Expected update action, got %v", i, data["action"])
+		}
+
+		if delta, _ := data["delta"].(bool); delta != s.expectDelta {
+			t.Fatalf("[%d] Expected delta %v, got %v", i, s.expectDelta, data["delta"])
+		}
+
+		if keys := recordKeys(data); !slices.Equal(keys, s.expectedKeys) {
+			t.Fatalf("[%d] Expected record keys %v, got %v", i, s.expectedKeys, keys)
+		}
+	}
+}
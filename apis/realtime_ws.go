@@ -0,0 +1,508 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"golang.org/x/net/websocket"
+)
+
+// Realtime WebSocket in-band client message types.
+const (
+	RealtimeWSMessageSubscribe   = "subscribe"
+	RealtimeWSMessageUnsubscribe = "unsubscribe"
+	RealtimeWSMessageAuth        = "auth"
+	RealtimeWSMessagePing        = "ping"
+	RealtimeWSMessagePublish     = "publish"
+	RealtimeWSMessagePresence    = "presence"
+)
+
+// note: the limits are the same as the ones of the SSE subscriptions form
+const (
+	realtimeWSMaxSubscriptions     = 1000
+	realtimeWSMaxSubscriptionLen   = 2500
+	realtimeWSMaxClientMessageSize = 1 << 20
+	realtimeWSWriteTimeout         = 30 * time.Second
+)
+
+// realtimeWSClientMessage defines a single in-band WebSocket client message, eg.:
+//
+//	{"id": "1", "type": "subscribe", "subscriptions": ["posts/*", "posts/abc"]}
+//	{"id": "2", "type": "unsubscribe", "subscriptions": ["posts/abc"]}
+//	{"id": "3", "type": "auth", "token": "..."}
+//	{"id": "4", "type": "ping"}
+//	{"id": "5", "type": "publish", "channel": "chat", "data": {"text": "hello"}}
+//	{"id": "6", "type": "presence", "channel": "chat"}
+//
+// The optional id is returned back with the PB_ACK or PB_ERROR reply.
+type realtimeWSClientMessage struct {
+	Id            string          `json:"id"`
+	Type          string          `json:"type"`
+	Token         string          `json:"token"`
+	Channel       string          `json:"channel"`
+	Data          json.RawMessage `json:"data"`
+	Subscriptions []string        `json:"subscriptions"`
+}
+
+func (m *realtimeWSClientMessage) validate() error {
+	return validation.ValidateStruct(m,
+		validation.Field(&m.Id, validation.Length(0, 255)),
+		validation.Field(
+			&m.Type,
+			validation.Required,
+			validation.In(
+				RealtimeWSMessageSubscribe,
+				RealtimeWSMessageUnsubscribe,
+				RealtimeWSMessageAuth,
+				RealtimeWSMessagePing,
+				RealtimeWSMessagePublish,
+				RealtimeWSMessagePresence,
+			),
+		),
+		validation.Field(&m.Token, validation.When(m.Type == RealtimeWSMessageAuth, validation.Required)),
+		validation.Field(
+			&m.Channel,
+			validation.When(m.Type == RealtimeWSMessagePublish || m.Type == RealtimeWSMessagePresence, validation.Required),
+			validation.Length(0, 255),
+		),
+		validation.Field(&m.Subscriptions,
+			validation.Length(0, realtimeWSMaxSubscriptions),
+			validation.Each(validation.Length(0, realtimeWSMaxSubscriptionLen)),
+		),
+	)
+}
+
+// realtimeWSServerMessage defines a single WebSocket server message frame.
+//
+// The optional id is the realtime history event id (if any).
+type realtimeWSServerMessage struct {
+	Event string `json:"event"`
+	Data  any    `json:"data"`
+	Id    string `json:"id,omitempty"`
+}
+
+// realtimeConnectWS handles the WebSocket realtime connection.
+//
+// It is similar to the SSE realtimeConnect but the subscriptions and the
+// auth state are managed with in-band client messages on the same connection.
+func realtimeConnectWS(e *core.RequestEvent) error {
+	if !e.Request.ProtoAtLeast(1, 1) || !headerContainsToken(e.Request.Header, "Upgrade", "websocket") {
+		return e.BadRequestError("Expected WebSocket upgrade request.", nil)
+	}
+
+	// create cancellable request
+	cancelCtx, cancelRequest := context.WithCancel(e.Request.Context())
+	defer cancelRequest()
+	e.Request = e.Request.Clone(cancelCtx)
+
+	connectEvent := new(core.RealtimeConnectRequestEvent)
+	connectEvent.RequestEvent = e
+	connectEvent.Client = subscriptions.NewDefaultClient()
+	connectEvent.IdleTimeout = 5 * time.Minute
+
+	return e.App.OnRealtimeConnectRequest().Trigger(connectEvent, func(ce *core.RealtimeConnectRequestEvent) error {
+		var handlerErr error
+
+		server := websocket.Server{
+			// the connection is authorized with bearer tokens (header or in-band)
+			// and not with cookies so there is no need for an origin check
+			Handshake: func(config *websocket.Config, req *http.Request) error {
+				return nil
+			},
+			Handler: func(conn *websocket.Conn) {
+				handlerErr = realtimeServeWS(ce, conn, cancelRequest)
+			},
+		}
+
+		server.ServeHTTP(ce.Response, ce.Request)
+
+		return handlerErr
+	})
+}
+
+func realtimeServeWS(ce *core.RealtimeConnectRequestEvent, conn *websocket.Conn, cancelRequest context.CancelFunc) error {
+	defer conn.Close()
+
+	conn.MaxPayloadBytes = realtimeWSMaxClientMessageSize
+
+	// reset the inherited server read deadline of the hijacked connection
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return err
+	}
+
+	if ce.Auth != nil {
+		ce.Client.Set(RealtimeClientAuthKey, ce.Auth)
+	}
+
+	// register new subscription client
+	ce.App.SubscriptionsBroker().Register(ce.Client)
+	defer func() {
+		realtimeSaveClientHistory(ce.App, ce.Client)
+		ce.App.SubscriptionsBroker().Unregister(ce.Client.Id())
+		realtimeLeaveClientPresence(ce.App, ce.Client)
+	}()
+
+	// check for reconnect
+	realtimeInitClientHistory(ce.App, ce.Client, ce.Request)
+
+	ce.App.Logger().Debug("Realtime WebSocket connection established.", slog.String("clientId", ce.Client.Id()))
+
+	// signalize established connection (aka. fire "connect" message)
+	connectMsgErr := realtimeSendWSMessage(ce, conn, &subscriptions.Message{
+		Name: "PB_CONNECT",
+		Data: []byte(`{"clientId":"` + ce.Client.Id() + `","memberId":"` + realtimeClientMemberId(ce.Client) + `"}`),
+	})
+	if connectMsgErr != nil {
+		ce.App.Logger().Debug(
+			"Realtime WebSocket connection closed (failed to deliver PB_CONNECT)",
+			slog.String("clientId", ce.Client.Id()),
+			slog.String("error", connectMsgErr.Error()),
+		)
+		return nil
+	}
+
+	// read the client messages in a separate goroutine
+	// (all writes are performed only from the below loop)
+	incoming := make(chan []byte)
+	go func() {
+		defer cancelRequest()
+
+		for {
+			var raw []byte
+			if err := websocket.Message.Receive(conn, &raw); err != nil {
+				return
+			}
+
+			select {
+			case incoming <- raw:
+			case <-ce.Request.Context().Done():
+				return
+			}
+		}
+	}()
+
+	// start an idle timer to keep track of inactive/forgotten connections
+	idleTimer := time.NewTimer(ce.IdleTimeout)
+	defer idleTimer.Stop()
+
+	for {
+		var msgErr error
+
+		select {
+		case <-idleTimer.C:
+			cancelRequest()
+			continue
+		case raw := <-incoming:
+			msgErr = realtimeHandleWSClientMessage(ce, conn, raw)
+		case msg, ok := <-ce.Client.Channel():
+			if !ok {
+				// channel is closed
+				ce.App.Logger().Debug(
+					"Realtime WebSocket connection closed (closed channel)",
+					slog.String("clientId", ce.Client.Id()),
+				)
+				return nil
+			}
+
+			// skip already replayed events
+			if !realtimeTrackClientMessage(ce.Client, &msg) {
+				continue
+			}
+
+			msgErr = realtimeSendWSMessage(ce, conn, &msg)
+		case <-ce.Request.Context().Done():
+			// connection is closed
+			ce.App.Logger().Debug(
+				"Realtime WebSocket connection closed (cancelled request)",
+				slog.String("clientId", ce.Client.Id()),
+			)
+			return nil
+		}
+
+		if msgErr != nil {
+			ce.App.Logger().Debug(
+				"Realtime WebSocket connection closed (failed to deliver message)",
+				slog.String("clientId", ce.Client.Id()),
+				slog.String("error", msgErr.Error()),
+			)
+			return nil
+		}
+
+		idleTimer.Stop()
+		idleTimer.Reset(ce.IdleTimeout)
+	}
+}
+
+// realtimeSendWSMessage triggers the OnRealtimeMessageSend hook
+// and writes the message as json text frame.
+//
+// The message data is inlined as it is if it is a valid json,
+// otherwise it is sent as json string.
+func realtimeSendWSMessage(ce *core.RealtimeConnectRequestEvent, conn *websocket.Conn, msg *subscriptions.Message) error {
+	msgEvent := new(core.RealtimeMessageEvent)
+	msgEvent.RequestEvent = ce.RequestEvent
+	msgEvent.Client = ce.Client
+	msgEvent.Message = msg
+
+	return ce.App.OnRealtimeMessageSend().Trigger(msgEvent, func(me *core.RealtimeMessageEvent) error {
+		frame := realtimeWSServerMessage{Event: me.Message.Name, Id: me.Message.Id}
+		if json.Valid(me.Message.Data) {
+			frame.Data = json.RawMessage(me.Message.Data)
+		} else {
+			frame.Data = string(me.Message.Data)
+		}
+
+		raw, err := json.Marshal(frame)
+		if err != nil {
+			return err
+		}
+
+		if err := conn.SetWriteDeadline(time.Now().Add(realtimeWSWriteTimeout)); err != nil {
+			return err
+		}
+
+		return websocket.Message.Send(conn, string(raw))
+	})
+}
+
+// realtimeHandleWSClientMessage processes a single in-band client message
+// and replies with PB_ACK on success or PB_ERROR on failure.
+//
+// Only errors related to the connection itself are returned.
+func realtimeHandleWSClientMessage(ce *core.RealtimeConnectRequestEvent, conn *websocket.Conn, raw []byte) error {
+	msg := new(realtimeWSClientMessage)
+
+	var handleErr error
+	var members []realtimeChannelMember
+	if err := json.Unmarshal(raw, msg); err != nil {
+		handleErr = router.NewBadRequestError("Invalid WebSocket message.", err)
+	} else if err := msg.validate(); err != nil {
+		handleErr = router.NewBadRequestError("", err)
+	} else {
+		switch msg.Type {
+		case RealtimeWSMessageAuth:
+			handleErr = realtimeWSAuth(ce, msg.Token)
+		case RealtimeWSMessageSubscribe:
+			handleErr = realtimeWSSetSubscriptions(ce, realtimeWSMergeSubscriptions(ce.Client, msg.Subscriptions))
+		case RealtimeWSMessageUnsubscribe:
+			handleErr = realtimeWSSetSubscriptions(ce, realtimeWSExcludeSubscriptions(ce.Client, msg.Subscriptions))
+		case RealtimeWSMessagePublish:
+			handleErr = realtimeWSPublish(ce, msg.Channel, msg.Data)
+		case RealtimeWSMessagePresence:
+			members, handleErr = realtimeWSPresence(ce, msg.Channel)
+		}
+	}
+
+	data := map[string]any{"id": msg.Id, "type": msg.Type}
+	if members != nil {
+		data["members"] = members
+	}
+
+	reply := &subscriptions.Message{Name: "PB_ACK"}
+	if handleErr != nil {
+		reply.Name = "PB_ERROR"
+		data["error"] = router.ToApiError(handleErr)
+	}
+
+	var err error
+	reply.Data, err = json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return realtimeSendWSMessage(ce, conn, reply)
+}
+
+// realtimeWSAuth updates the connection auth state with the provided auth token.
+//
+// Similar to the SSE subscriptions, only guest->auth upgrades
+// are allowed and any other auth change is forbidden.
+func realtimeWSAuth(ce *core.RealtimeConnectRequestEvent, token string) error {
+	authRecord, err := ce.App.FindAuthRecordByToken(token, core.TokenTypeAuth)
+	if err != nil || authRecord == nil {
+		return router.NewUnauthorizedError("The request requires valid record authorization token.", err)
+	}
+
+	clientAuth, _ := ce.Client.Get(RealtimeClientAuthKey).(*core.Record)
+	if clientAuth != nil && !isSameAuth(clientAuth, authRecord) {
+		return router.NewForbiddenError("The current and the previous request authorization don't match.", nil)
+	}
+
+	ce.Auth = authRecord
+	ce.Client.Set(RealtimeClientAuthKey, authRecord)
+
+	return nil
+}
+
+// realtimeWSSetSubscriptions replaces the client subscriptions with the provided
+// list using the same OnRealtimeSubscribeRequest hook as the SSE subscriptions form.
+func realtimeWSSetSubscriptions(ce *core.RealtimeConnectRequestEvent, subs []string) error {
+	if len(subs) > realtimeWSMaxSubscriptions {
+		return router.NewBadRequestError("", validation.Errors{
+			"subscriptions": validation.ErrLengthOutOfRange.SetParams(map[string]any{"min": 0, "max": realtimeWSMaxSubscriptions}),
+		})
+	}
+
+	// sync with the client auth state since it could have been
+	// updated or unset in the meantime (eg. on auth record update or delete)
+	ce.Auth, _ = ce.Client.Get(RealtimeClientAuthKey).(*core.Record)
+
+	if err := realtimeCheckChannelSubscriptions(ce.App, ce.Auth, subs); err != nil {
+		return err
+	}
+
+	event := new(core.RealtimeSubscribeRequestEvent)
+	event.RequestEvent = ce.RequestEvent
+	event.Client = ce.Client
+	event.Subscriptions = subs
+
+	return ce.App.OnRealtimeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeRequestEvent) error {
+		// update auth state
+		e.Client.Set(RealtimeClientAuthKey, e.Auth)
+
+		// unsubscribe from any previous existing subscriptions
+		e.Client.Unsubscribe()
+
+		// subscribe to the new subscriptions
+		e.Client.Subscribe(e.Subscriptions...)
+
+		// join or leave the custom presence channels
+		realtimeSyncClientPresence(e.App, e.Client)
+
+		e.App.Logger().Debug(
+			"Realtime WebSocket subscriptions updated.",
+			slog.String("clientId", e.Client.Id()),
+			slog.Any("subscriptions", e.Subscriptions),
+		)
+
+		// send the missed events (if reconnecting)
+		realtimeReplayClientHistory(e.App, e.Client)
+
+		return nil
+	})
+}
+
+// realtimeWSPublish publishes the client message to the specified custom channel.
+func realtimeWSPublish(ce *core.RealtimeConnectRequestEvent, channel string, rawData json.RawMessage) error {
+	var data any
+	if len(rawData) > 0 {
+		if err := json.Unmarshal(rawData, &data); err != nil {
+			return router.NewBadRequestError("Invalid publish data.", err)
+		}
+	}
+
+	requestInfo, err := realtimeWSRequestInfo(ce, "POST", channel, data)
+	if err != nil {
+		return router.NewBadRequestError("", err)
+	}
+
+	config, ok := ce.App.Settings().Realtime.FindChannel(channel)
+	if !ok || !realtimeCanAccessChannel(ce.App, requestInfo, config.PublishRule) {
+		return router.NewForbiddenError("You are not allowed to publish to the realtime channel.", nil)
+	}
+
+	return realtimePublishChannelData(ce.App, &realtimeChannelData{
+		realtimeChannelMember: newRealtimeChannelMember(realtimeClientMemberId(ce.Client), requestInfo.Auth),
+		Action:                RealtimeChannelActionPublish,
+		Channel:               channel,
+		Data:                  data,
+	})
+}
+
+// realtimeWSPresence returns the presence members of the specified custom channel.
+func realtimeWSPresence(ce *core.RealtimeConnectRequestEvent, channel string) ([]realtimeChannelMember, error) {
+	requestInfo, err := realtimeWSRequestInfo(ce, "GET", channel, nil)
+	if err != nil {
+		return nil, router.NewBadRequestError("", err)
+	}
+
+	return realtimeChannelMembers(ce.App, channel, requestInfo)
+}
+
+// realtimeWSRequestInfo returns the custom channel rules request info
+// of an in-band client message.
+//
+// The query and headers are the ones of the WebSocket handshake request and
+// the body contains the channel name and the published data (if any).
+func realtimeWSRequestInfo(ce *core.RealtimeConnectRequestEvent, method string, channel string, data any) (*core.RequestInfo, error) {
+	info, err := ce.RequestInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	info = info.Clone()
+	info.Context = core.RequestInfoContextRealtime
+	info.Method = method
+	info.Body = map[string]any{"channel": channel}
+	if data != nil {
+		info.Body["data"] = data
+	}
+
+	// the client auth could have been changed with in-band message
+	info.Auth, _ = ce.Client.Get(RealtimeClientAuthKey).(*core.Record)
+
+	return info, nil
+}
+
+// realtimeWSMergeSubscriptions returns the current client subscriptions extended with subs.
+func realtimeWSMergeSubscriptions(client subscriptions.Client, subs []string) []string {
+	result := realtimeWSClientSubscriptions(client)
+
+	for _, s := range subs {
+		if s != "" && !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// realtimeWSExcludeSubscriptions returns the current client subscriptions without subs.
+//
+// If subs is empty, returns an empty list (aka. unsubscribe from everything).
+func realtimeWSExcludeSubscriptions(client subscriptions.Client, subs []string) []string {
+	if len(subs) == 0 {
+		return []string{}
+	}
+
+	return slices.DeleteFunc(realtimeWSClientSubscriptions(client), func(s string) bool {
+		return slices.Contains(subs, s)
+	})
+}
+
+func realtimeWSClientSubscriptions(client subscriptions.Client) []string {
+	current := client.Subscriptions()
+
+	result := make([]string, 0, len(current))
+	for s := range current {
+		result = append(result, s)
+	}
+
+	// for consistent hook event values
+	slices.Sort(result)
+
+	return result
+}
+
+// headerContainsToken reports whether the comma separated header
+// values contain the specified case-insensitive token.
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, v := range header.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
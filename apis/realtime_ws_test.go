@@ -0,0 +1,295 @@
+package apis_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"golang.org/x/net/websocket"
+)
+
+func TestRealtimeConnectWSInvalidUpgrade(t *testing.T) {
+	scenario := tests.ApiScenario{
+		Method:          http.MethodGet,
+		URL:             "/api/realtime/ws",
+		ExpectedStatus:  400,
+		ExpectedContent: []string{`"data":{}`},
+		ExpectedEvents:  map[string]int{"*": 0},
+	}
+
+	scenario.Test(t)
+}
+
+type realtimeWSTestFrame struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func startRealtimeWSTestServer(t *testing.T, app *tests.TestApp) *httptest.Server {
+	baseRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux, err := baseRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(mux)
+}
+
+func dialRealtimeWS(t *testing.T, server *httptest.Server) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/realtime/ws"
+
+	conn, err := websocket.Dial(url, "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func readRealtimeWSFrame(t *testing.T, conn *websocket.Conn, timeout time.Duration) (*realtimeWSTestFrame, bool) {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	frame := new(realtimeWSTestFrame)
+	if err := websocket.JSON.Receive(conn, frame); err != nil {
+		return nil, false
+	}
+
+	return frame, true
+}
+
+func sendRealtimeWSMessage(t *testing.T, conn *websocket.Conn, msg map[string]any) *realtimeWSTestFrame {
+	if err := websocket.JSON.Send(conn, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	frame, ok := readRealtimeWSFrame(t, conn, 2*time.Second)
+	if !ok {
+		t.Fatalf("Missing reply for message %v", msg)
+	}
+
+	return frame
+}
+
+func TestRealtimeConnectWS(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("ws_test")
+	collection.ListRule = types.Pointer("@request.auth.id != ''")
+	collection.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userToken, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user2Token, err := user2.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscribeCalls := map[string][]string{}
+	app.OnRealtimeSubscribeRequest().BindFunc(func(e *core.RealtimeSubscribeRequestEvent) error {
+		subscribeCalls[e.Client.Id()] = e.Subscriptions
+		return e.Next()
+	})
+
+	var sendCalls int
+	app.OnRealtimeMessageSend().BindFunc(func(e *core.RealtimeMessageEvent) error {
+		sendCalls++
+		return e.Next()
+	})
+
+	server := startRealtimeWSTestServer(t, app)
+	defer server.Close()
+
+	conn := dialRealtimeWS(t, server)
+	defer conn.Close()
+
+	// connect
+	// ---
+	connect, ok := readRealtimeWSFrame(t, conn, 2*time.Second)
+	if !ok || connect.Event != "PB_CONNECT" {
+		t.Fatalf("Expected PB_CONNECT frame, got %v", connect)
+	}
+
+	connectData := struct {
+		ClientId string `json:"clientId"`
+	}{}
+	if err := json.Unmarshal(connect.Data, &connectData); err != nil || connectData.ClientId == "" {
+		t.Fatalf("Expected PB_CONNECT clientId, got %s", connect.Data)
+	}
+
+	client, err := app.SubscriptionsBroker().ClientById(connectData.ClientId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid messages
+	// ---
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"id": "a", "type": "unknown"}); reply.Event != "PB_ERROR" ||
+		!strings.Contains(string(reply.Data), `"id":"a"`) ||
+		!strings.Contains(string(reply.Data), `"status":400`) {
+		t.Fatalf("Expected PB_ERROR reply for unknown message type, got %s %s", reply.Event, reply.Data)
+	}
+
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"id": "b", "type": "auth", "token": "invalid"}); reply.Event != "PB_ERROR" ||
+		!strings.Contains(string(reply.Data), `"status":401`) {
+		t.Fatalf("Expected PB_ERROR reply for invalid auth token, got %s %s", reply.Event, reply.Data)
+	}
+
+	// guest subscribe
+	// ---
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"id": "c", "type": "subscribe", "subscriptions": []string{"ws_test/*", "other"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	if subs := subscribeCalls[client.Id()]; len(subs) != 2 || !client.HasSubscription("ws_test/*") || !client.HasSubscription("other") {
+		t.Fatalf("Expected 2 subscriptions, got %v", subs)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "guest")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if frame, ok := readRealtimeWSFrame(t, conn, 300*time.Millisecond); ok {
+		t.Fatalf("Expected no guest record event, got %s %s", frame.Event, frame.Data)
+	}
+
+	// auth
+	// ---
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"id": "d", "type": "auth", "token": userToken}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK auth reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	if auth, _ := client.Get(apis.RealtimeClientAuthKey).(*core.Record); auth == nil || auth.Id != user.Id {
+		t.Fatalf("Expected client auth %q, got %v", user.Id, auth)
+	}
+
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"id": "e", "type": "auth", "token": user2Token}); reply.Event != "PB_ERROR" ||
+		!strings.Contains(string(reply.Data), `"status":403`) {
+		t.Fatalf("Expected PB_ERROR reply for auth change, got %s %s", reply.Event, reply.Data)
+	}
+
+	record = core.NewRecord(collection)
+	record.Set("title", "auth")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	frame, ok := readRealtimeWSFrame(t, conn, 2*time.Second)
+	if !ok || frame.Event != "ws_test/*" ||
+		!strings.Contains(string(frame.Data), `"action":"create"`) ||
+		!strings.Contains(string(frame.Data), `"title":"auth"`) {
+		t.Fatalf("Expected auth record create event, got %v", frame)
+	}
+
+	// unsubscribe
+	// ---
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"type": "unsubscribe", "subscriptions": []string{"ws_test/*"}}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	if subs := subscribeCalls[client.Id()]; len(subs) != 1 || subs[0] != "other" || client.HasSubscription("ws_test/*") {
+		t.Fatalf("Expected only the other subscription, got %v", subs)
+	}
+
+	record = core.NewRecord(collection)
+	record.Set("title", "unsubscribed")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if frame, ok := readRealtimeWSFrame(t, conn, 300*time.Millisecond); ok {
+		t.Fatalf("Expected no event after unsubscribe, got %s %s", frame.Event, frame.Data)
+	}
+
+	if reply := sendRealtimeWSMessage(t, conn, map[string]any{"type": "ping"}); reply.Event != "PB_ACK" {
+		t.Fatalf("Expected PB_ACK ping reply, got %s %s", reply.Event, reply.Data)
+	}
+
+	// PB_CONNECT + 7 replies + 1 record event
+	if sendCalls != 9 {
+		t.Fatalf("Expected %d OnRealtimeMessageSend calls, got %d", 9, sendCalls)
+	}
+
+	// disconnect
+	// ---
+	conn.Close()
+
+	for i := 0; i < 20 && len(app.SubscriptionsBroker().Clients()) > 0; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if total := len(app.SubscriptionsBroker().Clients()); total != 0 {
+		t.Fatalf("Expected the client to be unregistered after disconnect, found %d", total)
+	}
+}
+
+func TestRealtimeConnectWSHeaderAuth(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := startRealtimeWSTestServer(t, app)
+	defer server.Close()
+
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/api/realtime/ws", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Header.Set("Authorization", token)
+
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if frame, ok := readRealtimeWSFrame(t, conn, 2*time.Second); !ok || frame.Event != "PB_CONNECT" {
+		t.Fatalf("Expected PB_CONNECT frame, got %v", frame)
+	}
+
+	clients := app.SubscriptionsBroker().Clients()
+	if len(clients) != 1 {
+		t.Fatalf("Expected 1 client, got %d", len(clients))
+	}
+
+	for _, client := range clients {
+		if auth, _ := client.Get(apis.RealtimeClientAuthKey).(*core.Record); auth == nil || auth.Id != user.Id {
+			t.Fatalf("Expected client auth %q, got %v", user.Id, auth)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package apis
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+const (
+	aggregateGroupByQueryParam = "groupBy"
+	aggregateAggQueryParam     = "agg"
+	aggregateLimitQueryParam   = "limit"
+)
+
+// bindRecordAggregateApi registers the records aggregate api endpoint.
+func bindRecordAggregateApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	rg.GET("/collections/{collection}/aggregate", recordsAggregate)
+}
+
+func recordsAggregate(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	// forbid users and guests to query special filter/sort fields
+	err = checkForSuperuserOnlyRuleFields(requestInfo)
+	if err != nil {
+		return err
+	}
+	if !requestInfo.HasSuperuserAuth() {
+		for _, param := range []string{aggregateGroupByQueryParam, aggregateAggQueryParam} {
+			for _, field := range superuserOnlyRuleFields {
+				if strings.Contains(requestInfo.Query[param], field) {
+					return e.ForbiddenError("Only superusers can aggregate by "+field, nil)
+				}
+			}
+		}
+	}
+
+	query := e.Request.URL.Query()
+
+	options := core.RecordsAggregateOptions{
+		GroupBy:     splitAggregateQueryParam(query.Get(aggregateGroupByQueryParam)),
+		Aggregates:  splitAggregateQueryParam(query.Get(aggregateAggQueryParam)),
+		Sort:        splitAggregateQueryParam(query.Get(search.SortQueryParam)),
+		Limit:       cast.ToInt(query.Get(aggregateLimitQueryParam)),
+		RequestInfo: requestInfo,
+		// hidden fields are searchable only by superusers
+		AllowHiddenFields: requestInfo.HasSuperuserAuth(),
+	}
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil && *collection.ListRule != "" {
+		options.RuleFilters = append(options.RuleFilters, *collection.ListRule)
+	}
+
+	if filter := query.Get(search.FilterQueryParam); filter != "" {
+		options.Filters = append(options.Filters, filter)
+	}
+
+	items, err := e.App.AggregateRecords(collection, options)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Invalid aggregate query.", err))
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{"items": items})
+}
+
+// splitAggregateQueryParam splits the comma separated aggregate
+// query param value into a list of trimmed non-empty expressions.
+func splitAggregateQueryParam(value string) []string {
+	parts := strings.Split(value, ",")
+
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
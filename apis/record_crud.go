@@ -28,7 +28,7 @@ func bindRecordCrudApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 	subGroup := rg.Group("/collections/{collection}/records").Unbind(DefaultRateLimitMiddlewareId)
 	subGroup.GET("", recordsList)
 	subGroup.GET("/{id}", recordView)
-	subGroup.POST("", recordCreate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
+	subGroup.POST("", recordCreate(true, nil)).Bind(dynamicCollectionBodyLimit(""), Idempotency())
 	subGroup.PATCH("/{id}", recordUpdate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
 	subGroup.DELETE("/{id}", recordDelete(true, nil))
 }
@@ -59,7 +59,17 @@ func recordsList(e *core.RequestEvent) error {
 		return err
 	}
 
-	query := e.App.RecordQuery(collection)
+	withDeleted, err := recordsWithDeleted(e, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	var query *dbx.SelectQuery
+	if withDeleted {
+		query = e.App.RecordQueryWithDeleted(collection)
+	} else {
+		query = e.App.RecordQuery(collection)
+	}
 
 	fieldsResolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
 
@@ -77,7 +87,9 @@ func recordsList(e *core.RequestEvent) error {
 	// hidden fields are searchable only by superusers
 	fieldsResolver.SetAllowHiddenFields(requestInfo.HasSuperuserAuth())
 
-	searchProvider := search.NewProvider(fieldsResolver).Query(query)
+	searchProvider := search.NewProvider(fieldsResolver).
+		Query(query).
+		CursorSecret(recordsCursorSecret(e.App, collection))
 
 	// use rowid when available to minimize the need of a covering index with the "id" field
 	if !collection.IsView() {
@@ -129,6 +141,20 @@ func recordsList(e *core.RequestEvent) error {
 
 var listTimingRateLimitRule = core.RateLimitRule{MaxRequests: 3, Duration: 3}
 
+// recordsCursorSecret returns the secret used to seal
+// the records list pagination cursors of the provided collection.
+//
+// The secret is derived from the superusers auth token secret so that
+// the cursors remain valid across restarts and app instances.
+func recordsCursorSecret(app core.App, collection *core.Collection) string {
+	superusers, err := app.FindCachedCollectionByNameOrId(core.CollectionNameSuperusers)
+	if err != nil {
+		return "" // fallback to the process wide secret
+	}
+
+	return security.HS256(collection.Id, superusers.AuthToken.Secret)
+}
+
 func randomizedThrottle(softMax int64) {
 	var timeout int64
 	randRange, err := cryptoRand.Int(cryptoRand.Reader, big.NewInt(softMax))
@@ -179,7 +205,22 @@ func recordView(e *core.RequestEvent) error {
 		return nil
 	}
 
-	record, fetchErr := e.App.FindRecordById(collection, recordId, ruleFunc)
+	withDeleted, err := recordsWithDeleted(e, collection, requestInfo)
+	if err != nil {
+		return err
+	}
+
+	var record *core.Record
+	var fetchErr error
+	if withDeleted {
+		record = &core.Record{}
+		fetchErr = e.App.RecordQueryWithDeleted(collection).
+			AndWhere(dbx.HashExp{inflector.Columnify(collection.Name) + ".id": recordId}).
+			Limit(1).
+			One(record)
+	} else {
+		record, fetchErr = e.App.FindRecordById(collection, recordId, ruleFunc)
+	}
 	if fetchErr != nil || record == nil {
 		return firstApiError(err, e.NotFoundError("", fetchErr))
 	}
@@ -248,6 +289,7 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 		requestInfo.Body = data
 
 		form := forms.NewRecordUpsert(e.App, record)
+		form.SetContext(recordHistoryContext(e))
 		if hasSuperuserAuth {
 			form.GrantSuperuserAccess()
 		}
@@ -447,6 +489,7 @@ func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 		}
 
 		form := forms.NewRecordUpsert(e.App, record)
+		form.SetContext(recordHistoryContext(e))
 		if hasSuperuserAuth {
 			form.GrantSuperuserAccess()
 		}
@@ -569,7 +612,7 @@ func recordDelete(responseWriteAfterTx bool, optFinalizer func(data any) error)
 		event.Record = record
 
 		hookErr := e.App.OnRecordDeleteRequest().Trigger(event, func(e *core.RecordRequestEvent) error {
-			if err := e.App.Delete(e.Record); err != nil {
+			if err := e.App.DeleteWithContext(recordHistoryContext(e.RequestEvent), e.Record); err != nil {
 				return firstApiError(err, e.BadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err))
 			}
 
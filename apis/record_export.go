@@ -0,0 +1,571 @@
+package apis
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/picker"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+// The supported records export formats.
+const (
+	RecordsExportFormatCSV    = "csv"
+	RecordsExportFormatNDJSON = "ndjson"
+	RecordsExportFormatJSONL  = "jsonl"
+)
+
+// The supported records export relation modes.
+const (
+	RecordsExportRelationsIds    = "ids"
+	RecordsExportRelationsExpand = "expand"
+)
+
+const (
+	exportFormatQueryParam    = "format"
+	exportFileURLsQueryParam  = "fileUrls"
+	exportRelationsQueryParam = "relations"
+)
+
+// recordsExportChunkSize is the number of records that are fetched,
+// enriched and written at once.
+const recordsExportChunkSize = 500
+
+// bindRecordExportApi registers the records export api endpoint.
+func bindRecordExportApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	rg.GET("/collections/{collection}/export", recordsExport).Unbind(DefaultRateLimitMiddlewareId)
+}
+
+// RecordsExportOptions defines the records export options.
+type RecordsExportOptions struct {
+	// Format is the output format - csv, ndjson or jsonl (default to csv).
+	Format string
+
+	// Relations specifies how to render the relation fields
+	// - as plain ids (default) or as their expanded records.
+	Relations string
+
+	// FileURLs specifies whether to render the file fields as absolute
+	// file urls (based on the settings app url) instead of plain file names.
+	FileURLs bool
+}
+
+// Validate checks whether the export options have supported values.
+func (o RecordsExportOptions) Validate() error {
+	switch o.Format {
+	case "", RecordsExportFormatCSV, RecordsExportFormatNDJSON, RecordsExportFormatJSONL:
+	default:
+		return fmt.Errorf("unsupported export format %q", o.Format)
+	}
+
+	switch o.Relations {
+	case "", RecordsExportRelationsIds, RecordsExportRelationsExpand:
+	default:
+		return fmt.Errorf("unsupported export relations mode %q", o.Relations)
+	}
+
+	return nil
+}
+
+// ContentType returns the content type of the options export format.
+func (o RecordsExportOptions) ContentType() string {
+	switch o.Format {
+	case RecordsExportFormatNDJSON:
+		return "application/x-ndjson"
+	case RecordsExportFormatJSONL:
+		return "application/jsonl"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Ext returns the file extension (without dot) of the options export format.
+func (o RecordsExportOptions) Ext() string {
+	if o.Format == "" {
+		return RecordsExportFormatCSV
+	}
+
+	return o.Format
+}
+
+func recordsExport(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	query := e.Request.URL.Query()
+
+	options := RecordsExportOptions{
+		Format:    query.Get(exportFormatQueryParam),
+		Relations: query.Get(exportRelationsQueryParam),
+		FileURLs:  cast.ToBool(query.Get(exportFileURLsQueryParam)),
+	}
+
+	if err := options.Validate(); err != nil {
+		return e.BadRequestError("Invalid export options.", err)
+	}
+
+	w := &recordsExportResponseWriter{
+		event:       e,
+		contentType: options.ContentType(),
+		filename:    collection.Name + "." + options.Ext(),
+	}
+
+	err = ExportRecords(e, collection, w, options)
+	if err != nil {
+		if w.written {
+			// the response has been already started and the error can't be sent
+			e.App.Logger().Warn("Records export failed", "error", err, "collectionName", collection.Name)
+			return nil
+		}
+
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	// no records
+	if !w.written {
+		w.writeHeader()
+	}
+
+	return nil
+}
+
+// ExportRecords streams all collection records matching the request
+// filter, sort, fields and expand query parameters into w, applying
+// the collection list API rule for non-superusers.
+//
+// The records are fetched and written in chunks so that the entire
+// result set is never loaded in memory at once.
+//
+// If w implements the Flush() error method, it is called after each chunk.
+func ExportRecords(e *core.RequestEvent, collection *core.Collection, w io.Writer, options RecordsExportOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return err
+	}
+
+	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
+		return router.NewForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	// forbid users and guests to query special filter/sort fields
+	err = checkForSuperuserOnlyRuleFields(requestInfo)
+	if err != nil {
+		return err
+	}
+
+	rawQuery := e.Request.URL.Query()
+	rawFields := rawQuery.Get(fieldsQueryParam)
+
+	var defaultExpands []string
+	if options.Relations == RecordsExportRelationsExpand {
+		for _, f := range collection.Fields {
+			if f.Type() == core.FieldTypeRelation && !f.GetHidden() {
+				defaultExpands = append(defaultExpands, f.GetName())
+			}
+		}
+	}
+
+	var fileFields []string
+	if options.FileURLs {
+		for _, f := range collection.Fields {
+			if f.Type() == core.FieldTypeFile {
+				fileFields = append(fileFields, f.GetName())
+			}
+		}
+	}
+
+	var baseFilesURL string
+	if options.FileURLs {
+		baseFilesURL = strings.TrimRight(e.App.Settings().Meta.AppURL, "/") + "/api/files/" + url.PathEscape(collection.Id) + "/"
+	}
+
+	encoder := newRecordsExportEncoder(w, options.Format)
+
+	var cursor string
+
+	for page := 1; ; page++ {
+		records, nextCursor, err := findRecordsExportChunk(e, collection, requestInfo, rawQuery.Encode(), cursor)
+		if err != nil {
+			return err
+		}
+
+		if err := EnrichRecords(e, records, defaultExpands...); err != nil {
+			return err
+		}
+
+		rows := make([]map[string]any, 0, len(records))
+
+		for _, record := range records {
+			data := record.PublicExport()
+
+			for _, name := range fileFields {
+				data[name] = exportFileURLs(baseFilesURL+url.PathEscape(record.Id)+"/", data[name])
+			}
+
+			if len(defaultExpands) > 0 {
+				expand, _ := data[core.FieldNameExpand].(map[string]any)
+				for _, name := range defaultExpands {
+					if v, ok := expand[name]; ok {
+						data[name] = v
+						delete(expand, name)
+					}
+				}
+				if len(expand) == 0 {
+					delete(data, core.FieldNameExpand)
+				}
+			}
+
+			row, err := normalizeExportData(data, rawFields)
+			if err != nil {
+				return err
+			}
+
+			rows = append(rows, row)
+		}
+
+		if page == 1 {
+			columns, err := recordsExportColumns(collection, rows, rawFields)
+			if err != nil {
+				return err
+			}
+
+			if err := encoder.start(columns); err != nil {
+				return err
+			}
+		}
+
+		if err := encoder.write(rows); err != nil {
+			return err
+		}
+
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+
+		if nextCursor == "" {
+			return nil
+		}
+
+		cursor = nextCursor
+	}
+}
+
+// findRecordsExportChunk fetches a single records export chunk
+// positioned after the provided cursor and returns the next chunk cursor.
+//
+// Keyset pagination is used to guarantee stable order between the chunks
+// even if records are created or deleted during the export.
+func findRecordsExportChunk(
+	e *core.RequestEvent,
+	collection *core.Collection,
+	requestInfo *core.RequestInfo,
+	rawQuery string,
+	cursor string,
+) ([]*core.Record, string, error) {
+	query := e.App.RecordQuery(collection)
+
+	fieldsResolver := core.NewRecordFieldResolver(e.App, collection, requestInfo, true)
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil && *collection.ListRule != "" {
+		expr, err := search.FilterData(*collection.ListRule).BuildExpr(fieldsResolver)
+		if err != nil {
+			return nil, "", err
+		}
+		query.AndWhere(expr)
+	}
+
+	// hidden fields are searchable only by superusers
+	fieldsResolver.SetAllowHiddenFields(requestInfo.HasSuperuserAuth())
+
+	searchProvider := search.NewProvider(fieldsResolver).
+		Query(query).
+		CursorSecret(recordsCursorSecret(e.App, collection))
+
+	if err := searchProvider.Parse(rawQuery); err != nil {
+		return nil, "", err
+	}
+
+	records := []*core.Record{}
+
+	result, err := searchProvider.
+		SkipTotal(true).
+		Cursor(cursor).
+		PerPage(recordsExportChunkSize).
+		Exec(&records)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return records, result.NextCursor, nil
+}
+
+// exportFileURLs converts the provided file field value into absolute file url(s).
+func exportFileURLs(baseURL string, value any) any {
+	if name, ok := value.(string); ok {
+		if name == "" {
+			return name
+		}
+		return baseURL + url.PathEscape(name)
+	}
+
+	names := list.ToUniqueStringSlice(value)
+	urls := make([]string, len(names))
+	for i, name := range names {
+		urls[i] = baseURL + url.PathEscape(name)
+	}
+
+	return urls
+}
+
+// normalizeExportData converts data into a plain json map
+// containing only the fields from the rawFields expression (if any).
+func normalizeExportData(data map[string]any, rawFields string) (map[string]any, error) {
+	var normalized any
+
+	if rawFields != "" {
+		picked, err := picker.Pick(data, rawFields)
+		if err != nil {
+			return nil, err
+		}
+		normalized = picked
+	} else {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &normalized); err != nil {
+			return nil, err
+		}
+	}
+
+	result, _ := normalized.(map[string]any)
+	if result == nil {
+		return nil, errors.New("failed to normalize export data")
+	}
+
+	return result, nil
+}
+
+// recordsExportColumns returns the ordered list of export columns based on
+// the collection visible fields and the keys of the provided sample rows.
+func recordsExportColumns(collection *core.Collection, rows []map[string]any, rawFields string) ([]string, error) {
+	defaults := map[string]any{
+		core.FieldNameCollectionId:   nil,
+		core.FieldNameCollectionName: nil,
+	}
+	for _, f := range collection.Fields {
+		if !f.GetHidden() {
+			defaults[f.GetName()] = nil
+		}
+	}
+	if collection.IsAuth() {
+		delete(defaults, core.FieldNamePassword)
+		delete(defaults, core.FieldNameTokenKey)
+	}
+
+	base, err := normalizeExportData(defaults, rawFields)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]struct{}{}
+	for k := range base {
+		keys[k] = struct{}{}
+	}
+	for _, row := range rows {
+		for k := range row {
+			keys[k] = struct{}{}
+		}
+	}
+
+	columns := make([]string, 0, len(keys))
+
+	// collection fields order
+	for _, f := range collection.Fields {
+		if _, ok := keys[f.GetName()]; ok {
+			columns = append(columns, f.GetName())
+			delete(keys, f.GetName())
+		}
+	}
+
+	for _, name := range []string{core.FieldNameCollectionId, core.FieldNameCollectionName} {
+		if _, ok := keys[name]; ok {
+			columns = append(columns, name)
+			delete(keys, name)
+		}
+	}
+
+	// custom fields
+	_, hasExpand := keys[core.FieldNameExpand]
+	delete(keys, core.FieldNameExpand)
+	extra := make([]string, 0, len(keys))
+	for k := range keys {
+		extra = append(extra, k)
+	}
+	slices.Sort(extra)
+	columns = append(columns, extra...)
+
+	if hasExpand {
+		columns = append(columns, core.FieldNameExpand)
+	}
+
+	return columns, nil
+}
+
+// -------------------------------------------------------------------
+
+type recordsExportEncoder interface {
+	start(columns []string) error
+	write(rows []map[string]any) error
+}
+
+func newRecordsExportEncoder(w io.Writer, format string) recordsExportEncoder {
+	switch format {
+	case RecordsExportFormatNDJSON, RecordsExportFormatJSONL:
+		return &recordsExportJSONLinesEncoder{enc: json.NewEncoder(w)}
+	default:
+		return &recordsExportCSVEncoder{w: csv.NewWriter(w)}
+	}
+}
+
+type recordsExportJSONLinesEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *recordsExportJSONLinesEncoder) start(columns []string) error {
+	return nil
+}
+
+func (e *recordsExportJSONLinesEncoder) write(rows []map[string]any) error {
+	for _, row := range rows {
+		if err := e.enc.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type recordsExportCSVEncoder struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (e *recordsExportCSVEncoder) start(columns []string) error {
+	e.columns = columns
+
+	if err := e.w.Write(columns); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+func (e *recordsExportCSVEncoder) write(rows []map[string]any) error {
+	line := make([]string, len(e.columns))
+
+	for _, row := range rows {
+		for i, col := range e.columns {
+			v, err := csvExportValue(row[col])
+			if err != nil {
+				return err
+			}
+			line[i] = v
+		}
+
+		if err := e.w.Write(line); err != nil {
+			return err
+		}
+	}
+
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+// csvExportValue converts a plain json value into a csv cell value.
+//
+// Arrays and objects are serialized as json.
+func csvExportValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
+
+// -------------------------------------------------------------------
+
+// recordsExportResponseWriter delays the response headers write
+// until the first export data is available, allowing regular
+// error responses in case of an invalid filter, sort, etc.
+type recordsExportResponseWriter struct {
+	event       *core.RequestEvent
+	contentType string
+	filename    string
+	written     bool
+}
+
+func (w *recordsExportResponseWriter) writeHeader() {
+	w.written = true
+
+	header := w.event.Response.Header()
+	header.Set("Content-Type", w.contentType)
+	header.Set("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+	header.Set("Cache-Control", "no-store")
+	w.event.Response.WriteHeader(http.StatusOK)
+}
+
+func (w *recordsExportResponseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.writeHeader()
+	}
+
+	return w.event.Response.Write(b)
+}
+
+func (w *recordsExportResponseWriter) Flush() error {
+	if !w.written {
+		return nil
+	}
+
+	return w.event.Flush()
+}
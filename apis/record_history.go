@@ -0,0 +1,308 @@
+package apis
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+// bindRecordHistoryApi registers the record version history api endpoints.
+func bindRecordHistoryApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/collections/{collection}/records/{id}/history")
+	sub.GET("", recordHistoryList)
+	sub.GET("/diff", recordHistoryDiff)
+	sub.POST("/{version}/restore", recordHistoryRestore)
+}
+
+var recordHistoryFilterFields = []string{
+	"id", "created", "version", "action", "changes", "authCollectionRef", "authRef",
+}
+
+var superuserRecordHistoryFilterFields = append([]string{"ip"}, recordHistoryFilterFields...)
+
+func recordHistoryList(e *core.RequestEvent) error {
+	collection, requestInfo, err := checkRecordHistoryAccess(e, "historyList")
+	if err != nil {
+		return err
+	}
+
+	filterFields := recordHistoryFilterFields
+	if requestInfo.HasSuperuserAuth() {
+		filterFields = superuserRecordHistoryFilterFields
+	}
+
+	query := e.App.RecordHistoryQuery().AndWhere(dbx.HashExp{
+		"collectionRef": collection.Id,
+		"recordRef":     e.Request.PathValue("id"),
+	})
+
+	items := []*core.RecordHistory{}
+
+	result, err := search.NewProvider(search.NewSimpleFieldResolver(filterFields...)).
+		Query(query).
+		ParseAndExec(e.Request.URL.Query().Encode(), &items)
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	ignoreEmailVisibility := recordHistoryIgnoreEmailVisibility(e, collection, requestInfo)
+
+	for _, item := range items {
+		sanitizeRecordHistory(collection, requestInfo, ignoreEmailVisibility, item)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func recordHistoryDiff(e *core.RequestEvent) error {
+	collection, requestInfo, err := checkRecordHistoryAccess(e, "historyDiff")
+	if err != nil {
+		return err
+	}
+
+	query := e.Request.URL.Query()
+
+	from, err := findRecordHistoryVersion(e, collection, query.Get("from"))
+	if err != nil {
+		return err
+	}
+
+	to, err := findRecordHistoryVersion(e, collection, query.Get("to"))
+	if err != nil {
+		return err
+	}
+
+	ignoreEmailVisibility := recordHistoryIgnoreEmailVisibility(e, collection, requestInfo)
+
+	sanitizeRecordHistory(collection, requestInfo, ignoreEmailVisibility, from)
+	sanitizeRecordHistory(collection, requestInfo, ignoreEmailVisibility, to)
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"from":    from.Version,
+		"to":      to.Version,
+		"changes": from.Diff(to),
+	})
+}
+
+// recordHistoryRestore restores a record version.
+//
+// Because the restore is a write operation, in addition to the history rule
+// non-superusers must also satisfy the collection update rule
+// (or the create rule if the record no longer exists).
+func recordHistoryRestore(e *core.RequestEvent) error {
+	collection, requestInfo, err := checkRecordHistoryAccess(e, "historyRestore")
+	if err != nil {
+		return err
+	}
+
+	entry, err := findRecordHistoryVersion(e, collection, e.Request.PathValue("version"))
+	if err != nil {
+		return err
+	}
+
+	hasSuperuserAuth := requestInfo.HasSuperuserAuth()
+
+	var isNew bool
+
+	if !hasSuperuserAuth {
+		existing, err := e.App.FindRecordById(collection, entry.RecordRef)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return e.NotFoundError("", err)
+			}
+			isNew = true
+		}
+
+		rule := collection.UpdateRule
+		if isNew {
+			rule = collection.CreateRule
+		}
+
+		if rule == nil {
+			return e.ForbiddenError("Only superusers can perform this action.", nil)
+		}
+
+		restrictRecordHistoryRestore(collection, entry, isNew)
+
+		// make the restored data available as request body for the rules
+		requestInfo.Body = entry.Data
+
+		if !isNew {
+			canAccess, err := e.App.CanAccessRecord(existing, requestInfo, rule)
+			if !canAccess {
+				return e.NotFoundError("", err)
+			}
+		}
+	}
+
+	var record *core.Record
+
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		restored, err := txApp.RestoreRecordHistory(recordHistoryContext(e), entry)
+		if err != nil {
+			return err
+		}
+
+		// the create rule is checked against the recreated record
+		if isNew {
+			canAccess, err := txApp.CanAccessRecord(restored, requestInfo, collection.CreateRule)
+			if !canAccess {
+				return e.BadRequestError("Failed to restore the record version.", errors.Join(errors.New("create rule failure"), err))
+			}
+		}
+
+		record = restored
+
+		return nil
+	})
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to restore the record version.", err))
+	}
+
+	err = EnrichRecord(e, record)
+	if err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to enrich the restored record.", err))
+	}
+
+	return e.JSON(http.StatusOK, record)
+}
+
+// checkRecordHistoryAccess loads the request collection and checks
+// whether the current request has access to the record history.
+//
+// Because the history rule is checked against the current record state,
+// the history of an already deleted record is accessible only by
+// superusers or if the collection history rule is public.
+func checkRecordHistoryAccess(e *core.RequestEvent, rateLimitTag string) (*core.Collection, *core.RequestInfo, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil || collection.IsView() {
+		return nil, nil, e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, rateLimitTag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil, nil, firstApiError(err, e.BadRequestError("", err))
+	}
+
+	if requestInfo.HasSuperuserAuth() {
+		return collection, requestInfo, nil
+	}
+
+	if collection.HistoryRule == nil {
+		return nil, nil, e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) && *collection.HistoryRule == "" {
+			return collection, requestInfo, nil
+		}
+		return nil, nil, e.NotFoundError("", err)
+	}
+
+	canAccess, err := e.App.CanAccessRecord(record, requestInfo, collection.HistoryRule)
+	if !canAccess {
+		return nil, nil, e.NotFoundError("", err)
+	}
+
+	return collection, requestInfo, nil
+}
+
+func findRecordHistoryVersion(e *core.RequestEvent, collection *core.Collection, rawVersion string) (*core.RecordHistory, error) {
+	version, err := strconv.Atoi(rawVersion)
+	if err != nil {
+		return nil, e.BadRequestError("Invalid or missing record history version.", err)
+	}
+
+	entry, err := e.App.FindRecordHistoryVersion(collection, e.Request.PathValue("id"), version)
+	if err != nil {
+		return nil, e.NotFoundError("", err)
+	}
+
+	return entry, nil
+}
+
+// restrictRecordHistoryRestore removes from the entry snapshot the fields
+// that non-superusers are not allowed to restore, aka. the hidden fields
+// and the auth verified and existing record email fields (similar to
+// the regular record create/update they require manage access).
+func restrictRecordHistoryRestore(collection *core.Collection, entry *core.RecordHistory, isNew bool) {
+	for _, f := range collection.Fields {
+		if f.GetHidden() {
+			delete(entry.Data, f.GetName())
+		}
+	}
+
+	if collection.IsAuth() {
+		delete(entry.Data, core.FieldNameVerified)
+
+		if !isNew {
+			delete(entry.Data, core.FieldNameEmail)
+		}
+	}
+}
+
+// recordHistoryIgnoreEmailVisibility reports whether the request could
+// access the history record email regardless of its emailVisibility state
+// (the same as in the record responses - superuser, owner or manager).
+func recordHistoryIgnoreEmailVisibility(e *core.RequestEvent, collection *core.Collection, requestInfo *core.RequestInfo) bool {
+	if !collection.IsAuth() {
+		return false
+	}
+
+	dummy := core.NewRecord(collection)
+	dummy.Id = e.Request.PathValue("id")
+
+	if err := autoResolveRecordsFlags(e.App, []*core.Record{dummy}, requestInfo); err != nil {
+		return false
+	}
+
+	_, ok := dummy.PublicExport()[core.FieldNameEmail]
+
+	return ok
+}
+
+// sanitizeRecordHistory removes the hidden fields values, the non-public
+// auth record email and the request ip from the history entry for non-superusers.
+func sanitizeRecordHistory(collection *core.Collection, requestInfo *core.RequestInfo, ignoreEmailVisibility bool, entry *core.RecordHistory) {
+	if requestInfo.HasSuperuserAuth() {
+		return
+	}
+
+	entry.IP = ""
+
+	for _, f := range collection.Fields {
+		if f.GetHidden() {
+			delete(entry.Data, f.GetName())
+		}
+	}
+
+	if collection.IsAuth() && !ignoreEmailVisibility && !cast.ToBool(entry.Data[core.FieldNameEmailVisibility]) {
+		delete(entry.Data, core.FieldNameEmail)
+	}
+}
+
+// recordHistoryContext returns a new context with the request auth
+// and ip that will be stored as part of the record history.
+//
+// note: the request context is not used to avoid interrupting
+// the record write in case the client disconnects
+func recordHistoryContext(e *core.RequestEvent) context.Context {
+	return core.WithRecordHistoryActor(context.Background(), core.RecordHistoryActor{
+		Auth: e.Auth,
+		IP:   e.RealIP(),
+	})
+}
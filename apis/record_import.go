@@ -0,0 +1,101 @@
+package apis
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/spf13/cast"
+)
+
+const (
+	importFormatQueryParam    = "format"
+	importMappingQueryParam   = "mapping"
+	importUpsertByQueryParam  = "upsertBy"
+	importDryRunQueryParam    = "dryRun"
+	importChunkSizeQueryParam = "chunkSize"
+
+	// importFileFormKey is the multipart/form-data key of the import file.
+	importFileFormKey = "file"
+)
+
+// bindRecordImportApi registers the records import api endpoint.
+func bindRecordImportApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	rg.POST("/collections/{collection}/import", recordsImport).Bind(BodyLimit(0), RequireSuperuserAuth())
+}
+
+// recordsImport imports the CSV or NDJSON rows from the request body
+// (or from the multipart/form-data "file" field) into the path collection.
+//
+// The import options are loaded from the query parameters:
+//   - format - csv, ndjson or jsonl (default to the content type or file extension)
+//   - mapping - JSON object with source column -> collection field name pairs
+//   - upsertBy - field name used to lookup the existing records to update
+//   - dryRun - validate the rows without persisting them
+//   - chunkSize - number of rows per transaction
+func recordsImport(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	query := e.Request.URL.Query()
+
+	options := core.RecordsImportOptions{
+		Format:    query.Get(importFormatQueryParam),
+		UpsertBy:  query.Get(importUpsertByQueryParam),
+		DryRun:    cast.ToBool(query.Get(importDryRunQueryParam)),
+		ChunkSize: cast.ToInt(query.Get(importChunkSizeQueryParam)),
+	}
+
+	if rawMapping := query.Get(importMappingQueryParam); rawMapping != "" {
+		if err := json.Unmarshal([]byte(rawMapping), &options.Mapping); err != nil {
+			return e.BadRequestError("Invalid import mapping.", err)
+		}
+	}
+
+	var source io.Reader = e.Request.Body
+
+	mediaType, _, _ := mime.ParseMediaType(e.Request.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		file, header, err := e.Request.FormFile(importFileFormKey)
+		if err != nil {
+			return e.BadRequestError("Missing or invalid import file.", err)
+		}
+		defer file.Close()
+
+		source = file
+
+		if options.Format == "" {
+			options.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+	} else if options.Format == "" {
+		options.Format = recordsImportFormatFromMediaType(mediaType)
+	}
+
+	result, err := e.App.ImportRecords(collection, source, options)
+	if err != nil {
+		if result == nil {
+			return firstApiError(err, e.BadRequestError("Invalid import options or data.", err))
+		}
+
+		return e.BadRequestError("Failed to import the records.", err)
+	}
+
+	return e.JSON(200, result)
+}
+
+func recordsImportFormatFromMediaType(mediaType string) string {
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson":
+		return core.RecordsImportFormatNDJSON
+	case "application/jsonl", "application/x-jsonlines":
+		return core.RecordsImportFormatJSONL
+	default:
+		return core.RecordsImportFormatCSV
+	}
+}
@@ -0,0 +1,119 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/spf13/cast"
+)
+
+// bindRecordSoftDeleteApi registers the soft deleted records api endpoints.
+func bindRecordSoftDeleteApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/collections/{collection}/records/{id}")
+	sub.POST("/restore", recordRestore)
+	sub.DELETE("/purge", recordPurge)
+}
+
+// recordsWithDeleted reports whether the request has asked to include
+// the soft deleted records via the "withDeleted" query parameter.
+//
+// Returns an error if the parameter is set by a non-superuser.
+func recordsWithDeleted(e *core.RequestEvent, collection *core.Collection, requestInfo *core.RequestInfo) (bool, error) {
+	if !collection.SoftDelete || !cast.ToBool(requestInfo.Query["withDeleted"]) {
+		return false, nil
+	}
+
+	if !requestInfo.HasSuperuserAuth() {
+		return false, e.ForbiddenError("Only superusers can view the deleted records.", nil)
+	}
+
+	return true, nil
+}
+
+func recordRestore(e *core.RequestEvent) error {
+	collection, requestInfo, err := findSoftDeleteCollection(e, "restore")
+	if err != nil {
+		return err
+	}
+
+	if !requestInfo.HasSuperuserAuth() && collection.DeleteRule == nil {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	record := &core.Record{}
+	err = e.App.RecordQueryWithDeleted(collection).
+		AndWhere(dbx.HashExp{inflector.Columnify(collection.Name) + ".id": e.Request.PathValue("id")}).
+		AndWhere(dbx.NewExp("[[" + inflector.Columnify(collection.Name) + "." + core.FieldNameDeleted + "]] != ''")).
+		Limit(1).
+		One(record)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	// the delete rule is checked against the soft deleted record state
+	canAccess, err := e.App.CanAccessRecord(record, requestInfo, collection.DeleteRule)
+	if !canAccess {
+		return e.NotFoundError("", err)
+	}
+
+	if err := e.App.RestoreDeletedRecord(recordHistoryContext(e), record); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to restore the record. Make sure that its relation references are not deleted.", err))
+	}
+
+	err = EnrichRecord(e, record)
+	if err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to enrich the restored record.", err))
+	}
+
+	return e.JSON(http.StatusOK, record)
+}
+
+func recordPurge(e *core.RequestEvent) error {
+	collection, requestInfo, err := findSoftDeleteCollection(e, "purge")
+	if err != nil {
+		return err
+	}
+
+	if !requestInfo.HasSuperuserAuth() {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	record := &core.Record{}
+	err = e.App.RecordQueryWithDeleted(collection).
+		AndWhere(dbx.HashExp{inflector.Columnify(collection.Name) + ".id": e.Request.PathValue("id")}).
+		Limit(1).
+		One(record)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if err := e.App.PurgeRecord(recordHistoryContext(e), record); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to purge the record. Make sure that the record is not part of a required relation reference.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// findSoftDeleteCollection loads the request collection and ensures
+// that it has the soft delete mode enabled.
+func findSoftDeleteCollection(e *core.RequestEvent, rateLimitTag string) (*core.Collection, *core.RequestInfo, error) {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil || collection.IsView() || !collection.SoftDelete {
+		return nil, nil, e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, rateLimitTag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return nil, nil, firstApiError(err, e.BadRequestError("", err))
+	}
+
+	return collection, requestInfo, nil
+}
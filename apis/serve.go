@@ -3,10 +3,12 @@ package apis
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"log/slog"
 	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -14,6 +16,8 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/certstore"
+	"github.com/pocketbase/pocketbase/tools/dns01"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/routine"
@@ -36,14 +40,51 @@ type ServeConfig struct {
 	// QuicAddr is the UDP address to listen for the HTTP3 server (eg. "127.0.0.1:8964").
 	QuicAddr string
 
+	// ProxyAddr is the optional TCP address to listen for the HTTP/1.1 only
+	// server intended for the forward proxy clients (eg. "127.0.0.1:8443").
+	//
+	// When set, HTTP/2 is enabled for the main HTTPS listener since the
+	// proxy CONNECT requests (that require connection hijacking) could be
+	// served by the dedicated listener.
+	//
+	// The proxy listener uses TLS only if HttpsAddr is also set.
+	ProxyAddr string
+
 	// Optional domains list to use when issuing the TLS certificate.
 	//
 	// If not set, the host from the bound server address will be used.
 	//
 	// For convenience, for each "non-www" domain a "www" entry and
 	// redirect will be automatically added.
+	//
+	// Wildcard domains (eg. "*.example.com") require a DNS01Solver.
 	CertificateDomains []string
 
+	// ACMEDirectoryURL is an optional ACME directory URL of a custom CA
+	// (default to the Let's Encrypt production directory).
+	ACMEDirectoryURL string
+
+	// ACMERootCA is an optional path to a PEM file with the root CA
+	// certificate(s) to trust when connecting to the ACME directory
+	// (eg. the root of a private step-ca instance).
+	ACMERootCA string
+
+	// ACMEEmail is an optional ACME account contact email.
+	ACMEEmail string
+
+	// ACMEEABKeyID and ACMEEABHMACKey are the optional External Account Binding
+	// credentials required by some CAs to register a new ACME account.
+	//
+	// The HMAC key is expected to be base64url encoded (as usually provided by the CAs).
+	ACMEEABKeyID   string
+	ACMEEABHMACKey string
+
+	// DNS01Solver is an optional DNS-01 challenge solver.
+	//
+	// When set, the certificates for all CertificateDomains (including the wildcard ones)
+	// are issued using the DNS-01 challenge instead of HTTP-01/TLS-ALPN-01.
+	DNS01Solver dns01.Solver
+
 	// AllowedOrigins is an optional list of CORS origins (default to "*").
 	AllowedOrigins []string
 }
@@ -112,6 +153,13 @@ func Serve(app core.App, config ServeConfig) error {
 		hostNames = append(hostNames, host)
 	}
 	for _, host := range hostNames {
+		if strings.HasPrefix(host, "*.") {
+			if config.DNS01Solver == nil {
+				return fmt.Errorf("the wildcard certificate domain %q requires a DNS-01 solver", host)
+			}
+			continue
+		}
+
 		if strings.HasPrefix(host, "www.") {
 			continue // explicitly set www host
 		}
@@ -128,11 +176,61 @@ func Serve(app core.App, config ServeConfig) error {
 		pbRouter.Bind(wwwRedirect(wwwRedirects))
 	}
 
+	eab, err := acmeExternalAccountBinding(config.ACMEEABKeyID, config.ACMEEABHMACKey)
+	if err != nil {
+		return err
+	}
+
+	certCache := autocert.DirCache(filepath.Join(app.DataDir(), core.LocalAutocertCacheDirName))
+
+	certManagerClient, err := newACMEClient(config.ACMEDirectoryURL, config.ACMERootCA)
+	if err != nil {
+		return err
+	}
+
 	certManager := &autocert.Manager{
-		Prompt:     autocert.AcceptTOS,
-		Cache:      autocert.DirCache(filepath.Join(app.DataDir(), core.LocalAutocertCacheDirName)),
-		HostPolicy: autocert.HostWhitelist(hostNames...),
+		Prompt:                 autocert.AcceptTOS,
+		Cache:                  certCache,
+		HostPolicy:             autocert.HostWhitelist(hostNames...),
+		Client:                 certManagerClient,
+		Email:                  config.ACMEEmail,
+		ExternalAccountBinding: eab,
+	}
+
+	var dnsCertManager *dns01.Manager
+	if config.DNS01Solver != nil {
+		// use a separate client because the account key is lazily initialized by each manager
+		dnsClient, err := newACMEClient(config.ACMEDirectoryURL, config.ACMERootCA)
+		if err != nil {
+			return err
+		}
+
+		dnsCertManager = &dns01.Manager{
+			Client:                 dnsClient,
+			Solver:                 config.DNS01Solver,
+			Cache:                  certCache,
+			Domains:                hostNames,
+			Email:                  config.ACMEEmail,
+			ExternalAccountBinding: eab,
+		}
+	}
+
+	// directory based SNI certificates (pb_data/.certs/<host>.pem)
+	certStore := certstore.New(filepath.Join(app.DataDir(), core.LocalCertsDirName))
+	if err := os.MkdirAll(certStore.Dir(), os.ModePerm); err != nil {
+		return err
 	}
+	if err := certStore.Load(); err != nil {
+		app.Logger().Warn("Failed to load some of the SNI certificates", "error", err)
+	}
+	if err := certStore.Watch(func(err error) {
+		if err != nil {
+			app.Logger().Warn("Failed to reload some of the SNI certificates", "error", err)
+		}
+	}); err != nil {
+		app.Logger().Warn("Failed to watch the SNI certificates directory", "error", err)
+	}
+	defer certStore.Close()
 
 	// base request context used for cancelling long running requests
 	// like the SSE connections
@@ -140,7 +238,7 @@ func Serve(app core.App, config ServeConfig) error {
 	defer cancelBaseCtx()
 
 	server := &http.Server{
-		TLSConfig: LocalTLSConfig(CERT, KEY),
+		TLSConfig: DefaultTLSConfig(certManager, dnsCertManager, certStore),
 		// higher defaults to accommodate large file uploads/downloads
 		WriteTimeout:      5 * time.Minute,
 		ReadTimeout:       5 * time.Minute,
@@ -154,6 +252,35 @@ func Serve(app core.App, config ServeConfig) error {
 
 	var listener net.Listener
 
+	var proxyServer *http.Server
+	var proxyListener net.Listener
+	if config.ProxyAddr != "" {
+		// per listener ALPN: h2 for the main server and http/1.1 for the proxy
+		server.TLSConfig = WithNextProtos(server.TLSConfig, "h2", "http/1.1")
+
+		// note: the proxy TLS config is derived from the main server one after the OnServe hooks
+		proxyServer = &http.Server{
+			// no read/write timeouts because the hijacked tunnel connections are usually long-lived
+			ReadHeaderTimeout: 1 * time.Minute,
+			Addr:              config.ProxyAddr,
+			BaseContext: func(l net.Listener) context.Context {
+				return baseCtx
+			},
+			ErrorLog: log.New(&serverErrorLogWriter{app: app}, "", 0),
+		}
+		proxyServer.Protocols = new(http.Protocols)
+		proxyServer.Protocols.SetHTTP1(true)
+	}
+
+	var quicServer *http3.Server
+	var quicConn net.PacketConn
+	if config.QuicAddr != "" {
+		quicServer = &http3.Server{
+			Addr:      config.QuicAddr,
+			TLSConfig: server.TLSConfig,
+		}
+	}
+
 	// graceful shutdown
 	// ---------------------------------------------------------------
 	// WaitGroup to block until server.ShutDown() returns because Serve and similar methods exit immediately.
@@ -171,6 +298,14 @@ func Serve(app core.App, config ServeConfig) error {
 
 			wg.Add(1)
 
+			if quicServer != nil {
+				_ = quicServer.Shutdown(ctx)
+			}
+
+			if proxyServer != nil {
+				_ = proxyServer.Shutdown(ctx)
+			}
+
 			_ = server.Shutdown(ctx)
 
 			if te.IsRestart {
@@ -194,6 +329,14 @@ func Serve(app core.App, config ServeConfig) error {
 		if listener != nil {
 			_ = listener.Close()
 		}
+
+		if quicConn != nil {
+			_ = quicConn.Close()
+		}
+
+		if proxyListener != nil {
+			_ = proxyListener.Close()
+		}
 	}()
 	// ---------------------------------------------------------------
 
@@ -204,6 +347,10 @@ func Serve(app core.App, config ServeConfig) error {
 	serveEvent.Router = pbRouter
 	serveEvent.Server = server
 	serveEvent.CertManager = certManager
+	serveEvent.DNSCertManager = dnsCertManager
+	serveEvent.CertStore = certStore
+	serveEvent.QuicServer = quicServer
+	serveEvent.ProxyServer = proxyServer
 	serveEvent.InstallerFunc = DefaultInstallerFunc
 
 	lastHook := func(e *core.ServeEvent) error {
@@ -212,12 +359,37 @@ func Serve(app core.App, config ServeConfig) error {
 			return err
 		}
 
+		if e.QuicServer != nil {
+			if e.QuicServer.Handler == nil {
+				e.QuicServer.Handler = handler
+			}
+
+			if e.QuicServer.TLSConfig == nil {
+				e.QuicServer.TLSConfig = e.Server.TLSConfig
+			}
+
+			// advertise the HTTP/3 server (unless explicitly overwritten with the ALT_SVC env)
+			if ALT_SVC == "" {
+				handler = QuicAltSvcMiddleware(handler, e.QuicServer)
+			}
+		}
+
 		if ALT_SVC != "" {
 			handler = AltSvcMiddleware(handler)
 		}
 
 		e.Server.Handler = handler
 
+		if e.ProxyServer != nil {
+			if e.ProxyServer.Handler == nil {
+				e.ProxyServer.Handler = handler
+			}
+
+			if e.ProxyServer.TLSConfig == nil && e.Server.TLSConfig != nil {
+				e.ProxyServer.TLSConfig = WithNextProtos(e.Server.TLSConfig, "http/1.1")
+			}
+		}
+
 		if config.HttpsAddr == "" {
 			baseURL = "http://" + serverAddrToHost(serveEvent.Server.Addr)
 		} else {
@@ -248,6 +420,41 @@ func Serve(app core.App, config ServeConfig) error {
 			listener = e.Listener
 		}
 
+		if e.ProxyServer != nil {
+			if e.ProxyListener == nil {
+				proxyListener, err = net.Listen("tcp", e.ProxyServer.Addr)
+				if err != nil {
+					return err
+				}
+			} else {
+				proxyListener = e.ProxyListener
+			}
+
+			proxyServer = e.ProxyServer
+		} else {
+			proxyServer = nil
+		}
+
+		if e.QuicServer != nil {
+			if e.QuicConn == nil {
+				quicAddr := e.QuicServer.Addr
+				if quicAddr == "" {
+					quicAddr = ":https"
+				}
+
+				quicConn, err = net.ListenPacket("udp", quicAddr)
+				if err != nil {
+					return err
+				}
+			} else {
+				quicConn = e.QuicConn
+			}
+
+			quicServer = e.QuicServer
+		} else {
+			quicServer = nil
+		}
+
 		if e.InstallerFunc != nil {
 			app := e.App
 			installerFunc := e.InstallerFunc
@@ -289,11 +496,28 @@ func Serve(app core.App, config ServeConfig) error {
 	}
 
 	var serveErr error
-	if config.QuicAddr != "" {
-		slog.Info("Starting HTTP3 server", "on", config.QuicAddr, "CERT", CERT, "KEY", KEY)
-		if config.QuicAddr != "" {
-			go http3.ListenAndServeQUIC(config.QuicAddr, CERT, KEY, serveEvent.Server.Handler)
-		}
+	if quicServer != nil {
+		slog.Info("Starting HTTP3 server", "on", quicConn.LocalAddr().String())
+		routine.FireAndForget(func() {
+			err := quicServer.Serve(quicConn)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				app.Logger().Error("HTTP3 server error", slog.String("error", err.Error()))
+			}
+		})
+	}
+	if proxyServer != nil {
+		slog.Info("Starting HTTP/1.1 proxy server", "on", proxyListener.Addr().String())
+		routine.FireAndForget(func() {
+			var err error
+			if config.HttpsAddr != "" {
+				err = proxyServer.ServeTLS(proxyListener, "", "")
+			} else {
+				err = proxyServer.Serve(proxyListener)
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				app.Logger().Error("Proxy server error", slog.String("error", err.Error()))
+			}
+		})
 	}
 	if config.HttpsAddr != "" {
 		slog.Info("Starting HTTPS server", "on", config.HttpsAddr, "CERT", CERT, "KEY", KEY)
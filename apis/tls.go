@@ -2,10 +2,21 @@ package apis
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 
+	"github.com/pocketbase/pocketbase/tools/certstore"
+	"github.com/pocketbase/pocketbase/tools/dns01"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/webteleport/utils"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 var (
@@ -17,29 +28,174 @@ var (
 
 // disable HTTP/2, because http.Hijacker is not supported,
 // which is required by https://github.com/elazarl/goproxy
+//
+// Note that this applies only to the main listener when there is no
+// dedicated proxy listener (see [ServeConfig.ProxyAddr]).
 var NextProtos = []string{"http/1.1"}
 
+// LocalTLSConfig returns a TLS config serving the specified cert/key pair.
+//
+// The key pair is loaded in memory and it is reloaded only when
+// the cert or key file changes (the files are watched for the
+// lifetime of the process).
+//
+// Use [DefaultTLSConfig] to serve the pair from an already running [certstore.Store].
 func LocalTLSConfig(certFile, keyFile string) *tls.Config {
-	GetCertificate := func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
-		// Always get latest localhost.crt and localhost.key
-		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
-		if err != nil {
-			return nil, err
-		}
-		return &cert, nil
-	}
+	store := certstore.New("")
+
+	// note: the load error is also returned by GetCertificate during the handshake
+	_ = store.SetDefault(certFile, keyFile)
+
+	_ = store.Watch(nil)
+
+	return localTLSConfig(store.GetCertificate)
+}
+
+func localTLSConfig(getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) *tls.Config {
 	if os.Getenv("HTTP2") != "" {
 		NextProtos = []string{"h2", "http/1.1"}
 	}
 	return &tls.Config{
-		GetCertificate: GetCertificate,
+		GetCertificate: getCertificate,
 		NextProtos:     NextProtos,
 	}
 }
 
+// DefaultTLSConfig returns the TLS config shared by the HTTPS and HTTP/3 servers.
+//
+// The certificates are resolved in the following order:
+//   - the SNI matching certificate from the optional certStore
+//   - the CERT and KEY files (if they exist); they are registered as
+//     the certStore default certificate or served with [LocalTLSConfig] if certStore is nil
+//   - the certificates issued by the optional DNS-01 manager (for its managed domains)
+//   - the certificates issued by the provided autocert manager
+func DefaultTLSConfig(certManager *autocert.Manager, dnsCertManager *dns01.Manager, certStore *certstore.Store) *tls.Config {
+	if fileExists(CERT) && fileExists(KEY) {
+		if certStore == nil {
+			return LocalTLSConfig(CERT, KEY)
+		}
+
+		// note: the load error is also returned by GetCertificate during the handshake
+		_ = certStore.SetDefault(CERT, KEY)
+
+		return localTLSConfig(certStore.GetCertificate)
+	}
+
+	config := certManager.TLSConfig()
+
+	protos := NextProtos
+	if os.Getenv("HTTP2") != "" {
+		protos = []string{"h2", "http/1.1"}
+	}
+
+	// keep the ACME TLS-ALPN challenge protocol but respect the configured app protocols
+	config.NextProtos = append(slices.Clone(protos), acme.ALPNProto)
+
+	if dnsCertManager != nil {
+		autocertGetCertificate := config.GetCertificate
+		config.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if dnsCertManager.Manages(hello.ServerName) && !slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+				return dnsCertManager.GetCertificate(hello)
+			}
+
+			return autocertGetCertificate(hello)
+		}
+	}
+
+	if certStore != nil {
+		fallback := config.GetCertificate
+		config.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			// leave the ACME TLS-ALPN challenges to the autocert manager
+			if !slices.Contains(hello.SupportedProtos, acme.ALPNProto) {
+				if cert, err := certStore.GetCertificate(hello); err == nil {
+					return cert, nil
+				}
+			}
+
+			return fallback(hello)
+		}
+	}
+
+	return config
+}
+
+// WithNextProtos returns a clone of the provided TLS config with the
+// specified ALPN protocols (eg. "h2", "http/1.1").
+//
+// The ACME TLS-ALPN challenge protocol is preserved if present in the original config.
+func WithNextProtos(config *tls.Config, protos ...string) *tls.Config {
+	clone := config.Clone()
+
+	clone.NextProtos = slices.Clone(protos)
+	if slices.Contains(config.NextProtos, acme.ALPNProto) {
+		clone.NextProtos = append(clone.NextProtos, acme.ALPNProto)
+	}
+
+	return clone
+}
+
+// newACMEClient creates a new ACME client for the specified directory
+// (default to Let's Encrypt) that optionally trusts the root CA(s) from rootCAFile.
+func newACMEClient(directoryURL string, rootCAFile string) (*acme.Client, error) {
+	client := &acme.Client{DirectoryURL: directoryURL}
+
+	if rootCAFile != "" {
+		raw, err := os.ReadFile(rootCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the ACME root CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw) {
+			return nil, fmt.Errorf("no valid certificates found in the ACME root CA %q", rootCAFile)
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+		client.HTTPClient = &http.Client{Transport: transport}
+	}
+
+	return client, nil
+}
+
+// acmeExternalAccountBinding returns an ACME EAB from the provided
+// key id and base64url encoded HMAC key (returns nil if the key id is empty).
+func acmeExternalAccountBinding(keyId string, hmacKey string) (*acme.ExternalAccountBinding, error) {
+	if keyId == "" {
+		return nil, nil
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(hmacKey, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ACME EAB HMAC key: %w", err)
+	}
+
+	if len(key) == 0 {
+		return nil, errors.New("missing ACME EAB HMAC key")
+	}
+
+	return &acme.ExternalAccountBinding{KID: keyId, Key: key}, nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
 func AltSvcMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Alt-Svc", ALT_SVC)
 		next.ServeHTTP(w, r)
 	})
 }
+
+// QuicAltSvcMiddleware advertises the provided HTTP/3 server via the Alt-Svc header.
+//
+// The header is not set if the server is not listening yet.
+func QuicAltSvcMiddleware(next http.Handler, quicServer *http3.Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = quicServer.SetQUICHeaders(w.Header())
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,125 @@
+package apis_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tools/dns01"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestWithNextProtos(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		original []string
+		protos   []string
+		expected []string
+	}{
+		{"empty", nil, nil, nil},
+		{"without acme", []string{"http/1.1"}, []string{"h2", "http/1.1"}, []string{"h2", "http/1.1"}},
+		{"with acme", []string{"h2", "http/1.1", acme.ALPNProto}, []string{"http/1.1"}, []string{"http/1.1", acme.ALPNProto}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			original := &tls.Config{NextProtos: s.original, ServerName: "test"}
+
+			result := apis.WithNextProtos(original, s.protos...)
+
+			if result == original {
+				t.Fatal("Expected a new config instance")
+			}
+
+			if result.ServerName != "test" {
+				t.Fatalf("Expected the original config fields to be preserved, got %q", result.ServerName)
+			}
+
+			if !slices.Equal(result.NextProtos, s.expected) {
+				t.Fatalf("Expected protos %v, got %v", s.expected, result.NextProtos)
+			}
+
+			if len(s.original) > 0 && !slices.Equal(original.NextProtos, s.original) {
+				t.Fatalf("The original protos were modified: %v", original.NextProtos)
+			}
+		})
+	}
+}
+
+func TestDefaultTLSConfigDNSCertManager(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		DNSNames:     []string{"*.example.com"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := autocert.DirCache(t.TempDir())
+
+	err = cache.Put(context.Background(), "dns01+_.example.com", append(
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certManager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      cache,
+		HostPolicy: autocert.HostWhitelist("other.com"),
+	}
+
+	dnsCertManager := &dns01.Manager{
+		Cache:   cache,
+		Domains: []string{"*.example.com"},
+	}
+
+	config := apis.DefaultTLSConfig(certManager, dnsCertManager, nil)
+
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "a.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Leaf == nil || cert.Leaf.Subject.CommonName != "*.example.com" {
+		t.Fatalf("Expected the DNS-01 manager certificate, got %v", cert.Leaf)
+	}
+
+	// unmanaged domain should fallback to the autocert manager
+	_, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "missing.com"})
+	if err == nil || errors.Is(err, dns01.ErrUnmanagedDomain) {
+		t.Fatalf("Expected autocert host policy error, got %v", err)
+	}
+}
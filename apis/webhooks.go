@@ -0,0 +1,96 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// bindWebhooksApi registers the webhook deliveries api endpoints.
+func bindWebhooksApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/webhooks/deliveries").Bind(RequireSuperuserAuth(), SkipSuccessActivityLog())
+	sub.GET("", webhookDeliveriesList)
+	sub.GET("/{id}", webhookDeliveryView)
+	sub.POST("/{id}/redeliver", webhookDeliveryRedeliver)
+}
+
+var webhookDeliveryFilterFields = []string{
+	"id", "created", "updated", "nextAttempt", "webhook", "event", "collection",
+	"recordId", "url", "status", "attempts", "responseStatus", "responseBody", "error",
+}
+
+func webhookDeliveriesList(e *core.RequestEvent) error {
+	fieldResolver := search.NewSimpleFieldResolver(webhookDeliveryFilterFields...)
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(e.App.WebhookDeliveryQuery()).
+		ParseAndExec(e.Request.URL.Query().Encode(), &[]*core.WebhookDelivery{})
+
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func webhookDeliveryView(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+	if id == "" {
+		return e.NotFoundError("", nil)
+	}
+
+	delivery, err := e.App.FindWebhookDeliveryById(id)
+	if err != nil || delivery == nil {
+		return e.NotFoundError("", err)
+	}
+
+	return e.JSON(http.StatusOK, delivery)
+}
+
+// webhookDeliveryRedeliver creates and sends a new delivery with the payload of an existing one.
+func webhookDeliveryRedeliver(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+	if id == "" {
+		return e.NotFoundError("", nil)
+	}
+
+	original, err := e.App.FindWebhookDeliveryById(id)
+	if err != nil || original == nil {
+		return e.NotFoundError("", err)
+	}
+
+	hook, ok := e.App.Settings().Webhooks.FindWebhook(original.Webhook)
+	if !ok {
+		return e.BadRequestError("The delivery webhook configuration no longer exists.", nil)
+	}
+
+	now := types.NowDateTime()
+
+	delivery := &core.WebhookDelivery{
+		Webhook:     original.Webhook,
+		Event:       original.Event,
+		Collection:  original.Collection,
+		RecordId:    original.RecordId,
+		URL:         hook.URL,
+		Payload:     original.Payload,
+		Status:      core.WebhookDeliveryStatusPending,
+		NextAttempt: now,
+		Created:     now,
+		Updated:     now,
+	}
+
+	delivery.Id = core.GenerateDefaultRandomId()
+
+	if err := e.App.AuxSaveNoValidate(delivery); err != nil {
+		return e.BadRequestError("Failed to create the new delivery.", err)
+	}
+
+	if err := e.App.SendWebhookDelivery(e.Request.Context(), delivery); err != nil {
+		return e.BadRequestError("Failed to send the new delivery.", err)
+	}
+
+	return e.JSON(http.StatusOK, delivery)
+}
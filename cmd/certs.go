@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/certstore"
+	"github.com/spf13/cobra"
+)
+
+// NewCertsCommand creates and returns new command for managing
+// the directory based SNI certificates (pb_data/.certs).
+func NewCertsCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "certs",
+		Short: "Manage the SNI certificates",
+	}
+
+	command.AddCommand(certsListCommand(app))
+
+	return command
+}
+
+func certsListCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:          "list",
+		Example:      "certs list",
+		Short:        "Lists the loaded SNI certificates and their expiry dates",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			// prefer the store of the server running in the same process
+			// and fallback to the certificates that the server would load on start
+			store := apis.ServeCertStore(app)
+			if store == nil {
+				store = certstore.New(filepath.Join(app.DataDir(), core.LocalCertsDirName))
+
+				if err := store.Load(); err != nil {
+					color.Yellow("Failed to load some of the certificates:\n%v", err)
+				}
+			}
+
+			certs := store.List()
+			if len(certs) == 0 {
+				color.Yellow("No certificates found in %q.", store.Dir())
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "FILE\tHOSTS\tISSUER\tEXPIRES")
+
+			now := time.Now()
+			for _, cert := range certs {
+				expires := cert.NotAfter.UTC().Format(time.DateTime)
+				if cert.NotAfter.Before(now) {
+					expires += " (expired)"
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cert.File, strings.Join(cert.Hosts, ","), cert.Issuer, expires)
+			}
+
+			return w.Flush()
+		},
+	}
+
+	return command
+}
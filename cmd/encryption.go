@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewEncryptionCommand creates and returns new command for managing
+// the app encryption key (settings and encrypted record fields).
+func NewEncryptionCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "encryption",
+		Short: "Manage the app encryption key",
+	}
+
+	command.AddCommand(encryptionRotateCommand(app))
+
+	return command
+}
+
+func encryptionRotateCommand(app core.App) *cobra.Command {
+	var newEnv string
+
+	command := &cobra.Command{
+		Use:          "rotate",
+		Example:      "encryption rotate --newEnv=PB_NEW_ENCRYPTION_KEY",
+		Short:        "Re-encrypts the app settings and all encrypted record fields with a new key",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if app.EncryptionEnv() == "" {
+				return errors.New("the app encryption env is not configured (see the --encryptionEnv flag)")
+			}
+
+			if newEnv == "" {
+				return errors.New("missing --newEnv flag")
+			}
+
+			// the new secret is read from env so that it doesn't end up in the shell history
+			newSecret := os.Getenv(newEnv)
+			if newSecret == "" {
+				return fmt.Errorf("missing or empty %q env variable", newEnv)
+			}
+
+			if err := app.RotateEncryptionKey(newSecret); err != nil {
+				return fmt.Errorf("failed to rotate the encryption key: %w", err)
+			}
+
+			color.Green("Successfully rotated the encryption key!")
+			color.Yellow("Don't forget to set the %q env variable to the new key value before restarting the app.", app.EncryptionEnv())
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&newEnv, "newEnv", "", "the name of the env variable holding the new encryption key")
+
+	return command
+}
@@ -0,0 +1,66 @@
+package cmd_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestEncryptionRotateCommand(t *testing.T) {
+	oldSecret := strings.Repeat("a", 32)
+	newSecret := strings.Repeat("b", 32)
+
+	t.Setenv("pb_test_env", oldSecret)
+	t.Setenv("PB_TEST_NEW_KEY", newSecret)
+	t.Setenv("PB_TEST_EMPTY_KEY", "")
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name           string
+		args           []string
+		expectError    bool
+		expectedSecret string
+	}{
+		{
+			"missing newEnv flag",
+			[]string{"rotate"},
+			true,
+			oldSecret,
+		},
+		{
+			"empty new env variable",
+			[]string{"rotate", "--newEnv=PB_TEST_EMPTY_KEY"},
+			true,
+			oldSecret,
+		},
+		{
+			"valid new env variable",
+			[]string{"rotate", "--newEnv=PB_TEST_NEW_KEY"},
+			false,
+			newSecret,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			command := cmd.NewEncryptionCommand(app)
+			command.SetArgs(s.args)
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if s.expectError != hasErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if v := os.Getenv("pb_test_env"); v != s.expectedSecret {
+				t.Fatalf("Expected encryption env secret %q, got %q", s.expectedSecret, v)
+			}
+		})
+	}
+}
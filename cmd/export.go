@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewExportCommand creates and returns new command for exporting
+// the records of a single collection as CSV, NDJSON or JSON Lines.
+func NewExportCommand(app core.App) *cobra.Command {
+	var output string
+	var format string
+	var filter string
+	var sort string
+	var fields string
+	var expand string
+	var relations string
+	var fileURLs bool
+
+	command := &cobra.Command{
+		Use:          "export [collection]",
+		Example:      "export posts --filter=\"created>'2024-01-01'\" --sort=-created --output=posts.csv",
+		Short:        "Exports the records of a single collection as CSV, NDJSON or JSON Lines",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			collection, err := app.FindCachedCollectionByNameOrId(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to fetch %q collection: %w", args[0], err)
+			}
+
+			superusers, err := app.FindCachedCollectionByNameOrId(core.CollectionNameSuperusers)
+			if err != nil {
+				return fmt.Errorf("failed to fetch %q collection: %w", core.CollectionNameSuperusers, err)
+			}
+
+			// infer the format from the output file extension
+			if format == "" && output != "" {
+				format = strings.TrimPrefix(strings.ToLower(filepath.Ext(output)), ".")
+			}
+
+			options := apis.RecordsExportOptions{
+				Format:    format,
+				Relations: relations,
+				FileURLs:  fileURLs,
+			}
+			if err := options.Validate(); err != nil {
+				return err
+			}
+
+			query := url.Values{}
+			if filter != "" {
+				query.Set("filter", filter)
+			}
+			if sort != "" {
+				query.Set("sort", sort)
+			}
+			if fields != "" {
+				query.Set("fields", fields)
+			}
+			if expand != "" {
+				query.Set("expand", expand)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/?"+query.Encode(), http.NoBody)
+			if err != nil {
+				return err
+			}
+
+			// the CLI export is always executed with superuser access
+			event := new(core.RequestEvent)
+			event.App = app
+			event.Request = req
+			event.Auth = core.NewRecord(superusers)
+
+			var out io.Writer = os.Stdout
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				out = f
+			}
+
+			bw := bufio.NewWriter(out)
+
+			err = errors.Join(apis.ExportRecords(event, collection, bw, options), bw.Flush())
+			if err != nil {
+				return err
+			}
+
+			if output != "" {
+				color.Green("Successfully exported %q records to %s.", collection.Name, output)
+			}
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"",
+		"Output file path (default to stdout)",
+	)
+
+	command.PersistentFlags().StringVar(
+		&format,
+		"format",
+		"",
+		"Export format - csv, ndjson or jsonl\n(default to the output file extension or csv)",
+	)
+
+	command.PersistentFlags().StringVar(
+		&filter,
+		"filter",
+		"",
+		"Optional records filter expression",
+	)
+
+	command.PersistentFlags().StringVar(
+		&sort,
+		"sort",
+		"",
+		"Optional records sort expression (eg. \"-created,id\")",
+	)
+
+	command.PersistentFlags().StringVar(
+		&fields,
+		"fields",
+		"",
+		"Optional comma separated list of the fields to export",
+	)
+
+	command.PersistentFlags().StringVar(
+		&expand,
+		"expand",
+		"",
+		"Optional comma separated list of the relations to expand",
+	)
+
+	command.PersistentFlags().StringVar(
+		&relations,
+		"relations",
+		apis.RecordsExportRelationsIds,
+		"How to render the relation fields - ids or expand",
+	)
+
+	command.PersistentFlags().BoolVar(
+		&fileURLs,
+		"file-urls",
+		false,
+		"Render the file fields as absolute urls based on the settings app URL",
+	)
+
+	return command
+}
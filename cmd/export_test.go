@@ -0,0 +1,109 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestExportCommand(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name             string
+		args             []string
+		output           string
+		expectError      bool
+		expectedContent  []string
+		notExpectContent []string
+	}{
+		{
+			"missing collection",
+			[]string{"missing"},
+			"export.csv",
+			true,
+			nil,
+			nil,
+		},
+		{
+			"unsupported output extension",
+			[]string{"demo2"},
+			"export.txt",
+			true,
+			nil,
+			nil,
+		},
+		{
+			"csv with superusers only list rule",
+			[]string{"demo1", "--fields=id,text", "--sort=id"},
+			"export.csv",
+			false,
+			[]string{
+				"id,text\n",
+				"84nmscqy84lsi1t,test\n",
+			},
+			nil,
+		},
+		{
+			"ndjson with filter",
+			[]string{"demo2", "--filter=active=false", "--fields=id,title"},
+			"export.ndjson",
+			false,
+			[]string{`{"id":"llvuca81nly1qls","title":"test1"}` + "\n"},
+			[]string{"achvryl401bhse3", "0yxhwia2amd8gec"},
+		},
+		{
+			"explicit format with file urls",
+			[]string{"demo3", "--format=jsonl", "--file-urls", "--fields=files", "--filter=id='7nwo8tuiatetxdm'"},
+			"export.out",
+			false,
+			[]string{`{"files":["http://localhost:8090/api/files/wzlqyes4orhoygb/7nwo8tuiatetxdm/test_JnXeKEwgwr.txt"]}` + "\n"},
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), s.output)
+
+			command := cmd.NewExportCommand(app)
+			command.SetArgs(append(s.args, "--output="+output))
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if s.expectError != hasErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			raw, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			content := string(raw)
+
+			for _, str := range s.expectedContent {
+				if !strings.Contains(content, str) {
+					t.Fatalf("Cannot find %q in\n%s", str, content)
+				}
+			}
+
+			for _, str := range s.notExpectContent {
+				if strings.Contains(content, str) {
+					t.Fatalf("Didn't expect %q in\n%s", str, content)
+				}
+			}
+		})
+	}
+}
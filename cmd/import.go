@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand creates and returns new command for importing
+// CSV, NDJSON or JSON Lines rows as records of a single collection.
+func NewImportCommand(app core.App) *cobra.Command {
+	var format string
+	var mapping map[string]string
+	var upsertBy string
+	var dryRun bool
+	var chunkSize int
+	var report string
+
+	command := &cobra.Command{
+		Use:          "import [collection] [file]",
+		Example:      "import posts posts.csv --mapping=\"Title=title,Body=content\" --upsert-by=slug",
+		Short:        "Imports CSV, NDJSON or JSON Lines rows as records of a single collection",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			collection, err := app.FindCachedCollectionByNameOrId(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to fetch %q collection: %w", args[0], err)
+			}
+
+			// infer the format from the input file extension
+			if format == "" {
+				format = strings.TrimPrefix(strings.ToLower(filepath.Ext(args[1])), ".")
+			}
+
+			var source io.Reader = os.Stdin
+			if args[1] != "-" {
+				f, err := os.Open(args[1])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				source = f
+			}
+
+			result, err := app.ImportRecords(collection, source, core.RecordsImportOptions{
+				Format:    format,
+				Mapping:   mapping,
+				UpsertBy:  upsertBy,
+				DryRun:    dryRun,
+				ChunkSize: chunkSize,
+			})
+			if err != nil && result == nil {
+				return err
+			}
+
+			if report != "" {
+				raw, reportErr := json.MarshalIndent(result, "", "  ")
+				if reportErr == nil {
+					reportErr = os.WriteFile(report, raw, 0644)
+				}
+				if reportErr != nil {
+					return fmt.Errorf("failed to write the import report: %w", reportErr)
+				}
+			} else {
+				for _, rowErr := range result.Errors {
+					color.Red(rowErr.Error())
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+
+			summary := fmt.Sprintf(
+				"Processed %d rows (created: %d, updated: %d, failed: %d).",
+				result.Total,
+				result.Created,
+				result.Updated,
+				result.Failed,
+			)
+			if dryRun {
+				summary = "[dry-run] " + summary
+			}
+
+			if result.Failed > 0 {
+				color.Yellow(summary)
+				return fmt.Errorf("%d of %d rows failed to import", result.Failed, result.Total)
+			}
+
+			color.Green(summary)
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().StringVar(
+		&format,
+		"format",
+		"",
+		"Import format - csv, ndjson or jsonl\n(default to the input file extension)",
+	)
+
+	command.PersistentFlags().StringToStringVar(
+		&mapping,
+		"mapping",
+		nil,
+		"Optional comma separated source column=collection field pairs\n(the unmapped columns are imported in the field with the same name)",
+	)
+
+	command.PersistentFlags().StringVar(
+		&upsertBy,
+		"upsert-by",
+		"",
+		"Optional field name used to lookup and update the existing records",
+	)
+
+	command.PersistentFlags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"Validate the rows without persisting any changes",
+	)
+
+	command.PersistentFlags().IntVar(
+		&chunkSize,
+		"chunk-size",
+		core.DefaultRecordsImportChunkSize,
+		"Number of rows persisted in a single transaction",
+	)
+
+	command.PersistentFlags().StringVar(
+		&report,
+		"report",
+		"",
+		"Optional file path to write the JSON import report\n(default to printing the failed rows)",
+	)
+
+	return command
+}
@@ -0,0 +1,128 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/cmd"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestImportCommand(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		args           []string
+		input          string
+		data           string
+		expectError    bool
+		expectedReport []string
+		expectedTitles []string
+		missingTitles  []string
+	}{
+		{
+			"missing collection",
+			[]string{"missing"},
+			"import.csv",
+			"title\nimport1\n",
+			true,
+			nil,
+			nil,
+			[]string{"import1"},
+		},
+		{
+			"unsupported input extension",
+			[]string{"demo2"},
+			"import.txt",
+			"title\nimport1\n",
+			true,
+			nil,
+			nil,
+			[]string{"import1"},
+		},
+		{
+			"csv with mapping",
+			[]string{"demo2", "--mapping=Name=title,Other="},
+			"import.csv",
+			"Name,Other\nimport1,a\nimport2,b\n",
+			false,
+			[]string{`"total": 2`, `"created": 2`, `"failed": 0`},
+			[]string{"import1", "import2"},
+			nil,
+		},
+		{
+			"ndjson with failed rows",
+			[]string{"demo2"},
+			"import.ndjson",
+			`{"title":"import1"}` + "\n" + `{"title":"x"}`,
+			true,
+			[]string{`"total": 2`, `"created": 1`, `"failed": 1`, `"row": 2`},
+			[]string{"import1"},
+			[]string{"x"},
+		},
+		{
+			"explicit format with upsert and dry-run",
+			[]string{"demo2", "--format=jsonl", "--upsert-by=title", "--dry-run"},
+			"import.out",
+			`{"title":"test1","active":true}` + "\n" + `{"title":"import1"}`,
+			false,
+			[]string{`"dryRun": true`, `"created": 1`, `"updated": 1`},
+			nil,
+			[]string{"import1"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			dir := t.TempDir()
+			input := filepath.Join(dir, s.input)
+			report := filepath.Join(dir, "report.json")
+
+			if err := os.WriteFile(input, []byte(s.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			command := cmd.NewImportCommand(app)
+			command.SetArgs(append(s.args, input, "--report="+report))
+
+			err := command.Execute()
+
+			hasErr := err != nil
+			if s.expectError != hasErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if len(s.expectedReport) > 0 {
+				raw, err := os.ReadFile(report)
+				if err != nil {
+					t.Fatal(err)
+				}
+				content := string(raw)
+
+				for _, str := range s.expectedReport {
+					if !strings.Contains(content, str) {
+						t.Fatalf("Cannot find %q in\n%s", str, content)
+					}
+				}
+			}
+
+			for _, title := range s.expectedTitles {
+				if _, err := app.FindFirstRecordByData("demo2", "title", title); err != nil {
+					t.Fatalf("Missing record with title %q: %v", title, err)
+				}
+			}
+
+			for _, title := range s.missingTitles {
+				if _, err := app.FindFirstRecordByData("demo2", "title", title); err == nil {
+					t.Fatalf("Expected record with title %q to be missing", title)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// NewOpenAPICommand creates and returns new command for generating
+// the app OpenAPI document without starting the web server.
+//
+// Note that the routes registered in OnServe hooks are documented
+// only by the /api/openapi.json endpoint of the running server.
+func NewOpenAPICommand(app core.App) *cobra.Command {
+	var output string
+
+	command := &cobra.Command{
+		Use:          "openapi",
+		Example:      "openapi --output=openapi.json",
+		Short:        "Generates the OpenAPI 3.1 document of the app routes and collections",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			// ensure that the latest migrations are applied so that
+			// the document could reflect the current collections
+			if err := app.RunAllMigrations(); err != nil {
+				return err
+			}
+
+			pbRouter, err := apis.NewRouter(app)
+			if err != nil {
+				return err
+			}
+
+			// note: the OnServe hooks are not triggered because they could have
+			// side effects (eg. starting the cron jobs or other background workers)
+			doc, err := apis.NewOpenAPIDocument(app, pbRouter)
+			if err != nil {
+				return err
+			}
+
+			if version := command.Root().Version; version != "" {
+				if info, ok := doc["info"].(map[string]any); ok {
+					info["version"] = version
+				}
+			}
+
+			raw, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err = os.Stdout.Write(append(raw, '\n'))
+				return err
+			}
+
+			if err := os.WriteFile(output, raw, 0644); err != nil {
+				return err
+			}
+
+			color.Green("Successfully generated %q!", output)
+
+			return nil
+		},
+	}
+
+	command.PersistentFlags().StringVarP(
+		&output,
+		"output",
+		"o",
+		"",
+		"Output file path of the generated document (default to stdout)",
+	)
+
+	return command
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/dns01"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +17,13 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 	var httpAddr string
 	var httpsAddr string
 	var quicAddr string
+	var proxyAddr string
+	var acmeDirectory string
+	var acmeRootCA string
+	var acmeEmail string
+	var acmeEABKeyId string
+	var acmeEABHMACKey string
+	var acmeDNSExec string
 
 	command := &cobra.Command{
 		Use:          "serve [domain(s)]",
@@ -37,13 +45,25 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 				}
 			}
 
+			var dnsSolver dns01.Solver
+			if acmeDNSExec != "" {
+				dnsSolver = &dns01.ExecSolver{Command: acmeDNSExec}
+			}
+
 			err := apis.Serve(app, apis.ServeConfig{
 				HttpAddr:           httpAddr,
 				HttpsAddr:          httpsAddr,
 				QuicAddr:           quicAddr,
+				ProxyAddr:          proxyAddr,
 				ShowStartBanner:    showStartBanner,
 				AllowedOrigins:     allowedOrigins,
 				CertificateDomains: args,
+				ACMEDirectoryURL:   acmeDirectory,
+				ACMERootCA:         acmeRootCA,
+				ACMEEmail:          acmeEmail,
+				ACMEEABKeyID:       acmeEABKeyId,
+				ACMEEABHMACKey:     acmeEABHMACKey,
+				DNS01Solver:        dnsSolver,
 			})
 
 			if errors.Is(err, http.ErrServerClosed) {
@@ -82,5 +102,54 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 		"UDP address to listen for the HTTP3 server\n(if domain args are specified - default to 0.0.0.0:8964, otherwise - default to 127.0.0.1:8964)",
 	)
 
+	command.PersistentFlags().StringVar(
+		&proxyAddr,
+		"proxy",
+		"",
+		"TCP address to listen for the HTTP/1.1 only forward proxy server\n(when set, HTTP/2 is enabled for the main HTTPS server)",
+	)
+
+	command.PersistentFlags().StringVar(
+		&acmeDirectory,
+		"acme-directory",
+		"",
+		"ACME directory URL of a custom CA used to issue the TLS certificates\n(default to the Let's Encrypt production directory)",
+	)
+
+	command.PersistentFlags().StringVar(
+		&acmeRootCA,
+		"acme-root-ca",
+		"",
+		"Path to a PEM file with the root CA certificate(s) to trust when connecting to the ACME directory",
+	)
+
+	command.PersistentFlags().StringVar(
+		&acmeEmail,
+		"acme-email",
+		"",
+		"Optional ACME account contact email",
+	)
+
+	command.PersistentFlags().StringVar(
+		&acmeEABKeyId,
+		"acme-eab-kid",
+		"",
+		"ACME External Account Binding key id",
+	)
+
+	command.PersistentFlags().StringVar(
+		&acmeEABHMACKey,
+		"acme-eab-hmac",
+		"",
+		"ACME External Account Binding base64url encoded HMAC key",
+	)
+
+	command.PersistentFlags().StringVar(
+		&acmeDNSExec,
+		"acme-dns-exec",
+		"",
+		"Path to a program that manages the DNS-01 challenge TXT records\n(invoked as \"<program> present|cleanup <name> <value>\"; enables wildcard certificate domains)",
+	)
+
 	return command
 }
@@ -5,6 +5,7 @@ package core
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"time"
 
@@ -371,6 +372,55 @@ type App interface {
 
 	// ---------------------------------------------------------------
 
+	// WebhookDeliveryQuery returns a new WebhookDelivery select query.
+	WebhookDeliveryQuery() *dbx.SelectQuery
+
+	// FindWebhookDeliveryById finds a single WebhookDelivery entry by its id.
+	FindWebhookDeliveryById(id string) (*WebhookDelivery, error)
+
+	// DeleteOldWebhookDeliveries deletes all webhook deliveries that are created before createdBefore.
+	DeleteOldWebhookDeliveries(createdBefore time.Time) error
+
+	// SendWebhookDelivery sends the provided pending webhook delivery
+	// and updates its status, attempts and response details.
+	//
+	// If the delivery fails and there are remaining attempts, it is
+	// scheduled for a retry with exponential backoff.
+	SendWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error
+
+	// ---------------------------------------------------------------
+
+	// IdempotencyKeyQuery returns a new IdempotencyKey select query.
+	IdempotencyKeyQuery() *dbx.SelectQuery
+
+	// FindIdempotencyKey finds a single IdempotencyKey entry by its auth scope and key.
+	FindIdempotencyKey(auth string, key string) (*IdempotencyKey, error)
+
+	// DeleteOldIdempotencyKeys deletes all idempotency keys that are created before createdBefore.
+	DeleteOldIdempotencyKeys(createdBefore time.Time) error
+
+	// ---------------------------------------------------------------
+
+	// RecordHistoryQuery returns a new RecordHistory select query.
+	RecordHistoryQuery() *dbx.SelectQuery
+
+	// FindRecordHistory returns all version history entries of a single
+	// collection record ordered by their version (oldest first).
+	FindRecordHistory(collectionModelOrIdentifier any, recordId string) ([]*RecordHistory, error)
+
+	// FindRecordHistoryVersion finds a single collection record version history entry.
+	FindRecordHistoryVersion(collectionModelOrIdentifier any, recordId string, version int) (*RecordHistory, error)
+
+	// RestoreRecordHistory restores the record fields to the state
+	// of the provided history entry snapshot and saves it.
+	//
+	// If the record was deleted, it is recreated with the same id.
+	//
+	// Note that the password, file and autodate fields are not restored.
+	RestoreRecordHistory(ctx context.Context, entry *RecordHistory) (*Record, error)
+
+	// ---------------------------------------------------------------
+
 	// CollectionQuery returns a new Collection select query.
 	CollectionQuery() *dbx.SelectQuery
 
@@ -468,6 +518,14 @@ type App interface {
 	// but accept marshaled json array as import data (usually used for the autogenerated snapshots).
 	ImportCollectionsByMarshaledJSON(rawSliceOfMaps []byte, deleteMissing bool) error
 
+	// ImportRecords reads the CSV or NDJSON rows from r and creates
+	// (or updates, if options.UpsertBy is set) a record for each of them
+	// in transactions of options.ChunkSize rows.
+	//
+	// Each record is validated and saved with [App.Save] and
+	// the rows that failed are reported in the returned result.
+	ImportRecords(collection *Collection, r io.Reader, options RecordsImportOptions) (*RecordsImportResult, error)
+
 	// SyncRecordTableSchema compares the two provided collections
 	// and applies the necessary related record table changes.
 	//
@@ -555,8 +613,18 @@ type App interface {
 	// In case a collection id or name is provided and that collection doesn't
 	// actually exists, the generated query will be created with a cancelled context
 	// and will fail once an executor (Row(), One(), All(), etc.) is called.
+	//
+	// For collections with enabled soft delete mode, the soft deleted records
+	// are excluded from the query (see also [App.RecordQueryWithDeleted]).
+	//
+	// For collections with enabled TTL, the expired records are also
+	// excluded from the query even if they are not deleted yet.
 	RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery
 
+	// RecordQueryWithDeleted is similar to [App.RecordQuery] but
+	// the returned query includes also the soft deleted and the expired (not yet deleted) records.
+	RecordQueryWithDeleted(collectionModelOrIdentifier any) *dbx.SelectQuery
+
 	// FindRecordById finds the Record model by its id.
 	FindRecordById(collectionModelOrIdentifier any, recordId string, optFilters ...func(q *dbx.SelectQuery) error) (*Record, error)
 
@@ -639,6 +707,12 @@ type App interface {
 	// CountRecords returns the total number of records in a collection.
 	CountRecords(collectionModelOrIdentifier any, exprs ...dbx.Expression) (int64, error)
 
+	// AggregateRecords groups the collection records matching the options filters
+	// and calculates the options aggregates (count, sum, avg, min, max) for each group.
+	//
+	// Each returned item is a map with the options GroupBy and Aggregates expressions as keys.
+	AggregateRecords(collection *Collection, options RecordsAggregateOptions) ([]map[string]any, error)
+
 	// FindAuthRecordByToken finds the auth record associated with the provided JWT
 	// (auth, file, verifyEmail, changeEmail, passwordReset types).
 	//
@@ -688,6 +762,26 @@ type App interface {
 	// Returns a map with the failed expand parameters and their errors.
 	ExpandRecords(records []*Record, expands []string, optFetchFunc ExpandFetchFunc) map[string]error
 
+	// RestoreDeletedRecord restores a soft deleted record
+	// by clearing its "deleted" system field and saving it.
+	RestoreDeletedRecord(ctx context.Context, record *Record) error
+
+	// PurgeRecord permanently deletes the provided record
+	// (including its files) no matter of the collection soft delete mode.
+	//
+	// The cascade deletes triggered by the record delete are also permanent.
+	PurgeRecord(ctx context.Context, record *Record) error
+
+	// RotateEncryptionKey re-encrypts all encrypted record fields values
+	// and the app settings with the provided new secret and on success
+	// sets it as value of the [App.EncryptionEnv] env variable.
+	//
+	// The records are updated directly in the db without triggering hooks.
+	//
+	// Note that the new secret must be also persisted in the
+	// app environment, otherwise it will be lost on restart.
+	RotateEncryptionKey(newSecret string) error
+
 	// ---------------------------------------------------------------
 	// App event hooks
 	// ---------------------------------------------------------------
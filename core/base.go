@@ -41,6 +41,7 @@ const (
 	LocalBackupsDirName       string = "backups"
 	LocalTempDirName          string = ".pb_temp_to_delete" // temp pb_data sub directory that will be deleted on each app.Bootstrap()
 	LocalAutocertCacheDirName string = ".autocert_cache"
+	LocalCertsDirName         string = ".certs" // SNI certificates directory with "<host>.pem" files
 )
 
 // FilesManager defines an interface with common methods that files manager models should implement.
@@ -1310,6 +1311,11 @@ func (app *BaseApp) registerBaseHooks() {
 	app.OnModelAfterDeleteSuccess().Bind(&hook.Handler[*ModelEvent]{
 		Id: "__pbFilesManagerDelete__",
 		Func: func(e *ModelEvent) error {
+			// soft deleted records keep their files
+			if r, ok := e.Model.(*Record); ok && isRecordSoftDelete(e.Context, r) {
+				return e.Next()
+			}
+
 			if m, ok := e.Model.(FilesManager); ok && m.BaseFilesPath() != "" && supportFiles(e.Model) {
 				// ensure that there is a trailing slash so that the list iterator could start walking from the prefix dir
 				// (https://github.com/pocketbase/pocketbase/discussions/5246#discussioncomment-10128955)
@@ -1380,6 +1386,12 @@ func (app *BaseApp) registerBaseHooks() {
 	app.registerMFAHooks()
 	app.registerOTPHooks()
 	app.registerAuthOriginHooks()
+	app.registerWebhookHooks()
+	app.registerIdempotencyKeyHooks()
+	app.registerRecordHistoryHooks()
+	app.registerRecordSoftDeleteHooks()
+	app.registerRecordTTLHooks()
+	app.registerRecordVectorIndexHooks()
 }
 
 // getLoggerMinLevel returns the logger min level based on the
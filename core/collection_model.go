@@ -382,6 +382,9 @@ type Collection struct {
 	baseCollection
 	collectionAuthOptions
 	collectionViewOptions
+	collectionHistoryOptions
+	collectionSoftDeleteOptions
+	collectionTTLOptions
 }
 
 // NewCollection initializes and returns a new Collection model with the specified type and name.
@@ -510,10 +513,20 @@ func (m *Collection) unmarshalRawOptions() error {
 	case CollectionTypeView:
 		return json.Unmarshal(raw, &m.collectionViewOptions)
 	case CollectionTypeAuth:
-		return json.Unmarshal(raw, &m.collectionAuthOptions)
+		if err := json.Unmarshal(raw, &m.collectionAuthOptions); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	if err := json.Unmarshal(raw, &m.collectionHistoryOptions); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw, &m.collectionSoftDeleteOptions); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, &m.collectionTTLOptions)
 }
 
 // UnmarshalJSON implements the [json.Unmarshaler] interface.
@@ -557,7 +570,10 @@ func (m Collection) MarshalJSON() ([]byte, error) {
 		alias := struct {
 			baseCollection
 			collectionAuthOptions
-		}{m.baseCollection, m.collectionAuthOptions}
+			collectionHistoryOptions
+			collectionSoftDeleteOptions
+			collectionTTLOptions
+		}{m.baseCollection, m.collectionAuthOptions, m.collectionHistoryOptions, m.collectionSoftDeleteOptions, m.collectionTTLOptions}
 
 		// ensure that it is always returned as array
 		if alias.OAuth2.Providers == nil {
@@ -576,7 +592,12 @@ func (m Collection) MarshalJSON() ([]byte, error) {
 
 		return json.Marshal(alias)
 	default:
-		return json.Marshal(m.baseCollection)
+		return json.Marshal(struct {
+			baseCollection
+			collectionHistoryOptions
+			collectionSoftDeleteOptions
+			collectionTTLOptions
+		}{m.baseCollection, m.collectionHistoryOptions, m.collectionSoftDeleteOptions, m.collectionTTLOptions})
 	}
 }
 
@@ -613,7 +634,22 @@ func (m *Collection) DBExport(app App) (map[string]any, error) {
 			return nil, err
 		}
 	case CollectionTypeAuth:
-		if raw, err := types.ParseJSONRaw(m.collectionAuthOptions); err == nil {
+		if raw, err := types.ParseJSONRaw(struct {
+			collectionAuthOptions
+			collectionHistoryOptions
+			collectionSoftDeleteOptions
+			collectionTTLOptions
+		}{m.collectionAuthOptions, m.collectionHistoryOptions, m.collectionSoftDeleteOptions, m.collectionTTLOptions}); err == nil {
+			result["options"] = raw
+		} else {
+			return nil, err
+		}
+	case CollectionTypeBase:
+		if raw, err := types.ParseJSONRaw(struct {
+			collectionHistoryOptions
+			collectionSoftDeleteOptions
+			collectionTTLOptions
+		}{m.collectionHistoryOptions, m.collectionSoftDeleteOptions, m.collectionTTLOptions}); err == nil {
 			result["options"] = raw
 		} else {
 			return nil, err
@@ -716,6 +752,22 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 			if err := txApp.DeleteTable(e.Collection.Name); err != nil {
 				return err
 			}
+
+			if err := recordFTSTable.dropAll(txApp, e.Collection); err != nil {
+				return err
+			}
+
+			if err := recordRTreeTable.dropAll(txApp, e.Collection); err != nil {
+				return err
+			}
+
+			if err := recordVectorIndexTable.dropAll(txApp, e.Collection); err != nil {
+				return err
+			}
+
+			if err := deleteCollectionRecordHistory(txApp, e.Collection); err != nil {
+				return err
+			}
 		}
 
 		if !e.Collection.disableIntegrityChecks {
@@ -908,6 +960,7 @@ func (c *Collection) initDefaultFields() {
 	switch c.Type {
 	case CollectionTypeBase:
 		c.initIdField()
+		c.initDeletedField()
 	case CollectionTypeAuth:
 		c.initIdField()
 		c.initPasswordField()
@@ -915,6 +968,7 @@ func (c *Collection) initDefaultFields() {
 		c.initEmailField()
 		c.initEmailVisibilityField()
 		c.initVerifiedField()
+		c.initDeletedField()
 	case CollectionTypeView:
 		// view fields are autogenerated
 	}
@@ -999,6 +1053,34 @@ func (c *Collection) initTokenKeyField() {
 	}
 }
 
+// initDeletedField ensures that the "deleted" system field exists
+// when the collection soft delete mode is enabled.
+//
+// If the soft delete mode is disabled, the existing field is only
+// unmarked as system so that it could be removed or reused.
+func (c *Collection) initDeletedField() {
+	field, _ := c.Fields.GetByName(FieldNameDeleted).(*DateField)
+
+	if !c.SoftDelete {
+		if field != nil {
+			field.System = false
+		}
+		return
+	}
+
+	if field == nil {
+		// load default field
+		c.Fields.Add(&DateField{
+			Name:   FieldNameDeleted,
+			System: true,
+		})
+	} else {
+		// enforce system defaults
+		field.System = true
+		field.Required = false
+	}
+}
+
 func (c *Collection) initEmailField() {
 	field, _ := c.Fields.GetByName(FieldNameEmail).(*EmailField)
 	if field == nil {
@@ -0,0 +1,35 @@
+package core
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var _ optionsValidator = (*collectionHistoryOptions)(nil)
+
+// collectionHistoryOptions defines the record version history options
+// of the "base" and "auth" type collections.
+type collectionHistoryOptions struct {
+	// History enables storing a snapshot of the collection records
+	// on each create, update and delete (see [RecordHistory]).
+	History bool `form:"history" json:"history"`
+
+	// HistoryRule specifies who can list, diff and restore the record history versions.
+	//
+	// Set it to nil to allow only superusers to access the record history.
+	//
+	// Note that the rule is checked against the current record state,
+	// so for deleted records it applies only if it is the empty (aka. public) rule.
+	HistoryRule *string `form:"historyRule" json:"historyRule"`
+}
+
+func (o *collectionHistoryOptions) validate(cv *collectionValidator) error {
+	return validation.ValidateStruct(o,
+		validation.Field(&o.History, validation.When(cv.new.IsView(), validation.Empty)),
+		validation.Field(
+			&o.HistoryRule,
+			validation.When(cv.new.IsView(), validation.Nil),
+			validation.By(cv.checkRule),
+			validation.By(cv.ensureNoSystemRuleChange(cv.original.HistoryRule)),
+		),
+	)
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var _ optionsValidator = (*collectionSoftDeleteOptions)(nil)
+
+// collectionSoftDeleteOptions defines the soft delete options
+// of the "base" and "auth" type collections.
+type collectionSoftDeleteOptions struct {
+	// SoftDelete enables the soft delete mode for the collection records.
+	//
+	// When enabled, deleting a record only sets its "deleted" system
+	// field and the record is excluded from the default records queries
+	// (see [App.RecordQuery] and [App.RecordQueryWithDeleted]) and
+	// from the relation and @collection joins of the API rules and filters.
+	//
+	// Note that the soft deleted records still hold their unique index
+	// values (eg. the auth record email) so that they could be always
+	// restored. The values are released after the record is purged.
+	SoftDelete bool `form:"softDelete" json:"softDelete"`
+
+	// SoftDeleteRetention specifies the number of days after which
+	// the soft deleted records are permanently deleted.
+	//
+	// Set it to 0 to keep the soft deleted records until explicitly purged.
+	SoftDeleteRetention int `form:"softDeleteRetention" json:"softDeleteRetention"`
+}
+
+func (o *collectionSoftDeleteOptions) validate(cv *collectionValidator) error {
+	return validation.ValidateStruct(o,
+		validation.Field(&o.SoftDelete, validation.When(cv.new.IsView(), validation.Empty)),
+		validation.Field(
+			&o.SoftDeleteRetention,
+			validation.When(cv.new.IsView() || !o.SoftDelete, validation.Empty),
+			validation.Min(0),
+		),
+	)
+}
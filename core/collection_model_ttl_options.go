@@ -0,0 +1,89 @@
+package core
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var _ optionsValidator = (*collectionTTLOptions)(nil)
+
+// collectionTTLOptions defines the records expiration options
+// of the "base" and "auth" type collections.
+type collectionTTLOptions struct {
+	// TTLField specifies the name of the date or autodate field
+	// from which the records expiration is calculated.
+	//
+	// Records with empty TTLField value never expire.
+	//
+	// Leave it empty to disable the records expiration.
+	TTLField string `form:"ttlField" json:"ttlField"`
+
+	// TTL specifies the number of seconds after the TTLField date
+	// when the record is considered expired.
+	//
+	// Set it to 0 if the TTLField value is the expiration date itself.
+	//
+	// The expired records are excluded from the default records queries
+	// and are periodically deleted by the "__pbTTLCleanup__" cron job.
+	TTL int64 `form:"ttl" json:"ttl"`
+}
+
+// HasTTL reports whether the collection records could expire.
+func (o *collectionTTLOptions) HasTTL() bool {
+	return o.TTLField != ""
+}
+
+func (o *collectionTTLOptions) validate(cv *collectionValidator) error {
+	return validation.ValidateStruct(o,
+		validation.Field(
+			&o.TTLField,
+			validation.When(cv.new.IsView(), validation.Empty),
+			validation.By(cv.checkTTLField),
+		),
+		validation.Field(
+			&o.TTL,
+			validation.When(cv.new.IsView() || o.TTLField == "", validation.Empty),
+			validation.Min(int64(0)),
+		),
+	)
+}
+
+func (cv *collectionValidator) checkTTLField(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	switch cv.new.Fields.GetByName(v).(type) {
+	case *DateField, *AutodateField:
+		return nil
+	default:
+		return validation.NewError("validation_invalid_ttl_field", "The TTL field must be an existing date or autodate field.")
+	}
+}
+
+// recordExpirationThreshold returns the date before which (inclusive)
+// the collection records are considered expired.
+func recordExpirationThreshold(collection *Collection, now time.Time) types.DateTime {
+	threshold, _ := types.ParseDateTime(now.Add(-time.Duration(collection.TTL) * time.Second))
+
+	return threshold
+}
+
+// notExpiredRecordsExpr returns an expression that excludes the expired
+// records of the specified TTL enabled collection.
+//
+// The threshold param name is derived from the table name (or alias)
+// so that the expression could be applied to multiple joined tables.
+func notExpiredRecordsExpr(collection *Collection, tableName string, now time.Time) dbx.Expression {
+	column := "[[" + tableName + "." + collection.TTLField + "]]"
+	param := "ttlThreshold_" + tableName
+
+	return dbx.NewExp(
+		"("+column+" = '' OR "+column+" > {:"+param+"})",
+		dbx.Params{param: recordExpirationThreshold(collection, now).String()},
+	)
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -218,6 +219,8 @@ func (app *BaseApp) IsCollectionNameUnique(name string, excludeIds ...string) bo
 //
 // Note that this method will also trigger the records related
 // cascade and file delete actions.
+//
+// The records of collections with soft delete mode are permanently deleted.
 func (app *BaseApp) TruncateCollection(collection *Collection) error {
 	if collection.IsView() {
 		return errors.New("view collections cannot be truncated since they don't store their own records")
@@ -227,7 +230,7 @@ func (app *BaseApp) TruncateCollection(collection *Collection) error {
 		records := make([]*Record, 0, 500)
 
 		for {
-			err := txApp.RecordQuery(collection).Limit(500).All(&records)
+			err := txApp.RecordQueryWithDeleted(collection).Limit(500).All(&records)
 			if err != nil {
 				return err
 			}
@@ -237,7 +240,7 @@ func (app *BaseApp) TruncateCollection(collection *Collection) error {
 			}
 
 			for _, record := range records {
-				err = txApp.Delete(record)
+				err = txApp.PurgeRecord(context.Background(), record)
 				if err != nil && !errors.Is(err, sql.ErrNoRows) {
 					return err
 				}
@@ -43,7 +43,19 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 				return err
 			}
 
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
+
+			if err := recordFTSTable.sync(txApp, newCollection, nil); err != nil {
+				return err
+			}
+
+			if err := recordRTreeTable.sync(txApp, newCollection, nil); err != nil {
+				return err
+			}
+
+			return recordVectorIndexTable.sync(txApp, newCollection, nil)
 		}
 
 		// update
@@ -132,6 +144,18 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 			return err
 		}
 
+		if err := recordFTSTable.sync(txApp, newCollection, oldCollection); err != nil {
+			return err
+		}
+
+		if err := recordRTreeTable.sync(txApp, newCollection, oldCollection); err != nil {
+			return err
+		}
+
+		if err := recordVectorIndexTable.sync(txApp, newCollection, oldCollection); err != nil {
+			return err
+		}
+
 		if needIndexesUpdate {
 			return createCollectionIndexes(txApp, newCollection)
 		}
@@ -370,6 +370,16 @@ func (cv *collectionValidator) checkMinFields(value any) error {
 		return validation.NewError("validation_missing_primary_key", `Missing or invalid "id" PK field.`)
 	}
 
+	if cv.new.SoftDelete && !cv.new.IsView() {
+		deletedField, _ := fields.GetByName(FieldNameDeleted).(*DateField)
+		if deletedField == nil {
+			return validation.NewError("validation_missing_deleted_field", `System "deleted" date field is required.`)
+		}
+		if !deletedField.System {
+			return validation.Errors{FieldNameDeleted: ErrMustBeSystem}
+		}
+	}
+
 	switch cv.new.Type {
 	case CollectionTypeAuth:
 		passwordField, _ := fields.GetByName(FieldNamePassword).(*PasswordField)
@@ -683,12 +693,20 @@ func (cv *collectionValidator) checkIndexes(value any) error {
 }
 
 func (validator *collectionValidator) validateOptions() error {
+	commonErr := validators.JoinValidationErrors(
+		validators.JoinValidationErrors(
+			validator.new.collectionHistoryOptions.validate(validator),
+			validator.new.collectionSoftDeleteOptions.validate(validator),
+		),
+		validator.new.collectionTTLOptions.validate(validator),
+	)
+
 	switch validator.new.Type {
 	case CollectionTypeAuth:
-		return validator.new.collectionAuthOptions.validate(validator)
+		return validators.JoinValidationErrors(validator.new.collectionAuthOptions.validate(validator), commonErr)
 	case CollectionTypeView:
-		return validator.new.collectionViewOptions.validate(validator)
+		return validators.JoinValidationErrors(validator.new.collectionViewOptions.validate(validator), commonErr)
 	}
 
-	return nil
+	return commonErr
 }
@@ -364,6 +364,38 @@ func TestCollectionValidate(t *testing.T) {
 			},
 			expectedErrors: []string{},
 		},
+		{
+			name: "view with history options",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewViewCollection("new")
+				c.ViewQuery = "select 1 as id, 'text' as f1"
+				c.History = true
+				c.HistoryRule = types.Pointer("")
+				return c, nil
+			},
+			expectedErrors: []string{"history", "historyRule"},
+		},
+		{
+			name: "base with history options",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewBaseCollection("new")
+				c.Fields.Add(&core.TextField{Name: "f1"})
+				c.History = true
+				c.HistoryRule = types.Pointer("f1 = 123 && @request.auth.id != ''")
+				return c, nil
+			},
+			expectedErrors: []string{},
+		},
+		{
+			name: "base with invalid history rule",
+			collection: func(app core.App) (*core.Collection, error) {
+				c := core.NewBaseCollection("new")
+				c.History = true
+				c.HistoryRule = types.Pointer("missing = 123")
+				return c, nil
+			},
+			expectedErrors: []string{"historyRule"},
+		},
 		{
 			name: "changing api rules",
 			collection: func(app core.App) (*core.Collection, error) {
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"database/sql/driver"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// VectorDistanceSQLFunction is the name of the SQL function used by
+// the vectorDistance() filter and sort function.
+//
+// It is registered automatically for the default SQLite driver.
+// If you use a custom [DBConnectFunc] (eg. with the no_default_driver build tag),
+// you'll have to register it manually with 3 arguments and [VectorDistanceSQL]
+// as its implementation.
+const VectorDistanceSQLFunction = "pb_vector_distance"
+
+// VectorDistanceSQL is the driver agnostic implementation of the
+// [VectorDistanceSQLFunction] with args (vectorA, vectorB, metric).
+//
+// The vector arguments are expected to be float32 blobs (see [types.Vector.Bytes]).
+//
+// Returns NULL if one of the vectors is NULL or the distance can't be
+// calculated (eg. different dimensions) so that a single malformed
+// value doesn't abort the entire query.
+func VectorDistanceSQL(args ...driver.Value) (driver.Value, error) {
+	if len(args) != 3 {
+		return nil, nil
+	}
+
+	a := sqlVectorArg(args[0])
+	b := sqlVectorArg(args[1])
+	if len(a) == 0 || len(b) == 0 {
+		return nil, nil
+	}
+
+	distance, err := a.Distance(b, cast.ToString(args[2]))
+	if err != nil {
+		return nil, nil
+	}
+
+	return distance, nil
+}
+
+func sqlVectorArg(arg driver.Value) types.Vector {
+	var v types.Vector
+
+	switch val := arg.(type) {
+	case []byte:
+		v, _ = types.ParseVectorBytes(val)
+	case string:
+		v, _ = types.ParseVectorBytes([]byte(val))
+	}
+
+	return v
+}
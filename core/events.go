@@ -7,11 +7,14 @@ import (
 	"time"
 
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/certstore"
+	"github.com/pocketbase/pocketbase/tools/dns01"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/router"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/quic-go/quic-go/http3"
 	"golang.org/x/crypto/acme/autocert"
 )
 
@@ -105,11 +108,50 @@ type ServeEvent struct {
 	Server      *http.Server
 	CertManager *autocert.Manager
 
+	// DNSCertManager is the optional DNS-01 certificates manager that is
+	// used instead of the CertManager for the managed domains.
+	//
+	// It is initialized only if a DNS-01 solver is configured.
+	DNSCertManager *dns01.Manager
+
+	// CertStore is the directory based SNI certificates store
+	// (pb_data/.certs) that is checked before the CertManager.
+	CertStore *certstore.Store
+
 	// Listener allow specifying a custom network listener.
 	//
 	// Leave it nil to use the default net.Listen("tcp", e.Server.Addr).
 	Listener net.Listener
 
+	// ProxyServer is the optional HTTP/1.1 only server that is started together
+	// with the main TCP server and shares its handler.
+	//
+	// It is usually used by forward proxy clients since the CONNECT proxy
+	// handlers require connection hijacking which is not available with HTTP/2.
+	//
+	// It is initialized only if a proxy address is configured.
+	//
+	// If its TLSConfig is nil, it is set after the OnServe hooks to a clone
+	// of the Server.TLSConfig with "http/1.1" as the only ALPN protocol.
+	ProxyServer *http.Server
+
+	// ProxyListener allow specifying a custom proxy network listener.
+	//
+	// Leave it nil to use the default net.Listen("tcp", e.ProxyServer.Addr).
+	ProxyListener net.Listener
+
+	// QuicServer is the optional HTTP/3 server that is started together with
+	// the main TCP server and shares its TLS config and handler.
+	//
+	// It is initialized only if a QUIC address is configured.
+	// You can replace it with a custom one or set it to nil to disable HTTP/3.
+	QuicServer *http3.Server
+
+	// QuicConn allow specifying a custom QUIC packet connection.
+	//
+	// Leave it nil to use the default net.ListenPacket("udp", e.QuicServer.Addr).
+	QuicConn net.PacketConn
+
 	// InstallerFunc is the "installer" function that is called after
 	// successful server tcp bind but only if there is no explicit
 	// superuser record created yet.
@@ -9,6 +9,7 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core/validators"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 var fieldNameRegex = regexp.MustCompile(`^\w+$`)
@@ -26,6 +27,7 @@ const (
 	FieldNameVerified        = "verified"
 	FieldNameTokenKey        = "tokenKey"
 	FieldNamePassword        = "password"
+	FieldNameDeleted         = "deleted"
 )
 
 // SystemFields returns special internal field names that are usually readonly.
@@ -119,6 +121,28 @@ type MaxBodySizeCalculator interface {
 	CalculateMaxBodySize() int64
 }
 
+// FullTextSearcher defines an optional field interface for
+// fields whose values could be full-text indexed.
+type FullTextSearcher interface {
+	// IsSearchable reports whether the field values should be full-text indexed.
+	IsSearchable() bool
+
+	// SearchableText returns the plain text representation
+	// of the record field value that will be indexed.
+	SearchableText(record *Record) string
+}
+
+// SpatialIndexer defines an optional field interface for
+// fields whose values could be indexed with a R*Tree spatial index.
+type SpatialIndexer interface {
+	// IsSpatialIndexed reports whether the field values should be spatial indexed.
+	IsSpatialIndexed() bool
+
+	// SpatialBBox returns the bounding box of the record field value
+	// that will be indexed (ok is false for values that shouldn't be indexed).
+	SpatialBBox(record *Record) (bbox types.GeoBBox, ok bool)
+}
+
 type (
 	SetterFunc func(record *Record, raw any)
 
@@ -21,6 +21,8 @@ const DefaultEditorFieldMaxSize int64 = 5 << 20
 var (
 	_ Field                 = (*EditorField)(nil)
 	_ MaxBodySizeCalculator = (*EditorField)(nil)
+	_ RecordInterceptor     = (*EditorField)(nil)
+	_ FullTextSearcher      = (*EditorField)(nil)
 )
 
 // EditorField defines "editor" type field to store HTML formatted text.
@@ -61,6 +63,13 @@ type EditorField struct {
 
 	// Required will require the field value to be non-empty string.
 	Required bool `form:"required" json:"required"`
+
+	// Searchable maintains a SQLite FTS5 full-text index of the field
+	// plain text values (aka. with stripped HTML tags) that could be queried
+	// with the ftsMatch() filter function and the @rank sort key.
+	//
+	// It is ignored for view collections.
+	Searchable bool `form:"searchable" json:"searchable"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -160,3 +169,26 @@ func (f *EditorField) CalculateMaxBodySize() int64 {
 
 	return f.MaxSize
 }
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *EditorField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	return recordFTSTable.intercept(app, record, f, actionName, actionFunc)
+}
+
+// IsSearchable implements the [FullTextSearcher] interface.
+func (f *EditorField) IsSearchable() bool {
+	return f.Searchable
+}
+
+// SearchableText implements the [FullTextSearcher] interface.
+//
+// The HTML tags are stripped and only the plain text content is returned.
+func (f *EditorField) SearchableText(record *Record) string {
+	return stripHTML(record.GetString(f.Name))
+}
@@ -0,0 +1,497 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeEncrypted] = func() Field {
+		return &EncryptedField{}
+	}
+}
+
+const FieldTypeEncrypted = "encrypted"
+
+const DefaultEncryptedFieldMaxSize int64 = 64 << 10
+
+// List with the supported EncryptedField value formats.
+const (
+	EncryptedFormatText = "text"
+	EncryptedFormatJSON = "json"
+)
+
+var (
+	_ Field                 = (*EncryptedField)(nil)
+	_ GetterFinder          = (*EncryptedField)(nil)
+	_ SetterFinder          = (*EncryptedField)(nil)
+	_ DriverValuer          = (*EncryptedField)(nil)
+	_ MaxBodySizeCalculator = (*EncryptedField)(nil)
+)
+
+// ErrMissingEncryptionKey is returned when an encrypted field value
+// has to be encrypted or decrypted but the app encryption env variable is not set.
+var ErrMissingEncryptionKey = errors.New("missing or empty app encryption env variable")
+
+// EncryptedField defines "encrypted" type field for storing text or
+// JSON values encrypted at rest with AES-256-GCM.
+//
+// The encryption key is derived from the secret stored in the env variable
+// with the name of [BaseAppConfig.EncryptionEnv]. The values are decrypted
+// transparently when accessed, for example:
+//
+//	record.Set("token", "abc")
+//	record.GetString("token") // "abc"
+//
+// Encrypted fields can't be filtered or sorted. If BlindIndex is enabled,
+// a keyed hash of the value is stored together with the encrypted value
+// allowing "=" and "!=" comparisons against a single plain value.
+//
+// The respective zero record field value is empty string (or the zero
+// [types.JSONRaw] for the "json" format).
+//
+// The following additional getter keys are available:
+//
+//   - "fieldName:encrypted" - returns the raw stored (aka. encrypted) field value. For example:
+//     record.GetString("token:encrypted")
+type EncryptedField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Format specifies the format of the plain field value.
+	//
+	// Supported values are "text" and "json". If empty, fallback to "text".
+	Format string `form:"format" json:"format"`
+
+	// MaxSize specifies the maximum size of the plain field value (in bytes).
+	//
+	// If zero, a default limit of 64KB is applied.
+	MaxSize int64 `form:"maxSize" json:"maxSize"`
+
+	// BlindIndex enables storing a keyed hash of the plain value
+	// allowing equality filters on the field.
+	//
+	// Note that existing values are not reindexed automatically on change.
+	// To update them you could run the "encryption rotate" command with the same key.
+	BlindIndex bool `form:"blindIndex" json:"blindIndex"`
+
+	// Required will require the field value to be non-empty.
+	Required bool `form:"required" json:"required"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *EncryptedField) Type() string {
+	return FieldTypeEncrypted
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *EncryptedField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *EncryptedField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *EncryptedField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *EncryptedField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *EncryptedField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *EncryptedField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *EncryptedField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *EncryptedField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *EncryptedField) ColumnType(app App) string {
+	return "TEXT DEFAULT '' NOT NULL"
+}
+
+// IsJSON reports whether the field plain value is in JSON format.
+func (f *EncryptedField) IsJSON() bool {
+	return f.Format == EncryptedFormatJSON
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// The raw value is expected to be the stored encrypted field value.
+func (f *EncryptedField) PrepareValue(record *Record, raw any) (any, error) {
+	return &encryptedFieldValue{stored: cast.ToString(raw)}, nil
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *EncryptedField) DriverValue(record *Record) (driver.Value, error) {
+	fv := f.getEncryptedValue(record)
+
+	// the stored value is returned as it is so that
+	// values encrypted with an unknown key are not lost
+	if !fv.changed {
+		return fv.stored, nil
+	}
+
+	plain := f.plainBytes(fv.plain)
+	if len(plain) == 0 {
+		return "", nil
+	}
+
+	keys, err := encryptedFieldKeysFromEnv(fv.env)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := keys.encrypt(plain, f.BlindIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	fv.stored = stored
+	fv.changed = false
+
+	return stored, nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *EncryptedField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	fv, ok := record.GetRaw(f.Name).(*encryptedFieldValue)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	// existing value
+	if !fv.changed {
+		if f.Required && fv.stored == "" {
+			return validation.ErrRequired
+		}
+		return nil
+	}
+
+	plain := f.plainBytes(fv.plain)
+
+	maxSize := f.CalculateMaxBodySize()
+	if int64(len(plain)) > maxSize {
+		return validation.NewError(
+			"validation_encrypted_size_limit",
+			"The maximum allowed value size is {{.maxSize}} bytes",
+		).SetParams(map[string]any{"maxSize": maxSize})
+	}
+
+	if f.IsJSON() && len(plain) > 0 && is.JSON.Validate(plain) != nil {
+		return validation.NewError("validation_invalid_json", "Must be a valid json value")
+	}
+
+	if len(plain) == 0 {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil
+	}
+
+	if f.Required && f.IsJSON() && slices.Contains(emptyJSONValues, strings.TrimSpace(string(plain))) {
+		return validation.ErrRequired
+	}
+
+	fv.env = app.EncryptionEnv()
+
+	if _, err := encryptedFieldKeysFromEnv(fv.env); err != nil {
+		return validation.NewError("validation_missing_encryption_key", "Missing encryption key.")
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *EncryptedField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Format, validation.In(EncryptedFormatText, EncryptedFormatJSON)),
+		validation.Field(&f.MaxSize, validation.Min(0), validation.Max(maxSafeJSONInt)),
+	)
+}
+
+// CalculateMaxBodySize implements the [MaxBodySizeCalculator] interface.
+func (f *EncryptedField) CalculateMaxBodySize() int64 {
+	if f.MaxSize <= 0 {
+		return DefaultEncryptedFieldMaxSize
+	}
+
+	return f.MaxSize
+}
+
+// FindGetter implements the [GetterFinder] interface.
+func (f *EncryptedField) FindGetter(key string) GetterFunc {
+	switch key {
+	case f.Name:
+		return func(record *Record) any {
+			return f.getPlainValue(f.getEncryptedValue(record))
+		}
+	case f.Name + ":encrypted":
+		return func(record *Record) any {
+			return f.getEncryptedValue(record).stored
+		}
+	default:
+		return nil
+	}
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *EncryptedField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		return f.setValue
+	default:
+		return nil
+	}
+}
+
+func (f *EncryptedField) setValue(record *Record, raw any) {
+	// copied as it is from another record
+	if fv, ok := raw.(*encryptedFieldValue); ok {
+		record.SetRaw(f.Name, fv)
+		return
+	}
+
+	record.SetRaw(f.Name, &encryptedFieldValue{
+		plain:   f.normalizePlainValue(raw),
+		changed: true,
+		decoded: true,
+	})
+}
+
+// normalizePlainValue normalizes the raw plain value to string
+// or to [types.JSONRaw] (for the "json" format).
+func (f *EncryptedField) normalizePlainValue(raw any) any {
+	if f.IsJSON() {
+		// same normalizations as for the regular json field
+		v, _ := (&JSONField{}).PrepareValue(nil, raw)
+		return v
+	}
+
+	return cast.ToString(raw)
+}
+
+func (f *EncryptedField) getEncryptedValue(record *Record) *encryptedFieldValue {
+	switch v := record.GetRaw(f.Name).(type) {
+	case *encryptedFieldValue:
+		return v
+	case string:
+		return &encryptedFieldValue{stored: v}
+	}
+
+	return &encryptedFieldValue{}
+}
+
+// getPlainValue returns the decrypted field value.
+//
+// It returns the field zero value if the stored value can't be decrypted.
+func (f *EncryptedField) getPlainValue(fv *encryptedFieldValue) any {
+	if !fv.decoded {
+		fv.decoded = true
+
+		if fv.stored != "" {
+			if keys, err := encryptedFieldKeysFromEnv(fv.env); err == nil {
+				plain, err := keys.decrypt(fv.stored)
+				if err == nil {
+					fv.plain = f.fromPlainBytes(plain)
+				}
+			}
+		}
+	}
+
+	if fv.plain == nil {
+		return f.fromPlainBytes(nil)
+	}
+
+	return fv.plain
+}
+
+// plainBytes returns the normalized byte representation of the plain field value.
+//
+// JSON values are compacted so that the blind index hashes of
+// equivalent values with different whitespaces are the same.
+func (f *EncryptedField) plainBytes(plain any) []byte {
+	var raw []byte
+
+	switch v := plain.(type) {
+	case types.JSONRaw:
+		raw = v
+	case []byte:
+		raw = v
+	default:
+		raw = []byte(cast.ToString(v))
+	}
+
+	if f.IsJSON() {
+		if len(raw) == 0 || string(raw) == "null" {
+			return nil
+		}
+
+		buf := new(bytes.Buffer)
+		if err := json.Compact(buf, raw); err == nil {
+			return buf.Bytes()
+		}
+	}
+
+	return raw
+}
+
+func (f *EncryptedField) fromPlainBytes(plain []byte) any {
+	if f.IsJSON() {
+		raw, _ := types.ParseJSONRaw(plain)
+		return raw
+	}
+
+	return string(plain)
+}
+
+// -------------------------------------------------------------------
+
+type encryptedFieldValue struct {
+	plain   any
+	stored  string
+	decoded bool
+	changed bool
+
+	// env is the name of the encryption env variable of the app
+	// the value was loaded with or saved through (see [App.EncryptionEnv]).
+	env string
+}
+
+// -------------------------------------------------------------------
+
+const encryptedFieldBlindIndexLength = 64
+
+// BindEncryptedFields binds the encrypted field values of the provided
+// record to the encryption key of the specified app (see [App.EncryptionEnv]).
+//
+// The records loaded with the app record queries and the saved ones
+// are bound automatically, so usually it is needed only for records
+// constructed manually from raw stored values.
+func BindEncryptedFields(app App, record *Record) {
+	env := app.EncryptionEnv()
+
+	for _, field := range record.Collection().Fields {
+		if _, ok := field.(*EncryptedField); !ok {
+			continue
+		}
+
+		if fv, ok := record.GetRaw(field.GetName()).(*encryptedFieldValue); ok {
+			fv.env = env
+		}
+	}
+}
+
+// encryptedFieldKeysFromEnv returns the encrypted fields keys
+// derived from the secret of the env variable with the provided name.
+func encryptedFieldKeysFromEnv(name string) (*encryptedFieldKeys, error) {
+	if name == "" {
+		return nil, ErrMissingEncryptionKey
+	}
+
+	return newEncryptedFieldKeys(os.Getenv(name))
+}
+
+type encryptedFieldKeys struct {
+	cipher     string
+	blindIndex string
+}
+
+func newEncryptedFieldKeys(secret string) (*encryptedFieldKeys, error) {
+	if secret == "" {
+		return nil, ErrMissingEncryptionKey
+	}
+
+	// derive separate keys so that the blind index hashes
+	// can't be used to recover the AES key and vice versa
+	cipherKey := hmac.New(sha256.New, []byte(secret))
+	cipherKey.Write([]byte("pb_encrypted_field_cipher"))
+
+	return &encryptedFieldKeys{
+		cipher:     string(cipherKey.Sum(nil)),
+		blindIndex: security.HS256("pb_encrypted_field_blind_index", secret),
+	}, nil
+}
+
+// hash returns the blind index hash of the plain value.
+func (keys *encryptedFieldKeys) hash(plain []byte) string {
+	return security.HS256(string(plain), keys.blindIndex)
+}
+
+// encrypt encrypts the plain value and returns its storage representation
+// ("hash:cipher" when withBlindIndex is set, otherwise just "cipher").
+func (keys *encryptedFieldKeys) encrypt(plain []byte, withBlindIndex bool) (string, error) {
+	cipher, err := security.Encrypt(plain, keys.cipher)
+	if err != nil {
+		return "", err
+	}
+
+	if withBlindIndex {
+		return keys.hash(plain) + ":" + cipher, nil
+	}
+
+	return cipher, nil
+}
+
+// decrypt decrypts the stored encrypted value (with or without blind index prefix).
+func (keys *encryptedFieldKeys) decrypt(stored string) ([]byte, error) {
+	// the base64 std encoding doesn't contain ":"
+	if i := strings.IndexByte(stored, ':'); i >= 0 {
+		stored = stored[i+1:]
+	}
+
+	return security.Decrypt(stored, keys.cipher)
+}
@@ -0,0 +1,507 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// note: 32 bytes so that it could be used also for the settings encryption
+const testEncryptionSecret = "12345678901234567890123456789012"
+
+func createEncryptedTestCollection(t testing.TB, app core.App) *core.Collection {
+	collection := core.NewBaseCollection("encrypted_test")
+	collection.ListRule = types.Pointer("")
+	collection.Fields.Add(
+		&core.EncryptedField{Name: "token", BlindIndex: true},
+		&core.EncryptedField{Name: "note"},
+		&core.EncryptedField{Name: "data", Format: core.EncryptedFormatJSON},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func encryptedTestRawValue(t testing.TB, app core.App, collection *core.Collection, id string, column string) string {
+	var raw string
+
+	err := app.DB().Select(column).From(collection.Name).AndWhere(dbx.HashExp{"id": id}).Row(&raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return raw
+}
+
+func TestEncryptedFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeEncrypted)
+}
+
+func TestEncryptedFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.EncryptedField{}
+
+	expected := "TEXT DEFAULT '' NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestEncryptedFieldPersistence(t *testing.T) {
+	t.Setenv("pb_test_env", testEncryptionSecret)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createEncryptedTestCollection(t, app)
+
+	record := core.NewRecord(collection)
+	record.Set("token", "abc")
+	record.Set("note", "secret note")
+	record.Set("data", map[string]any{"a": 1})
+
+	// plain value before save
+	if v := record.GetString("token"); v != "abc" {
+		t.Fatalf("Expected the plain token before save, got %q", v)
+	}
+
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	rawToken := encryptedTestRawValue(t, app, collection, record.Id, "token")
+	hash, cipher, ok := strings.Cut(rawToken, ":")
+	if !ok || len(hash) != 64 || cipher == "" || strings.Contains(rawToken, "abc") {
+		t.Fatalf("Expected blind index prefixed encrypted token, got %q", rawToken)
+	}
+
+	rawNote := encryptedTestRawValue(t, app, collection, record.Id, "note")
+	if rawNote == "" || strings.Contains(rawNote, ":") || strings.Contains(rawNote, "secret") {
+		t.Fatalf("Expected encrypted note without blind index, got %q", rawNote)
+	}
+
+	if v := record.GetString("token:encrypted"); v != rawToken {
+		t.Fatalf("Expected the token:encrypted getter to return %q, got %q", rawToken, v)
+	}
+
+	fresh, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := fresh.GetString("token"); v != "abc" {
+		t.Fatalf("Expected decrypted token %q, got %q", "abc", v)
+	}
+
+	if v := fresh.GetString("note"); v != "secret note" {
+		t.Fatalf("Expected decrypted note %q, got %q", "secret note", v)
+	}
+
+	exported, err := json.Marshal(fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(exported), `"data":{"a":1}`) || !strings.Contains(string(exported), `"token":"abc"`) {
+		t.Fatalf("Expected decrypted values in the serialized record, got %s", exported)
+	}
+
+	// resave without changes should preserve the stored value
+	if err := app.Save(fresh); err != nil {
+		t.Fatal(err)
+	}
+	if v := encryptedTestRawValue(t, app, collection, record.Id, "token"); v != rawToken {
+		t.Fatalf("Expected unchanged stored token %q, got %q", rawToken, v)
+	}
+
+	// clear
+	fresh.Set("token", "")
+	if err := app.Save(fresh); err != nil {
+		t.Fatal(err)
+	}
+	if v := encryptedTestRawValue(t, app, collection, record.Id, "token"); v != "" {
+		t.Fatalf("Expected empty stored token, got %q", v)
+	}
+
+	// wrong key
+	t.Setenv("pb_test_env", strings.Repeat("a", 32))
+	fresh, err = app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := fresh.GetString("note"); v != "" {
+		t.Fatalf("Expected empty note with wrong key, got %q", v)
+	}
+
+	// missing key
+	t.Setenv("pb_test_env", "")
+	record = core.NewRecord(collection)
+	record.Set("note", "test")
+	tests.TestValidationErrors(t, app.Validate(record), []string{"note"})
+}
+
+func TestEncryptedFieldPerAppEncryptionEnv(t *testing.T) {
+	t.Setenv("pb_test_env", testEncryptionSecret)
+	t.Setenv("pb_test_other_env", "")
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createEncryptedTestCollection(t, app)
+
+	// initializing another app with different encryption env
+	// shouldn't affect the encrypted fields of the existing one
+	core.NewBaseApp(core.BaseAppConfig{
+		DataDir:       t.TempDir(),
+		EncryptionEnv: "pb_test_other_env",
+	})
+
+	record := core.NewRecord(collection)
+	record.Set("note", "test")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err := app.FindRecordById(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := fresh.GetString("note"); v != "test" {
+		t.Fatalf("Expected decrypted note %q, got %q", "test", v)
+	}
+
+	// records constructed manually from the stored values
+	// are decrypted only after binding them to an app
+	raw := encryptedTestRawValue(t, app, collection, record.Id, "note")
+
+	manual := core.NewRecord(collection)
+	prepared, err := collection.Fields.GetByName("note").PrepareValue(manual, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manual.SetRaw("note", prepared)
+	core.BindEncryptedFields(app, manual)
+	if v := manual.GetString("note"); v != "test" {
+		t.Fatalf("Expected the bound note value to be %q, got %q", "test", v)
+	}
+}
+
+func TestEncryptedFieldValidateValue(t *testing.T) {
+	t.Setenv("pb_test_env", testEncryptionSecret)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.EncryptedField
+		record      func() *core.Record
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.EncryptedField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", 123)
+				return record
+			},
+			true,
+		},
+		{
+			"zero value (non-required)",
+			&core.EncryptedField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "")
+				return record
+			},
+			false,
+		},
+		{
+			"zero value (required)",
+			&core.EncryptedField{Name: "test", Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "")
+				return record
+			},
+			true,
+		},
+		{
+			"existing stored value (required)",
+			&core.EncryptedField{Name: "test", Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				stored, _ := (&core.EncryptedField{}).PrepareValue(record, "abc")
+				record.SetRaw("test", stored)
+				return record
+			},
+			false,
+		},
+		{
+			"> default MaxSize",
+			&core.EncryptedField{Name: "test"},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", strings.Repeat("a", 1+int(core.DefaultEncryptedFieldMaxSize)))
+				return record
+			},
+			true,
+		},
+		{
+			"> MaxSize",
+			&core.EncryptedField{Name: "test", MaxSize: 2},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "abc")
+				return record
+			},
+			true,
+		},
+		{
+			"<= MaxSize",
+			&core.EncryptedField{Name: "test", MaxSize: 3},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "abc")
+				return record
+			},
+			false,
+		},
+		{
+			"empty json value (required)",
+			&core.EncryptedField{Name: "test", Format: core.EncryptedFormatJSON, Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", "[]")
+				return record
+			},
+			true,
+		},
+		{
+			"invalid json value",
+			&core.EncryptedField{Name: "test", Format: core.EncryptedFormatJSON},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", []byte("{a"))
+				return record
+			},
+			true,
+		},
+		{
+			"valid json value",
+			&core.EncryptedField{Name: "test", Format: core.EncryptedFormatJSON, Required: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("test", `{"a":1}`)
+				return record
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection.Fields = core.NewFieldsList(s.field)
+
+			err := s.field.ValidateValue(context.Background(), app, s.record())
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestEncryptedFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeEncrypted)
+	testDefaultFieldNameValidation(t, core.FieldTypeEncrypted)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name         string
+		field        *core.EncryptedField
+		expectErrors []string
+	}{
+		{
+			"empty format",
+			&core.EncryptedField{Id: "test", Name: "test"},
+			[]string{},
+		},
+		{
+			"invalid format",
+			&core.EncryptedField{Id: "test", Name: "test", Format: "xml"},
+			[]string{"format"},
+		},
+		{
+			"negative MaxSize",
+			&core.EncryptedField{Id: "test", Name: "test", Format: core.EncryptedFormatJSON, MaxSize: -1},
+			[]string{"maxSize"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field.ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestEncryptedFieldFilters(t *testing.T) {
+	t.Setenv("pb_test_env", testEncryptionSecret)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createEncryptedTestCollection(t, app)
+
+	r1 := saveSoftDeleteTestRecord(t, app, collection, map[string]any{"token": "abc", "note": "a"})
+	r2 := saveSoftDeleteTestRecord(t, app, collection, map[string]any{"token": "def", "note": "b"})
+	r3 := saveSoftDeleteTestRecord(t, app, collection, map[string]any{"note": "c"})
+
+	scenarios := []struct {
+		filter      string
+		params      dbx.Params
+		expectError bool
+		expectIds   []string
+	}{
+		{"token = 'abc'", nil, false, []string{r1.Id}},
+		{"'def' = token", nil, false, []string{r2.Id}},
+		{"token != 'abc'", nil, false, []string{r2.Id, r3.Id}},
+		{"token = ''", nil, false, []string{r3.Id}},
+		{"token != ''", nil, false, []string{r1.Id, r2.Id}},
+		{"token = {:token}", dbx.Params{"token": "def"}, false, []string{r2.Id}},
+		{"token = 'missing'", nil, false, []string{}},
+		{"token ~ 'abc'", nil, true, nil},
+		{"token > 'abc'", nil, true, nil},
+		{"token ?= 'abc'", nil, true, nil},
+		{"token = note", nil, true, nil},
+		{"token:lower = 'abc'", nil, true, nil},
+		{"note = 'a'", nil, true, nil},
+		{"data = '1'", nil, true, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.filter, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(collection, s.filter, "", 0, 0, s.params)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if len(records) != len(s.expectIds) {
+				t.Fatalf("Expected %d records, got %d", len(s.expectIds), len(records))
+			}
+
+			for _, id := range s.expectIds {
+				var found bool
+				for _, r := range records {
+					if r.Id == id {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Fatalf("Missing expected record %q", id)
+				}
+			}
+		})
+	}
+
+	// sorting is not allowed
+	_, err := app.FindRecordsByFilter(collection, "", "token", 0, 0)
+	if err == nil {
+		t.Fatal("Expected sort error")
+	}
+}
+
+func TestRotateEncryptionKey(t *testing.T) {
+	t.Setenv("pb_test_env", testEncryptionSecret)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createEncryptedTestCollection(t, app)
+
+	record := saveSoftDeleteTestRecord(t, app, collection, map[string]any{
+		"token": "abc",
+		"note":  "test",
+		"data":  `{"a":1}`,
+	})
+	oldRawToken := encryptedTestRawValue(t, app, collection, record.Id, "token")
+
+	if err := app.RotateEncryptionKey(""); err == nil {
+		t.Fatal("Expected error for empty new secret")
+	}
+
+	newSecret := strings.Repeat("b", 32)
+
+	if err := app.RotateEncryptionKey(newSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := os.Getenv("pb_test_env"); v != newSecret {
+		t.Fatalf("Expected the env variable to be updated to %q, got %q", newSecret, v)
+	}
+
+	if v := encryptedTestRawValue(t, app, collection, record.Id, "token"); v == oldRawToken || v == "" {
+		t.Fatalf("Expected the token to be re-encrypted, got %q", v)
+	}
+
+	// the settings should be also re-encrypted
+	if err := app.ReloadSettings(); err != nil {
+		t.Fatalf("Failed to reload the settings with the new key: %v", err)
+	}
+
+	records, err := app.FindRecordsByFilter(collection, "token = 'abc'", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(records))
+	}
+
+	if v := records[0].GetString("note"); v != "test" {
+		t.Fatalf("Expected note %q, got %q", "test", v)
+	}
+
+	if v := records[0].GetString("data"); v != `{"a":1}` {
+		t.Fatalf("Expected data %q, got %q", `{"a":1}`, v)
+	}
+
+	// values that can't be decrypted with the current key should fail the rotation
+	t.Setenv("pb_test_env", testEncryptionSecret)
+	if err := app.RotateEncryptionKey(strings.Repeat("c", 32)); err == nil {
+		t.Fatal("Expected rotation error")
+	}
+	if v := os.Getenv("pb_test_env"); v != testEncryptionSecret {
+		t.Fatalf("Expected the env variable to be restored, got %q", v)
+	}
+}
@@ -17,7 +17,9 @@ func init() {
 const FieldTypeGeoPoint = "geoPoint"
 
 var (
-	_ Field = (*GeoPointField)(nil)
+	_ Field             = (*GeoPointField)(nil)
+	_ RecordInterceptor = (*GeoPointField)(nil)
+	_ SpatialIndexer    = (*GeoPointField)(nil)
 )
 
 // GeoPointField defines "geoPoint" type field for storing latitude and longitude GPS coordinates.
@@ -31,6 +33,13 @@ var (
 //	record.Set("location", types.GeoPoint{Lat: 123, Lon: 456})
 //	record.Set("location", map[string]any{"lat":123, "lon":456})
 //	record.Set("location", []byte(`{"lat":123, "lon":456}`)
+//
+// When SpatialIndex is enabled, the field values are also stored in a
+// R*Tree index table that is used to prefilter the geoWithinBox() and
+// geoDistance() filter expressions, eg.:
+//
+//	geoWithinBox(location, 23.2, 42.6, 23.4, 42.8) = true
+//	geoDistance(location.lon, location.lat, 23.32, 42.69) < 25
 type GeoPointField struct {
 	// Name (required) is the unique name of the field.
 	Name string `form:"name" json:"name"`
@@ -54,6 +63,10 @@ type GeoPointField struct {
 
 	// Required will require the field coordinates to be non-zero (aka. not "Null Island").
 	Required bool `form:"required" json:"required"`
+
+	// SpatialIndex enables the R*Tree index of the field values
+	// to speed up the bounding box and distance filters.
+	SpatialIndex bool `form:"spatialIndex" json:"spatialIndex"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -146,3 +159,32 @@ func (f *GeoPointField) ValidateSettings(ctx context.Context, app App, collectio
 		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
 	)
 }
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *GeoPointField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	return recordRTreeTable.intercept(app, record, f, actionName, actionFunc)
+}
+
+// IsSpatialIndexed implements the [SpatialIndexer] interface.
+func (f *GeoPointField) IsSpatialIndexed() bool {
+	return f.SpatialIndex
+}
+
+// SpatialBBox implements the [SpatialIndexer] interface.
+//
+// Note that the zero "Null Island" points are also indexed so that the
+// index prefilter doesn't change the result of the filter expressions.
+func (f *GeoPointField) SpatialBBox(record *Record) (types.GeoBBox, bool) {
+	point, ok := record.GetRaw(f.Name).(types.GeoPoint)
+	if !ok {
+		return types.GeoBBox{}, false
+	}
+
+	return types.GeoBBox{MinLon: point.Lon, MinLat: point.Lat, MaxLon: point.Lon, MaxLat: point.Lat}, true
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	Fields[FieldTypeGeoShape] = func() Field {
+		return &GeoShapeField{}
+	}
+}
+
+const FieldTypeGeoShape = "geoShape"
+
+var (
+	_ Field             = (*GeoShapeField)(nil)
+	_ RecordInterceptor = (*GeoShapeField)(nil)
+	_ SpatialIndexer    = (*GeoShapeField)(nil)
+)
+
+// GeoShapeField defines "geoShape" type field for storing GeoJSON
+// LineString, Polygon and MultiPolygon geometries (eg. delivery zones, routes).
+//
+// You can set the record field value as [types.GeoShape], map or serialized GeoJSON geometry object.
+// The stored value is always converted to [types.GeoShape] (with its bounding box cached in the "bbox" member).
+// Nil, empty map, empty bytes slice, etc. results in zero [types.GeoShape].
+//
+// Examples of updating a record's GeoShapeField value programmatically:
+//
+//	record.Set("zone", types.NewGeoPolygon([]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 10, Lat: 0}, {Lon: 10, Lat: 10}, {Lon: 0, Lat: 0}}))
+//	record.Set("zone", map[string]any{"type": "LineString", "coordinates": [][]float64{{0, 0}, {10, 10}}})
+//	record.Set("zone", []byte(`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]]}`))
+//
+// The field values could be queried with the geoContains() and geoIntersects() filter functions, eg.:
+//
+//	geoContains(zone, @request.body.location) = true
+//	geoContains(zone, 23.32, 42.69) = true
+//	geoIntersects(zone, @request.body.route) = true
+type GeoShapeField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Required will require the field value to be non-zero shape.
+	Required bool `form:"required" json:"required"`
+
+	// SpatialIndex enables the R*Tree index of the field values bounding box
+	// to speed up the geoContains() and geoIntersects() filters.
+	SpatialIndex bool `form:"spatialIndex" json:"spatialIndex"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *GeoShapeField) Type() string {
+	return FieldTypeGeoShape
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *GeoShapeField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *GeoShapeField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *GeoShapeField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *GeoShapeField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *GeoShapeField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *GeoShapeField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *GeoShapeField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *GeoShapeField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *GeoShapeField) ColumnType(app App) string {
+	return "JSON DEFAULT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *GeoShapeField) PrepareValue(record *Record, raw any) (any, error) {
+	shape := types.GeoShape{}
+	err := shape.Scan(raw)
+	return shape, err
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *GeoShapeField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	val, ok := record.GetRaw(f.Name).(types.GeoShape)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	if val.IsZero() {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil
+	}
+
+	if err := val.Validate(); err != nil {
+		return validation.NewError("validation_invalid_geo_shape", "Invalid GeoJSON shape - "+err.Error()+".")
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *GeoShapeField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+	)
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *GeoShapeField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	return recordRTreeTable.intercept(app, record, f, actionName, actionFunc)
+}
+
+// IsSpatialIndexed implements the [SpatialIndexer] interface.
+func (f *GeoShapeField) IsSpatialIndexed() bool {
+	return f.SpatialIndex
+}
+
+// SpatialBBox implements the [SpatialIndexer] interface.
+//
+// The zero shapes are not indexed.
+func (f *GeoShapeField) SpatialBBox(record *Record) (types.GeoBBox, bool) {
+	shape, ok := record.GetRaw(f.Name).(types.GeoShape)
+	if !ok || shape.IsZero() {
+		return types.GeoBBox{}, false
+	}
+
+	return shape.BBox(), true
+}
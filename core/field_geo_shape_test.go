@@ -0,0 +1,157 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestGeoShapeFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeGeoShape)
+}
+
+func TestGeoShapeFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.GeoShapeField{}
+
+	expected := "JSON DEFAULT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestGeoShapeFieldPrepareValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.GeoShapeField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	line := types.NewGeoLineString(types.GeoPoint{Lon: 1, Lat: 2}, types.GeoPoint{Lon: 3, Lat: 4})
+
+	scenarios := []struct {
+		raw      any
+		expected string
+	}{
+		{nil, `null`},
+		{"", `null`},
+		{[]byte{}, `null`},
+		{map[string]any{}, `null`},
+		{line, `{"type":"LineString","coordinates":[[1,2],[3,4]],"bbox":[1,2,3,4]}`},
+		{&line, `{"type":"LineString","coordinates":[[1,2],[3,4]],"bbox":[1,2,3,4]}`},
+		{[]byte(`{"type":"LineString","coordinates":[[1,2],[3,4]]}`), `{"type":"LineString","coordinates":[[1,2],[3,4]],"bbox":[1,2,3,4]}`},
+		{
+			map[string]any{"type": "Polygon", "coordinates": [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+			`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"bbox":[0,0,1,1]}`,
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			raw, err := json.Marshal(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rawStr := string(raw)
+
+			if rawStr != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, rawStr)
+			}
+		})
+	}
+}
+
+func TestGeoShapeFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.GeoShapeField
+		raw         any
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.GeoShapeField{Name: "test"},
+			123,
+			true,
+		},
+		{
+			"zero field value (non-required)",
+			&core.GeoShapeField{Name: "test"},
+			types.GeoShape{},
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.GeoShapeField{Name: "test", Required: true},
+			types.GeoShape{},
+			true,
+		},
+		{
+			"non-zero field value (required)",
+			&core.GeoShapeField{Name: "test", Required: true},
+			types.NewGeoLineString(types.GeoPoint{Lon: 1, Lat: 2}, types.GeoPoint{Lon: 3, Lat: 4}),
+			false,
+		},
+		{
+			"unsupported shape type",
+			&core.GeoShapeField{Name: "test"},
+			types.GeoShape{Type: "Point"},
+			true,
+		},
+		{
+			"unclosed polygon",
+			&core.GeoShapeField{Name: "test"},
+			types.NewGeoPolygon([]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 1, Lat: 0}, {Lon: 1, Lat: 1}, {Lon: 0, Lat: 1}}),
+			true,
+		},
+		{
+			"out of range position",
+			&core.GeoShapeField{Name: "test"},
+			types.NewGeoLineString(types.GeoPoint{Lon: 1, Lat: 2}, types.GeoPoint{Lon: 180.1, Lat: 4}),
+			true,
+		},
+		{
+			"valid polygon",
+			&core.GeoShapeField{Name: "test"},
+			types.NewGeoPolygon([]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 1, Lat: 0}, {Lon: 1, Lat: 1}, {Lon: 0, Lat: 0}}),
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record := core.NewRecord(collection)
+			record.SetRaw("test", s.raw)
+
+			err := s.field.ValidateValue(context.Background(), app, record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestGeoShapeFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeGeoShape)
+	testDefaultFieldNameValidation(t, core.FieldTypeGeoShape)
+}
@@ -218,12 +218,18 @@ func (f *RelationField) ValidateValue(ctx context.Context, app App, record *Reco
 		return validation.NewError("validation_missing_rel_collection", "Relation connection is missing or cannot be accessed")
 	}
 
-	var total int
-	_ = app.ConcurrentDB().
+	query := app.ConcurrentDB().
 		Select("count(*)").
 		From(relCollection.Name).
-		AndWhere(dbx.In("id", list.ToInterfaceSlice(ids)...)).
-		Row(&total)
+		AndWhere(dbx.In("id", list.ToInterfaceSlice(ids)...))
+
+	// soft deleted records are not valid relation targets
+	if relCollection.SoftDelete && !relCollection.IsView() {
+		query.AndWhere(dbx.HashExp{FieldNameDeleted: ""})
+	}
+
+	var total int
+	_ = query.Row(&total)
 	if total != len(ids) {
 		return validation.NewError("validation_missing_rel_records", "Failed to find all relation records with the provided ids")
 	}
@@ -29,6 +29,7 @@ var (
 	_ Field             = (*TextField)(nil)
 	_ SetterFinder      = (*TextField)(nil)
 	_ RecordInterceptor = (*TextField)(nil)
+	_ FullTextSearcher  = (*TextField)(nil)
 )
 
 // TextField defines "text" type field for storing any string value.
@@ -91,6 +92,13 @@ type TextField struct {
 	//
 	// A single collection can have only 1 field marked as primary key.
 	PrimaryKey bool `form:"primaryKey" json:"primaryKey"`
+
+	// Searchable maintains a SQLite FTS5 full-text index of the field values
+	// that could be queried with the ftsMatch() filter function and
+	// the @rank sort key (ex. "ftsMatch(title, 'go AND sqlite') = true").
+	//
+	// It is ignored for view collections.
+	Searchable bool `form:"searchable" json:"searchable"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -263,6 +271,7 @@ func (f *TextField) ValidateSettings(ctx context.Context, app App, collection *C
 		validation.Field(&f.Max, validation.Min(f.Min), validation.Max(maxSafeJSONInt)),
 		validation.Field(&f.Pattern, validation.When(f.PrimaryKey, validation.Required), validation.By(validators.IsRegex)),
 		validation.Field(&f.Hidden, validation.When(f.PrimaryKey, validation.Empty)),
+		validation.Field(&f.Searchable, validation.When(f.PrimaryKey, validation.Empty)),
 		validation.Field(&f.Required, validation.When(f.PrimaryKey, validation.Required)),
 		validation.Field(&f.AutogeneratePattern, validation.By(validators.IsRegex), validation.By(f.checkAutogeneratePattern)),
 	)
@@ -335,7 +344,17 @@ func (f *TextField) Intercept(
 		}
 	}
 
-	return actionFunc()
+	return recordFTSTable.intercept(app, record, f, actionName, actionFunc)
+}
+
+// IsSearchable implements the [FullTextSearcher] interface.
+func (f *TextField) IsSearchable() bool {
+	return f.Searchable
+}
+
+// SearchableText implements the [FullTextSearcher] interface.
+func (f *TextField) SearchableText(record *Record) string {
+	return record.GetString(f.Name)
 }
 
 func (f *TextField) hasZeroValue(record *Record) bool {
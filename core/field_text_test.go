@@ -348,6 +348,20 @@ func TestTextFieldValidateSettings(t *testing.T) {
 			},
 			[]string{"hidden"},
 		},
+		{
+			"primaryKey with searchable",
+			func() *core.TextField {
+				return &core.TextField{
+					Id:         "test",
+					Name:       "id",
+					Required:   true,
+					PrimaryKey: true,
+					Searchable: true,
+					Pattern:    `\d+`,
+				}
+			},
+			[]string{"searchable"},
+		},
 		{
 			"primaryKey with name != id",
 			func() *core.TextField {
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	Fields[FieldTypeVector] = func() Field {
+		return &VectorField{}
+	}
+}
+
+const FieldTypeVector = "vector"
+
+// MaxVectorDimensions is the max allowed [VectorField.Dimensions] value.
+const MaxVectorDimensions = 4096
+
+var (
+	_ Field             = (*VectorField)(nil)
+	_ SetterFinder      = (*VectorField)(nil)
+	_ DriverValuer      = (*VectorField)(nil)
+	_ RecordInterceptor = (*VectorField)(nil)
+)
+
+// VectorField defines "vector" type field for storing fixed size
+// float32 embedding vectors (eg. for semantic search).
+//
+// The field value is stored in the db as compact little-endian float32 blob
+// and it is serialized as json array of numbers.
+//
+// You can set the record field value as [types.Vector], slice of numbers,
+// json array string or comma separated list of numbers.
+// Nil, empty string, empty slice, etc. results in zero [types.Vector] (stored as NULL).
+//
+// Examples of updating a record's VectorField value programmatically:
+//
+//	record.Set("embedding", types.Vector{0.1, 0.2, 0.3})
+//	record.Set("embedding", []float64{0.1, 0.2, 0.3})
+//	record.Set("embedding", "[0.1, 0.2, 0.3]")
+//
+// The records could be filtered and sorted by their distance to another
+// vector with the vectorDistance() function, eg.:
+//
+//	sort=vectorDistance(embedding, @request.query.q_vec, 'cosine')
+//	filter=vectorDistance(embedding, @request.query.q_vec, 'l2') < 0.5
+type VectorField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Dimensions (required) specifies the exact number of the vector elements.
+	//
+	// It can't be changed after the field creation.
+	Dimensions int `form:"dimensions" json:"dimensions"`
+
+	// Required will require the field value to be non-zero vector.
+	Required bool `form:"required" json:"required"`
+
+	// Index enables the approximate nearest neighbour (IVF) index of the
+	// field values to speed up the vectorDistance() upper bound filters
+	// (eg. "vectorDistance(embedding, @request.query.q_vec) < 0.3").
+	//
+	// The index trades accuracy for speed, aka. some of the matching
+	// records may be missing from the filtered results.
+	Index bool `form:"index" json:"index"`
+
+	// Probes specifies the number of the nearest index lists that are
+	// scanned when Index is enabled.
+	//
+	// If zero, defaults to 1/4 of the index lists (but no less than 2).
+	Probes int `form:"probes" json:"probes"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *VectorField) Type() string {
+	return FieldTypeVector
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *VectorField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *VectorField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *VectorField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *VectorField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *VectorField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *VectorField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *VectorField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *VectorField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *VectorField) ColumnType(app App) string {
+	return "BLOB DEFAULT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+//
+// Plain string and bytes values are treated as the raw db float32 blob.
+// Malformed blobs result in zero vector.
+func (f *VectorField) PrepareValue(record *Record, raw any) (any, error) {
+	switch v := raw.(type) {
+	case string:
+		val, _ := types.ParseVectorBytes([]byte(v))
+		return val, nil
+	case []byte:
+		val, _ := types.ParseVectorBytes(v)
+		return val, nil
+	}
+
+	val, _ := types.ParseVector(raw)
+	return val, nil
+}
+
+// DriverValue implements the [DriverValuer] interface.
+func (f *VectorField) DriverValue(record *Record) (driver.Value, error) {
+	val, ok := record.GetRaw(f.Name).(types.Vector)
+	if !ok {
+		return nil, fmt.Errorf("invalid %q vector value", f.Name)
+	}
+
+	return val.Value()
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *VectorField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	val, ok := record.GetRaw(f.Name).(types.Vector)
+	if !ok {
+		return validation.NewError("validation_invalid_vector", "Must be an array of numbers.")
+	}
+
+	if len(val) == 0 {
+		if f.Required {
+			return validation.ErrRequired
+		}
+		return nil
+	}
+
+	if len(val) != f.Dimensions {
+		return validation.NewError("validation_invalid_vector_dimensions", "Must have exactly {{.dimensions}} elements.").
+			SetParams(map[string]any{"dimensions": f.Dimensions})
+	}
+
+	if !val.IsFinite() {
+		return validation.NewError("validation_invalid_vector", "Must contain only finite numbers.")
+	}
+
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *VectorField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	var oldDimensions int
+
+	oldCollection, _ := app.FindCollectionByNameOrId(collection.Id)
+	if oldCollection != nil {
+		oldField, ok := oldCollection.Fields.GetById(f.Id).(*VectorField)
+		if ok && oldField != nil {
+			oldDimensions = oldField.Dimensions
+		}
+	}
+
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(
+			&f.Dimensions,
+			validation.Required,
+			validation.Min(1),
+			validation.Max(MaxVectorDimensions),
+			validation.When(oldDimensions > 0, validation.By(validators.Equal(oldDimensions))),
+		),
+		validation.Field(&f.Probes, validation.Min(0), validation.Max(maxVectorIndexLists)),
+	)
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *VectorField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		return f.setValue
+	default:
+		return nil
+	}
+}
+
+func (f *VectorField) setValue(record *Record, raw any) {
+	val, err := types.ParseVector(raw)
+	if err != nil {
+		// store the invalid value as it is so that it can be reported on validation
+		record.SetRaw(f.Name, raw)
+		return
+	}
+
+	record.SetRaw(f.Name, val)
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *VectorField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	return recordVectorIndexTable.intercept(app, record, f, actionName, actionFunc)
+}
@@ -0,0 +1,275 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestVectorFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeVector)
+}
+
+func TestVectorFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.VectorField{}
+
+	expected := "BLOB DEFAULT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestVectorFieldPrepareValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.VectorField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	blob := types.Vector{1, -2.5}.Bytes()
+
+	scenarios := []struct {
+		raw      any
+		expected string
+	}{
+		{nil, "[]"},
+		{"", "[]"},
+		{[]byte{}, "[]"},
+		{blob, "[1,-2.5]"},
+		{string(blob), "[1,-2.5]"},
+		{blob[:3], "[]"}, // malformed blob
+		{types.Vector{0.5}, "[0.5]"},
+		{[]float64{1, 2}, "[1,2]"},
+		{123, "[]"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			vector, ok := v.(types.Vector)
+			if !ok {
+				t.Fatalf("Expected types.Vector instance, got %T", v)
+			}
+
+			if str := vector.String(); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestVectorFieldSetter(t *testing.T) {
+	collection := core.NewBaseCollection("test")
+	collection.Fields.Add(&core.VectorField{Name: "test", Dimensions: 2})
+
+	scenarios := []struct {
+		raw      any
+		expected any
+	}{
+		{nil, "[]"},
+		{"[0.1, 0.2]", "[0.1,0.2]"},
+		{"0.1,0.2", "[0.1,0.2]"},
+		{[]any{1, "2"}, "[1,2]"},
+		{[]string{"1", "2"}, "[1,2]"},
+		{"invalid", "invalid"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			record := core.NewRecord(collection)
+			record.Set("test", s.raw)
+
+			v := record.GetRaw("test")
+			if vector, ok := v.(types.Vector); ok {
+				v = vector.String()
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestVectorFieldDriverValue(t *testing.T) {
+	collection := core.NewBaseCollection("test")
+	f := &core.VectorField{Name: "test", Dimensions: 2}
+	collection.Fields.Add(f)
+
+	record := core.NewRecord(collection)
+
+	v, err := f.DriverValue(record)
+	if err != nil || v != nil {
+		t.Fatalf("Expected nil driver value, got %v (%v)", v, err)
+	}
+
+	record.Set("test", "[1,2]")
+	v, err = f.DriverValue(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str := fmt.Sprintf("%v", v); str != fmt.Sprintf("%v", types.Vector{1, 2}.Bytes()) {
+		t.Fatalf("Expected float32 blob, got %v", v)
+	}
+
+	record.Set("test", "invalid")
+	if _, err := f.DriverValue(record); err == nil {
+		t.Fatal("Expected invalid value error")
+	}
+}
+
+func TestVectorFieldValidateValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+
+	scenarios := []struct {
+		name        string
+		field       *core.VectorField
+		raw         any
+		expectError bool
+	}{
+		{
+			"invalid raw value",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			"invalid",
+			true,
+		},
+		{
+			"zero field value (non-required)",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			types.Vector(nil),
+			false,
+		},
+		{
+			"zero field value (required)",
+			&core.VectorField{Name: "test", Dimensions: 2, Required: true},
+			types.Vector(nil),
+			true,
+		},
+		{
+			"non-zero field value (required)",
+			&core.VectorField{Name: "test", Dimensions: 2, Required: true},
+			types.Vector{1, 2},
+			false,
+		},
+		{
+			"less dimensions",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			types.Vector{1},
+			true,
+		},
+		{
+			"more dimensions",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			types.Vector{1, 2, 3},
+			true,
+		},
+		{
+			"non-finite element",
+			&core.VectorField{Name: "test", Dimensions: 2},
+			types.Vector{1, float32(math.Inf(1))},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record := core.NewRecord(collection)
+			record.SetRaw("test", s.raw)
+
+			err := s.field.ValidateValue(context.Background(), app, record)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestVectorFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeVector)
+	testDefaultFieldNameValidation(t, core.FieldTypeVector)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	existing := core.NewBaseCollection("vector_settings_test")
+	existing.Fields.Add(&core.VectorField{Id: "vec", Name: "vec", Dimensions: 3})
+	if err := app.Save(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		collection   *core.Collection
+		field        *core.VectorField
+		expectErrors []string
+	}{
+		{
+			"zero dimensions",
+			core.NewBaseCollection("test_collection"),
+			&core.VectorField{Id: "test", Name: "test"},
+			[]string{"dimensions"},
+		},
+		{
+			"negative dimensions",
+			core.NewBaseCollection("test_collection"),
+			&core.VectorField{Id: "test", Name: "test", Dimensions: -1},
+			[]string{"dimensions"},
+		},
+		{
+			"too many dimensions",
+			core.NewBaseCollection("test_collection"),
+			&core.VectorField{Id: "test", Name: "test", Dimensions: core.MaxVectorDimensions + 1},
+			[]string{"dimensions"},
+		},
+		{
+			"negative probes",
+			core.NewBaseCollection("test_collection"),
+			&core.VectorField{Id: "test", Name: "test", Dimensions: 3, Probes: -1},
+			[]string{"probes"},
+		},
+		{
+			"valid settings",
+			core.NewBaseCollection("test_collection"),
+			&core.VectorField{Id: "test", Name: "test", Dimensions: core.MaxVectorDimensions, Index: true, Probes: 5},
+			[]string{},
+		},
+		{
+			"changed dimensions of existing field",
+			existing,
+			&core.VectorField{Id: "vec", Name: "vec", Dimensions: 4},
+			[]string{"dimensions"},
+		},
+		{
+			"unchanged dimensions of existing field",
+			existing,
+			&core.VectorField{Id: "vec", Name: "vec", Dimensions: 3, Index: true},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field.ValidateSettings(context.Background(), app, s.collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package core
+
+import "github.com/pocketbase/pocketbase/tools/types"
+
+var (
+	_ Model = (*IdempotencyKey)(nil)
+)
+
+const IdempotencyKeysTableName = "_idempotencyKeys"
+
+// The available IdempotencyKey.Status values.
+const (
+	IdempotencyKeyStatusPending   = "pending"
+	IdempotencyKeyStatusCompleted = "completed"
+)
+
+// IdempotencyKey defines a single stored Idempotency-Key request entry
+// together with its response (once completed).
+type IdempotencyKey struct {
+	BaseModel
+
+	Created         types.DateTime          `db:"created" json:"created"`
+	Updated         types.DateTime          `db:"updated" json:"updated"`
+	Key             string                  `db:"key" json:"key"`
+	Auth            string                  `db:"auth" json:"auth"`
+	Fingerprint     string                  `db:"fingerprint" json:"fingerprint"`
+	Status          string                  `db:"status" json:"status"`
+	ResponseHeaders types.JSONMap[[]string] `db:"responseHeaders" json:"responseHeaders"`
+	ResponseBody    string                  `db:"responseBody" json:"responseBody"`
+	ResponseStatus  int                     `db:"responseStatus" json:"responseStatus"`
+}
+
+func (m *IdempotencyKey) TableName() string {
+	return IdempotencyKeysTableName
+}
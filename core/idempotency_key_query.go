@@ -0,0 +1,61 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// IdempotencyKeyQuery returns a new IdempotencyKey select query.
+func (app *BaseApp) IdempotencyKeyQuery() *dbx.SelectQuery {
+	return app.AuxModelQuery(&IdempotencyKey{})
+}
+
+// FindIdempotencyKey finds a single IdempotencyKey entry by its auth scope and key.
+//
+// auth is the "collectionId/recordId" identifier of the request auth
+// record or empty string for guests.
+func (app *BaseApp) FindIdempotencyKey(auth string, key string) (*IdempotencyKey, error) {
+	model := &IdempotencyKey{}
+
+	err := app.IdempotencyKeyQuery().
+		AndWhere(dbx.HashExp{"auth": auth, "key": key}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// DeleteOldIdempotencyKeys deletes all idempotency keys that are created before createdBefore.
+//
+// For better performance the delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteOldIdempotencyKeys(createdBefore time.Time) error {
+	formattedDate := createdBefore.UTC().Format(types.DefaultDateLayout)
+	expr := dbx.NewExp("[[created]] <= {:date}", dbx.Params{"date": formattedDate})
+
+	_, err := app.auxNonconcurrentDB.Delete((&IdempotencyKey{}).TableName(), expr).Execute()
+
+	return err
+}
+
+func (app *BaseApp) registerIdempotencyKeyHooks() {
+	// run on every hour to cleanup the keys outside of the configured window
+	app.Cron().Add("__pbIdempotencyKeysCleanup__", "0 * * * *", func() {
+		window := app.Settings().Idempotency.Window
+		if window <= 0 {
+			return
+		}
+
+		createdBefore := time.Now().Add(-time.Duration(window) * time.Second)
+
+		if err := app.DeleteOldIdempotencyKeys(createdBefore); err != nil {
+			app.Logger().Warn("Failed to delete old idempotency keys", "error", err)
+		}
+	})
+}
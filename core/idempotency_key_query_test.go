@@ -0,0 +1,117 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func stubIdempotencyKeys(t *testing.T, app core.App) {
+	stubs := []struct {
+		auth    string
+		key     string
+		created string
+	}{
+		{"", "key1", "2022-05-01 10:00:00.000Z"},
+		{"users/test", "key1", "2022-05-02 10:00:00.000Z"},
+	}
+
+	for _, s := range stubs {
+		created, err := types.ParseDateTime(s.created)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := &core.IdempotencyKey{}
+		m.Id = core.GenerateDefaultRandomId()
+		m.Auth = s.auth
+		m.Key = s.key
+		m.Status = core.IdempotencyKeyStatusCompleted
+		m.Created = created
+		m.Updated = created
+
+		if err := app.AuxSaveNoValidate(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubIdempotencyKeys(t, app)
+
+	scenarios := []struct {
+		auth        string
+		key         string
+		expectError bool
+	}{
+		{"", "missing", true},
+		{"", "key1", false},
+		{"users/test", "key1", false},
+		{"users/missing", "key1", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.auth+"_"+s.key, func(t *testing.T) {
+			entry, err := app.FindIdempotencyKey(s.auth, s.key)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if entry != nil && (entry.Auth != s.auth || entry.Key != s.key) {
+				t.Fatalf("Expected entry with auth %q and key %q, got %q and %q", s.auth, s.key, entry.Auth, entry.Key)
+			}
+		})
+	}
+}
+
+func TestDeleteOldIdempotencyKeys(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubIdempotencyKeys(t, app)
+
+	scenarios := []struct {
+		date          string
+		expectedTotal int
+	}{
+		{"2022-01-01 10:00:00.000Z", 2}, // no keys to delete before that time
+		{"2022-05-01 11:00:00.000Z", 1}, // only 1 key should have left
+		{"2022-05-03 11:00:00.000Z", 0}, // no more keys should have left
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.date, func(t *testing.T) {
+			date, dateErr := time.Parse(types.DefaultDateLayout, s.date)
+			if dateErr != nil {
+				t.Fatalf("Date error %v", dateErr)
+			}
+
+			deleteErr := app.DeleteOldIdempotencyKeys(date)
+			if deleteErr != nil {
+				t.Fatalf("Delete error %v", deleteErr)
+			}
+
+			var total int
+			countErr := app.IdempotencyKeyQuery().Select("count(*)").Row(&total)
+			if countErr != nil {
+				t.Errorf("Count error %v", countErr)
+			}
+
+			if total != s.expectedTotal {
+				t.Errorf("Expected %d remaining keys, got %d", s.expectedTotal, total)
+			}
+		})
+	}
+}
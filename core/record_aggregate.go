@@ -0,0 +1,402 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// The supported records aggregate groupBy date bucket modifiers
+// (eg. "created:month").
+const (
+	RecordsAggregateBucketHour  = "hour"
+	RecordsAggregateBucketDay   = "day"
+	RecordsAggregateBucketWeek  = "week"
+	RecordsAggregateBucketMonth = "month"
+	RecordsAggregateBucketYear  = "year"
+)
+
+const (
+	// DefaultRecordsAggregateLimit specifies the default max number of returned aggregate groups.
+	DefaultRecordsAggregateLimit = 1000
+
+	// MaxRecordsAggregateLimit specifies the max allowed number of returned aggregate groups.
+	MaxRecordsAggregateLimit = 10000
+
+	// MaxRecordsAggregateExprs specifies the max allowed number of
+	// groupBy and aggregate expressions (each).
+	MaxRecordsAggregateExprs = 10
+)
+
+// recordsAggregateBuckets maps the groupBy date bucket modifiers
+// to their related SQLite date expression formats.
+//
+// Note: the "week" bucket is represented with the date of its Monday.
+var recordsAggregateBuckets = map[string]string{
+	RecordsAggregateBucketHour:  "strftime('%%Y-%%m-%%d %%H:00:00.000Z', %s)",
+	RecordsAggregateBucketDay:   "strftime('%%Y-%%m-%%d', %s)",
+	RecordsAggregateBucketWeek:  "date(%s, 'weekday 0', '-6 days')",
+	RecordsAggregateBucketMonth: "strftime('%%Y-%%m', %s)",
+	RecordsAggregateBucketYear:  "strftime('%%Y', %s)",
+}
+
+// recordsAggregateFuncs maps the supported aggregate functions to their SQL equivalent.
+var recordsAggregateFuncs = map[string]string{
+	"count": "COUNT",
+	"sum":   "SUM",
+	"avg":   "AVG",
+	"min":   "MIN",
+	"max":   "MAX",
+}
+
+// recordsAggregateExprDB is a connectionless db instance used only
+// for building the resolved field guard expressions.
+var recordsAggregateExprDB = dbx.NewFromDB(nil, "sqlite")
+
+var recordsAggregateFuncRegex = regexp.MustCompile(`^(\w+)\(\s*([^\(\)]*?)\s*\)$`)
+
+// RecordsAggregateOptions defines the options of a records aggregate query.
+type RecordsAggregateOptions struct {
+	// GroupBy is a list of field identifiers to group the records by
+	// (including relation paths and modifiers, eg. "status", "author.name", "tags:each").
+	//
+	// Date and autodate fields values could be bucketed with one of the
+	// ":hour", ":day", ":week", ":month" or ":year" modifiers (eg. "created:month").
+	GroupBy []string
+
+	// Aggregates is a list of aggregate function expressions to
+	// calculate for each group - count(), count(field), sum(field),
+	// avg(field), min(field) and max(field).
+	//
+	// count() returns the number of records in the group.
+	//
+	// If empty, defaults to ["count()"].
+	Aggregates []string
+
+	// RuleFilters is a list of trusted filter expressions that the
+	// aggregated records must satisfy (eg. the collection ListRule).
+	//
+	// Unlike Filters, they are always allowed to reference hidden fields.
+	RuleFilters []string
+
+	// Filters is a list of filter expressions that the aggregated
+	// records must satisfy (eg. the user filter).
+	Filters []string
+
+	// Sort is a list of GroupBy or Aggregates expressions to order
+	// the result by (prefix with "-" for DESC order, eg. "-count()").
+	//
+	// If empty, the result is ordered by the GroupBy expressions.
+	Sort []string
+
+	// Limit specifies the max number of returned groups
+	// (default to [DefaultRecordsAggregateLimit]).
+	Limit int
+
+	// RequestInfo is used to resolve the @request.* filter identifiers.
+	RequestInfo *RequestInfo
+
+	// AllowHiddenFields allows using hidden fields in the expressions.
+	AllowHiddenFields bool
+}
+
+// AggregateRecords groups the collection records matching the options filters
+// and calculates the options aggregates for each group.
+//
+// Each returned item is a map with the GroupBy and Aggregates
+// expressions as keys, eg. {"status": "active", "created:month": "2024-01", "count()": 10}.
+//
+// The identifiers are resolved with [RecordFieldResolver] (aka. the same
+// as in the API rules and filters).
+func (app *BaseApp) AggregateRecords(collection *Collection, options RecordsAggregateOptions) ([]map[string]any, error) {
+	aggregates := options.Aggregates
+	if len(aggregates) == 0 {
+		aggregates = []string{"count()"}
+	}
+
+	if len(options.GroupBy) > MaxRecordsAggregateExprs || len(aggregates) > MaxRecordsAggregateExprs {
+		return nil, fmt.Errorf("max %d groupBy and aggregate expressions are allowed", MaxRecordsAggregateExprs)
+	}
+
+	limit := options.Limit
+	if limit <= 0 {
+		limit = DefaultRecordsAggregateLimit
+	} else if limit > MaxRecordsAggregateLimit {
+		limit = MaxRecordsAggregateLimit
+	}
+
+	tableName := collection.Name
+
+	query := app.ConcurrentDB().Select().From(tableName)
+
+	if collection.SoftDelete && !collection.IsView() {
+		query.AndWhere(dbx.HashExp{tableName + "." + FieldNameDeleted: ""})
+	}
+
+	if collection.HasTTL() && !collection.IsView() {
+		query.AndWhere(notExpiredRecordsExpr(collection, tableName, time.Now()))
+	}
+
+	// apply the filters in a subquery to prevent their relation joins
+	// from duplicating the aggregated rows
+	if err := applyRecordsAggregateFilters(app, collection, query, options); err != nil {
+		return nil, err
+	}
+
+	resolver := NewRecordFieldResolver(app, collection, options.RequestInfo, options.AllowHiddenFields)
+
+	keys := make([]string, 0, len(options.GroupBy)+len(aggregates))
+	aliases := make(map[string]string, cap(keys))
+	selects := make([]string, 0, cap(keys))
+	groupBy := make([]string, 0, len(options.GroupBy))
+
+	addSelect := func(key string, alias string, expr string) error {
+		if _, ok := aliases[key]; ok {
+			return fmt.Errorf("duplicated expression %q", key)
+		}
+		keys = append(keys, key)
+		aliases[key] = alias
+		selects = append(selects, expr+" AS "+alias)
+		return nil
+	}
+
+	for i, g := range options.GroupBy {
+		g = strings.TrimSpace(g)
+
+		expr, err := resolveRecordsAggregateGroupBy(app, collection, resolver, g)
+		if err != nil {
+			return nil, err
+		}
+
+		alias := "g" + strconv.Itoa(i)
+		if err := addSelect(g, alias, expr); err != nil {
+			return nil, err
+		}
+		groupBy = append(groupBy, alias)
+	}
+
+	for i, a := range aggregates {
+		a = strings.TrimSpace(a)
+
+		expr, err := resolveRecordsAggregateFunc(tableName, resolver, a)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := addSelect(a, "a"+strconv.Itoa(i), expr); err != nil {
+			return nil, err
+		}
+	}
+
+	query.Select(selects...).GroupBy(groupBy...).Limit(int64(limit))
+
+	if len(options.Sort) == 0 {
+		for _, alias := range groupBy {
+			query.AndOrderBy(alias + " ASC")
+		}
+	} else {
+		for _, s := range options.Sort {
+			s = strings.TrimSpace(s)
+
+			dir := search.SortAsc
+			if strings.HasPrefix(s, "-") {
+				dir = search.SortDesc
+				s = s[1:]
+			} else {
+				s = strings.TrimPrefix(s, "+")
+			}
+
+			alias, ok := aliases[s]
+			if !ok {
+				return nil, fmt.Errorf("invalid sort expression %q - must be one of the groupBy or aggregate expressions", s)
+			}
+
+			query.AndOrderBy(alias + " " + dir)
+		}
+	}
+
+	if err := resolver.UpdateQuery(query); err != nil {
+		return nil, err
+	}
+
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []map[string]any{}
+
+	for rows.Next() {
+		values := make([]any, len(keys))
+		ptrs := make([]any, len(keys))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		item := make(map[string]any, len(keys))
+		for i, key := range keys {
+			if b, ok := values[i].([]byte); ok {
+				item[key] = string(b)
+			} else {
+				item[key] = values[i]
+			}
+		}
+
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+
+func applyRecordsAggregateFilters(app App, collection *Collection, query *dbx.SelectQuery, options RecordsAggregateOptions) error {
+	resolver := NewRecordFieldResolver(app, collection, options.RequestInfo, true)
+
+	subQuery := app.ConcurrentDB().Select("[[" + collection.Name + ".id]]").From(collection.Name)
+
+	var hasFilters bool
+	applyFilters := func(filters []string) error {
+		for _, f := range filters {
+			if f == "" {
+				continue
+			}
+
+			if len(f) > search.MaxFilterLength {
+				return search.ErrFilterLengthLimit
+			}
+
+			expr, err := search.FilterData(f).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+
+			subQuery.AndWhere(expr)
+			hasFilters = true
+		}
+
+		return nil
+	}
+
+	if err := applyFilters(options.RuleFilters); err != nil {
+		return err
+	}
+
+	resolver.SetAllowHiddenFields(options.AllowHiddenFields)
+
+	if err := applyFilters(options.Filters); err != nil {
+		return err
+	}
+
+	if !hasFilters {
+		return nil
+	}
+
+	if err := resolver.UpdateQuery(subQuery); err != nil {
+		return err
+	}
+
+	built := subQuery.Build()
+
+	query.AndWhere(dbx.NewExp("[["+collection.Name+".id]] IN ("+built.SQL()+")", built.Params()))
+
+	return nil
+}
+
+func resolveRecordsAggregateGroupBy(app App, collection *Collection, resolver *RecordFieldResolver, groupBy string) (string, error) {
+	path := groupBy
+
+	var bucketFormat string
+	if i := strings.LastIndex(groupBy, ":"); i > 0 {
+		if format, ok := recordsAggregateBuckets[groupBy[i+1:]]; ok {
+			path = groupBy[:i]
+			bucketFormat = format
+
+			if !isRecordsAggregateDateField(app, collection, path) {
+				return "", fmt.Errorf("invalid groupBy expression %q - date buckets are supported only for date and autodate fields", groupBy)
+			}
+		}
+	}
+
+	identifier, err := resolveRecordsAggregateIdentifier(resolver, path)
+	if err != nil {
+		return "", err
+	}
+
+	if bucketFormat != "" {
+		return fmt.Sprintf(bucketFormat, identifier), nil
+	}
+
+	return identifier, nil
+}
+
+func resolveRecordsAggregateFunc(tableName string, resolver *RecordFieldResolver, aggregate string) (string, error) {
+	matches := recordsAggregateFuncRegex.FindStringSubmatch(aggregate)
+	if len(matches) != 3 {
+		return "", fmt.Errorf("invalid aggregate expression %q", aggregate)
+	}
+
+	name := strings.ToLower(matches[1])
+	fn, ok := recordsAggregateFuncs[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported aggregate function %q", matches[1])
+	}
+
+	if matches[2] == "" {
+		if name != "count" {
+			return "", fmt.Errorf("aggregate function %q requires a field argument", matches[1])
+		}
+
+		// the number of the group records
+		return "COUNT(DISTINCT [[" + tableName + ".id]])", nil
+	}
+
+	identifier, err := resolveRecordsAggregateIdentifier(resolver, matches[2])
+	if err != nil {
+		return "", err
+	}
+
+	return fn + "(" + identifier + ")", nil
+}
+
+func resolveRecordsAggregateIdentifier(resolver *RecordFieldResolver, field string) (string, error) {
+	result, err := resolver.Resolve(field)
+
+	// invalidate empty fields and non-column identifiers
+	if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.EqualFold(result.Identifier, "null") {
+		return "", errors.Join(fmt.Errorf("invalid or unsupported field %q", field), err)
+	}
+
+	identifier := result.Identifier
+
+	// apply the resolver expression guards (eg. the auth record email visibility check)
+	// so that the values of the rows that don't satisfy them are aggregated as NULL
+	if result.AfterBuild != nil {
+		params := dbx.Params{}
+		guard := result.AfterBuild(dbx.NewExp("1=1")).Build(recordsAggregateExprDB, params)
+		if len(params) > 0 {
+			return "", fmt.Errorf("invalid or unsupported field %q", field)
+		}
+
+		identifier = "(CASE WHEN " + guard + " THEN " + identifier + " END)"
+	}
+
+	return identifier, nil
+}
+
+// isRecordsAggregateDateField reports whether the provided field path
+// (eg. "created", "author.created") points to a date or autodate field.
+func isRecordsAggregateDateField(app App, collection *Collection, path string) bool {
+	field := findRecordFieldByPath(app, collection, path)
+
+	return field != nil && (field.Type() == FieldTypeDate || field.Type() == FieldTypeAutodate)
+}
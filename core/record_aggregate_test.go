@@ -0,0 +1,224 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestAggregateRecords(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name        string
+		collection  string
+		options     core.RecordsAggregateOptions
+		expectError bool
+		expected    string
+	}{
+		{
+			"default count",
+			"demo2",
+			core.RecordsAggregateOptions{},
+			false,
+			`[{"count()":3}]`,
+		},
+		{
+			"invalid aggregate expression",
+			"demo2",
+			core.RecordsAggregateOptions{Aggregates: []string{"count"}},
+			true,
+			"",
+		},
+		{
+			"unsupported aggregate function",
+			"demo2",
+			core.RecordsAggregateOptions{Aggregates: []string{"median(title)"}},
+			true,
+			"",
+		},
+		{
+			"aggregate function without argument",
+			"demo2",
+			core.RecordsAggregateOptions{Aggregates: []string{"sum()"}},
+			true,
+			"",
+		},
+		{
+			"aggregate function with unknown field",
+			"demo2",
+			core.RecordsAggregateOptions{Aggregates: []string{"max(missing)"}},
+			true,
+			"",
+		},
+		{
+			"duplicated expressions",
+			"demo2",
+			core.RecordsAggregateOptions{Aggregates: []string{"count()", " count()"}},
+			true,
+			"",
+		},
+		{
+			"invalid sort",
+			"demo2",
+			core.RecordsAggregateOptions{GroupBy: []string{"active"}, Sort: []string{"title"}},
+			true,
+			"",
+		},
+		{
+			"date bucket for non-date field",
+			"demo2",
+			core.RecordsAggregateOptions{GroupBy: []string{"title:day"}},
+			true,
+			"",
+		},
+		{
+			"hidden field",
+			"users",
+			core.RecordsAggregateOptions{GroupBy: []string{"tokenKey"}},
+			true,
+			"",
+		},
+		{
+			"hidden field (allowHiddenFields)",
+			"users",
+			core.RecordsAggregateOptions{GroupBy: []string{"verified"}, Aggregates: []string{"count(tokenKey)"}, AllowHiddenFields: true},
+			false,
+			`[{"count(tokenKey)":1,"verified":0},{"count(tokenKey)":2,"verified":1}]`,
+		},
+		{
+			"hidden field in rule filters",
+			"users",
+			core.RecordsAggregateOptions{RuleFilters: []string{"tokenKey != '' && verified = true"}},
+			false,
+			`[{"count()":2}]`,
+		},
+		{
+			"hidden field in filters with rule filters",
+			"users",
+			core.RecordsAggregateOptions{RuleFilters: []string{"verified = true"}, Filters: []string{"tokenKey != ''"}},
+			true,
+			"",
+		},
+		{
+			"non-public emails",
+			"users",
+			core.RecordsAggregateOptions{GroupBy: []string{"email"}, Aggregates: []string{"count()", "min(email)", "count(email)"}},
+			false,
+			`[{"count()":2,"count(email)":0,"email":null,"min(email)":null},{"count()":1,"count(email)":1,"email":"test3@example.com","min(email)":"test3@example.com"}]`,
+		},
+		{
+			"non-public emails (allowHiddenFields)",
+			"users",
+			core.RecordsAggregateOptions{Aggregates: []string{"min(email)", "count(email)"}, AllowHiddenFields: true},
+			false,
+			`[{"count(email)":3,"min(email)":"test2@example.com"}]`,
+		},
+		{
+			"non-public relation emails",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"rel_many.email"}, Aggregates: []string{"max(rel_many.email)"}},
+			false,
+			`[{"max(rel_many.email)":null,"rel_many.email":null},{"max(rel_many.email)":"test3@example.com","rel_many.email":"test3@example.com"}]`,
+		},
+		{
+			"group by with multiple aggregates",
+			"demo1",
+			core.RecordsAggregateOptions{
+				GroupBy:    []string{"select_one"},
+				Aggregates: []string{"count()", "sum(number)", "avg(number)", "min(number)", "max(number)", "count(rel_one)"},
+			},
+			false,
+			`[{"avg(number)":0,"count()":1,"count(rel_one)":1,"max(number)":0,"min(number)":0,"select_one":"","sum(number)":0},{"avg(number)":61956,"count()":2,"count(rel_one)":2,"max(number)":123456,"min(number)":456,"select_one":"optionB","sum(number)":123912}]`,
+		},
+		{
+			"sort and limit",
+			"demo2",
+			core.RecordsAggregateOptions{
+				GroupBy:    []string{"active", "title"},
+				Aggregates: []string{"count()"},
+				Sort:       []string{"-active", "+title"},
+				Limit:      2,
+			},
+			false,
+			`[{"active":1,"count()":1,"title":"test2"},{"active":1,"count()":1,"title":"test3"}]`,
+		},
+		{
+			"filters",
+			"demo2",
+			core.RecordsAggregateOptions{
+				GroupBy: []string{"active"},
+				Filters: []string{"title != 'test3'", "", "created > '2022-01-01'"},
+			},
+			false,
+			`[{"active":0,"count()":1},{"active":1,"count()":1}]`,
+		},
+		{
+			"filters with multiple relation joins",
+			"demo4",
+			core.RecordsAggregateOptions{
+				Aggregates: []string{"count()", "count(title)"},
+				Filters:    []string{"rel_many_no_cascade.title ?~ 'test' || self_rel_many.title ?~ 'test'"},
+			},
+			false,
+			`[{"count()":1,"count(title)":1}]`,
+		},
+		{
+			"group by relation field",
+			"demo4",
+			core.RecordsAggregateOptions{GroupBy: []string{"rel_one_no_cascade.title"}},
+			false,
+			`[{"count()":1,"rel_one_no_cascade.title":null},{"count()":1,"rel_one_no_cascade.title":"test4"}]`,
+		},
+		{
+			"date buckets",
+			"demo2",
+			core.RecordsAggregateOptions{
+				GroupBy: []string{"created:hour", "created:day", "created:week", "created:month", "created:year"},
+			},
+			false,
+			`[{"count()":3,"created:day":"2022-10-12","created:hour":"2022-10-12 11:00:00.000Z","created:month":"2022-10","created:week":"2022-10-10","created:year":"2022"}]`,
+		},
+		{
+			"relation date bucket",
+			"demo4",
+			core.RecordsAggregateOptions{GroupBy: []string{"rel_one_no_cascade.created:year", "updated:month"}},
+			false,
+			`[{"count()":1,"rel_one_no_cascade.created:year":null,"updated:month":"2022-10"},{"count()":1,"rel_one_no_cascade.created:year":"2022","updated:month":"2022-10"}]`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection, err := app.FindCollectionByNameOrId(s.collection)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.AggregateRecords(collection, s.options)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			raw, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(raw) != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pocketbase/dbx"
+)
+
+// rotateEncryptionBatchSize is the number of records
+// that are processed at once during key rotation.
+const rotateEncryptionBatchSize = 500
+
+// RotateEncryptionKey re-encrypts all encrypted record fields values
+// and the app settings with the provided new secret and on success
+// sets it as value of the [App.EncryptionEnv] env variable.
+//
+// The records are updated directly in the db without triggering hooks.
+//
+// It could be also called with the current secret to rebuild the
+// encrypted fields blind indexes (eg. after changing the BlindIndex option).
+//
+// Note that the new secret must be also persisted in the
+// app environment, otherwise it will be lost on restart.
+func (app *BaseApp) RotateEncryptionKey(newSecret string) error {
+	envName := app.EncryptionEnv()
+	if envName == "" {
+		return errors.New("the app encryption env variable name is not configured")
+	}
+
+	newKeys, err := newEncryptedFieldKeys(newSecret)
+	if err != nil {
+		return err
+	}
+
+	oldSecret := os.Getenv(envName)
+
+	// nil if there is no previous secret
+	// (an error is returned only if there are values to decrypt)
+	oldKeys, _ := newEncryptedFieldKeys(oldSecret)
+
+	err = app.RunInTransaction(func(txApp App) error {
+		collections, err := txApp.FindAllCollections(CollectionTypeBase, CollectionTypeAuth)
+		if err != nil {
+			return err
+		}
+
+		for _, collection := range collections {
+			fields := make([]*EncryptedField, 0, len(collection.Fields))
+			for _, f := range collection.Fields {
+				if ef, ok := f.(*EncryptedField); ok {
+					fields = append(fields, ef)
+				}
+			}
+
+			if len(fields) == 0 {
+				continue
+			}
+
+			if err := rotateCollectionEncryptedFields(txApp, collection, fields, oldKeys, newKeys); err != nil {
+				return err
+			}
+		}
+
+		// re-encrypt the settings with the new secret
+		if err := os.Setenv(envName, newSecret); err != nil {
+			return err
+		}
+
+		return txApp.Save(txApp.Settings())
+	})
+	if err != nil {
+		_ = os.Setenv(envName, oldSecret)
+		return err
+	}
+
+	return nil
+}
+
+func rotateCollectionEncryptedFields(
+	app App,
+	collection *Collection,
+	fields []*EncryptedField,
+	oldKeys *encryptedFieldKeys,
+	newKeys *encryptedFieldKeys,
+) error {
+	columns := make([]string, 0, len(fields)+1)
+	columns = append(columns, FieldNameId)
+	for _, f := range fields {
+		columns = append(columns, f.Name)
+	}
+
+	var lastId string
+
+	for {
+		rows := []dbx.NullStringMap{}
+
+		// note: query the raw table to include the soft deleted records too
+		err := app.DB().Select(columns...).
+			From(collection.Name).
+			AndWhere(dbx.NewExp("[[id]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy("id ASC").
+			Limit(rotateEncryptionBatchSize).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			lastId = row[FieldNameId].String
+
+			updates := dbx.Params{}
+
+			for _, f := range fields {
+				stored := row[f.Name].String
+				if stored == "" {
+					continue
+				}
+
+				if oldKeys == nil {
+					return fmt.Errorf("failed to decrypt %s.%s of record %q: %w", collection.Name, f.Name, lastId, ErrMissingEncryptionKey)
+				}
+
+				plain, err := oldKeys.decrypt(stored)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt %s.%s of record %q: %w", collection.Name, f.Name, lastId, err)
+				}
+
+				updates[f.Name], err = newKeys.encrypt(plain, f.BlindIndex)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(updates) == 0 {
+				continue
+			}
+
+			_, err := app.DB().Update(collection.Name, updates, dbx.HashExp{FieldNameId: lastId}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < rotateEncryptionBatchSize {
+			return nil
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/security"
@@ -26,6 +27,9 @@ const (
 // ensure that `search.FieldResolver` interface is implemented
 var _ search.FieldResolver = (*RecordFieldResolver)(nil)
 
+// ensure that `search.FunctionResolver` interface is implemented
+var _ search.FunctionResolver = (*RecordFieldResolver)(nil)
+
 // RecordFieldResolver defines a custom search resolver struct for
 // managing Record model search fields.
 //
@@ -47,6 +51,7 @@ type RecordFieldResolver struct {
 	staticRequestInfo map[string]any
 	allowedFields     []string
 	joins             []*join
+	ftsMatches        []*ftsMatch
 	allowHiddenFields bool
 }
 
@@ -152,10 +157,53 @@ func (r *RecordFieldResolver) UpdateQuery(query *dbx.SelectQuery) error {
 //	@request.body.someSelect:each
 //	@request.body.someField:isset
 //	@collection.product.name
+//	@rank
 func (r *RecordFieldResolver) Resolve(fieldName string) (*search.ResolverResult, error) {
+	if fieldName == search.RankSortKey {
+		return r.resolveFTSRank()
+	}
+
 	return parseAndRun(fieldName, r)
 }
 
+// ResolveFunction implements the `search.FunctionResolver` interface.
+//
+// It resolves the following record specific filter functions:
+//
+//	ftsMatch(field, query)                                    - full-text index match of a searchable field
+//	geoWithinBox(field, minLon, minLat, maxLon, maxLat)       - geoPoint field bounding box check
+//	geoDistance(lonA, latA, lonB, latB)                       - the global geoDistance function with optional spatial index prefilter
+//	geoContains(shape, point) / geoContains(shape, lon, lat)  - geoShape point containment check
+//	geoIntersects(shapeA, shapeB)                             - geoShapes intersection check
+//	vectorDistance(field, vector, metric?)                    - vector field distance (cosine, l2 or dot)
+func (r *RecordFieldResolver) ResolveFunction(
+	name string,
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, bool, error) {
+	var result *search.ResolverResult
+	var err error
+
+	switch name {
+	case ftsMatchFunction:
+		result, err = r.resolveFTSMatch(argTokenResolverFunc, args...)
+	case geoWithinBoxFunction:
+		result, err = r.resolveGeoWithinBox(argTokenResolverFunc, args...)
+	case geoDistanceFunction:
+		result, err = r.resolveGeoDistance(argTokenResolverFunc, args...)
+	case geoContainsFunction:
+		result, err = r.resolveGeoContains(argTokenResolverFunc, args...)
+	case geoIntersectsFunction:
+		result, err = r.resolveGeoIntersects(argTokenResolverFunc, args...)
+	case vectorDistanceFunction:
+		result, err = r.resolveVectorDistance(argTokenResolverFunc, args...)
+	default:
+		return nil, false, nil
+	}
+
+	return result, true, err
+}
+
 func (r *RecordFieldResolver) resolveStaticRequestField(path ...string) (*search.ResolverResult, error) {
 	if len(path) == 0 {
 		return nil, errors.New("at least one path key should be provided")
@@ -401,3 +449,36 @@ func splitModifier(combined string) (string, string, error) {
 
 	return "", "", fmt.Errorf("unknown modifier in %q", combined)
 }
+
+// findRecordFieldByPath returns the field of the provided dot separated
+// field path (eg. "location", "author.location").
+//
+// It returns nil if the field is missing or the path is not a chain of
+// direct relation fields (modifiers and back-relations are not supported).
+func findRecordFieldByPath(app App, collection *Collection, path string) Field {
+	parts := strings.Split(path, ".")
+
+	for i, part := range parts {
+		field := collection.Fields.GetByName(part)
+		if field == nil {
+			return nil
+		}
+
+		if i == len(parts)-1 {
+			return field
+		}
+
+		relField, ok := field.(*RelationField)
+		if !ok {
+			return nil
+		}
+
+		var err error
+		collection, err = app.FindCachedCollectionByNameOrId(relField.CollectionId)
+		if err != nil {
+			return nil
+		}
+	}
+
+	return nil
+}
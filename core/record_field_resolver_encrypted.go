@@ -0,0 +1,95 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// processEncryptedField resolves an encrypted field identifier.
+//
+// Encrypted fields can be used only in "=" and "!=" comparisons with a single
+// plain value (eg. "token = 'abc'" or "token = @request.body.token")
+// and only if the field has a blind index.
+func (r *runner) processEncryptedField(field *EncryptedField, modifier string) (*search.ResolverResult, error) {
+	if !field.BlindIndex {
+		return nil, fmt.Errorf("encrypted field %q without blind index can't be filtered or sorted", field.Name)
+	}
+
+	if modifier != "" {
+		return nil, fmt.Errorf("encrypted field %q doesn't support modifiers", field.Name)
+	}
+
+	if r.withMultiMatch {
+		return nil, fmt.Errorf("encrypted field %q can be compared only as direct collection field", field.Name)
+	}
+
+	column := "[[" + r.activeTableAlias + "." + inflector.Columnify(field.Name) + "]]"
+
+	lengthPlaceholder := "encLen" + security.PseudorandomString(6)
+	lengthParams := dbx.Params{lengthPlaceholder: encryptedFieldBlindIndexLength}
+
+	hashIdentifier := fmt.Sprintf("substr(%s, 1, {:%s})", column, lengthPlaceholder)
+
+	return &search.ResolverResult{
+		// note: the length is intentionally a param because
+		// identifiers with params are not allowed as sort fields
+		Identifier: hashIdentifier,
+		Params:     lengthParams,
+		NoCoalesce: true,
+		AfterCompare: func(expr dbx.Expression, op fexpr.SignOp, other *search.ResolverResult, isRight bool) (dbx.Expression, error) {
+			var sign string
+			switch op {
+			case fexpr.SignEq:
+				sign = "="
+			case fexpr.SignNeq:
+				sign = "!="
+			default:
+				return nil, fmt.Errorf("encrypted field %q supports only = and != comparisons", field.Name)
+			}
+
+			value, err := encryptedFieldComparisonValue(other)
+			if err != nil {
+				return nil, fmt.Errorf("encrypted field %q: %w", field.Name, err)
+			}
+
+			plain := field.plainBytes(field.normalizePlainValue(value))
+			if len(plain) == 0 {
+				return dbx.NewExp(fmt.Sprintf("%s %s ''", column, sign)), nil
+			}
+
+			keys, err := encryptedFieldKeysFromEnv(r.resolver.app.EncryptionEnv())
+			if err != nil {
+				return nil, err
+			}
+
+			hashPlaceholder := "encHash" + security.PseudorandomString(6)
+
+			return dbx.NewExp(
+				fmt.Sprintf("%s %s {:%s}", hashIdentifier, sign, hashPlaceholder),
+				dbx.Params{lengthPlaceholder: encryptedFieldBlindIndexLength, hashPlaceholder: keys.hash(plain)},
+			), nil
+		},
+	}, nil
+}
+
+// encryptedFieldComparisonValue extracts the plain value of a single param resolver result.
+func encryptedFieldComparisonValue(result *search.ResolverResult) (any, error) {
+	if len(result.Params) != 1 ||
+		result.MultiMatchSubQuery != nil ||
+		!strings.HasPrefix(result.Identifier, "{:") {
+		return nil, errors.New("can be compared only with a single plain value")
+	}
+
+	for _, v := range result.Params {
+		return v, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// ftsMatchFunction is the name of the filter function for querying
+// the full-text index of a searchable field, eg.:
+//
+//	ftsMatch(title, 'go AND sqlite') = true
+const ftsMatchFunction = "ftsMatch"
+
+// ftsMatch holds the details of a single resolved ftsMatch() function call
+// that are used later to resolve the @rank sort key.
+type ftsMatch struct {
+	tableName string
+	query     *search.ResolverResult
+}
+
+func (r *RecordFieldResolver) resolveFTSMatch(
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("[%s] expected 2 arguments, got %d", ftsMatchFunction, len(args))
+	}
+
+	if args[0].Type != fexpr.TokenIdentifier {
+		return nil, fmt.Errorf("[%s] the first argument must be a field identifier", ftsMatchFunction)
+	}
+
+	field := r.baseCollection.Fields.GetByName(args[0].Literal)
+	if field == nil || r.baseCollection.IsView() || !isSearchableField(field) {
+		return nil, fmt.Errorf("[%s] %q is not a searchable field", ftsMatchFunction, args[0].Literal)
+	}
+
+	if field.GetHidden() && !r.allowHiddenFields {
+		return nil, fmt.Errorf("[%s] non-filterable field %q", ftsMatchFunction, args[0].Literal)
+	}
+
+	if args[1].Type != fexpr.TokenText && args[1].Type != fexpr.TokenIdentifier {
+		return nil, fmt.Errorf("[%s] the second argument must be a text or identifier", ftsMatchFunction)
+	}
+
+	query, err := argTokenResolverFunc(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve the query argument: %w", ftsMatchFunction, err)
+	}
+
+	tableName := recordFTSTableName(r.baseCollection, field)
+
+	r.ftsMatches = append(r.ftsMatches, &ftsMatch{
+		tableName: tableName,
+		query:     query,
+	})
+
+	return &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: fmt.Sprintf(
+			"([[%s.id]] IN (SELECT [[id]] FROM {{%s}} WHERE {{%s}} MATCH %s))",
+			inflector.Columnify(r.baseCollection.Name),
+			tableName,
+			tableName,
+			query.Identifier,
+		),
+		Params: query.Params,
+	}, nil
+}
+
+// resolveFTSRank resolves the @rank sort key to the sum of the
+// FTS5 bm25 scores of all previously resolved ftsMatch() filters
+// (lower is more relevant, aka. "sort=@rank" returns the best matches first).
+//
+// It resolves to NULL if there are no ftsMatch() filters.
+func (r *RecordFieldResolver) resolveFTSRank() (*search.ResolverResult, error) {
+	if len(r.ftsMatches) == 0 {
+		return &search.ResolverResult{Identifier: "NULL"}, nil
+	}
+
+	baseTableAlias := inflector.Columnify(r.baseCollection.Name)
+
+	parts := make([]string, len(r.ftsMatches))
+	for i, m := range r.ftsMatches {
+		alias := "__fts" + strconv.Itoa(i)
+
+		r.registerJoin(
+			fmt.Sprintf("(SELECT [[id]], [[rank]] FROM {{%s}} WHERE {{%s}} MATCH %s)", m.tableName, m.tableName, m.query.Identifier),
+			alias,
+			dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s.id]]", alias, baseTableAlias), m.query.Params),
+		)
+
+		parts[i] = fmt.Sprintf("COALESCE([[%s.rank]], 0)", alias)
+	}
+
+	return &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: "(" + strings.Join(parts, " + ") + ")",
+	}, nil
+}
@@ -0,0 +1,280 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// geoWithinBoxFunction is the name of the filter function for checking
+// whether a geoPoint field value is inside a bounding box, eg.:
+//
+//	geoWithinBox(location, 23.2, 42.6, 23.4, 42.8) = true
+const geoWithinBoxFunction = "geoWithinBox"
+
+// geoDistanceFunction is the name of the global geoDistance filter function
+// (see [search.TokenFunctions]) that is additionally prefiltered with
+// the spatial index of the geoPoint field arguments (if any).
+const geoDistanceFunction = "geoDistance"
+
+// resolveGeoWithinBox resolves the geoWithinBox(field, minLon, minLat, maxLon, maxLat) filter function.
+//
+// The field could be a geoPoint field of the base collection or of a
+// related collection (eg. "author.location"). Similar to geoDistance,
+// for multiple relation fields the function evaluates to true if
+// at-least-one of the related geoPoint values is inside the box.
+//
+// The box is not wrapped around the antimeridian (aka. minLon must be <= maxLon).
+//
+// If the field is a spatial indexed field of the base collection,
+// the "= true" comparisons are additionally prefiltered with the R*Tree index.
+func (r *RecordFieldResolver) resolveGeoWithinBox(
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, error) {
+	if len(args) != 5 {
+		return nil, fmt.Errorf("[%s] expected 5 arguments, got %d", geoWithinBoxFunction, len(args))
+	}
+
+	if args[0].Type != fexpr.TokenIdentifier {
+		return nil, fmt.Errorf("[%s] the first argument must be a geoPoint field identifier", geoWithinBoxFunction)
+	}
+
+	field := findRecordFieldByPath(r.app, r.baseCollection, args[0].Literal)
+	if field == nil || field.Type() != FieldTypeGeoPoint {
+		return nil, fmt.Errorf("[%s] %q is not a geoPoint field", geoWithinBoxFunction, args[0].Literal)
+	}
+
+	lon, err := argTokenResolverFunc(fexpr.Token{Type: fexpr.TokenIdentifier, Literal: args[0].Literal + ".lon"})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve the field argument: %w", geoWithinBoxFunction, err)
+	}
+
+	lat, err := argTokenResolverFunc(fexpr.Token{Type: fexpr.TokenIdentifier, Literal: args[0].Literal + ".lat"})
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve the field argument: %w", geoWithinBoxFunction, err)
+	}
+
+	// minLon, minLat, maxLon, maxLat
+	box := make([]*search.ResolverResult, 4)
+	for i, arg := range args[1:] {
+		if arg.Type != fexpr.TokenIdentifier && arg.Type != fexpr.TokenNumber {
+			return nil, fmt.Errorf("[%s] argument %d must be an identifier or number", geoWithinBoxFunction, i+1)
+		}
+
+		box[i], err = argTokenResolverFunc(arg)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] failed to resolve argument %d: %w", geoWithinBoxFunction, i+1, err)
+		}
+	}
+
+	result := &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: fmt.Sprintf(
+			"(%s BETWEEN %s AND %s AND %s BETWEEN %s AND %s)",
+			lon.Identifier, box[0].Identifier, box[2].Identifier,
+			lat.Identifier, box[1].Identifier, box[3].Identifier,
+		),
+		Params: mergeResolverResultsParams(lon, lat, box[0], box[1], box[2], box[3]),
+	}
+
+	if r.baseCollection.IsView() || !isSpatialIndexedField(field) || r.baseCollection.Fields.GetByName(args[0].Literal) != field {
+		return result, nil
+	}
+
+	prefilter := geoBBoxPrefilterExpr(
+		inflector.Columnify(r.baseCollection.Name),
+		recordRTreeTableName(r.baseCollection, field),
+		[4]string{box[0].Identifier, box[1].Identifier, box[2].Identifier, box[3].Identifier},
+		mergeResolverResultsParams(box...),
+	)
+
+	result.AfterCompare = func(expr dbx.Expression, op fexpr.SignOp, other *search.ResolverResult, isRight bool) (dbx.Expression, error) {
+		if !isTrueComparison(op, other) {
+			return expr, nil
+		}
+
+		return dbx.And(expr, prefilter), nil
+	}
+
+	return result, nil
+}
+
+// resolveGeoDistance resolves the global geoDistance(lonA, latA, lonB, latB) filter function.
+//
+// If one of the function points is a spatial indexed geoPoint field of
+// the base collection (eg. "geoDistance(location.lon, location.lat, 23.32, 42.69) < 25"),
+// the "less than" radius comparisons are additionally prefiltered with the
+// R*Tree index using the bounding box of the radius circle.
+func (r *RecordFieldResolver) resolveGeoDistance(
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, error) {
+	result, err := search.TokenFunctions[geoDistanceFunction](argTokenResolverFunc, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.baseCollection.IsView() {
+		return result, nil
+	}
+
+	field, centerArgs := r.findGeoDistanceIndexedField(args)
+	if field == nil {
+		return result, nil
+	}
+
+	centerLon, err := argTokenResolverFunc(centerArgs[0])
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve the center longitude: %w", geoDistanceFunction, err)
+	}
+
+	centerLat, err := argTokenResolverFunc(centerArgs[1])
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve the center latitude: %w", geoDistanceFunction, err)
+	}
+
+	tableName := recordRTreeTableName(r.baseCollection, field)
+	baseTableAlias := inflector.Columnify(r.baseCollection.Name)
+
+	result.AfterCompare = func(expr dbx.Expression, op fexpr.SignOp, other *search.ResolverResult, isRight bool) (dbx.Expression, error) {
+		if !isUpperBoundComparison(op, isRight) {
+			return expr, nil
+		}
+
+		return dbx.And(expr, geoDistancePrefilterExpr(baseTableAlias, tableName, centerLon, centerLat, other)), nil
+	}
+
+	return result, nil
+}
+
+// findGeoDistanceIndexedField returns the spatial indexed field of the
+// base collection used as one of the geoDistance function points
+// together with the arguments of the other (aka. center) point.
+//
+// It returns nil if none of the points is a spatial indexed field.
+func (r *RecordFieldResolver) findGeoDistanceIndexedField(args []fexpr.Token) (Field, []fexpr.Token) {
+	for i := 0; i < len(args)-1; i += 2 {
+		lonArg, latArg := args[i], args[i+1]
+		if lonArg.Type != fexpr.TokenIdentifier || latArg.Type != fexpr.TokenIdentifier {
+			continue
+		}
+
+		name, ok := strings.CutSuffix(lonArg.Literal, ".lon")
+		if !ok || latArg.Literal != name+".lat" {
+			continue
+		}
+
+		field := r.baseCollection.Fields.GetByName(name)
+		if field == nil || field.Type() != FieldTypeGeoPoint || !isSpatialIndexedField(field) {
+			continue
+		}
+
+		if i == 0 {
+			return field, args[2:4]
+		}
+
+		return field, args[0:2]
+	}
+
+	return nil, nil
+}
+
+// geoDistancePrefilterExpr returns an R*Tree index friendly expression
+// matching the records whose indexed point is inside the bounding box
+// of the circle with the specified center and radius (in km).
+//
+// The bounding box longitude is not restricted if the circle overlaps
+// a pole or the antimeridian (https://www.movable-type.co.uk/scripts/latlong-db.html).
+func geoDistancePrefilterExpr(
+	baseTableAlias string,
+	tableName string,
+	lon *search.ResolverResult,
+	lat *search.ResolverResult,
+	radius *search.ResolverResult,
+) dbx.Expression {
+	// the angular radius is slightly enlarged to compensate
+	// for floating point rounding errors near the circle boundary
+	angularRadius := "((" + radius.Identifier + ") * 1.000001 / 6371.0)"
+
+	dLat := "degrees(" + angularRadius + ")"
+	dLon := "degrees(asin(sin(" + angularRadius + ") / cos(radians(" + lat.Identifier + "))))"
+
+	unbounded := fmt.Sprintf(
+		"(%[1]s + %[2]s >= 90 OR %[1]s - %[2]s <= -90 OR %[3]s - %[4]s < -180 OR %[3]s + %[4]s > 180)",
+		lat.Identifier, dLat, lon.Identifier, dLon,
+	)
+
+	return dbx.NewExp(
+		fmt.Sprintf(
+			"[[%s.id]] IN (SELECT [[id]] FROM {{%s}} WHERE "+
+				"[[maxLat]] >= %s - %s AND [[minLat]] <= %s + %s AND "+
+				"[[maxLon]] >= (CASE WHEN %s THEN -180 ELSE %s - %s END) AND "+
+				"[[minLon]] <= (CASE WHEN %s THEN 180 ELSE %s + %s END))",
+			baseTableAlias,
+			tableName,
+			lat.Identifier, dLat, lat.Identifier, dLat,
+			unbounded, lon.Identifier, dLon,
+			unbounded, lon.Identifier, dLon,
+		),
+		mergeResolverResultsParams(lon, lat, radius),
+	)
+}
+
+// geoBBoxPrefilterExpr returns an R*Tree index friendly expression matching
+// the records whose indexed bounding box intersects with the provided
+// [minLon, minLat, maxLon, maxLat] bounding box SQL expressions.
+func geoBBoxPrefilterExpr(baseTableAlias string, tableName string, bbox [4]string, params dbx.Params) dbx.Expression {
+	return dbx.NewExp(
+		fmt.Sprintf(
+			"[[%s.id]] IN (SELECT [[id]] FROM {{%s}} WHERE [[maxLon]] >= %s AND [[minLon]] <= %s AND [[maxLat]] >= %s AND [[minLat]] <= %s)",
+			baseTableAlias,
+			tableName,
+			bbox[0], bbox[2], bbox[1], bbox[3],
+		),
+		params,
+	)
+}
+
+// isTrueComparison reports whether the comparison with the other
+// operand is a "= true" or "!= false" check.
+func isTrueComparison(op fexpr.SignOp, other *search.ResolverResult) bool {
+	switch op {
+	case fexpr.SignEq, fexpr.SignAnyEq:
+		return other.Identifier == "1"
+	case fexpr.SignNeq, fexpr.SignAnyNeq:
+		return other.Identifier == "0"
+	}
+
+	return false
+}
+
+// isUpperBoundComparison reports whether the operator limits the
+// max value of the current operand (eg. "a < b" for the left side or "b > a" for the right side).
+func isUpperBoundComparison(op fexpr.SignOp, isRight bool) bool {
+	switch op {
+	case fexpr.SignLt, fexpr.SignAnyLt, fexpr.SignLte, fexpr.SignAnyLte:
+		return !isRight
+	case fexpr.SignGt, fexpr.SignAnyGt, fexpr.SignGte, fexpr.SignAnyGte:
+		return isRight
+	}
+
+	return false
+}
+
+// mergeResolverResultsParams merges the params of the provided resolver results into a new params map.
+func mergeResolverResultsParams(results ...*search.ResolverResult) dbx.Params {
+	params := dbx.Params{}
+
+	for _, r := range results {
+		for k, v := range r.Params {
+			params[k] = v
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,366 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// geoContainsFunction is the name of the filter function for checking
+// whether a geoShape contains a point, eg.:
+//
+//	geoContains(zone, @request.body.location) = true
+//	geoContains(zone, 23.32, 42.69) = true
+const geoContainsFunction = "geoContains"
+
+// geoIntersectsFunction is the name of the filter function for checking
+// whether 2 geoShapes have at least one common point, eg.:
+//
+//	geoIntersects(zone, @request.body.route) = true
+const geoIntersectsFunction = "geoIntersects"
+
+// geoShapeEpsilon is the max allowed planar deviation when checking
+// whether a point lies on a line segment (see also [types.GeoShape.ContainsPoint]).
+const geoShapeEpsilon = "1e-9"
+
+// geoArg holds a single resolved geoShape or geoPoint filter function argument.
+type geoArg struct {
+	literal string
+	result  *search.ResolverResult
+
+	// field is the record field of the argument
+	// (nil for the non-field identifiers, eg. @request.body.zone).
+	field Field
+
+	// indexed reports whether the argument is a spatial indexed field of the base collection.
+	indexed bool
+}
+
+// resolveGeoContains resolves the geoContains(shape, point) and
+// geoContains(shape, lon, lat) filter functions.
+//
+// The shape must be a geoShape field (including relation paths, eg. "store.zone")
+// or a @request.* identifier with GeoJSON geometry value.
+// The point must be a geoPoint field or a @request.* identifier with lon-lat object value.
+//
+// See [types.GeoShape.ContainsPoint] for the containment rules.
+//
+// If one of the arguments is a spatial indexed field of the base collection,
+// the "= true" comparisons are additionally prefiltered with the R*Tree index.
+func (r *RecordFieldResolver) resolveGeoContains(
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("[%s] expected 2 or 3 arguments, got %d", geoContainsFunction, len(args))
+	}
+
+	shape, err := r.resolveGeoArg(geoContainsFunction, FieldTypeGeoShape, args[0], argTokenResolverFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	var point *geoArg
+	var lon, lat string
+	var pointParams dbx.Params
+
+	if len(args) == 2 {
+		point, err = r.resolveGeoArg(geoContainsFunction, FieldTypeGeoPoint, args[1], argTokenResolverFunc)
+		if err != nil {
+			return nil, err
+		}
+
+		lon = "json_extract(" + point.result.Identifier + ", '$.lon')"
+		lat = "json_extract(" + point.result.Identifier + ", '$.lat')"
+		pointParams = point.result.Params
+	} else {
+		coords := make([]*search.ResolverResult, 2)
+		for i, arg := range args[1:] {
+			if arg.Type != fexpr.TokenIdentifier && arg.Type != fexpr.TokenNumber {
+				return nil, fmt.Errorf("[%s] argument %d must be an identifier or number", geoContainsFunction, i+1)
+			}
+
+			coords[i], err = argTokenResolverFunc(arg)
+			if err != nil {
+				return nil, fmt.Errorf("[%s] failed to resolve argument %d: %w", geoContainsFunction, i+1, err)
+			}
+		}
+
+		lon = coords[0].Identifier
+		lat = coords[1].Identifier
+		pointParams = mergeResolverResultsParams(coords...)
+	}
+
+	identifier := geoShapeContainsPointSQL(shape.result.Identifier, lon, lat)
+
+	// check first the cached bbox of the stored shapes
+	if shape.field != nil {
+		bbox := geoShapeBBoxSQL(shape.result.Identifier, true)
+		identifier = fmt.Sprintf(
+			"(%s BETWEEN %s AND %s AND %s BETWEEN %s AND %s AND %s)",
+			lon, bbox[0], bbox[2], lat, bbox[1], bbox[3], identifier,
+		)
+	}
+
+	result := &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: identifier,
+		Params:     mergeResolverResultsParams(shape.result, &search.ResolverResult{Params: pointParams}),
+	}
+
+	var prefilters []dbx.Expression
+
+	if shape.indexed {
+		prefilters = append(prefilters, r.geoPrefilterExpr(shape.field, [4]string{lon, lat, lon, lat}, pointParams))
+	}
+
+	if point != nil && point.indexed {
+		prefilters = append(prefilters, r.geoPrefilterExpr(
+			point.field,
+			geoShapeBBoxSQL(shape.result.Identifier, shape.field != nil),
+			shape.result.Params,
+		))
+	}
+
+	setGeoTrueComparisonPrefilters(result, prefilters)
+
+	return result, nil
+}
+
+// resolveGeoIntersects resolves the geoIntersects(shapeA, shapeB) filter function.
+//
+// The shapes must be geoShape fields (including relation paths, eg. "store.zone")
+// or @request.* identifiers with GeoJSON geometry value.
+//
+// See [types.GeoShape.Intersects] for the intersection rules.
+//
+// If one of the arguments is a spatial indexed field of the base collection,
+// the "= true" comparisons are additionally prefiltered with the R*Tree index.
+func (r *RecordFieldResolver) resolveGeoIntersects(
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("[%s] expected 2 arguments, got %d", geoIntersectsFunction, len(args))
+	}
+
+	a, err := r.resolveGeoArg(geoIntersectsFunction, FieldTypeGeoShape, args[0], argTokenResolverFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := r.resolveGeoArg(geoIntersectsFunction, FieldTypeGeoShape, args[1], argTokenResolverFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	identifier := geoShapesIntersectSQL(a.result.Identifier, b.result.Identifier)
+
+	// check first the cached bbox of the stored shapes
+	if a.field != nil && b.field != nil {
+		bboxA := geoShapeBBoxSQL(a.result.Identifier, true)
+		bboxB := geoShapeBBoxSQL(b.result.Identifier, true)
+		identifier = fmt.Sprintf(
+			"(%s >= %s AND %s >= %s AND %s >= %s AND %s >= %s AND %s)",
+			bboxA[2], bboxB[0], bboxB[2], bboxA[0],
+			bboxA[3], bboxB[1], bboxB[3], bboxA[1],
+			identifier,
+		)
+	}
+
+	result := &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: identifier,
+		Params:     mergeResolverResultsParams(a.result, b.result),
+	}
+
+	var prefilters []dbx.Expression
+
+	if a.indexed {
+		prefilters = append(prefilters, r.geoPrefilterExpr(a.field, geoShapeBBoxSQL(b.result.Identifier, b.field != nil), b.result.Params))
+	}
+
+	if b.indexed {
+		prefilters = append(prefilters, r.geoPrefilterExpr(b.field, geoShapeBBoxSQL(a.result.Identifier, a.field != nil), a.result.Params))
+	}
+
+	setGeoTrueComparisonPrefilters(result, prefilters)
+
+	return result, nil
+}
+
+// resolveGeoArg resolves a single geoShape or geoPoint filter function argument.
+//
+// The argument must be an identifier of a field with the specified type
+// or a @-prefixed identifier (eg. @request.body.location).
+//
+// The non-field identifiers are resolved to NULL in case of invalid json value.
+func (r *RecordFieldResolver) resolveGeoArg(
+	functionName string,
+	fieldType string,
+	arg fexpr.Token,
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+) (*geoArg, error) {
+	if arg.Type != fexpr.TokenIdentifier {
+		return nil, fmt.Errorf("[%s] %q must be a %s field or @request.* identifier", functionName, arg.Literal, fieldType)
+	}
+
+	result := &geoArg{literal: arg.Literal}
+
+	if !strings.HasPrefix(arg.Literal, "@") {
+		result.field = findRecordFieldByPath(r.app, r.baseCollection, arg.Literal)
+		if result.field == nil || result.field.Type() != fieldType {
+			return nil, fmt.Errorf("[%s] %q is not a %s field", functionName, arg.Literal, fieldType)
+		}
+
+		result.indexed = !r.baseCollection.IsView() &&
+			isSpatialIndexedField(result.field) &&
+			r.baseCollection.Fields.GetByName(arg.Literal) == result.field
+	}
+
+	resolved, err := argTokenResolverFunc(arg)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve %q: %w", functionName, arg.Literal, err)
+	}
+
+	if result.field != nil {
+		result.result = resolved
+	} else {
+		// guard against malformed json values (eg. invalid request data)
+		result.result = &search.ResolverResult{
+			Identifier: "(CASE WHEN json_valid(" + resolved.Identifier + ") THEN " + resolved.Identifier + " END)",
+			Params:     resolved.Params,
+		}
+	}
+
+	return result, nil
+}
+
+// geoPrefilterExpr returns the R*Tree prefilter expression of the spatial
+// indexed base collection field for the provided bounding box SQL expressions.
+func (r *RecordFieldResolver) geoPrefilterExpr(field Field, bbox [4]string, params dbx.Params) dbx.Expression {
+	return geoBBoxPrefilterExpr(
+		inflector.Columnify(r.baseCollection.Name),
+		recordRTreeTableName(r.baseCollection, field),
+		bbox,
+		params,
+	)
+}
+
+// setGeoTrueComparisonPrefilters registers an AfterCompare handler
+// that adds the provided prefilters to the "= true" comparisons.
+func setGeoTrueComparisonPrefilters(result *search.ResolverResult, prefilters []dbx.Expression) {
+	if len(prefilters) == 0 {
+		return
+	}
+
+	result.AfterCompare = func(expr dbx.Expression, op fexpr.SignOp, other *search.ResolverResult, isRight bool) (dbx.Expression, error) {
+		if !isTrueComparison(op, other) {
+			return expr, nil
+		}
+
+		return dbx.And(append([]dbx.Expression{expr}, prefilters...)...), nil
+	}
+}
+
+// geoShapePositionsSQL returns a subquery selecting all positions ([[x]], [[y]])
+// of the GeoJSON geometry json expression with the id of their parent
+// array ([[ring]]) and their index in it ([[idx]]).
+func geoShapePositionsSQL(shape string) string {
+	return "SELECT json_extract([[value]], '$[0]') AS [[x]], json_extract([[value]], '$[1]') AS [[y]], [[parent]] AS [[ring]], [[key]] AS [[idx]] " +
+		"FROM json_tree(" + shape + ", '$.coordinates') " +
+		"WHERE [[type]] = 'array' AND json_type([[value]], '$[0]') IN ('integer', 'real')"
+}
+
+// geoShapeSegmentsSQL returns a subquery selecting all line segments
+// ([[x1]], [[y1]], [[x2]], [[y2]]) of the GeoJSON geometry json expression
+// (aka. the polygon rings edges or the line parts).
+func geoShapeSegmentsSQL(shape string) string {
+	return "SELECT * FROM (" +
+		"SELECT " +
+		"LAG([[x]]) OVER (PARTITION BY [[ring]] ORDER BY [[idx]]) AS [[x1]], " +
+		"LAG([[y]]) OVER (PARTITION BY [[ring]] ORDER BY [[idx]]) AS [[y1]], " +
+		"[[x]] AS [[x2]], [[y]] AS [[y2]] " +
+		"FROM (" + geoShapePositionsSQL(shape) + ")" +
+		") WHERE [[x1]] IS NOT NULL"
+}
+
+// geoShapeBBoxSQL returns the [minLon, minLat, maxLon, maxLat] SQL
+// expressions of the GeoJSON geometry json expression bounding box.
+//
+// If cached is true, the stored "bbox" member is used instead of calculating it
+// (it should be set only for stored geoShape field values because the
+// "bbox" member of the user provided data cannot be trusted).
+func geoShapeBBoxSQL(shape string, cached bool) [4]string {
+	if cached {
+		return [4]string{
+			"json_extract(" + shape + ", '$.bbox[0]')",
+			"json_extract(" + shape + ", '$.bbox[1]')",
+			"json_extract(" + shape + ", '$.bbox[2]')",
+			"json_extract(" + shape + ", '$.bbox[3]')",
+		}
+	}
+
+	positions := geoShapePositionsSQL(shape)
+
+	return [4]string{
+		"(SELECT MIN([[x]]) FROM (" + positions + "))",
+		"(SELECT MIN([[y]]) FROM (" + positions + "))",
+		"(SELECT MAX([[x]]) FROM (" + positions + "))",
+		"(SELECT MAX([[y]]) FROM (" + positions + "))",
+	}
+}
+
+// geoShapeContainsPointSQL returns an SQL expression checking whether the
+// GeoJSON geometry json expression contains the lon-lat point
+// (mirroring [types.GeoShape.ContainsPoint]).
+func geoShapeContainsPointSQL(shape string, lon string, lat string) string {
+	shapeType := "json_extract(" + shape + ", '$.type')"
+
+	onLine := "EXISTS (SELECT 1 FROM (" + geoShapeSegmentsSQL(shape) + ") WHERE " +
+		"abs(([[x2]] - [[x1]]) * (" + lat + " - [[y1]]) - ([[y2]] - [[y1]]) * (" + lon + " - [[x1]])) <= " + geoShapeEpsilon + " AND " +
+		lon + " BETWEEN min([[x1]], [[x2]]) AND max([[x1]], [[x2]]) AND " +
+		lat + " BETWEEN min([[y1]], [[y2]]) AND max([[y1]], [[y2]]))"
+
+	// even-odd rule
+	inPolygon := "(SELECT COUNT(*) FROM (" + geoShapeSegmentsSQL(shape) + ") WHERE " +
+		"([[y1]] > " + lat + ") != ([[y2]] > " + lat + ") AND " +
+		lon + " < ([[x2]] - [[x1]]) * (" + lat + " - [[y1]]) / ([[y2]] - [[y1]]) + [[x1]]) % 2 = 1"
+
+	return "(CASE " +
+		"WHEN " + shapeType + " = 'LineString' THEN " + onLine + " " +
+		"WHEN " + shapeType + " IN ('Polygon', 'MultiPolygon') THEN " + inPolygon + " " +
+		"ELSE 0 END)"
+}
+
+// geoShapesIntersectSQL returns an SQL expression checking whether the 2 GeoJSON
+// geometry json expressions have at least one common point
+// (mirroring [types.GeoShape.Intersects]).
+func geoShapesIntersectSQL(a string, b string) string {
+	orientation := func(ax, ay, bx, by, cx, cy string) string {
+		return "((" + bx + " - " + ax + ") * (" + cy + " - " + ay + ") - (" + by + " - " + ay + ") * (" + cx + " - " + ax + "))"
+	}
+
+	d1 := orientation("[[__a.x1]]", "[[__a.y1]]", "[[__a.x2]]", "[[__a.y2]]", "[[__b.x1]]", "[[__b.y1]]")
+	d2 := orientation("[[__a.x1]]", "[[__a.y1]]", "[[__a.x2]]", "[[__a.y2]]", "[[__b.x2]]", "[[__b.y2]]")
+	d3 := orientation("[[__b.x1]]", "[[__b.y1]]", "[[__b.x2]]", "[[__b.y2]]", "[[__a.x1]]", "[[__a.y1]]")
+	d4 := orientation("[[__b.x1]]", "[[__b.y1]]", "[[__b.x2]]", "[[__b.y2]]", "[[__a.x2]]", "[[__a.y2]]")
+
+	edgesIntersect := "EXISTS (SELECT 1 FROM (" + geoShapeSegmentsSQL(a) + ") AS [[__a]], (" + geoShapeSegmentsSQL(b) + ") AS [[__b]] WHERE " +
+		d1 + " * " + d2 + " <= 0 AND " + d3 + " * " + d4 + " <= 0 AND " +
+		"max([[__a.x1]], [[__a.x2]]) >= min([[__b.x1]], [[__b.x2]]) AND max([[__b.x1]], [[__b.x2]]) >= min([[__a.x1]], [[__a.x2]]) AND " +
+		"max([[__a.y1]], [[__a.y2]]) >= min([[__b.y1]], [[__b.y2]]) AND max([[__b.y1]], [[__b.y2]]) >= min([[__a.y1]], [[__a.y2]]))"
+
+	// no edges intersection -> check whether one of the shapes is inside the other
+	aContainsB := "(json_extract(" + a + ", '$.type') IN ('Polygon', 'MultiPolygon') AND " +
+		"EXISTS (SELECT 1 FROM (" + geoShapePositionsSQL(b) + ") AS [[__p]] WHERE " + geoShapeContainsPointSQL(a, "[[__p.x]]", "[[__p.y]]") + "))"
+
+	bContainsA := "(json_extract(" + b + ", '$.type') IN ('Polygon', 'MultiPolygon') AND " +
+		"EXISTS (SELECT 1 FROM (" + geoShapePositionsSQL(a) + ") AS [[__p]] WHERE " + geoShapeContainsPointSQL(b, "[[__p.x]]", "[[__p.y]]") + "))"
+
+	return "(" + edgesIntersect + " OR " + aContainsB + " OR " + bContainsA + ")"
+}
@@ -0,0 +1,291 @@
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRecordGeoShapeFilters(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// "indexed" and "plain" hold the same values to ensure that
+	// the index prefilter doesn't change the filter results
+	collection := core.NewBaseCollection("geo_shape_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.GeoShapeField{Name: "indexed", SpatialIndex: true},
+		&core.GeoShapeField{Name: "plain"},
+		&core.GeoPointField{Name: "location", SpatialIndex: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	shapes := map[string]types.GeoShape{
+		"square": types.NewGeoPolygon(
+			[]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 10, Lat: 0}, {Lon: 10, Lat: 10}, {Lon: 0, Lat: 10}, {Lon: 0, Lat: 0}},
+			[]types.GeoPoint{{Lon: 4, Lat: 4}, {Lon: 6, Lat: 4}, {Lon: 6, Lat: 6}, {Lon: 4, Lat: 6}, {Lon: 4, Lat: 4}},
+		),
+		"triangles": types.NewGeoMultiPolygon(
+			[][]types.GeoPoint{{{Lon: 20, Lat: 0}, {Lon: 30, Lat: 0}, {Lon: 20, Lat: 10}, {Lon: 20, Lat: 0}}},
+			[][]types.GeoPoint{{{Lon: -20, Lat: 0}, {Lon: -30, Lat: 0}, {Lon: -20, Lat: -10}, {Lon: -20, Lat: 0}}},
+		),
+		"line":  types.NewGeoLineString(types.GeoPoint{Lon: -5, Lat: 5}, types.GeoPoint{Lon: 5, Lat: 5}, types.GeoPoint{Lon: 5, Lat: 15}),
+		"empty": {},
+	}
+
+	locations := map[string]types.GeoPoint{
+		"square":    {Lon: 2, Lat: 2},   // inside its own shape
+		"triangles": {Lon: 29, Lat: 9},  // outside its own shape but inside the bbox
+		"line":      {Lon: 5, Lat: 10},  // on its own line
+		"empty":     {Lon: 50, Lat: 50}, // no shape
+	}
+
+	for name, shape := range shapes {
+		record := core.NewRecord(collection)
+		record.Set("name", name)
+		record.Set("indexed", shape)
+		record.Set("plain", shape)
+		record.Set("location", locations[name])
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rtreeTable := "_rtree_" + collection.Id + "_" + collection.Fields.GetByName("indexed").GetId()
+	assertRTreeEntries(t, app, rtreeTable, 3) // zero shapes are not indexed
+
+	// expected names calculated with the equivalent Go predicates
+	expectedNames := func(match func(name string) bool) []string {
+		result := []string{}
+		for name := range shapes {
+			if match(name) {
+				result = append(result, name)
+			}
+		}
+		slices.Sort(result)
+		return result
+	}
+
+	type scenario struct {
+		filter   string
+		body     map[string]any
+		expected []string
+	}
+
+	scenarios := []scenario{
+		{
+			"geoContains(FIELD, location) = true",
+			nil,
+			expectedNames(func(name string) bool { return shapes[name].ContainsPoint(locations[name]) }),
+		},
+		{
+			"geoContains(FIELD, location) = false",
+			nil,
+			expectedNames(func(name string) bool { return !shapes[name].ContainsPoint(locations[name]) }),
+		},
+	}
+
+	points := []types.GeoPoint{
+		{Lon: 1, Lat: 1},
+		{Lon: 5, Lat: 5},
+		{Lon: 4.5, Lat: 5},
+		{Lon: 21, Lat: 1},
+		{Lon: -21, Lat: -1},
+		{Lon: 29, Lat: 9},
+		{Lon: 0, Lat: 5},
+		{Lon: 5, Lat: 12},
+		{Lon: 50, Lat: 50},
+	}
+	for _, p := range points {
+		expected := expectedNames(func(name string) bool { return shapes[name].ContainsPoint(p) })
+
+		scenarios = append(scenarios,
+			scenario{fmt.Sprintf("geoContains(FIELD, %v, %v) = true", p.Lon, p.Lat), nil, expected},
+			scenario{"geoContains(FIELD, @request.body.point) = true", map[string]any{"point": p.AsMap()}, expected},
+			scenario{"geoContains(FIELD, @request.body.lon, @request.body.lat) = true", map[string]any{"lon": p.Lon, "lat": p.Lat}, expected},
+		)
+	}
+
+	others := []types.GeoShape{
+		types.NewGeoLineString(types.GeoPoint{Lon: -40, Lat: 2}, types.GeoPoint{Lon: 40, Lat: 2}),
+		types.NewGeoLineString(types.GeoPoint{Lon: 4.5, Lat: 4.5}, types.GeoPoint{Lon: 5.5, Lat: 5.5}),
+		types.NewGeoPolygon([]types.GeoPoint{{Lon: 1, Lat: 1}, {Lon: 2, Lat: 1}, {Lon: 2, Lat: 2}, {Lon: 1, Lat: 1}}),
+		types.NewGeoPolygon([]types.GeoPoint{{Lon: -100, Lat: -50}, {Lon: 100, Lat: -50}, {Lon: 100, Lat: 50}, {Lon: -100, Lat: -50}}),
+		types.NewGeoPolygon([]types.GeoPoint{{Lon: 28, Lat: 8}, {Lon: 29, Lat: 8}, {Lon: 29, Lat: 9}, {Lon: 28, Lat: 8}}),
+	}
+	for _, other := range others {
+		expected := expectedNames(func(name string) bool { return shapes[name].Intersects(other) })
+
+		// simulate json request data
+		raw, err := json.Marshal(map[string]any{"shape": other})
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := map[string]any{}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Fatal(err)
+		}
+
+		scenarios = append(scenarios,
+			scenario{"geoIntersects(FIELD, @request.body.shape) = true", body, expected},
+			scenario{"geoIntersects(@request.body.shape, FIELD) = true", body, expected},
+		)
+	}
+
+	// fake request bbox shouldn't affect the result
+	scenarios = append(scenarios, scenario{
+		"geoIntersects(FIELD, @request.body.shape) = false",
+		map[string]any{"shape": map[string]any{
+			"type":        "LineString",
+			"coordinates": [][]float64{{1, 1}, {2, 2}},
+			"bbox":        []float64{100, 50, 101, 51},
+		}},
+		[]string{"empty", "line", "triangles"},
+	})
+
+	// invalid request data
+	scenarios = append(scenarios,
+		scenario{"geoContains(FIELD, @request.body.point) = true", map[string]any{"point": "invalid"}, []string{}},
+		scenario{"geoIntersects(FIELD, @request.body.shape) = true", map[string]any{"shape": "invalid"}, []string{}},
+		scenario{"geoIntersects(FIELD, @request.body.missing) = false", nil, []string{"empty", "line", "square", "triangles"}},
+	)
+
+	for i, s := range scenarios {
+		for _, field := range []string{"indexed", "plain"} {
+			filter := strings.ReplaceAll(s.filter, "FIELD", field)
+
+			t.Run(fmt.Sprintf("%d_%s", i, filter), func(t *testing.T) {
+				resolver := core.NewRecordFieldResolver(app, collection, &core.RequestInfo{Body: s.body}, false)
+
+				expr, err := search.FilterData(filter).BuildExpr(resolver)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				records := []*core.Record{}
+				query := app.RecordQuery(collection).AndWhere(expr).OrderBy("name ASC")
+				resolver.UpdateQuery(query)
+				if err := query.All(&records); err != nil {
+					t.Fatal(err)
+				}
+
+				names := make([]string, len(records))
+				for i, r := range records {
+					names[i] = r.GetString("name")
+				}
+
+				if !slices.Equal(names, s.expected) {
+					t.Fatalf("Expected names %v, got %v", s.expected, names)
+				}
+
+				// check whether the index prefilter was applied
+				rawSQL := expr.Build(app.ConcurrentDB().(*dbx.DB), dbx.Params{})
+				hasPrefilter := strings.Contains(rawSQL, rtreeTable)
+				expectPrefilter := field == "indexed" && !strings.Contains(filter, ") = false")
+				if hasPrefilter != expectPrefilter {
+					t.Fatalf("Expected hasPrefilter %v, got %v\n%s", expectPrefilter, hasPrefilter, rawSQL)
+				}
+			})
+		}
+	}
+
+	invalidFilters := []string{
+		"geoContains(indexed) = true",
+		"geoContains(indexed, 1, 2, 3) = true",
+		"geoContains(name, location) = true",
+		"geoContains(location, location) = true",
+		"geoContains(indexed, indexed) = true",
+		"geoContains(indexed, 'a', 1) = true",
+		"geoContains(missing, location) = true",
+		"geoIntersects(indexed) = true",
+		"geoIntersects(indexed, location) = true",
+		"geoIntersects('indexed', plain) = true",
+	}
+	for _, filter := range invalidFilters {
+		if _, err := app.FindRecordsByFilter(collection, filter, "", 0, 0); err == nil {
+			t.Fatalf("Expected %q to fail", filter)
+		}
+	}
+}
+
+func TestRecordGeoShapeAuthZoneRule(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users.Fields.Add(&core.GeoShapeField{Name: "zone"})
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail(users, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Set("zone", types.NewGeoPolygon([]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 10, Lat: 0}, {Lon: 10, Lat: 10}, {Lon: 0, Lat: 0}}))
+	if err := app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	demo, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rule := "geoContains(@request.auth.zone, @request.body.location) = true"
+
+	scenarios := []struct {
+		name     string
+		location map[string]any
+		expected bool
+	}{
+		{"inside zone", map[string]any{"lon": 8, "lat": 2}, true},
+		{"outside zone", map[string]any{"lon": 2, "lat": 8}, false},
+		{"missing location", nil, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			requestInfo := &core.RequestInfo{Auth: user, Body: map[string]any{}}
+			if s.location != nil {
+				requestInfo.Body["location"] = s.location
+			}
+
+			resolver := core.NewRecordFieldResolver(app, demo, requestInfo, false)
+
+			expr, err := search.FilterData(rule).BuildExpr(resolver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var total int
+			query := app.RecordQuery(demo).Select("count(*)").AndWhere(expr)
+			resolver.UpdateQuery(query)
+			if err := query.Row(&total); err != nil {
+				t.Fatal(err)
+			}
+
+			if (total > 0) != s.expected {
+				t.Fatalf("Expected %v, got %d matching records", s.expected, total)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/dbutils"
@@ -180,13 +181,18 @@ func (r *runner) processCollectionField() (*search.ResolverResult, error) {
 	r.withMultiMatch = true
 
 	// join the collection to the main query
-	r.resolver.registerJoin(inflector.Columnify(collection.Name), r.activeTableAlias, nil)
+	r.resolver.registerJoin(
+		inflector.Columnify(collection.Name),
+		r.activeTableAlias,
+		withActiveRecordsJoinExpr(collection, r.activeTableAlias, nil),
+	)
 
 	// join the collection to the multi-match subquery
 	r.multiMatchActiveTableAlias = "__mm" + r.activeTableAlias
 	r.multiMatch.joins = append(r.multiMatch.joins, &join{
 		tableName:  inflector.Columnify(collection.Name),
 		tableAlias: r.multiMatchActiveTableAlias,
+		on:         withActiveRecordsJoinExpr(collection, r.multiMatchActiveTableAlias, nil),
 	})
 
 	// leave only the collection fields
@@ -218,10 +224,10 @@ func (r *runner) processRequestAuthField() (*search.ResolverResult, error) {
 	r.resolver.registerJoin(
 		inflector.Columnify(r.activeCollectionName),
 		r.activeTableAlias,
-		dbx.HashExp{
+		withActiveRecordsJoinExpr(collection, r.activeTableAlias, dbx.HashExp{
 			// aka. __auth_users.id = :userId
 			(r.activeTableAlias + ".id"): r.resolver.requestInfo.Auth.Id,
-		},
+		}),
 	)
 
 	// join the auth collection to the multi-match subquery
@@ -231,9 +237,9 @@ func (r *runner) processRequestAuthField() (*search.ResolverResult, error) {
 		&join{
 			tableName:  inflector.Columnify(r.activeCollectionName),
 			tableAlias: r.multiMatchActiveTableAlias,
-			on: dbx.HashExp{
+			on: withActiveRecordsJoinExpr(collection, r.multiMatchActiveTableAlias, dbx.HashExp{
 				(r.multiMatchActiveTableAlias + ".id"): r.resolver.requestInfo.Auth.Id,
-			},
+			}),
 		},
 	)
 
@@ -244,6 +250,40 @@ func (r *runner) processRequestAuthField() (*search.ResolverResult, error) {
 	return r.processActiveProps()
 }
 
+// withActiveRecordsJoinExpr extends the join "on" expression of the
+// provided collection table with the soft deleted and expired records
+// exclusion conditions (if the collection has soft delete or TTL enabled).
+//
+// on could be nil.
+func withActiveRecordsJoinExpr(collection *Collection, tableAlias string, on dbx.Expression) dbx.Expression {
+	if collection == nil || collection.IsView() {
+		return on
+	}
+
+	exprs := make([]dbx.Expression, 0, 3)
+
+	if on != nil {
+		exprs = append(exprs, on)
+	}
+
+	if collection.SoftDelete {
+		exprs = append(exprs, dbx.NewExp(fmt.Sprintf("[[%s.%s]] = ''", tableAlias, FieldNameDeleted)))
+	}
+
+	if collection.HasTTL() {
+		exprs = append(exprs, notExpiredRecordsExpr(collection, tableAlias, time.Now()))
+	}
+
+	switch len(exprs) {
+	case 0:
+		return nil
+	case 1:
+		return exprs[0]
+	default:
+		return dbx.And(exprs...)
+	}
+}
+
 // note: nil value is returned as empty slice
 func toSlice(value any) []any {
 	if value == nil {
@@ -419,9 +459,9 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			return nil, fmt.Errorf("non-filterable field %q", prop)
 		}
 
-		// json or geoPoint field -> treat the rest of the props as json path
+		// json, geoPoint or geoShape field -> treat the rest of the props as json path
 		// @todo consider converting to "JSONExtractable" interface with optional extra validation for the remaining props?
-		if field != nil && (field.Type() == FieldTypeJSON || field.Type() == FieldTypeGeoPoint) {
+		if field != nil && (field.Type() == FieldTypeJSON || field.Type() == FieldTypeGeoPoint || field.Type() == FieldTypeGeoShape) {
 			var jsonPath strings.Builder
 			for j, p := range r.activeProps[i+1:] {
 				if _, err := strconv.Atoi(p); err == nil {
@@ -522,20 +562,25 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 				r.resolver.registerJoin(
 					newCollectionName,
 					newTableAlias,
-					dbx.NewExp(fmt.Sprintf("[[%s.%s]] = [[%s.id]]", newTableAlias, cleanBackFieldName, r.activeTableAlias)),
+					withActiveRecordsJoinExpr(backCollection, newTableAlias, dbx.NewExp(fmt.Sprintf(
+						"[[%s.%s]] = [[%s.id]]",
+						newTableAlias,
+						cleanBackFieldName,
+						r.activeTableAlias,
+					))),
 				)
 			} else {
 				jeAlias := r.activeTableAlias + "_" + cleanProp + "_je"
 				r.resolver.registerJoin(
 					newCollectionName,
 					newTableAlias,
-					dbx.NewExp(fmt.Sprintf(
+					withActiveRecordsJoinExpr(backCollection, newTableAlias, dbx.NewExp(fmt.Sprintf(
 						"[[%s.id]] IN (SELECT [[%s.value]] FROM %s {{%s}})",
 						r.activeTableAlias,
 						jeAlias,
 						dbutils.JSONEach(newTableAlias+"."+cleanBackFieldName),
 						jeAlias,
-					)),
+					))),
 				)
 			}
 
@@ -557,7 +602,12 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 					&join{
 						tableName:  newCollectionName,
 						tableAlias: newTableAlias2,
-						on:         dbx.NewExp(fmt.Sprintf("[[%s.%s]] = [[%s.id]]", newTableAlias2, cleanBackFieldName, r.multiMatchActiveTableAlias)),
+						on: withActiveRecordsJoinExpr(backCollection, newTableAlias2, dbx.NewExp(fmt.Sprintf(
+							"[[%s.%s]] = [[%s.id]]",
+							newTableAlias2,
+							cleanBackFieldName,
+							r.multiMatchActiveTableAlias,
+						))),
 					},
 				)
 			} else {
@@ -567,13 +617,13 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 					&join{
 						tableName:  newCollectionName,
 						tableAlias: newTableAlias2,
-						on: dbx.NewExp(fmt.Sprintf(
+						on: withActiveRecordsJoinExpr(backCollection, newTableAlias2, dbx.NewExp(fmt.Sprintf(
 							"[[%s.id]] IN (SELECT [[%s.value]] FROM %s {{%s}})",
 							r.multiMatchActiveTableAlias,
 							jeAlias2,
 							dbutils.JSONEach(newTableAlias2+"."+cleanBackFieldName),
 							jeAlias2,
-						)),
+						))),
 					},
 				)
 			}
@@ -604,7 +654,8 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 
 		// "id" lookups optimization for single relations to avoid unnecessary joins,
 		// aka. "user.id" and "user" should produce the same query identifier
-		if !relField.IsMultiple() &&
+		// (skipped for soft delete and TTL collections because the relation could point to a soft deleted or expired record)
+		if !relField.IsMultiple() && !relCollection.SoftDelete && !relCollection.HasTTL() &&
 			// the penultimate prop is "id"
 			i == totalProps-2 && r.activeProps[i+1] == FieldNameId {
 			return r.processLastProp(collection, relField.Name)
@@ -619,7 +670,7 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			r.resolver.registerJoin(
 				inflector.Columnify(newCollectionName),
 				newTableAlias,
-				dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s]]", newTableAlias, prefixedFieldName)),
+				withActiveRecordsJoinExpr(relCollection, newTableAlias, dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s]]", newTableAlias, prefixedFieldName))),
 			)
 		} else {
 			jeAlias := r.activeTableAlias + "_" + cleanFieldName + "_je"
@@ -627,7 +678,7 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 			r.resolver.registerJoin(
 				inflector.Columnify(newCollectionName),
 				newTableAlias,
-				dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s.value]]", newTableAlias, jeAlias)),
+				withActiveRecordsJoinExpr(relCollection, newTableAlias, dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s.value]]", newTableAlias, jeAlias))),
 			)
 		}
 
@@ -650,7 +701,7 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 				&join{
 					tableName:  inflector.Columnify(newCollectionName),
 					tableAlias: newTableAlias2,
-					on:         dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s]]", newTableAlias2, prefixedFieldName2)),
+					on:         withActiveRecordsJoinExpr(relCollection, newTableAlias2, dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s]]", newTableAlias2, prefixedFieldName2))),
 				},
 			)
 		} else {
@@ -664,7 +715,7 @@ func (r *runner) processActiveProps() (*search.ResolverResult, error) {
 				&join{
 					tableName:  inflector.Columnify(newCollectionName),
 					tableAlias: newTableAlias2,
-					on:         dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s.value]]", newTableAlias2, jeAlias2)),
+					on:         withActiveRecordsJoinExpr(relCollection, newTableAlias2, dbx.NewExp(fmt.Sprintf("[[%s.id]] = [[%s.value]]", newTableAlias2, jeAlias2))),
 				},
 			)
 		}
@@ -694,6 +745,12 @@ func (r *runner) processLastProp(collection *Collection, prop string) (*search.R
 		return nil, fmt.Errorf("non-filterable field %q", name)
 	}
 
+	// encrypted fields (blind index equality comparisons only)
+	// -------------------------------------------------------
+	if encryptedField, ok := field.(*EncryptedField); ok {
+		return r.processEncryptedField(encryptedField, modifier)
+	}
+
 	multvaluer, isMultivaluer := field.(MultiValuer)
 
 	cleanFieldName := inflector.Columnify(field.GetName())
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// vectorDistanceFunction is the name of the filter and sort function for
+// calculating the distance between a vector field and another vector, eg.:
+//
+//	vectorDistance(embedding, @request.query.q_vec)
+//	vectorDistance(embedding, @request.query.q_vec, 'l2') < 0.5
+const vectorDistanceFunction = "vectorDistance"
+
+// resolveVectorDistance resolves the vectorDistance(field, vector, metric?) function.
+//
+// The field must be a vector field (including relation paths, eg. "doc.embedding")
+// and the vector must be a @request.* identifier (json array or comma separated
+// list of numbers) or another vector field.
+// The optional metric must be one of [types.VectorMetrics] (default to cosine).
+//
+// The result doesn't have any bound params so it could be used also as sort expression.
+//
+// If the field is a vector indexed field of the base collection, the upper bound
+// comparisons (eg. "< 0.5") are additionally prefiltered with the IVF index.
+func (r *RecordFieldResolver) resolveVectorDistance(
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("[%s] expected 2 or 3 arguments, got %d", vectorDistanceFunction, len(args))
+	}
+
+	if args[0].Type != fexpr.TokenIdentifier || strings.HasPrefix(args[0].Literal, "@") {
+		return nil, fmt.Errorf("[%s] the first argument must be a vector field", vectorDistanceFunction)
+	}
+
+	field, _ := findRecordFieldByPath(r.app, r.baseCollection, args[0].Literal).(*VectorField)
+	if field == nil {
+		return nil, fmt.Errorf("[%s] %q is not a vector field", vectorDistanceFunction, args[0].Literal)
+	}
+
+	fieldResult, err := argTokenResolverFunc(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve %q: %w", vectorDistanceFunction, args[0].Literal, err)
+	}
+
+	if fieldResult.MultiMatchSubQuery != nil || len(fieldResult.Params) > 0 {
+		return nil, fmt.Errorf("[%s] %q must be a single vector field", vectorDistanceFunction, args[0].Literal)
+	}
+
+	metric := types.VectorMetricCosine
+	if len(args) == 3 {
+		if args[2].Type != fexpr.TokenText || !slices.Contains(types.VectorMetrics, args[2].Literal) {
+			return nil, fmt.Errorf("[%s] the metric must be one of %v", vectorDistanceFunction, types.VectorMetrics)
+		}
+		metric = args[2].Literal
+	}
+
+	if args[1].Type != fexpr.TokenIdentifier {
+		return nil, fmt.Errorf("[%s] the second argument must be a vector field or @request.* identifier", vectorDistanceFunction)
+	}
+
+	queryResult, err := argTokenResolverFunc(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve %q: %w", vectorDistanceFunction, args[1].Literal, err)
+	}
+
+	if queryResult.MultiMatchSubQuery != nil {
+		return nil, fmt.Errorf("[%s] %q must be a single vector value", vectorDistanceFunction, args[1].Literal)
+	}
+
+	var queryExpr string
+	var queryVector types.Vector
+
+	if strings.HasPrefix(args[1].Literal, "@") {
+		// static request value
+		// (inlined as blob literal so that the expression is sortable)
+		raw, ok := staticResolverResultValue(queryResult)
+		if !ok {
+			return nil, fmt.Errorf("[%s] %q must be a plain vector value", vectorDistanceFunction, args[1].Literal)
+		}
+
+		if raw != nil {
+			queryVector, err = types.ParseVector(cast.ToString(raw))
+			if err != nil || !queryVector.IsFinite() {
+				return nil, fmt.Errorf("[%s] invalid %q vector value", vectorDistanceFunction, args[1].Literal)
+			}
+
+			if len(queryVector) > 0 && len(queryVector) != field.Dimensions {
+				return nil, fmt.Errorf(
+					"[%s] %q must have exactly %d elements, got %d",
+					vectorDistanceFunction, args[1].Literal, field.Dimensions, len(queryVector),
+				)
+			}
+		}
+
+		if len(queryVector) == 0 {
+			queryExpr = "NULL"
+		} else {
+			queryExpr = "X'" + hex.EncodeToString(queryVector.Bytes()) + "'"
+		}
+	} else {
+		other, _ := findRecordFieldByPath(r.app, r.baseCollection, args[1].Literal).(*VectorField)
+		if other == nil || len(queryResult.Params) > 0 {
+			return nil, fmt.Errorf("[%s] %q is not a vector field", vectorDistanceFunction, args[1].Literal)
+		}
+
+		queryExpr = queryResult.Identifier
+	}
+
+	result := &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: fmt.Sprintf("%s(%s, %s, '%s')", VectorDistanceSQLFunction, fieldResult.Identifier, queryExpr, metric),
+	}
+
+	if len(queryVector) > 0 &&
+		field.Index &&
+		!r.baseCollection.IsView() &&
+		r.baseCollection.Fields.GetByName(args[0].Literal) == field {
+		prefilter, err := r.vectorPrefilterExpr(field, queryVector)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] failed to load the %q index: %w", vectorDistanceFunction, field.Name, err)
+		}
+
+		if prefilter != nil {
+			result.AfterCompare = func(expr dbx.Expression, op fexpr.SignOp, other *search.ResolverResult, isRight bool) (dbx.Expression, error) {
+				if !isUpperBoundComparison(op, isRight) {
+					return expr, nil
+				}
+
+				return dbx.And(expr, prefilter), nil
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// vectorPrefilterExpr returns the IVF index prefilter expression of the
+// vector indexed base collection field, aka. limiting the records to
+// the ones from the index lists nearest to the query vector.
+//
+// Returns nil if the index is not trained yet.
+func (r *RecordFieldResolver) vectorPrefilterExpr(field *VectorField, queryVector types.Vector) (dbx.Expression, error) {
+	idx, err := loadRecordVectorIndex(r.app, r.baseCollection, field)
+	if err != nil {
+		return nil, err
+	}
+
+	probes := field.Probes
+	if probes <= 0 {
+		probes = idx.defaultProbes()
+	}
+
+	lists := idx.probe(queryVector, probes)
+	if len(lists) == 0 {
+		return nil, nil
+	}
+
+	listIds := make([]string, len(lists))
+	for i, l := range lists {
+		listIds[i] = strconv.Itoa(l)
+	}
+
+	return dbx.NewExp(fmt.Sprintf(
+		"[[%s.id]] IN (SELECT [[id]] FROM {{%s}} WHERE [[list]] IN (%s))",
+		inflector.Columnify(r.baseCollection.Name),
+		recordVectorIndexTableName(r.baseCollection, field),
+		strings.Join(listIds, ","),
+	)), nil
+}
+
+// staticResolverResultValue returns the bound value of a single
+// plain placeholder resolver result (eg. "{:abc}").
+//
+// Returns nil and true for "NULL" results (eg. missing @request.* field).
+func staticResolverResultValue(result *search.ResolverResult) (any, bool) {
+	if result.Identifier == "NULL" && len(result.Params) == 0 {
+		return nil, true
+	}
+
+	if len(result.Params) != 1 || !strings.HasPrefix(result.Identifier, "{:") || !strings.HasSuffix(result.Identifier, "}") {
+		return nil, false
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(result.Identifier, "{:"), "}")
+
+	v, ok := result.Params[name]
+
+	return v, ok
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"golang.org/x/net/html"
+)
+
+// recordFTSTablePrefix is the name prefix of the FTS5 shadow tables
+// that holds the full-text index of the searchable record fields.
+const recordFTSTablePrefix = "_fts_"
+
+// recordFTSTable manages the FTS5 shadow tables of the searchable fields.
+//
+// Each table row holds the plain searchable text of a single record
+// field value (see [FullTextSearcher]), keyed by the record id rowid hash.
+var recordFTSTable = &recordShadowTable{
+	prefix:    recordFTSTablePrefix,
+	label:     "full-text index",
+	isIndexed: isSearchableField,
+	schema: func(tableName string) []string {
+		return []string{
+			"CREATE VIRTUAL TABLE IF NOT EXISTS {{" + tableName + "}} USING fts5([[id]] UNINDEXED, [[value]], tokenize='unicode61 remove_diacritics 2')",
+		}
+	},
+	upsert: upsertRecordFTSEntry,
+	delete: deleteRecordFTSEntry,
+	changed: func(record *Record, field Field) bool {
+		return record.Original().GetRaw(field.GetName()) != record.GetRaw(field.GetName())
+	},
+}
+
+// recordFTSTableName returns the name of the FTS5 shadow table
+// queried by the full-text search filter of the specified collection field.
+func recordFTSTableName(collection *Collection, field Field) string {
+	return recordShadowTableName(recordFTSTablePrefix, collection, field)
+}
+
+// isSearchableField reports whether the provided field has an enabled full-text index.
+func isSearchableField(field Field) bool {
+	s, ok := field.(FullTextSearcher)
+	return ok && s.IsSearchable()
+}
+
+// upsertRecordFTSEntry replaces the full-text index entry of the provided record field.
+//
+// Fields with empty searchable text are not indexed.
+func upsertRecordFTSEntry(app App, record *Record, field Field) error {
+	if err := deleteRecordFTSEntry(app, record, field); err != nil {
+		return err
+	}
+
+	s, ok := field.(FullTextSearcher)
+	if !ok {
+		return nil
+	}
+
+	text := strings.TrimSpace(s.SearchableText(record))
+	if text == "" {
+		return nil // nothing to index
+	}
+
+	_, err := app.DB().Insert(recordFTSTableName(record.Collection(), field), dbx.Params{
+		"rowid": recordIndexRowid(record.Id),
+		"id":    record.Id,
+		"value": text,
+	}).Execute()
+
+	return err
+}
+
+// deleteRecordFTSEntry removes the full-text index entry of the provided record field (if any).
+func deleteRecordFTSEntry(app App, record *Record, field Field) error {
+	_, err := app.DB().Delete(
+		recordFTSTableName(record.Collection(), field),
+		dbx.HashExp{"rowid": recordIndexRowid(record.Id)},
+	).Execute()
+
+	return err
+}
+
+// inlineHTMLTags lists the html elements that are not separated
+// with whitespace from their surrounding text when stripping the html tags.
+var inlineHTMLTags = map[string]struct{}{
+	"a": {}, "abbr": {}, "b": {}, "bdi": {}, "bdo": {}, "cite": {}, "code": {},
+	"data": {}, "dfn": {}, "em": {}, "i": {}, "kbd": {}, "mark": {}, "q": {},
+	"s": {}, "samp": {}, "small": {}, "span": {}, "strong": {}, "sub": {},
+	"sup": {}, "time": {}, "u": {}, "var": {},
+}
+
+// stripHTML returns the plain text content of the provided html string.
+//
+// The tags are removed, the entities are decoded and the block elements
+// are separated with a single whitespace, eg. "<p>a&amp;b</p><p>c</p>" -> "a&b c".
+func stripHTML(str string) string {
+	var sb strings.Builder
+
+	var skip string // eg. script or style
+
+	tokenizer := html.NewTokenizer(strings.NewReader(str))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(sb.String()), " ")
+		case html.TextToken:
+			if skip == "" {
+				// note: the text entities are already decoded by the tokenizer
+				sb.Write(tokenizer.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+
+			if skip != "" {
+				if tag == skip {
+					skip = ""
+				}
+				continue
+			}
+
+			if tag == "script" || tag == "style" {
+				skip = tag
+				continue
+			}
+
+			if _, ok := inlineHTMLTags[tag]; !ok {
+				sb.WriteString(" ")
+			}
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package core_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func TestRecordFTSSync(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	titleField := collection.Fields.GetByName("title").(*core.TextField)
+	ftsTable := "_fts_" + collection.Id + "_" + titleField.Id
+
+	if app.HasTable(ftsTable) {
+		t.Fatalf("Expected %q to not exist", ftsTable)
+	}
+
+	if _, err := app.FindRecordsByFilter(collection, "ftsMatch(title, 'test1') = true", "", 0, 0); err == nil {
+		t.Fatal("Expected ftsMatch to fail for non-searchable field")
+	}
+
+	// enable and index the existing records
+	titleField.Searchable = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.HasTable(ftsTable) {
+		t.Fatalf("Expected %q to be created", ftsTable)
+	}
+
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'test1') = true", "test1")
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'test*') = true && active = true", "test2", "test3")
+
+	// create
+	record := core.NewRecord(collection)
+	record.Set("title", "Hello Wörld")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'world') = true", "Hello Wörld")
+
+	// update
+	record.Set("title", "Hello Gopher")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'world') = true")
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'gopher') = true", "Hello Gopher")
+
+	// update without title change
+	record.Set("active", true)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'gopher') = true", "Hello Gopher")
+
+	// delete
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'hello') = true")
+
+	// disable
+	titleField.Searchable = false
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(ftsTable) {
+		t.Fatalf("Expected %q to be deleted", ftsTable)
+	}
+
+	// reenable and remove the field
+	titleField.Searchable = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !app.HasTable(ftsTable) {
+		t.Fatalf("Expected %q to be recreated", ftsTable)
+	}
+	assertFTSMatches(t, app, collection, "ftsMatch(title, 'test1 OR test2') = true", "test1", "test2")
+
+	collection.RemoveIndex("idx_unique_demo2_title")
+	collection.Fields.RemoveById(titleField.Id)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(ftsTable) {
+		t.Fatalf("Expected %q to be deleted after the field removal", ftsTable)
+	}
+}
+
+func TestRecordFTSEditorAndRank(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("fts_test")
+	collection.ListRule = nil
+	collection.Fields.Add(
+		&core.TextField{Name: "title", Searchable: true},
+		&core.EditorField{Name: "content", Searchable: true},
+		&core.EditorField{Name: "secret", Searchable: true, Hidden: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []map[string]any{
+		{"title": "a", "content": `<p>Go&nbsp;and <strong>SQ</strong>Lite</p><p>full-text</p><script>hidden()</script>`},
+		{"title": "b", "content": `<ul><li>go</li><li>go</li><li>go</li></ul>`},
+		{"title": "c", "content": `<p>go with a lot of other words that make it less relevant</p>`},
+		{"title": "d", "content": `<p>unrelated</p>`, "secret": "<p>go</p>"},
+	}
+	for _, d := range data {
+		record := core.NewRecord(collection)
+		record.Load(d)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertFTSMatches(t, app, collection, "ftsMatch(content, 'sqlite') = true", "a")
+	assertFTSMatches(t, app, collection, "ftsMatch(content, 'full') = true", "a")
+	assertFTSMatches(t, app, collection, "ftsMatch(content, 'hidden OR script OR strong OR nbsp') = true")
+	assertFTSMatches(t, app, collection, "ftsMatch(content, 'go AND sqlite') = true || ftsMatch(title, 'd') = true", "a", "d")
+
+	scenarios := []struct {
+		name              string
+		filter            string
+		sort              string
+		allowHiddenFields bool
+		expectError       bool
+		expected          []string
+	}{
+		{
+			"@rank without ftsMatch",
+			"",
+			"@rank",
+			false,
+			true,
+			nil,
+		},
+		{
+			"ftsMatch with invalid arguments count",
+			"ftsMatch(content) = true",
+			"",
+			false,
+			true,
+			nil,
+		},
+		{
+			"ftsMatch with non-identifier field argument",
+			"ftsMatch('content', 'go') = true",
+			"",
+			false,
+			true,
+			nil,
+		},
+		{
+			"ftsMatch with unknown field",
+			"ftsMatch(missing, 'go') = true",
+			"",
+			false,
+			true,
+			nil,
+		},
+		{
+			"ftsMatch with hidden field",
+			"ftsMatch(secret, 'go') = true",
+			"",
+			false,
+			true,
+			nil,
+		},
+		{
+			"ftsMatch with hidden field (allowHiddenFields)",
+			"ftsMatch(secret, 'go') = true",
+			"",
+			true,
+			false,
+			[]string{"d"},
+		},
+		{
+			"sort by @rank",
+			"ftsMatch(content, 'go') = true",
+			"@rank",
+			false,
+			false,
+			[]string{"b", "a", "c"},
+		},
+		{
+			"sort by -@rank",
+			"ftsMatch(content, 'go') = true",
+			"-@rank,title",
+			false,
+			false,
+			[]string{"c", "a", "b"},
+		},
+		{
+			"sort by @rank with multiple matches",
+			"ftsMatch(content, 'go') = true || ftsMatch(title, 'd') = true",
+			"@rank,title",
+			false,
+			false,
+			[]string{"d", "b", "a", "c"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			resolver := core.NewRecordFieldResolver(app, collection, nil, s.allowHiddenFields)
+
+			records := []*core.Record{}
+
+			provider := search.NewProvider(resolver).Query(app.RecordQuery(collection))
+			if err := provider.Parse("sort=" + s.sort); err != nil {
+				t.Fatal(err)
+			}
+			if s.filter != "" {
+				provider.AddFilter(search.FilterData(s.filter))
+			}
+
+			_, err := provider.Exec(&records)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			titles := make([]string, len(records))
+			for i, r := range records {
+				titles[i] = r.GetString("title")
+			}
+
+			if !slices.Equal(titles, s.expected) {
+				t.Fatalf("Expected titles %v, got %v", s.expected, titles)
+			}
+		})
+	}
+
+	// delete the collection
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range collection.Fields {
+		table := "_fts_" + collection.Id + "_" + f.GetId()
+		if app.HasTable(table) {
+			t.Fatalf("Expected %q to be deleted", table)
+		}
+	}
+}
+
+func assertFTSMatches(t *testing.T, app core.App, collection *core.Collection, filter string, expectedTitles ...string) {
+	t.Helper()
+
+	records, err := app.FindRecordsByFilter(collection, filter, "title", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to find records for filter %q: %v", filter, err)
+	}
+
+	titles := make([]string, len(records))
+	for i, r := range records {
+		titles[i] = r.GetString("title")
+	}
+
+	if len(titles) != len(expectedTitles) {
+		t.Fatalf("[%s] Expected titles %v, got %v", filter, expectedTitles, titles)
+	}
+
+	for _, title := range expectedTitles {
+		if !slices.Contains(titles, title) {
+			t.Fatalf("[%s] Missing title %q in %v", filter, title, strings.Join(titles, ", "))
+		}
+	}
+}
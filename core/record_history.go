@@ -0,0 +1,234 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+const systemHookIdRecordHistory = "__pbRecordHistorySystemHook__"
+
+type recordHistoryActorCtxKey struct{}
+
+// RecordHistoryActor defines the actor details that are stored
+// together with the record history entry.
+type RecordHistoryActor struct {
+	// Auth is the auth record that performed the change (could be nil).
+	Auth *Record
+
+	// IP is the request client ip (could be empty).
+	IP string
+}
+
+// WithRecordHistoryActor returns a copy of ctx that carries the
+// provided record history actor.
+//
+// Pass the returned context to [App.SaveWithContext] or [App.DeleteWithContext]
+// to associate the record change with the actor in the collection history.
+func WithRecordHistoryActor(ctx context.Context, actor RecordHistoryActor) context.Context {
+	return context.WithValue(ctx, recordHistoryActorCtxKey{}, actor)
+}
+
+// RecordHistoryActorFromContext returns the record history actor
+// associated with ctx (if any).
+func RecordHistoryActorFromContext(ctx context.Context) (RecordHistoryActor, bool) {
+	if ctx == nil {
+		return RecordHistoryActor{}, false
+	}
+
+	actor, ok := ctx.Value(recordHistoryActorCtxKey{}).(RecordHistoryActor)
+
+	return actor, ok
+}
+
+func (app *BaseApp) registerRecordHistoryHooks() {
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Id: systemHookIdRecordHistory,
+		Func: func(e *RecordEvent) error {
+			return recordHistoryExecute(e, RecordHistoryActionCreate)
+		},
+		Priority: 98, // execute right before the system record db write handler
+	})
+
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Id: systemHookIdRecordHistory,
+		Func: func(e *RecordEvent) error {
+			return recordHistoryExecute(e, RecordHistoryActionUpdate)
+		},
+		Priority: 98,
+	})
+
+	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
+		Id: systemHookIdRecordHistory,
+		Func: func(e *RecordEvent) error {
+			return recordHistoryExecute(e, RecordHistoryActionDelete)
+		},
+		Priority: 98,
+	})
+}
+
+// recordHistoryExecute wraps the record db write and the new history
+// entry insert in a single transaction.
+func recordHistoryExecute(e *RecordEvent, action string) error {
+	collection := e.Record.Collection()
+	if !collection.History || collection.IsView() {
+		return e.Next()
+	}
+
+	entry := &RecordHistory{
+		Created:       types.NowDateTime(),
+		CollectionRef: collection.Id,
+		Action:        action,
+		Changes:       types.JSONArray[string]{},
+	}
+	entry.Id = GenerateDefaultRandomId()
+
+	if actor, ok := RecordHistoryActorFromContext(e.Context); ok {
+		entry.IP = actor.IP
+		if actor.Auth != nil {
+			entry.AuthCollectionRef = actor.Auth.Collection().Id
+			entry.AuthRef = actor.Auth.Id
+		}
+	}
+
+	originalApp := e.App
+	txErr := e.App.RunInTransaction(func(txApp App) error {
+		e.App = txApp
+
+		// compare with the last persisted record state
+		// (similar to the rule fields check of the submitted values)
+		switch action {
+		case RecordHistoryActionCreate:
+			entry.Changes = recordChangedFields(NewRecord(collection), e.Record)
+		case RecordHistoryActionUpdate:
+			lastSaved, err := findRecordByIdWithDeleted(txApp, collection, cast.ToString(e.Record.LastSavedPK()))
+			if err != nil {
+				return err
+			}
+			entry.Changes = recordChangedFields(lastSaved, e.Record)
+		}
+
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		// the snapshot is taken after the db write to include
+		// the field values normalized by the field interceptors
+		entry.RecordRef = e.Record.Id
+		entry.Data = recordHistorySnapshot(e.Record)
+
+		var lastVersion sql.NullInt64
+		err := txApp.RecordHistoryQuery().
+			Select("MAX([[version]])").
+			AndWhere(dbx.HashExp{
+				"collectionRef": entry.CollectionRef,
+				"recordRef":     entry.RecordRef,
+			}).
+			Row(&lastVersion)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the last record history version: %w", err)
+		}
+		entry.Version = int(lastVersion.Int64) + 1
+
+		if err := txApp.SaveNoValidate(entry); err != nil {
+			return fmt.Errorf("failed to save the record history entry: %w", err)
+		}
+
+		return nil
+	})
+	e.App = originalApp
+
+	return txErr
+}
+
+// recordHistorySnapshot returns the record fields data excluding
+// the password and encrypted fields and the auth token key.
+func recordHistorySnapshot(record *Record) types.JSONMap[any] {
+	result := types.JSONMap[any]{}
+
+	for _, f := range record.Collection().Fields {
+		if f.Type() == FieldTypePassword || f.Type() == FieldTypeEncrypted || f.GetName() == FieldNameTokenKey {
+			continue
+		}
+
+		result[f.GetName()] = record.Get(f.GetName())
+	}
+
+	return result
+}
+
+// recordChangedFields returns the names of the record fields whose
+// normalized value is different from the old record state.
+//
+// The id and the autodate fields are ignored.
+func recordChangedFields(old *Record, record *Record) types.JSONArray[string] {
+	result := types.JSONArray[string]{}
+
+	for _, f := range record.Collection().Fields {
+		name := f.GetName()
+		if name == FieldNameId || f.Type() == FieldTypeAutodate {
+			continue
+		}
+
+		if !recordHistoryValuesEqual(old.Get(name), record.Get(name)) {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
+
+// RestoreRecordHistory restores the record fields to the state
+// of the provided history entry snapshot and saves it.
+//
+// If the record was deleted, it is recreated with the same id
+// (auth records are created with a random password).
+// Soft deleted records are updated in place.
+//
+// Note that the password, encrypted, file and autodate fields are not restored
+// because their values are either not part of the snapshot or could be no longer valid.
+func (app *BaseApp) RestoreRecordHistory(ctx context.Context, entry *RecordHistory) (*Record, error) {
+	collection, err := app.FindCachedCollectionByNameOrId(entry.CollectionRef)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := findRecordByIdWithDeleted(app, collection, entry.RecordRef)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		record = NewRecord(collection)
+		record.Id = entry.RecordRef
+		if collection.IsAuth() {
+			record.SetRandomPassword()
+		}
+	}
+
+	skipTypes := []string{FieldTypePassword, FieldTypeEncrypted, FieldTypeFile, FieldTypeAutodate}
+
+	for _, f := range collection.Fields {
+		name := f.GetName()
+		if name == FieldNameId || name == FieldNameTokenKey || slices.Contains(skipTypes, f.Type()) {
+			continue
+		}
+
+		if v, ok := entry.Data[name]; ok {
+			record.Set(name, v)
+		}
+	}
+
+	if err := app.SaveWithContext(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ Model = (*RecordHistory)(nil)
+)
+
+const RecordHistoryTableName = "_recordHistory"
+
+// The available RecordHistory.Action values.
+const (
+	RecordHistoryActionCreate = "create"
+	RecordHistoryActionUpdate = "update"
+	RecordHistoryActionDelete = "delete"
+)
+
+// RecordHistory defines a single record version history entry.
+//
+// Data holds the record fields snapshot right after the create or update
+// action and right before the delete action (the password and tokenKey
+// fields are excluded from the snapshot).
+//
+// Changes holds the names of the fields whose value was changed
+// compared to the previous record state (it is empty for the delete action).
+type RecordHistory struct {
+	BaseModel
+
+	Created           types.DateTime          `db:"created" json:"created"`
+	Data              types.JSONMap[any]      `db:"data" json:"data"`
+	Changes           types.JSONArray[string] `db:"changes" json:"changes"`
+	CollectionRef     string                  `db:"collectionRef" json:"collectionRef"`
+	RecordRef         string                  `db:"recordRef" json:"recordRef"`
+	Action            string                  `db:"action" json:"action"`
+	AuthCollectionRef string                  `db:"authCollectionRef" json:"authCollectionRef"`
+	AuthRef           string                  `db:"authRef" json:"authRef"`
+	IP                string                  `db:"ip" json:"ip"`
+	Version           int                     `db:"version" json:"version"`
+}
+
+func (m *RecordHistory) TableName() string {
+	return RecordHistoryTableName
+}
+
+// RecordHistoryChange defines a single field value change between 2 record history versions.
+type RecordHistoryChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Diff returns the field values that are different between the
+// current and the other (aka. newer) record history entry snapshots.
+func (m *RecordHistory) Diff(other *RecordHistory) map[string]RecordHistoryChange {
+	result := map[string]RecordHistoryChange{}
+
+	keys := make([]string, 0, len(m.Data)+len(other.Data))
+	for k := range m.Data {
+		keys = append(keys, k)
+	}
+	for k := range other.Data {
+		if _, ok := m.Data[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		from := m.Data[k]
+		to := other.Data[k]
+
+		if !recordHistoryValuesEqual(from, to) {
+			result[k] = RecordHistoryChange{From: from, To: to}
+		}
+	}
+
+	return result
+}
+
+// recordHistoryValuesEqual loosely checks whether the serialized
+// representation of the 2 values is the same.
+func recordHistoryValuesEqual(a, b any) bool {
+	rawA, errA := json.Marshal(a)
+	rawB, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && string(rawA) == string(rawB)
+}
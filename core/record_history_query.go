@@ -0,0 +1,72 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// RecordHistoryQuery returns a new RecordHistory select query.
+func (app *BaseApp) RecordHistoryQuery() *dbx.SelectQuery {
+	return app.ModelQuery(&RecordHistory{})
+}
+
+// FindRecordHistory returns all version history entries of a single
+// collection record ordered by their version (oldest first).
+func (app *BaseApp) FindRecordHistory(collectionModelOrIdentifier any, recordId string) ([]*RecordHistory, error) {
+	collection, err := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*RecordHistory{}
+
+	err = app.RecordHistoryQuery().
+		AndWhere(dbx.HashExp{
+			"collectionRef": collection.Id,
+			"recordRef":     recordId,
+		}).
+		OrderBy("version ASC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindRecordHistoryVersion finds a single collection record version history entry.
+func (app *BaseApp) FindRecordHistoryVersion(collectionModelOrIdentifier any, recordId string, version int) (*RecordHistory, error) {
+	collection, err := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &RecordHistory{}
+
+	err = app.RecordHistoryQuery().
+		AndWhere(dbx.HashExp{
+			"collectionRef": collection.Id,
+			"recordRef":     recordId,
+			"version":       version,
+		}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// deleteCollectionRecordHistory deletes all record history entries of the provided collection.
+func deleteCollectionRecordHistory(app App, collection *Collection) error {
+	// the history table may not exist yet (e.g. when called as part of an older migration)
+	if !app.HasTable(RecordHistoryTableName) {
+		return nil
+	}
+
+	_, err := app.DB().Delete(RecordHistoryTableName, dbx.HashExp{"collectionRef": collection.Id}).Execute()
+
+	return err
+}
@@ -0,0 +1,254 @@
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func createRecordHistoryTestCollection(t testing.TB, app core.App, history bool) *core.Collection {
+	collection := core.NewBaseCollection("history_test")
+	collection.History = history
+	collection.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.NumberField{Name: "count"},
+		&core.TextField{Name: "secret", Hidden: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	return collection
+}
+
+func TestRecordHistoryHooks(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createRecordHistoryTestCollection(t, app, true)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := core.WithRecordHistoryActor(context.Background(), core.RecordHistoryActor{Auth: user, IP: "1.2.3.4"})
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	record.Set("secret", "s")
+	if err := app.SaveWithContext(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	record.Set("count", 10)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// no changes
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DeleteWithContext(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := app.FindRecordHistory(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		action  string
+		changes string
+		authRef string
+		ip      string
+		data    string
+	}{
+		{core.RecordHistoryActionCreate, `["title","secret"]`, user.Id, "1.2.3.4", `{"count":0,"secret":"s","title":"a"}`},
+		{core.RecordHistoryActionUpdate, `["count"]`, "", "", `{"count":10,"secret":"s","title":"a"}`},
+		{core.RecordHistoryActionUpdate, `[]`, "", "", `{"count":10,"secret":"s","title":"a"}`},
+		{core.RecordHistoryActionDelete, `[]`, user.Id, "1.2.3.4", `{"count":10,"secret":"s","title":"a"}`},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d history entries, got %d", len(expected), len(entries))
+	}
+
+	for i, e := range expected {
+		entry := entries[i]
+
+		if entry.Version != i+1 {
+			t.Errorf("[%d] Expected version %d, got %d", i, i+1, entry.Version)
+		}
+
+		if entry.Action != e.action {
+			t.Errorf("[%d] Expected action %q, got %q", i, e.action, entry.Action)
+		}
+
+		if changes := entry.Changes.String(); changes != e.changes {
+			t.Errorf("[%d] Expected changes %s, got %s", i, e.changes, changes)
+		}
+
+		if entry.AuthRef != e.authRef {
+			t.Errorf("[%d] Expected authRef %q, got %q", i, e.authRef, entry.AuthRef)
+		}
+
+		if e.authRef != "" && entry.AuthCollectionRef != user.Collection().Id {
+			t.Errorf("[%d] Expected authCollectionRef %q, got %q", i, user.Collection().Id, entry.AuthCollectionRef)
+		}
+
+		if entry.IP != e.ip {
+			t.Errorf("[%d] Expected ip %q, got %q", i, e.ip, entry.IP)
+		}
+
+		// exclude the autodate and id values for easier comparison
+		data := map[string]any{}
+		for k, v := range entry.Data {
+			if k != "id" && k != "updated" {
+				data[k] = v
+			}
+		}
+		raw, _ := json.Marshal(data)
+		if string(raw) != e.data {
+			t.Errorf("[%d] Expected data %s, got %s", i, e.data, raw)
+		}
+
+		if entry.Data["id"] != record.Id {
+			t.Errorf("[%d] Expected data id %q, got %v", i, record.Id, entry.Data["id"])
+		}
+	}
+}
+
+func TestRecordHistoryHooksDisabled(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createRecordHistoryTestCollection(t, app, false)
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := app.FindRecordHistory(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("Expected no history entries, got %d", len(entries))
+	}
+}
+
+func TestRecordHistoryDiff(t *testing.T) {
+	t.Parallel()
+
+	a := &core.RecordHistory{Data: map[string]any{"a": 1, "b": []any{"x"}, "c": "test"}}
+	b := &core.RecordHistory{Data: map[string]any{"a": 1.0, "b": []any{"x", "y"}, "d": true}}
+
+	raw, err := json.Marshal(a.Diff(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"b":{"from":["x"],"to":["x","y"]},"c":{"from":"test","to":null},"d":{"from":null,"to":true}}`
+	if string(raw) != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, raw)
+	}
+}
+
+func TestRestoreRecordHistory(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createRecordHistoryTestCollection(t, app, true)
+
+	record := core.NewRecord(collection)
+	record.Set("title", "a")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record.Set("title", "b")
+	record.Set("count", 2)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := app.FindRecordHistoryVersion(collection, record.Id, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// restore of a deleted record
+	restored, err := app.RestoreRecordHistory(context.Background(), first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Id != record.Id || restored.GetString("title") != "a" || restored.GetInt("count") != 0 {
+		t.Fatalf("Unexpected restored record %v", restored)
+	}
+
+	second, err := app.FindRecordHistoryVersion(collection, record.Id, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// restore of an existing record
+	restored, err = app.RestoreRecordHistory(context.Background(), second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetString("title") != "b" || restored.GetInt("count") != 2 {
+		t.Fatalf("Unexpected restored record %v", restored)
+	}
+
+	entries, err := app.FindRecordHistory(collection, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+
+	expectedActions := []string{"create", "update", "delete", "create", "update"}
+	if !slices.Equal(actions, expectedActions) {
+		t.Fatalf("Expected actions %v, got %v", expectedActions, actions)
+	}
+
+	// collection delete should remove its history
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	var total int
+	err = app.RecordHistoryQuery().Select("count(*)").Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected the collection history to be deleted, found %d entries", total)
+	}
+}
@@ -0,0 +1,405 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	RecordsImportFormatCSV    = "csv"
+	RecordsImportFormatNDJSON = "ndjson"
+	RecordsImportFormatJSONL  = "jsonl"
+)
+
+// DefaultRecordsImportChunkSize is the default number of rows
+// that are persisted in a single import transaction.
+const DefaultRecordsImportChunkSize = 500
+
+// recordsImportMaxLineSize is the max allowed size of a single NDJSON line.
+const recordsImportMaxLineSize = 32 << 20
+
+// errRecordsImportDryRun is used to rollback the dry-run import transaction.
+var errRecordsImportDryRun = errors.New("records import dry-run")
+
+// RecordsImportOptions defines the [BaseApp.ImportRecords] options.
+type RecordsImportOptions struct {
+	// Format is the import source format - csv, ndjson or jsonl (default to csv).
+	//
+	// The first line of the CSV source is expected to be the columns header.
+	Format string `json:"format"`
+
+	// Mapping is an optional map with source column -> collection field name pairs.
+	//
+	// Columns that are not in the mapping are imported in the collection
+	// field with the same name (if any). Mapping a column to an empty string
+	// excludes the column from the import.
+	Mapping map[string]string `json:"mapping"`
+
+	// UpsertBy is an optional collection field name used to lookup
+	// the existing records that should be updated instead of created.
+	UpsertBy string `json:"upsertBy"`
+
+	// DryRun validates and persists the rows in a single transaction
+	// that is always rolled back at the end of the import.
+	DryRun bool `json:"dryRun"`
+
+	// ChunkSize is the max number of rows persisted in a single
+	// transaction (default to [DefaultRecordsImportChunkSize]).
+	ChunkSize int `json:"chunkSize"`
+}
+
+// Validate makes RecordsImportOptions validatable by implementing [validation.Validatable] interface.
+func (o RecordsImportOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(
+			&o.Format,
+			validation.In(RecordsImportFormatCSV, RecordsImportFormatNDJSON, RecordsImportFormatJSONL),
+		),
+		validation.Field(&o.ChunkSize, validation.Min(0)),
+	)
+}
+
+// RecordsImportResult is the result report of a records import.
+type RecordsImportResult struct {
+	DryRun  bool                     `json:"dryRun"`
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Updated int                      `json:"updated"`
+	Failed  int                      `json:"failed"`
+	Errors  []*RecordsImportRowError `json:"errors"`
+}
+
+// RecordsImportRowError describes the failure of a single import row.
+type RecordsImportRowError struct {
+	// Row is the 1-based data row number (the CSV header is not counted).
+	Row int `json:"row"`
+
+	// Id is the id of the failed record (if known).
+	Id string `json:"id,omitempty"`
+
+	// Message is the error summary.
+	Message string `json:"message"`
+
+	// Data contains the field validation errors (if any).
+	Data validation.Errors `json:"data,omitempty"`
+}
+
+func (e *RecordsImportRowError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Data.Error())
+	}
+
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+type recordsImportRow struct {
+	data   map[string]any
+	err    error
+	number int
+}
+
+// ImportRecords reads the CSV or NDJSON rows from r and creates
+// (or updates, if options.UpsertBy is set) a record for each of them.
+//
+// The row values are normalized with the related collection field
+// setters and every record is persisted with the regular [App.Save]
+// (aka. it is validated and all record model hooks are triggered).
+//
+// The rows are read incrementally and saved in transactions of
+// options.ChunkSize rows. A row that fails to save doesn't interrupt
+// the import and is reported in the returned result errors.
+//
+// Malformed rows (eg. invalid JSON line or CSV quoting) are also
+// reported as row errors and the import continues with the next row.
+//
+// A non-nil error is returned only if the import couldn't start,
+// the source couldn't be read or a transaction failed, in which case
+// the returned result reflects the rows processed up until that moment.
+func (app *BaseApp) ImportRecords(collection *Collection, r io.Reader, options RecordsImportOptions) (*RecordsImportResult, error) {
+	if collection.IsView() {
+		return nil, errors.New("view collections don't support records import")
+	}
+
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultRecordsImportChunkSize
+	}
+
+	for column, fieldName := range options.Mapping {
+		if fieldName != "" && collection.Fields.GetByName(fieldName) == nil {
+			return nil, fmt.Errorf("column %q is mapped to missing collection field %q", column, fieldName)
+		}
+	}
+
+	if options.UpsertBy != "" && collection.Fields.GetByName(options.UpsertBy) == nil {
+		return nil, fmt.Errorf("missing upsert collection field %q", options.UpsertBy)
+	}
+
+	var next func() (*recordsImportRow, error)
+	switch options.Format {
+	case RecordsImportFormatNDJSON, RecordsImportFormatJSONL:
+		next = newNDJSONImportReader(r)
+	default:
+		var err error
+		next, err = newCSVImportReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CSV header: %w", err)
+		}
+	}
+
+	result := &RecordsImportResult{
+		DryRun: options.DryRun,
+		Errors: []*RecordsImportRowError{},
+	}
+
+	importChunks := func(chunkTx func(fn func(txApp App) error) error) error {
+		for {
+			chunk, readErr := readRecordsImportChunk(next, options.ChunkSize, result)
+
+			if len(chunk) > 0 {
+				err := chunkTx(func(txApp App) error {
+					for _, row := range chunk {
+						importRecordsRow(txApp, collection, row, options, result)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if readErr != nil {
+				if errors.Is(readErr, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("failed to read the import source: %w", readErr)
+			}
+
+			if len(chunk) < options.ChunkSize {
+				return nil
+			}
+		}
+	}
+
+	var err error
+	if options.DryRun {
+		// use a single transaction so that the rows could be validated
+		// against each other (eg. for unique constraints)
+		err = app.RunInTransaction(func(txApp App) error {
+			chunkErr := importChunks(func(fn func(txApp App) error) error {
+				return fn(txApp)
+			})
+			if chunkErr != nil {
+				return chunkErr
+			}
+
+			return errRecordsImportDryRun
+		})
+		if errors.Is(err, errRecordsImportDryRun) {
+			err = nil
+		}
+	} else {
+		err = importChunks(app.RunInTransaction)
+	}
+
+	result.Failed = len(result.Errors)
+
+	return result, err
+}
+
+// readRecordsImportChunk reads up to limit rows.
+//
+// A source read error (including [io.EOF]) is returned as second
+// argument to indicate that the reading should stop.
+func readRecordsImportChunk(
+	next func() (*recordsImportRow, error),
+	limit int,
+	result *RecordsImportResult,
+) ([]*recordsImportRow, error) {
+	chunk := make([]*recordsImportRow, 0, limit)
+
+	for len(chunk) < limit {
+		row, err := next()
+		if err != nil {
+			return chunk, err
+		}
+
+		result.Total++
+		row.number = result.Total
+
+		chunk = append(chunk, row)
+	}
+
+	return chunk, nil
+}
+
+func importRecordsRow(
+	txApp App,
+	collection *Collection,
+	row *recordsImportRow,
+	options RecordsImportOptions,
+	result *RecordsImportResult,
+) {
+	if row.err != nil {
+		result.Errors = append(result.Errors, newRecordsImportRowError(row.number, "", row.err))
+		return
+	}
+
+	data := make(map[string]any, len(row.data))
+	for column, value := range row.data {
+		fieldName, ok := options.Mapping[column]
+		if !ok {
+			fieldName = column
+		}
+
+		if fieldName == "" || collection.Fields.GetByName(fieldName) == nil {
+			continue // skip unknown columns (eg. "collectionName")
+		}
+
+		data[fieldName] = value
+	}
+
+	var record *Record
+
+	if options.UpsertBy != "" {
+		// normalize the lookup value with the field setter
+		lookup := NewRecord(collection)
+		lookup.Set(options.UpsertBy, data[options.UpsertBy])
+
+		if value := lookup.GetRaw(options.UpsertBy); value != nil && value != "" {
+			existing, err := txApp.FindFirstRecordByData(collection, options.UpsertBy, value)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				result.Errors = append(result.Errors, newRecordsImportRowError(row.number, "", err))
+				return
+			}
+			record = existing
+		}
+	}
+
+	isNew := record == nil
+	if isNew {
+		record = NewRecord(collection)
+	}
+
+	for fieldName, value := range data {
+		// keep the existing password if not explicitly set
+		if _, ok := collection.Fields.GetByName(fieldName).(*PasswordField); ok && value == "" {
+			continue
+		}
+
+		record.Set(fieldName, value)
+	}
+
+	if err := txApp.Save(record); err != nil {
+		result.Errors = append(result.Errors, newRecordsImportRowError(row.number, record.Id, err))
+		return
+	}
+
+	if isNew {
+		result.Created++
+	} else {
+		result.Updated++
+	}
+}
+
+func newRecordsImportRowError(row int, id string, err error) *RecordsImportRowError {
+	rowErr := &RecordsImportRowError{Row: row, Id: id}
+
+	var validationErrs validation.Errors
+	if errors.As(err, &validationErrs) {
+		rowErr.Message = "Failed to validate the record."
+		rowErr.Data = validationErrs
+	} else {
+		rowErr.Message = err.Error()
+	}
+
+	return rowErr
+}
+
+func newCSVImportReader(r io.Reader) (func() (*recordsImportRow, error), error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	header = slices.Clone(header)
+
+	// strip the UTF-8 BOM (usually added by spreadsheet applications)
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	return func() (*recordsImportRow, error) {
+		values, err := reader.Read()
+		if err != nil {
+			// note: the reader could continue with the next line after a parse error
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return &recordsImportRow{err: fmt.Errorf("failed to read the row: %w", err)}, nil
+			}
+
+			return nil, err
+		}
+
+		// note: reported as row error without interrupting the import
+		if len(values) != len(header) {
+			return &recordsImportRow{
+				err: fmt.Errorf("expected %d columns, got %d", len(header), len(values)),
+			}, nil
+		}
+
+		data := make(map[string]any, len(header))
+		for i, column := range header {
+			data[column] = values[i]
+		}
+
+		return &recordsImportRow{data: data}, nil
+	}, nil
+}
+
+func newNDJSONImportReader(r io.Reader) func() (*recordsImportRow, error) {
+	// note: each line is decoded separately so that a single
+	// malformed line doesn't interrupt the reading of the next ones
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, recordsImportMaxLineSize)
+
+	return func() (*recordsImportRow, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue // skip empty lines
+			}
+
+			data := map[string]any{}
+
+			// note: reported as row error without interrupting the import
+			if err := json.Unmarshal(line, &data); err != nil {
+				return &recordsImportRow{err: fmt.Errorf("failed to read the row: %w", err)}, nil
+			}
+
+			return &recordsImportRow{data: data}, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, io.EOF
+	}
+}
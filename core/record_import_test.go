@@ -0,0 +1,167 @@
+package core_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestImportRecords(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name           string
+		collection     string
+		data           string
+		options        core.RecordsImportOptions
+		expectError    bool
+		expectedResult string
+		expectedTitles map[string]bool // title -> active
+		missingTitles  []string
+	}{
+		{
+			name:        "view collection",
+			collection:  "view1",
+			data:        "id\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid format",
+			collection:  "demo2",
+			data:        "title\nimport1\n",
+			options:     core.RecordsImportOptions{Format: "xml"},
+			expectError: true,
+		},
+		{
+			name:        "mapping to missing field",
+			collection:  "demo2",
+			data:        "title\nimport1\n",
+			options:     core.RecordsImportOptions{Mapping: map[string]string{"title": "missing"}},
+			expectError: true,
+		},
+		{
+			name:        "missing upsert field",
+			collection:  "demo2",
+			data:        "title\nimport1\n",
+			options:     core.RecordsImportOptions{UpsertBy: "missing"},
+			expectError: true,
+		},
+		{
+			name:        "empty csv",
+			collection:  "demo2",
+			data:        "",
+			expectError: true,
+		},
+		{
+			name:           "csv with type coercion, unknown columns and row errors",
+			collection:     "demo2",
+			data:           "\ufefftitle,active,collectionName\nimport1,true,demo2\nimport2,0,demo2\nx,1,demo2\ntest1,false,demo2\nimport3\n",
+			expectedResult: `{"dryRun":false,"total":5,"created":2,"updated":0,"failed":3,"errors":[{"row":3,"id":"`,
+			expectedTitles: map[string]bool{"import1": true, "import2": false},
+			missingTitles:  []string{"x", "import3"},
+		},
+		{
+			name:       "csv with mapping and small chunk size",
+			collection: "demo2",
+			data:       "Name,Enabled,Ignored\nimport1,1,a\nimport2,true,b\nimport3,false,c\n",
+			options: core.RecordsImportOptions{
+				Mapping:   map[string]string{"Name": "title", "Enabled": "active", "Ignored": ""},
+				ChunkSize: 2,
+			},
+			expectedResult: `{"dryRun":false,"total":3,"created":3,"updated":0,"failed":0,"errors":[]}`,
+			expectedTitles: map[string]bool{"import1": true, "import2": true, "import3": false},
+		},
+		{
+			name:       "ndjson with upsert",
+			collection: "demo2",
+			data:       `{"title":"test1","active":true}` + "\n" + `{"title":"import1","active":true}` + "\n" + `{"title":"test2","active":false}`,
+			options: core.RecordsImportOptions{
+				Format:   core.RecordsImportFormatNDJSON,
+				UpsertBy: "title",
+			},
+			expectedResult: `{"dryRun":false,"total":3,"created":1,"updated":2,"failed":0,"errors":[]}`,
+			expectedTitles: map[string]bool{"test1": true, "import1": true, "test2": false},
+		},
+		{
+			name:           "ndjson with invalid json line",
+			collection:     "demo2",
+			data:           `{"title":"import1"}` + "\n\n" + `{"title":` + "\n" + `{"title":"import2"}` + "\n" + `[1]` + "\n" + `{"title":"import3"} {}` + "\n" + `{"title":"import4"}` + "\n",
+			options:        core.RecordsImportOptions{Format: core.RecordsImportFormatJSONL, ChunkSize: 2},
+			expectedResult: `{"dryRun":false,"total":6,"created":3,"updated":0,"failed":3,"errors":[{"row":2,"message":"failed to read the row:`,
+			expectedTitles: map[string]bool{"import1": false, "import2": false, "import4": false},
+			missingTitles:  []string{"import3"},
+		},
+		{
+			name:           "csv with malformed row in the middle",
+			collection:     "demo2",
+			data:           "title,active\nimport1,true\nimp\"ort2,true\nimport3,false\nimport4,true\n",
+			options:        core.RecordsImportOptions{ChunkSize: 2},
+			expectedResult: `{"dryRun":false,"total":4,"created":3,"updated":0,"failed":1,"errors":[{"row":2,"message":"failed to read the row:`,
+			expectedTitles: map[string]bool{"import1": true, "import3": false, "import4": true},
+			missingTitles:  []string{`imp"ort2`},
+		},
+		{
+			name:       "dry-run",
+			collection: "demo2",
+			data:       "title\nimport1\nimport1\n",
+			options: core.RecordsImportOptions{
+				DryRun:    true,
+				ChunkSize: 1,
+			},
+			expectedResult: `{"dryRun":true,"total":2,"created":1,"updated":0,"failed":1,"errors":[{"row":2,"id":"`,
+			missingTitles:  []string{"import1"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			collection, err := app.FindCollectionByNameOrId(s.collection)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.ImportRecords(collection, strings.NewReader(s.data), s.options)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			raw, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.HasPrefix(string(raw), s.expectedResult) {
+				t.Fatalf("Expected result to start with\n%s\ngot\n%s", s.expectedResult, raw)
+			}
+
+			for title, active := range s.expectedTitles {
+				record, err := app.FindFirstRecordByData(collection, "title", title)
+				if err != nil {
+					t.Fatalf("Missing record with title %q: %v", title, err)
+				}
+
+				if record.GetBool("active") != active {
+					t.Fatalf("Expected %q active %v, got %v", title, active, record.GetBool("active"))
+				}
+			}
+
+			for _, title := range s.missingTitles {
+				if _, err := app.FindFirstRecordByData(collection, "title", title); err == nil {
+					t.Fatalf("Expected record with title %q to be missing", title)
+				}
+			}
+		})
+	}
+}
@@ -434,6 +434,12 @@ func (app *BaseApp) registerRecordHooks() {
 	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
 		Id: systemHookIdRecord,
 		Func: func(e *RecordEvent) error {
+			// soft deleted records keep their row, files and field specific data
+			// so the field interceptors are not invoked
+			if isRecordSoftDelete(e.Context, e.Record) {
+				return onRecordSoftDeleteExecute(e)
+			}
+
 			return e.Record.callFieldInterceptors(
 				e.Context,
 				e.App,
@@ -1096,6 +1102,8 @@ func (m *Record) FindFileFieldByFile(filename string) *FileField {
 // DBExport implements the [DBExporter] interface and returns a key-value
 // map with the data to be persisted when saving the Record in the database.
 func (m *Record) DBExport(app App) (map[string]any, error) {
+	BindEncryptedFields(app, m)
+
 	result, err := m.dbExport()
 	if err != nil {
 		return nil, err
@@ -1123,6 +1131,40 @@ func (m *Record) DBExport(app App) (map[string]any, error) {
 	return result, nil
 }
 
+// ChangedFields returns the names of the collection fields which DB export
+// values differ from the ones of m.Original() (in the collection fields order).
+//
+// For new records all collection field names are returned.
+func (m *Record) ChangedFields() ([]string, error) {
+	result, err := m.dbExport()
+	if err != nil {
+		return nil, err
+	}
+
+	var oldResult map[string]any
+	if !m.IsNew() {
+		oldResult, err = m.Original().dbExport()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(result))
+
+	var fieldName string
+	for _, field := range m.Collection().Fields {
+		fieldName = field.GetName()
+
+		if oldResult != nil && areValuesEqual(result[fieldName], oldResult[fieldName]) {
+			continue
+		}
+
+		names = append(names, fieldName)
+	}
+
+	return names, nil
+}
+
 func (m *Record) dbExport() (map[string]any, error) {
 	fields := m.Collection().Fields
 
@@ -1430,7 +1472,7 @@ func onRecordSaveExecute(e *RecordEvent) error {
 	if e.Record.Collection().IsAuth() {
 		// ensure that the token key is regenerated on password change or email change
 		if !e.Record.IsNew() {
-			lastSavedRecord, err := e.App.FindRecordById(e.Record.Collection(), e.Record.Id)
+			lastSavedRecord, err := findRecordByIdWithDeleted(e.App, e.Record.Collection(), e.Record.Id)
 			if err != nil {
 				return err
 			}
@@ -1492,7 +1534,7 @@ func onRecordDeleteExecute(e *RecordEvent) error {
 			return err
 		}
 
-		return cascadeRecordDelete(txApp, e.Record, refs)
+		return cascadeRecordDelete(e.Context, txApp, e.Record, refs)
 	})
 	e.App = originalApp
 
@@ -1502,7 +1544,7 @@ func onRecordDeleteExecute(e *RecordEvent) error {
 // cascadeRecordDelete triggers cascade deletion for the provided references.
 //
 // NB! This method is expected to be called from inside of a transaction.
-func cascadeRecordDelete(app App, mainRecord *Record, refs map[*Collection][]Field) error {
+func cascadeRecordDelete(ctx context.Context, app App, mainRecord *Record, refs map[*Collection][]Field) error {
 	// Sort the refs keys to ensure that the cascade events firing order is always the same.
 	// This is not necessary for the operation to function correctly but it helps having deterministic output during testing.
 	sortedRefKeys := make([]*Collection, 0, len(refs))
@@ -1520,27 +1562,20 @@ func cascadeRecordDelete(app App, mainRecord *Record, refs map[*Collection][]Fie
 			continue // skip missing or view collections
 		}
 
-		recordTableName := inflector.Columnify(refCollection.Name)
-
 		for _, field := range fields {
-			prefixedFieldName := recordTableName + "." + inflector.Columnify(field.GetName())
-
-			query := app.RecordQuery(refCollection)
-
-			if opt, ok := field.(MultiValuer); !ok || !opt.IsMultiple() {
-				query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
+			var query *dbx.SelectQuery
+
+			// soft deleted references are also updated or purged, except when the
+			// cascade is a soft delete because the already soft deleted rows
+			// will remain in the query results
+			if relField, _ := field.(*RelationField); relField != nil && relField.CascadeDelete &&
+				refCollection.SoftDelete && !isRecordPurgeContext(ctx) {
+				query = app.RecordQuery(refCollection)
 			} else {
-				query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
-					`SELECT 1 FROM %s {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
-					dbutils.JSONEach(prefixedFieldName),
-				), dbx.Params{
-					"jevalue": mainRecord.Id,
-				})))
+				query = app.RecordQueryWithDeleted(refCollection)
 			}
 
-			if refCollection.Id == mainRecord.Collection().Id {
-				query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
-			}
+			filterRecordReferences(query, mainRecord, refCollection, field)
 
 			// trigger cascade for each batchSize rel items until there is none
 			batchSize := 4000
@@ -1555,7 +1590,7 @@ func cascadeRecordDelete(app App, mainRecord *Record, refs map[*Collection][]Fie
 					break
 				}
 
-				err := deleteRefRecords(app, mainRecord, rows, field)
+				err := deleteRefRecords(ctx, app, mainRecord, rows, field)
 				if err != nil {
 					return err
 				}
@@ -1572,12 +1607,35 @@ func cascadeRecordDelete(app App, mainRecord *Record, refs map[*Collection][]Fie
 	return nil
 }
 
+// filterRecordReferences adds a filter expression to the refCollection
+// records query matching the records that have the mainRecord id
+// as value of the provided relation field.
+func filterRecordReferences(query *dbx.SelectQuery, mainRecord *Record, refCollection *Collection, field Field) {
+	recordTableName := inflector.Columnify(refCollection.Name)
+	prefixedFieldName := recordTableName + "." + inflector.Columnify(field.GetName())
+
+	if opt, ok := field.(MultiValuer); !ok || !opt.IsMultiple() {
+		query.AndWhere(dbx.HashExp{prefixedFieldName: mainRecord.Id})
+	} else {
+		query.AndWhere(dbx.Exists(dbx.NewExp(fmt.Sprintf(
+			`SELECT 1 FROM %s {{__je__}} WHERE [[__je__.value]]={:jevalue}`,
+			dbutils.JSONEach(prefixedFieldName),
+		), dbx.Params{
+			"jevalue": mainRecord.Id,
+		})))
+	}
+
+	if refCollection.Id == mainRecord.Collection().Id {
+		query.AndWhere(dbx.Not(dbx.HashExp{recordTableName + ".id": mainRecord.Id}))
+	}
+}
+
 // deleteRefRecords checks if related records has to be deleted (if `CascadeDelete` is set)
 // OR
 // just unset the record id from any relation field values (if they are not required).
 //
 // NB! This method is expected to be called from inside of a transaction.
-func deleteRefRecords(app App, mainRecord *Record, refRecords []*Record, field Field) error {
+func deleteRefRecords(ctx context.Context, app App, mainRecord *Record, refRecords []*Record, field Field) error {
 	relField, _ := field.(*RelationField)
 	if relField == nil {
 		return errors.New("only RelationField is supported at the moment, got " + field.Type())
@@ -1597,7 +1655,7 @@ func deleteRefRecords(app App, mainRecord *Record, refRecords []*Record, field F
 		// cascade delete the reference
 		// (only if there are no other active references in case of multiple select)
 		if relField.CascadeDelete && len(ids) == 0 {
-			if err := app.Delete(refRecord); err != nil {
+			if err := app.DeleteWithContext(ctx, refRecord); err != nil {
 				return err
 			}
 			// no further actions are needed (the reference is deleted)
@@ -1611,7 +1669,7 @@ func deleteRefRecords(app App, mainRecord *Record, refRecords []*Record, field F
 		// save the reference changes
 		// (without validation because it is possible that another relation field to have a reference to a previous deleted record)
 		refRecord.Set(relField.Name, ids)
-		if err := app.SaveNoValidate(refRecord); err != nil {
+		if err := app.SaveNoValidateWithContext(ctx, refRecord); err != nil {
 			return err
 		}
 	}
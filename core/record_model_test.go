@@ -1365,6 +1365,62 @@ func TestRecordIgnoreUnchangedFields(t *testing.T) {
 	}
 }
 
+func TestRecordChangedFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	col, err := app.FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	new := core.NewRecord(col)
+	new.Set("title", "test_new")
+
+	unchanged, err := app.FindRecordById(col, "mk5fmymtx4wsprk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := app.FindRecordById(col, "mk5fmymtx4wsprk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed.Set("title", "test_new")
+	changed.Set("files", changed.Get("files")) // no change
+
+	scenarios := []struct {
+		name     string
+		record   *core.Record
+		expected []string
+	}{
+		{"new record", new, []string{"id", "created", "updated", "title", "files"}},
+		{"unchanged existing record", unchanged, []string{}},
+		{"changed existing record", changed, []string{"title"}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := s.record.ChangedFields()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+
+			for _, name := range s.expected {
+				if !slices.Contains(result, name) {
+					t.Fatalf("Missing expected field %q in %v", name, result)
+				}
+			}
+		})
+	}
+}
+
 func TestRecordPublicExportAndMarshalJSON(t *testing.T) {
 	t.Parallel()
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/dbutils"
@@ -23,7 +24,23 @@ var recordProxyType = reflect.TypeOf((*RecordProxy)(nil)).Elem()
 // In case a collection id or name is provided and that collection doesn't
 // actually exists, the generated query will be created with a cancelled context
 // and will fail once an executor (Row(), One(), All(), etc.) is called.
+//
+// For collections with enabled soft delete mode, the soft deleted records
+// are excluded from the query (see also [App.RecordQueryWithDeleted]).
+//
+// For collections with enabled TTL, the expired records are also
+// excluded from the query even if they are not deleted yet.
 func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuery {
+	return app.recordQuery(collectionModelOrIdentifier, false)
+}
+
+// RecordQueryWithDeleted is similar to [App.RecordQuery] but
+// the returned query includes also the soft deleted and the expired (not yet deleted) records.
+func (app *BaseApp) RecordQueryWithDeleted(collectionModelOrIdentifier any) *dbx.SelectQuery {
+	return app.recordQuery(collectionModelOrIdentifier, true)
+}
+
+func (app *BaseApp) recordQuery(collectionModelOrIdentifier any, withDeleted bool) *dbx.SelectQuery {
 	var tableName string
 
 	collection, collectionErr := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
@@ -37,6 +54,16 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 	query := app.ConcurrentDB().Select(app.ConcurrentDB().QuoteSimpleColumnName(tableName) + ".*").From(tableName)
 
+	if !withDeleted && collection != nil && !collection.IsView() {
+		if collection.SoftDelete {
+			query.AndWhere(dbx.HashExp{tableName + "." + FieldNameDeleted: ""})
+		}
+
+		if collection.HasTTL() {
+			query.AndWhere(notExpiredRecordsExpr(collection, tableName, time.Now()))
+		}
+	}
+
 	// in case of an error attach a new context and cancel it immediately with the error
 	if collectionErr != nil {
 		ctx, cancelFunc := context.WithCancelCause(context.Background())
@@ -53,7 +80,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 				switch v := a.(type) {
 				case *Record:
-					record, err := resolveRecordOneHook(collection, op)
+					record, err := resolveRecordOneHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -62,7 +89,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 					return nil
 				case RecordProxy:
-					record, err := resolveRecordOneHook(collection, op)
+					record, err := resolveRecordOneHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -80,7 +107,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 				switch v := sliceA.(type) {
 				case *[]*Record:
-					records, err := resolveRecordAllHook(collection, op)
+					records, err := resolveRecordAllHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -89,7 +116,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 
 					return nil
 				case *[]Record:
-					records, err := resolveRecordAllHook(collection, op)
+					records, err := resolveRecordAllHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -126,7 +153,7 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 						return op(sliceA)
 					}
 
-					records, err := resolveRecordAllHook(collection, op)
+					records, err := resolveRecordAllHook(app, collection, op)
 					if err != nil {
 						return err
 					}
@@ -164,20 +191,38 @@ func (app *BaseApp) RecordQuery(collectionModelOrIdentifier any) *dbx.SelectQuer
 	})
 }
 
-func resolveRecordOneHook(collection *Collection, op func(dst any) error) (*Record, error) {
+func resolveRecordOneHook(app App, collection *Collection, op func(dst any) error) (*Record, error) {
 	data := dbx.NullStringMap{}
 	if err := op(&data); err != nil {
 		return nil, err
 	}
-	return newRecordFromNullStringMap(collection, data)
+
+	record, err := newRecordFromNullStringMap(collection, data)
+	if err != nil {
+		return nil, err
+	}
+
+	BindEncryptedFields(app, record)
+
+	return record, nil
 }
 
-func resolveRecordAllHook(collection *Collection, op func(dst any) error) ([]*Record, error) {
+func resolveRecordAllHook(app App, collection *Collection, op func(dst any) error) ([]*Record, error) {
 	data := []dbx.NullStringMap{}
 	if err := op(&data); err != nil {
 		return nil, err
 	}
-	return newRecordsFromNullStringMaps(collection, data)
+
+	records, err := newRecordsFromNullStringMaps(collection, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		BindEncryptedFields(app, record)
+	}
+
+	return records, nil
 }
 
 // dereference returns the underlying value v points to.
@@ -601,7 +646,8 @@ func (app *BaseApp) CanAccessRecord(record *Record, requestInfo *RequestInfo, ac
 
 	var exists int
 
-	query := app.RecordQuery(record.Collection()).
+	// the record is already loaded so it could be also a soft deleted one
+	query := app.RecordQueryWithDeleted(record.Collection()).
 		Select("(1)").
 		AndWhere(dbx.HashExp{record.Collection().Name + ".id": record.Id})
 
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// recordRTreeTablePrefix is the name prefix of the R*Tree shadow tables
+// that holds the spatial index of the geoPoint and geoShape record fields.
+const recordRTreeTablePrefix = "_rtree_"
+
+// recordRTreeTable manages the R*Tree shadow tables of the spatial indexed fields.
+//
+// Each index entry is the bounding box of the field value
+// (zero-area box for points) with the record id stored as auxiliary column.
+var recordRTreeTable = &recordShadowTable{
+	prefix:    recordRTreeTablePrefix,
+	label:     "spatial index",
+	isIndexed: isSpatialIndexedField,
+	schema: func(tableName string) []string {
+		return []string{
+			"CREATE VIRTUAL TABLE IF NOT EXISTS {{" + tableName + "}} USING rtree(rid, minLon, maxLon, minLat, maxLat, +id)",
+		}
+	},
+	upsert:  upsertRecordRTreeEntry,
+	delete:  deleteRecordRTreeEntry,
+	changed: spatialBBoxChanged,
+}
+
+// recordRTreeTableName returns the name of the R*Tree shadow table
+// used by the geo filter functions of the specified collection field.
+func recordRTreeTableName(collection *Collection, field Field) string {
+	return recordShadowTableName(recordRTreeTablePrefix, collection, field)
+}
+
+// isSpatialIndexedField reports whether the provided field has an enabled R*Tree index.
+func isSpatialIndexedField(field Field) bool {
+	s, ok := field.(SpatialIndexer)
+	return ok && s.IsSpatialIndexed()
+}
+
+// upsertRecordRTreeEntry replaces the spatial index entry of the provided record field.
+//
+// Empty field values (without bounding box) are removed from the index.
+func upsertRecordRTreeEntry(app App, record *Record, field Field) error {
+	s, ok := field.(SpatialIndexer)
+	if !ok {
+		return nil
+	}
+
+	bbox, ok := s.SpatialBBox(record)
+	if !ok {
+		return deleteRecordRTreeEntry(app, record, field)
+	}
+
+	_, err := app.DB().NewQuery(
+		"INSERT OR REPLACE INTO {{" + recordRTreeTableName(record.Collection(), field) + "}} " +
+			"(rid, minLon, maxLon, minLat, maxLat, id) VALUES ({:rid}, {:minLon}, {:maxLon}, {:minLat}, {:maxLat}, {:id})",
+	).Bind(dbx.Params{
+		"rid":    recordIndexRowid(record.Id),
+		"minLon": bbox.MinLon,
+		"maxLon": bbox.MaxLon,
+		"minLat": bbox.MinLat,
+		"maxLat": bbox.MaxLat,
+		"id":     record.Id,
+	}).Execute()
+
+	return err
+}
+
+// deleteRecordRTreeEntry removes the spatial index entry of the provided record field (if any).
+func deleteRecordRTreeEntry(app App, record *Record, field Field) error {
+	_, err := app.DB().Delete(
+		recordRTreeTableName(record.Collection(), field),
+		dbx.HashExp{"rid": recordIndexRowid(record.Id)},
+	).Execute()
+
+	return err
+}
+
+// spatialBBoxChanged reports whether the indexed bounding box
+// of the record field value was changed compared to the original record.
+func spatialBBoxChanged(record *Record, field Field) bool {
+	s, ok := field.(SpatialIndexer)
+	if !ok {
+		return false
+	}
+
+	oldBBox, oldOk := s.SpatialBBox(record.Original())
+	newBBox, newOk := s.SpatialBBox(record)
+
+	return oldOk != newOk || oldBBox != newBBox
+}
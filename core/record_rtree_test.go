@@ -0,0 +1,232 @@
+package core_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRecordRTreeSync(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("rtree_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.GeoPointField{Name: "location"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	locationField := collection.Fields.GetByName("location").(*core.GeoPointField)
+	rtreeTable := "_rtree_" + collection.Id + "_" + locationField.Id
+
+	for _, name := range []string{"a", "b"} {
+		record := core.NewRecord(collection)
+		record.Set("name", name)
+		record.Set("location", types.GeoPoint{Lon: 1, Lat: 2})
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if app.HasTable(rtreeTable) {
+		t.Fatalf("Expected %q to not exist", rtreeTable)
+	}
+
+	// enable and index the existing records
+	locationField.SpatialIndex = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.HasTable(rtreeTable) {
+		t.Fatalf("Expected %q to be created", rtreeTable)
+	}
+	assertRTreeEntries(t, app, rtreeTable, 2)
+
+	// create
+	record := core.NewRecord(collection)
+	record.Set("name", "c")
+	record.Set("location", types.GeoPoint{Lon: 10, Lat: 20})
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	assertRTreeEntries(t, app, rtreeTable, 3)
+	assertRTreeMatches(t, app, collection, "geoWithinBox(location, 9, 19, 11, 21) = true", "c")
+
+	// update
+	record.Set("location", types.GeoPoint{Lon: -10, Lat: -20})
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	assertRTreeEntries(t, app, rtreeTable, 3)
+	assertRTreeMatches(t, app, collection, "geoWithinBox(location, 9, 19, 11, 21) = true")
+	assertRTreeMatches(t, app, collection, "geoWithinBox(location, -11, -21, -9, -19) = true", "c")
+
+	// delete
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+	assertRTreeEntries(t, app, rtreeTable, 2)
+
+	// disable
+	locationField.SpatialIndex = false
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(rtreeTable) {
+		t.Fatalf("Expected %q to be deleted", rtreeTable)
+	}
+
+	// reenable and delete the collection
+	locationField.SpatialIndex = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !app.HasTable(rtreeTable) {
+		t.Fatalf("Expected %q to be recreated", rtreeTable)
+	}
+	assertRTreeEntries(t, app, rtreeTable, 2)
+
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(rtreeTable) {
+		t.Fatalf("Expected %q to be deleted after the collection removal", rtreeTable)
+	}
+}
+
+func TestRecordRTreeFilters(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// "indexed" and "plain" hold the same values to ensure that
+	// the index prefilter doesn't change the filter results
+	collection := core.NewBaseCollection("rtree_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.GeoPointField{Name: "indexed", SpatialIndex: true},
+		&core.GeoPointField{Name: "plain"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	points := map[string]types.GeoPoint{
+		"sofia":   {Lon: 23.32, Lat: 42.69},
+		"plovdiv": {Lon: 24.75, Lat: 42.15},
+		"london":  {Lon: -0.12, Lat: 51.5},
+		"null":    {Lon: 0, Lat: 0},
+		"fiji":    {Lon: 179.9, Lat: -17.7},
+	}
+	for name, point := range points {
+		record := core.NewRecord(collection)
+		record.Set("name", name)
+		record.Set("indexed", point)
+		record.Set("plain", point)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		filter   string
+		expected []string
+	}{
+		{"geoWithinBox(FIELD, 22, 41, 25, 43) = true", []string{"plovdiv", "sofia"}},
+		{"geoWithinBox(FIELD, -1, 0, 1, 52) = true", []string{"london", "null"}},
+		{"geoWithinBox(FIELD, 22, 41, 25, 43) = false", []string{"fiji", "london", "null"}},
+		{"geoWithinBox(FIELD, 22, 41, 25, 43) != false && name != 'sofia'", []string{"plovdiv"}},
+		{"geoDistance(FIELD.lon, FIELD.lat, 23.32, 42.69) < 10", []string{"sofia"}},
+		{"geoDistance(FIELD.lon, FIELD.lat, 23.32, 42.69) <= 200", []string{"plovdiv", "sofia"}},
+		{"200 > geoDistance(23.32, 42.69, FIELD.lon, FIELD.lat)", []string{"plovdiv", "sofia"}},
+		{"geoDistance(FIELD.lon, FIELD.lat, 23.32, 42.69) > 200", []string{"fiji", "london", "null"}},
+		{"geoDistance(FIELD.lon, FIELD.lat, 23.32, 42.69) < 30000", []string{"fiji", "london", "null", "plovdiv", "sofia"}},
+		// antimeridian
+		{"geoDistance(FIELD.lon, FIELD.lat, -179.9, -17.7) < 50", []string{"fiji"}},
+		// pole
+		{"geoDistance(FIELD.lon, FIELD.lat, 0, 89.9) < 10000", []string{"london", "null", "plovdiv", "sofia"}},
+	}
+
+	for _, s := range scenarios {
+		for _, field := range []string{"indexed", "plain"} {
+			filter := strings.ReplaceAll(s.filter, "FIELD", field)
+
+			t.Run(filter, func(t *testing.T) {
+				assertRTreeMatches(t, app, collection, filter, s.expected...)
+
+				// check whether the index prefilter was applied
+				resolver := core.NewRecordFieldResolver(app, collection, nil, false)
+				expr, err := search.FilterData(filter).BuildExpr(resolver)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rawSQL := expr.Build(app.ConcurrentDB().(*dbx.DB), dbx.Params{})
+
+				hasPrefilter := strings.Contains(rawSQL, "_rtree_")
+				expectPrefilter := field == "indexed" && !strings.Contains(filter, ") = false") && !strings.Contains(filter, "> 200")
+				if hasPrefilter != expectPrefilter {
+					t.Fatalf("Expected hasPrefilter %v, got %v\n%s", expectPrefilter, hasPrefilter, rawSQL)
+				}
+			})
+		}
+	}
+
+	// invalid geoWithinBox arguments
+	invalidFilters := []string{
+		"geoWithinBox(indexed, 1, 2, 3) = true",
+		"geoWithinBox(name, 1, 2, 3, 4) = true",
+		"geoWithinBox('indexed', 1, 2, 3, 4) = true",
+		"geoWithinBox(indexed, 'a', 2, 3, 4) = true",
+		"geoWithinBox(missing, 1, 2, 3, 4) = true",
+	}
+	for _, filter := range invalidFilters {
+		if _, err := app.FindRecordsByFilter(collection, filter, "", 0, 0); err == nil {
+			t.Fatalf("Expected %q to fail", filter)
+		}
+	}
+}
+
+func assertRTreeEntries(t *testing.T, app core.App, table string, expected int) {
+	t.Helper()
+
+	var total int
+	err := app.DB().Select("count(*)").From(table).Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != expected {
+		t.Fatalf("Expected %d %q entries, got %d", expected, table, total)
+	}
+}
+
+func assertRTreeMatches(t *testing.T, app core.App, collection *core.Collection, filter string, expectedNames ...string) {
+	t.Helper()
+
+	records, err := app.FindRecordsByFilter(collection, filter, "name", 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to find records for filter %q: %v", filter, err)
+	}
+
+	names := make([]string, len(records))
+	for i, r := range records {
+		names[i] = r.GetString("name")
+	}
+
+	if !slices.Equal(names, expectedNames) {
+		t.Fatalf("[%s] Expected names %v, got %v", filter, expectedNames, names)
+	}
+}
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/pocketbase/dbx"
+)
+
+const recordShadowTableBackfillBatchSize = 500
+
+// recordShadowTable describes a per field auxiliary index table
+// (eg. the FTS5, R*Tree and IVF index tables) that is managed
+// together with its collection and kept in sync with the record changes.
+//
+// The table name is composed from the collection and field ids (instead of
+// their names) so that renaming the collection or field doesn't affect it.
+type recordShadowTable struct {
+	// prefix is the name prefix of the shadow tables (eg. "_fts_").
+	prefix string
+
+	// label is the index name used in the error messages (eg. "full-text index").
+	label string
+
+	// isIndexed reports whether the provided field has an enabled index.
+	isIndexed func(field Field) bool
+
+	// schema returns the queries that create (if missing) the shadow table
+	// with the provided name and its related db indexes (if any).
+	schema func(tableName string) []string
+
+	// backfill indexes the existing collection records field values
+	// of the newly created shadow table.
+	//
+	// If not set, upsert is called for each collection record.
+	backfill func(app App, collection *Collection, field Field) error
+
+	// onDrop is an optional callback that is invoked after the shadow table drop,
+	// eg. to cleanup any other index state stored outside of the db.
+	onDrop func(app App, collection *Collection, field Field) error
+
+	// upsert replaces the index entry of the provided record field.
+	upsert func(app App, record *Record, field Field) error
+
+	// delete removes the index entry of the provided record field (if any).
+	delete func(app App, record *Record, field Field) error
+
+	// changed reports whether the indexed record field value was changed.
+	//
+	// If not set, the index entry is upserted on every record update.
+	changed func(record *Record, field Field) bool
+}
+
+// recordIndexRowid returns a stable integer rowid of the provided record id
+// that is used by the FTS5 and R*Tree index tables.
+//
+// The record table rowid is not used because it is not guaranteed
+// to be preserved (eg. during VACUUM for tables without INTEGER PRIMARY KEY).
+func recordIndexRowid(recordId string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(recordId))
+	return int64(h.Sum64())
+}
+
+// recordShadowTableName returns the name of the shadow table
+// with the provided prefix for the specified collection field.
+func recordShadowTableName(prefix string, collection *Collection, field Field) string {
+	return prefix + collection.Id + "_" + field.GetId()
+}
+
+// tableName returns the name of the shadow table of the specified collection field.
+func (st *recordShadowTable) tableName(collection *Collection, field Field) string {
+	return recordShadowTableName(st.prefix, collection, field)
+}
+
+// create creates (if missing) the shadow table of the provided
+// collection field and indexes all existing collection records field values.
+func (st *recordShadowTable) create(app App, collection *Collection, field Field) error {
+	for _, query := range st.schema(st.tableName(collection, field)) {
+		if _, err := app.DB().NewQuery(query).Execute(); err != nil {
+			return fmt.Errorf("failed to create %q %s table: %w", field.GetName(), st.label, err)
+		}
+	}
+
+	if st.backfill != nil {
+		return st.backfill(app, collection, field)
+	}
+
+	// index the existing records in batches
+	records := make([]*Record, 0, recordShadowTableBackfillBatchSize)
+	var lastId string
+	for {
+		err := app.RecordQueryWithDeleted(collection).
+			AndWhere(dbx.NewExp("[[id]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy("id ASC").
+			Limit(recordShadowTableBackfillBatchSize).
+			All(&records)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if err := st.upsert(app, record, field); err != nil {
+				return err
+			}
+		}
+
+		if len(records) < recordShadowTableBackfillBatchSize {
+			return nil
+		}
+
+		lastId = records[len(records)-1].Id
+		records = records[:0]
+	}
+}
+
+// drop deletes the shadow table of the provided collection field (if exists).
+func (st *recordShadowTable) drop(app App, collection *Collection, field Field) error {
+	_, err := app.DB().NewQuery("DROP TABLE IF EXISTS {{" + st.tableName(collection, field) + "}}").Execute()
+	if err != nil {
+		return fmt.Errorf("failed to drop %q %s table: %w", field.GetName(), st.label, err)
+	}
+
+	if st.onDrop != nil {
+		return st.onDrop(app, collection, field)
+	}
+
+	return nil
+}
+
+// sync creates or drops the shadow tables based
+// on the indexed fields changes between the 2 collections.
+//
+// oldCollection could be nil in case of a new collection.
+func (st *recordShadowTable) sync(app App, newCollection *Collection, oldCollection *Collection) error {
+	if newCollection.IsView() {
+		return nil
+	}
+
+	if oldCollection != nil {
+		for _, oldField := range oldCollection.Fields {
+			if !st.isIndexed(oldField) {
+				continue
+			}
+
+			newField := newCollection.Fields.GetById(oldField.GetId())
+			if newField == nil || !st.isIndexed(newField) {
+				if err := st.drop(app, oldCollection, oldField); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, newField := range newCollection.Fields {
+		if !st.isIndexed(newField) {
+			continue
+		}
+
+		if oldCollection != nil {
+			if oldField := oldCollection.Fields.GetById(newField.GetId()); oldField != nil && st.isIndexed(oldField) {
+				continue // already indexed
+			}
+		}
+
+		if err := st.create(app, newCollection, newField); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropAll drops all shadow tables of the provided collection.
+func (st *recordShadowTable) dropAll(app App, collection *Collection) error {
+	for _, field := range collection.Fields {
+		if !st.isIndexed(field) {
+			continue
+		}
+
+		if err := st.drop(app, collection, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// intercept keeps the index of the provided field in sync
+// with the record create, update and delete operations.
+func (st *recordShadowTable) intercept(
+	app App,
+	record *Record,
+	field Field,
+	actionName string,
+	actionFunc func() error,
+) error {
+	if !st.isIndexed(field) || record.Collection().IsView() {
+		return actionFunc()
+	}
+
+	switch actionName {
+	case InterceptorActionCreateExecute, InterceptorActionUpdateExecute:
+		changed := record.IsNew() || st.changed == nil || st.changed(record, field)
+
+		if err := actionFunc(); err != nil {
+			return err
+		}
+
+		if !changed {
+			return nil
+		}
+
+		if err := st.upsert(app, record, field); err != nil {
+			return fmt.Errorf("failed to update %q %s: %w", field.GetName(), st.label, err)
+		}
+
+		return nil
+	case InterceptorActionDeleteExecute:
+		if err := actionFunc(); err != nil {
+			return err
+		}
+
+		if err := st.delete(app, record, field); err != nil {
+			return fmt.Errorf("failed to delete %q %s entry: %w", field.GetName(), st.label, err)
+		}
+
+		return nil
+	}
+
+	return actionFunc()
+}
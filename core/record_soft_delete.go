@@ -0,0 +1,290 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type recordPurgeCtxKey struct{}
+
+// isRecordPurgeContext reports whether ctx was marked for permanent record deletion.
+func isRecordPurgeContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	v, _ := ctx.Value(recordPurgeCtxKey{}).(bool)
+
+	return v
+}
+
+// isRecordSoftDelete reports whether the record delete operation
+// should only mark the record as deleted.
+func isRecordSoftDelete(ctx context.Context, record *Record) bool {
+	collection := record.Collection()
+
+	return collection.SoftDelete && !collection.IsView() && !isRecordPurgeContext(ctx)
+}
+
+// findRecordByIdWithDeleted finds the collection record by its id
+// including the soft deleted ones.
+func findRecordByIdWithDeleted(app App, collection *Collection, id string) (*Record, error) {
+	record := &Record{}
+
+	err := app.RecordQueryWithDeleted(collection).
+		AndWhere(dbx.HashExp{inflector.Columnify(collection.Name) + "." + FieldNameId: id}).
+		Limit(1).
+		One(record)
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// PurgeRecord permanently deletes the provided record
+// (aka. bypassing the collection soft delete mode).
+//
+// The cascade deletes triggered by the record are also permanent.
+func (app *BaseApp) PurgeRecord(ctx context.Context, record *Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return app.DeleteWithContext(context.WithValue(ctx, recordPurgeCtxKey{}, true), record)
+}
+
+// RestoreDeletedRecord clears the deleted date of the provided
+// soft deleted record and saves it.
+func (app *BaseApp) RestoreDeletedRecord(ctx context.Context, record *Record) error {
+	if !record.Collection().SoftDelete {
+		return errors.New("the record collection doesn't have the soft delete mode enabled")
+	}
+
+	if record.GetDateTime(FieldNameDeleted).IsZero() {
+		return errors.New("the record is not deleted")
+	}
+
+	record.Set(FieldNameDeleted, "")
+
+	return app.SaveWithContext(ctx, record)
+}
+
+// onRecordSoftDeleteExecute marks the event record as deleted
+// and soft deletes its CascadeDelete references.
+func onRecordSoftDeleteExecute(e *RecordEvent) error {
+	// note: the select is outside of the transaction to minimize SQLITE_BUSY errors
+	refs, err := e.App.FindCachedCollectionReferences(e.Record.Collection())
+	if err != nil {
+		return err
+	}
+
+	originalApp := e.App
+	txErr := e.App.RunInTransaction(func(txApp App) error {
+		e.App = txApp
+
+		deleted := types.NowDateTime()
+
+		// already soft deleted records keep their original deleted date
+		result, err := txApp.NonconcurrentDB().Update(
+			e.Record.Collection().Name,
+			dbx.Params{FieldNameDeleted: deleted.String()},
+			dbx.HashExp{FieldNameId: e.Record.Id, FieldNameDeleted: ""},
+		).WithContext(e.Context).Execute()
+		if err != nil {
+			return err
+		}
+
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			e.Record.SetRaw(FieldNameDeleted, deleted)
+		}
+
+		return softCascadeRecordDelete(e.Context, txApp, e.Record, refs)
+	})
+	e.App = originalApp
+
+	return txErr
+}
+
+// softCascadeRecordDelete soft deletes the CascadeDelete references
+// of mainRecord that have no other related records.
+//
+// References from collections without soft delete mode and
+// non-cascade relations are left untouched so that the relations
+// are still valid when the main record is restored.
+//
+// NB! This method is expected to be called from inside of a transaction.
+func softCascadeRecordDelete(ctx context.Context, app App, mainRecord *Record, refs map[*Collection][]Field) error {
+	sortedRefKeys := make([]*Collection, 0, len(refs))
+	for k := range refs {
+		sortedRefKeys = append(sortedRefKeys, k)
+	}
+	sort.Slice(sortedRefKeys, func(i, j int) bool {
+		return sortedRefKeys[i].Name < sortedRefKeys[j].Name
+	})
+
+	for _, refCollection := range sortedRefKeys {
+		if refCollection.IsView() || !refCollection.SoftDelete {
+			continue
+		}
+
+		idColumn := inflector.Columnify(refCollection.Name) + "." + FieldNameId
+
+		for _, field := range refs[refCollection] {
+			relField, _ := field.(*RelationField)
+			if relField == nil || !relField.CascadeDelete {
+				continue
+			}
+
+			// the records with other active references remain in the
+			// query results so iterate by id instead of using a fixed window
+			batchSize := 4000
+			lastId := ""
+			rows := make([]*Record, 0, batchSize)
+			for {
+				query := app.RecordQuery(refCollection).
+					AndWhere(dbx.NewExp("[["+idColumn+"]] > {:lastId}", dbx.Params{"lastId": lastId})).
+					OrderBy(idColumn + " ASC").
+					Limit(int64(batchSize))
+
+				filterRecordReferences(query, mainRecord, refCollection, relField)
+
+				if err := query.All(&rows); err != nil {
+					return err
+				}
+
+				total := len(rows)
+				if total == 0 {
+					break
+				}
+
+				for _, refRecord := range rows {
+					if hasOtherActiveRefs(app, refRecord, relField, mainRecord.Id) {
+						continue
+					}
+
+					if err := app.DeleteWithContext(ctx, refRecord); err != nil {
+						return err
+					}
+				}
+
+				if total < batchSize {
+					break // no more items
+				}
+
+				lastId = rows[total-1].Id
+				rows = rows[:0] // keep allocated memory
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasOtherActiveRefs reports whether the record relation field
+// has other non-deleted related records than excludeId.
+func hasOtherActiveRefs(app App, record *Record, field *RelationField, excludeId string) bool {
+	ids := record.GetStringSlice(field.Name)
+
+	otherIds := make([]any, 0, len(ids))
+	for _, id := range ids {
+		if id != excludeId {
+			otherIds = append(otherIds, id)
+		}
+	}
+
+	if len(otherIds) == 0 {
+		return false
+	}
+
+	relCollection, err := app.FindCachedCollectionByNameOrId(field.CollectionId)
+	if err != nil {
+		return true // the relations can't be checked so assume that there are
+	}
+
+	var total int
+	err = app.RecordQuery(relCollection).
+		Select("count(*)").
+		AndWhere(dbx.In(inflector.Columnify(relCollection.Name)+"."+FieldNameId, otherIds...)).
+		Row(&total)
+
+	return err != nil || total > 0
+}
+
+func (app *BaseApp) registerRecordSoftDeleteHooks() {
+	// run on every hour to purge the soft deleted records outside of the collection retention period
+	app.Cron().Add("__pbSoftDeleteCleanup__", "0 * * * *", func() {
+		collections, err := app.FindAllCollections(CollectionTypeBase, CollectionTypeAuth)
+		if err != nil {
+			app.Logger().Warn("Failed to fetch the soft delete collections", "error", err)
+			return
+		}
+
+		for _, collection := range collections {
+			if !collection.SoftDelete || collection.SoftDeleteRetention <= 0 {
+				continue
+			}
+
+			deletedBefore := time.Now().AddDate(0, 0, -collection.SoftDeleteRetention)
+
+			if err := app.purgeDeletedRecords(collection, deletedBefore); err != nil {
+				app.Logger().Warn(
+					"Failed to purge the expired soft deleted records",
+					"collection", collection.Name,
+					"error", err,
+				)
+			}
+		}
+	})
+}
+
+// purgeDeletedRecords permanently deletes in batches the collection
+// records that were soft deleted before the specified date.
+func (app *BaseApp) purgeDeletedRecords(collection *Collection, deletedBefore time.Time) error {
+	before, err := types.ParseDateTime(deletedBefore)
+	if err != nil {
+		return err
+	}
+
+	deletedColumn := inflector.Columnify(collection.Name) + "." + FieldNameDeleted
+
+	batchSize := 100
+	rows := make([]*Record, 0, batchSize)
+	for {
+		err := app.RecordQueryWithDeleted(collection).
+			AndWhere(dbx.NewExp(
+				"[["+deletedColumn+"]] != '' AND [["+deletedColumn+"]] < {:before}",
+				dbx.Params{"before": before.String()},
+			)).
+			Limit(int64(batchSize)).
+			All(&rows)
+		if err != nil {
+			return err
+		}
+
+		total := len(rows)
+		if total == 0 {
+			return nil
+		}
+
+		for _, record := range rows {
+			// stop on the first failure to prevent endless retries of the same batch
+			if err := app.PurgeRecord(context.Background(), record); err != nil {
+				return fmt.Errorf("failed to purge record %q: %w", record.Id, err)
+			}
+		}
+
+		if total < batchSize {
+			return nil
+		}
+
+		rows = rows[:0] // keep allocated memory
+	}
+}
@@ -0,0 +1,365 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func createSoftDeleteTestCollections(t testing.TB, app core.App) (parents, children, plainChildren *core.Collection) {
+	parents = core.NewBaseCollection("soft_parents")
+	parents.SoftDelete = true
+	parents.Fields.Add(&core.TextField{Name: "title"})
+	if err := app.Save(parents); err != nil {
+		t.Fatal(err)
+	}
+
+	children = core.NewBaseCollection("soft_children")
+	children.SoftDelete = true
+	children.Fields.Add(&core.RelationField{Name: "parent", CollectionId: parents.Id, CascadeDelete: true, MaxSelect: 5})
+	if err := app.Save(children); err != nil {
+		t.Fatal(err)
+	}
+
+	plainChildren = core.NewBaseCollection("plain_children")
+	plainChildren.Fields.Add(&core.RelationField{Name: "parent", CollectionId: parents.Id, CascadeDelete: true})
+	if err := app.Save(plainChildren); err != nil {
+		t.Fatal(err)
+	}
+
+	return parents, children, plainChildren
+}
+
+func saveSoftDeleteTestRecord(t testing.TB, app core.App, collection *core.Collection, data map[string]any) *core.Record {
+	record := core.NewRecord(collection)
+	record.Load(data)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+func softDeleteRecordExists(app core.App, collection *core.Collection, id string, withDeleted bool) bool {
+	var query *dbx.SelectQuery
+	if withDeleted {
+		query = app.RecordQueryWithDeleted(collection)
+	} else {
+		query = app.RecordQuery(collection)
+	}
+
+	var total int
+	_ = query.Select("count(*)").AndWhere(dbx.HashExp{collection.Name + ".id": id}).Row(&total)
+
+	return total > 0
+}
+
+func TestCollectionSoftDeleteField(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("soft_test")
+	collection.SoftDelete = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	field, ok := collection.Fields.GetByName(core.FieldNameDeleted).(*core.DateField)
+	if !ok || !field.System {
+		t.Fatalf("Expected system %q date field, got %#v", core.FieldNameDeleted, field)
+	}
+
+	// disabling the soft delete mode should unlock the field
+	collection.SoftDelete = false
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	field, ok = collection.Fields.GetByName(core.FieldNameDeleted).(*core.DateField)
+	if !ok || field.System {
+		t.Fatalf("Expected non-system %q date field, got %#v", core.FieldNameDeleted, field)
+	}
+
+	view := core.NewViewCollection("soft_view")
+	view.ViewQuery = "select id from soft_test"
+	view.SoftDelete = true
+	err := app.Validate(view)
+	tests.TestValidationErrors(t, err, []string{"softDelete"})
+}
+
+func TestRecordSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	parents, children, plainChildren := createSoftDeleteTestCollections(t, app)
+
+	p1 := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"title": "p1"})
+	p2 := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"title": "p2"})
+	c1 := saveSoftDeleteTestRecord(t, app, children, map[string]any{"parent": []string{p1.Id}})
+	c2 := saveSoftDeleteTestRecord(t, app, children, map[string]any{"parent": []string{p1.Id, p2.Id}})
+	plain := saveSoftDeleteTestRecord(t, app, plainChildren, map[string]any{"parent": p1.Id})
+
+	if err := app.Delete(p1); err != nil {
+		t.Fatal(err)
+	}
+
+	if p1.GetDateTime(core.FieldNameDeleted).IsZero() {
+		t.Fatal("Expected the deleted date to be set")
+	}
+
+	scenarios := []struct {
+		name          string
+		collection    *core.Collection
+		id            string
+		expectExists  bool
+		expectDeleted bool
+	}{
+		{"soft deleted parent", parents, p1.Id, true, true},
+		{"untouched parent", parents, p2.Id, true, false},
+		{"soft cascaded child", children, c1.Id, true, true},
+		{"child with other active reference", children, c2.Id, true, false},
+		{"child from collection without soft delete", plainChildren, plain.Id, true, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := softDeleteRecordExists(app, s.collection, s.id, true); v != s.expectExists {
+				t.Fatalf("Expected row exists %v, got %v", s.expectExists, v)
+			}
+
+			if v := !softDeleteRecordExists(app, s.collection, s.id, false); v != s.expectDeleted {
+				t.Fatalf("Expected deleted %v, got %v", s.expectDeleted, v)
+			}
+		})
+	}
+
+	// soft deleted records are not valid relation targets
+	invalid := core.NewRecord(children)
+	invalid.Set("parent", p1.Id)
+	tests.TestValidationErrors(t, app.Validate(invalid), []string{"parent"})
+
+	// deleting an already soft deleted record should keep its deleted date
+	deletedDate := p1.GetString(core.FieldNameDeleted)
+	time.Sleep(5 * time.Millisecond)
+	if err := app.Delete(p1); err != nil {
+		t.Fatal(err)
+	}
+	if v := p1.GetString(core.FieldNameDeleted); v != deletedDate {
+		t.Fatalf("Expected deleted date %q, got %q", deletedDate, v)
+	}
+
+	if err := app.RestoreDeletedRecord(context.Background(), p1); err != nil {
+		t.Fatal(err)
+	}
+
+	if !softDeleteRecordExists(app, parents, p1.Id, false) {
+		t.Fatal("Expected the restored record to be found")
+	}
+
+	if err := app.RestoreDeletedRecord(context.Background(), p1); err == nil {
+		t.Fatal("Expected restore error for non-deleted record")
+	}
+}
+
+func TestPurgeRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	parents, children, plainChildren := createSoftDeleteTestCollections(t, app)
+
+	p1 := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"title": "p1"})
+	c1 := saveSoftDeleteTestRecord(t, app, children, map[string]any{"parent": []string{p1.Id}})
+	plain := saveSoftDeleteTestRecord(t, app, plainChildren, map[string]any{"parent": p1.Id})
+
+	// soft delete first to ensure that the deleted references are also purged
+	if err := app.Delete(p1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.PurgeRecord(context.Background(), p1); err != nil {
+		t.Fatal(err)
+	}
+
+	if softDeleteRecordExists(app, parents, p1.Id, true) {
+		t.Fatal("Expected the parent to be purged")
+	}
+
+	if softDeleteRecordExists(app, children, c1.Id, true) {
+		t.Fatal("Expected the soft deleted child to be purged")
+	}
+
+	if softDeleteRecordExists(app, plainChildren, plain.Id, true) {
+		t.Fatal("Expected the plain child to be deleted")
+	}
+}
+
+func TestRecordSoftDeleteRetention(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	parents, _, _ := createSoftDeleteTestCollections(t, app)
+	parents.SoftDeleteRetention = 2
+	if err := app.Save(parents); err != nil {
+		t.Fatal(err)
+	}
+
+	active := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"title": "active"})
+	recent := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"title": "recent"})
+	expired := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"title": "expired"})
+
+	if err := app.Delete(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	old, _ := types.ParseDateTime(time.Now().AddDate(0, 0, -3))
+	expired.Set(core.FieldNameDeleted, old)
+	if err := app.SaveNoValidate(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	var job func()
+	for _, j := range app.Cron().Jobs() {
+		if j.Id() == "__pbSoftDeleteCleanup__" {
+			job = j.Run
+		}
+	}
+	if job == nil {
+		t.Fatal("Missing __pbSoftDeleteCleanup__ cron job")
+	}
+	job()
+
+	expectations := map[string]bool{
+		active.Id:  true,
+		recent.Id:  true,
+		expired.Id: false,
+	}
+
+	for id, exists := range expectations {
+		if v := softDeleteRecordExists(app, parents, id, true); v != exists {
+			t.Errorf("Expected record %q exists %v, got %v", id, exists, v)
+		}
+	}
+}
+
+func TestRecordSoftDeleteFilterJoins(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	members := core.NewBaseCollection("soft_members")
+	members.SoftDelete = true
+	members.Fields.Add(&core.TextField{Name: "user"})
+	if err := app.Save(members); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := core.NewBaseCollection("soft_docs")
+	docs.SoftDelete = true
+	docs.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.RelationField{Name: "owner", CollectionId: members.Id, MaxSelect: 1},
+		&core.RelationField{Name: "members", CollectionId: members.Id, MaxSelect: 5},
+	)
+	if err := app.Save(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	m1 := saveSoftDeleteTestRecord(t, app, members, map[string]any{"user": "u1"})
+	m2 := saveSoftDeleteTestRecord(t, app, members, map[string]any{"user": "u2"})
+	saveSoftDeleteTestRecord(t, app, docs, map[string]any{"title": "d1", "owner": m1.Id, "members": []string{m1.Id, m2.Id}})
+	d2 := saveSoftDeleteTestRecord(t, app, docs, map[string]any{"title": "d2", "owner": m2.Id})
+
+	scenarios := []struct {
+		collection    *core.Collection
+		filter        string
+		expectedTotal int
+	}{
+		{docs, "@collection.soft_members.user ?= 'u1'", 0},
+		{docs, "@collection.soft_members.user ?= 'u2'", 1},
+		{docs, "owner.user = 'u1'", 0},
+		{docs, "owner.id = '" + m1.Id + "'", 0},
+		{docs, "members.user ?= 'u1'", 0},
+		{docs, "members.user ?= 'u2'", 1},
+		{members, "soft_docs_via_owner.title ?= 'd1'", 0},
+		{members, "soft_docs_via_owner.title ?= 'd2'", 0},
+		{members, "soft_docs_via_members.title ?= 'd1'", 1},
+	}
+
+	if err := app.Delete(m1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(d2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.collection.Name+"_"+s.filter, func(t *testing.T) {
+			records, err := app.FindRecordsByFilter(s.collection, s.filter, "", 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(records) != s.expectedTotal {
+				t.Fatalf("Expected %d records, got %d", s.expectedTotal, len(records))
+			}
+		})
+	}
+}
+
+func TestRecordSoftDeleteUniqueValues(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("soft_unique")
+	collection.SoftDelete = true
+	collection.Fields.Add(&core.TextField{Name: "code"})
+	collection.AddIndex("idx_soft_unique_code", true, "code", "")
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := saveSoftDeleteTestRecord(t, app, collection, map[string]any{"code": "a"})
+
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// the soft deleted records still reserve their unique values
+	// so that they can be always restored
+	duplicated := core.NewRecord(collection)
+	duplicated.Set("code", "a")
+	tests.TestValidationErrors(t, app.Save(duplicated), []string{"code"})
+
+	if err := app.RestoreDeletedRecord(context.Background(), record); err != nil {
+		t.Fatalf("Expected the soft deleted record to be restored, got %v", err)
+	}
+
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// the unique values are released after purge
+	if err := app.PurgeRecord(context.Background(), record); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Save(duplicated); err != nil {
+		t.Fatalf("Expected the purged record unique value to be reusable, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+)
+
+func (app *BaseApp) registerRecordTTLHooks() {
+	// run on every minute to delete the expired records of the TTL enabled collections
+	// (until deleted the expired records are excluded from the default records queries)
+	app.Cron().Add("__pbTTLCleanup__", "* * * * *", func() {
+		collections, err := app.FindAllCollections(CollectionTypeBase, CollectionTypeAuth)
+		if err != nil {
+			app.Logger().Warn("Failed to fetch the TTL collections", "error", err)
+			return
+		}
+
+		now := time.Now()
+
+		for _, collection := range collections {
+			if !collection.HasTTL() {
+				continue
+			}
+
+			if err := app.deleteExpiredRecords(collection, now); err != nil {
+				app.Logger().Warn(
+					"Failed to delete the expired records",
+					"collection", collection.Name,
+					"error", err,
+				)
+			}
+		}
+	})
+}
+
+// deleteExpiredRecords deletes in batches the collection records
+// that are expired at the specified time.
+//
+// The records are deleted one by one with [App.Delete] so that the
+// regular delete hooks are triggered (files cleanup, cascade delete, etc.).
+//
+// For collections with enabled soft delete mode, the expired records are
+// soft deleted and later purged based on the collection retention period.
+func (app *BaseApp) deleteExpiredRecords(collection *Collection, now time.Time) error {
+	tableName := inflector.Columnify(collection.Name)
+	ttlColumn := "[[" + tableName + "." + collection.TTLField + "]]"
+	idColumn := tableName + "." + FieldNameId
+
+	batchSize := 100
+	rows := make([]*Record, 0, batchSize)
+	lastId := ""
+	failed := 0
+	var lastErr error
+	for {
+		query := app.RecordQueryWithDeleted(collection).
+			AndWhere(dbx.NewExp(
+				ttlColumn+" != '' AND "+ttlColumn+" <= {:ttlThreshold}",
+				dbx.Params{"ttlThreshold": recordExpirationThreshold(collection, now).String()},
+			)).
+			AndWhere(dbx.NewExp("[["+idColumn+"]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy(idColumn + " ASC").
+			Limit(int64(batchSize))
+
+		// already soft deleted records are handled by the retention cleanup
+		if collection.SoftDelete {
+			query.AndWhere(dbx.HashExp{tableName + "." + FieldNameDeleted: ""})
+		}
+
+		if err := query.All(&rows); err != nil {
+			return err
+		}
+
+		total := len(rows)
+		if total == 0 {
+			break
+		}
+
+		for _, record := range rows {
+			// continue with the rest of the records because the failed one
+			// will be skipped anyway thanks to the id cursor
+			if err := app.Delete(record); err != nil {
+				failed++
+				lastErr = fmt.Errorf("failed to delete record %q: %w", record.Id, err)
+			}
+		}
+
+		lastId = rows[total-1].Id
+
+		if total < batchSize {
+			break
+		}
+
+		rows = rows[:0] // keep allocated memory
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d expired record(s) failed to delete, last error: %w", failed, lastErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+package core_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+func TestCollectionTTLOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name         string
+		collection   func() *core.Collection
+		expectErrors []string
+	}{
+		{
+			"disabled",
+			func() *core.Collection {
+				return core.NewBaseCollection("ttl_test")
+			},
+			[]string{},
+		},
+		{
+			"ttl without ttlField",
+			func() *core.Collection {
+				c := core.NewBaseCollection("ttl_test")
+				c.TTL = 10
+				return c
+			},
+			[]string{"ttl"},
+		},
+		{
+			"missing ttlField",
+			func() *core.Collection {
+				c := core.NewBaseCollection("ttl_test")
+				c.TTLField = "missing"
+				return c
+			},
+			[]string{"ttlField"},
+		},
+		{
+			"non-date ttlField",
+			func() *core.Collection {
+				c := core.NewBaseCollection("ttl_test")
+				c.Fields.Add(&core.TextField{Name: "expires"})
+				c.TTLField = "expires"
+				return c
+			},
+			[]string{"ttlField"},
+		},
+		{
+			"negative ttl",
+			func() *core.Collection {
+				c := core.NewBaseCollection("ttl_test")
+				c.Fields.Add(&core.DateField{Name: "expires"})
+				c.TTLField = "expires"
+				c.TTL = -1
+				return c
+			},
+			[]string{"ttl"},
+		},
+		{
+			"date ttlField",
+			func() *core.Collection {
+				c := core.NewBaseCollection("ttl_test")
+				c.Fields.Add(&core.DateField{Name: "expires"})
+				c.TTLField = "expires"
+				return c
+			},
+			[]string{},
+		},
+		{
+			"autodate ttlField with ttl",
+			func() *core.Collection {
+				c := core.NewAuthCollection("ttl_test")
+				c.Fields.Add(&core.AutodateField{Name: "created", OnCreate: true})
+				c.TTLField = "created"
+				c.TTL = 3600
+				return c
+			},
+			[]string{},
+		},
+		{
+			"view collection",
+			func() *core.Collection {
+				c := core.NewViewCollection("ttl_test")
+				c.ViewQuery = "select id, created from demo1"
+				c.TTLField = "created"
+				c.TTL = 10
+				return c
+			},
+			[]string{"ttlField", "ttl"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.collection())
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestRecordQueryExcludesExpired(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("ttl_test")
+	collection.Fields.Add(&core.DateField{Name: "expires"})
+	collection.TTLField = "expires"
+	collection.TTL = 60
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	noDate := saveSoftDeleteTestRecord(t, app, collection, map[string]any{})
+	active := saveSoftDeleteTestRecord(t, app, collection, map[string]any{"expires": time.Now().Add(-30 * time.Second)})
+	expired := saveSoftDeleteTestRecord(t, app, collection, map[string]any{"expires": time.Now().Add(-90 * time.Second)})
+
+	expectations := map[string]bool{
+		noDate.Id:  true,
+		active.Id:  true,
+		expired.Id: false,
+	}
+
+	for id, exists := range expectations {
+		if v := softDeleteRecordExists(app, collection, id, false); v != exists {
+			t.Errorf("Expected record %q to be visible %v, got %v", id, exists, v)
+		}
+
+		if !softDeleteRecordExists(app, collection, id, true) {
+			t.Errorf("Expected record %q to be returned with RecordQueryWithDeleted", id)
+		}
+	}
+
+	if _, err := app.FindRecordById(collection, expired.Id); err == nil {
+		t.Fatal("Expected the expired record to not be found")
+	}
+
+	result, err := app.AggregateRecords(collection, core.RecordsAggregateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || cast.ToInt(result[0]["count()"]) != 2 {
+		t.Fatalf("Expected 2 aggregated records, got %v", result)
+	}
+}
+
+func TestRecordTTLFilterJoins(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	members := core.NewAuthCollection("ttl_members")
+	members.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.DateField{Name: "expires"},
+	)
+	members.TTLField = "expires"
+	if err := app.Save(members); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := core.NewBaseCollection("ttl_docs")
+	docs.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.RelationField{Name: "owner", CollectionId: members.Id, MaxSelect: 1},
+		&core.RelationField{Name: "members", CollectionId: members.Id, MaxSelect: 5},
+	)
+	if err := app.Save(docs); err != nil {
+		t.Fatal(err)
+	}
+
+	m1 := saveSoftDeleteTestRecord(t, app, members, map[string]any{
+		"name":     "m1",
+		"email":    "ttl_m1@example.com",
+		"password": "1234567890",
+		"expires":  time.Now().Add(-time.Minute),
+	})
+	m2 := saveSoftDeleteTestRecord(t, app, members, map[string]any{
+		"name":     "m2",
+		"email":    "ttl_m2@example.com",
+		"password": "1234567890",
+		"expires":  time.Now().Add(time.Hour),
+	})
+	d1 := saveSoftDeleteTestRecord(t, app, docs, map[string]any{"title": "d1", "owner": m1.Id, "members": []string{m1.Id, m2.Id}})
+	saveSoftDeleteTestRecord(t, app, docs, map[string]any{"title": "d2", "owner": m2.Id})
+
+	t.Run("filters", func(t *testing.T) {
+		scenarios := []struct {
+			collection    *core.Collection
+			filter        string
+			expectedTotal int
+		}{
+			{docs, "@collection.ttl_members.name ?= 'm1'", 0},
+			{docs, "@collection.ttl_members.name ?= 'm2'", 2},
+			{docs, "owner.name = 'm1'", 0},
+			{docs, "owner.id = '" + m1.Id + "'", 0},
+			{docs, "owner.id = '" + m2.Id + "'", 1},
+			{docs, "members.name ?= 'm1'", 0},
+			{docs, "members.name ?= 'm2'", 1},
+			{members, "ttl_docs_via_owner.title ?= 'd2'", 1},
+		}
+
+		for _, s := range scenarios {
+			t.Run(s.collection.Name+"_"+s.filter, func(t *testing.T) {
+				records, err := app.FindRecordsByFilter(s.collection, s.filter, "", 0, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(records) != s.expectedTotal {
+					t.Fatalf("Expected %d records, got %d", s.expectedTotal, len(records))
+				}
+			})
+		}
+	})
+
+	t.Run("request auth", func(t *testing.T) {
+		rule := types.Pointer("@request.auth.name != ''")
+
+		scenarios := []struct {
+			auth     *core.Record
+			expected bool
+		}{
+			{m1, false},
+			{m2, true},
+		}
+
+		for _, s := range scenarios {
+			t.Run(s.auth.GetString("name"), func(t *testing.T) {
+				canAccess, err := app.CanAccessRecord(d1, &core.RequestInfo{Auth: s.auth}, rule)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if canAccess != s.expected {
+					t.Fatalf("Expected canAccess %v, got %v", s.expected, canAccess)
+				}
+			})
+		}
+	})
+}
+
+func TestRecordTTLCleanup(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	parents := core.NewBaseCollection("ttl_parents")
+	parents.Fields.Add(&core.DateField{Name: "expires"})
+	parents.TTLField = "expires"
+	if err := app.Save(parents); err != nil {
+		t.Fatal(err)
+	}
+
+	children := core.NewBaseCollection("ttl_children")
+	children.Fields.Add(&core.RelationField{Name: "parent", CollectionId: parents.Id, CascadeDelete: true})
+	if err := app.Save(children); err != nil {
+		t.Fatal(err)
+	}
+
+	softParents := core.NewBaseCollection("ttl_soft_parents")
+	softParents.SoftDelete = true
+	softParents.Fields.Add(&core.DateField{Name: "expires"})
+	softParents.TTLField = "expires"
+	if err := app.Save(softParents); err != nil {
+		t.Fatal(err)
+	}
+
+	past, _ := types.ParseDateTime(time.Now().Add(-1 * time.Minute))
+	future, _ := types.ParseDateTime(time.Now().Add(1 * time.Hour))
+
+	active := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"expires": future})
+	expired := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"expires": past})
+	protected := saveSoftDeleteTestRecord(t, app, parents, map[string]any{"expires": past})
+	child := saveSoftDeleteTestRecord(t, app, children, map[string]any{"parent": expired.Id})
+	softExpired := saveSoftDeleteTestRecord(t, app, softParents, map[string]any{"expires": past})
+
+	deleted := []string{}
+	app.OnRecordDelete(parents.Name, softParents.Name).BindFunc(func(e *core.RecordEvent) error {
+		if e.Record.Id == protected.Id {
+			return errors.New("test") // simulate a hook failure
+		}
+
+		deleted = append(deleted, e.Record.Id)
+
+		return e.Next()
+	})
+
+	var job func()
+	for _, j := range app.Cron().Jobs() {
+		if j.Id() == "__pbTTLCleanup__" {
+			job = j.Run
+		}
+	}
+	if job == nil {
+		t.Fatal("Missing __pbTTLCleanup__ cron job")
+	}
+	job()
+
+	if len(deleted) != 2 {
+		t.Fatalf("Expected 2 records to be deleted through the delete hooks, got %v", deleted)
+	}
+
+	expectations := map[string]bool{
+		active.Id:    true,
+		expired.Id:   false,
+		protected.Id: true,
+		child.Id:     false,
+	}
+
+	for id, exists := range expectations {
+		collection := parents
+		if id == child.Id {
+			collection = children
+		}
+
+		if v := softDeleteRecordExists(app, collection, id, true); v != exists {
+			t.Errorf("Expected record %q exists %v, got %v", id, exists, v)
+		}
+	}
+
+	// the expired soft delete records should be only soft deleted
+	if !softDeleteRecordExists(app, softParents, softExpired.Id, true) {
+		t.Fatal("Expected the expired record to be soft deleted, not purged")
+	}
+	softRecord, err := app.FindRecordById(softParents, softExpired.Id)
+	if err == nil || softRecord != nil {
+		t.Fatal("Expected the soft deleted record to be excluded from the default queries")
+	}
+
+	// rerun to ensure that the already soft deleted record is not processed again
+	deleted = deleted[:0]
+	job()
+	if len(deleted) != 0 {
+		t.Fatalf("Expected no deleted records on the second run, got %v", deleted)
+	}
+}
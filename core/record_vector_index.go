@@ -0,0 +1,592 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// recordVectorIndexTablePrefix is the name prefix of the shadow tables
+// that holds the IVF index list assignments of the vector record fields.
+const recordVectorIndexTablePrefix = "_vector_"
+
+// recordVectorIndexDir is the name of the pb_data subdirectory
+// where the IVF index centroids are persisted.
+const recordVectorIndexDir = "vector_indexes"
+
+const recordVectorIndexBatchSize = 500
+
+const (
+	maxVectorIndexLists       = 1024
+	maxVectorIndexTrainSize   = 4096
+	vectorIndexKMeansIters    = 10
+	vectorIndexFileMagic      = "PBIVF1"
+	vectorIndexStoreKeyPrefix = "pbVectorIndex_"
+)
+
+// recordVectorIndex is an IVF (inverted file) index of a single vector field.
+//
+// The indexed vectors are partitioned around the index centroids
+// (trained with k-means) and each record is assigned to the list
+// of its nearest centroid.
+type recordVectorIndex struct {
+	centroids []types.Vector
+
+	// trained is the number of the vectors at the time of the training
+	trained int
+}
+
+// probe returns the sorted ids of the n index lists whose centroids are nearest to v.
+func (idx *recordVectorIndex) probe(v types.Vector, n int) []int {
+	type candidate struct {
+		list     int
+		distance float64
+	}
+
+	candidates := make([]candidate, 0, len(idx.centroids))
+	for i, c := range idx.centroids {
+		if len(c) != len(v) {
+			continue
+		}
+		candidates = append(candidates, candidate{i, vectorSquaredL2(c, v)})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	n = min(n, len(candidates))
+
+	lists := make([]int, n)
+	for i := 0; i < n; i++ {
+		lists[i] = candidates[i].list
+	}
+
+	slices.Sort(lists)
+
+	return lists
+}
+
+// nearest returns the id of the index list whose centroid is nearest to v
+// (or -1 if the index is empty).
+func (idx *recordVectorIndex) nearest(v types.Vector) int {
+	lists := idx.probe(v, 1)
+	if len(lists) == 0 {
+		return -1
+	}
+
+	return lists[0]
+}
+
+// defaultProbes returns the default number of the lists to probe.
+func (idx *recordVectorIndex) defaultProbes() int {
+	n := int(math.Ceil(float64(len(idx.centroids)) / 4))
+
+	return min(max(n, 2), len(idx.centroids))
+}
+
+// recordVectorIndexTable manages the IVF index shadow tables of the vector indexed fields.
+//
+// Each table row holds the id of the index list (aka. the nearest centroid)
+// of a single record vector. The centroids themselves are persisted in a
+// separate file (see [recordVectorIndexFilePath]) because they are retrained
+// as a whole and are loaded in memory for every vector search.
+var recordVectorIndexTable = &recordShadowTable{
+	prefix:    recordVectorIndexTablePrefix,
+	label:     "vector index",
+	isIndexed: isVectorIndexedField,
+	schema: func(tableName string) []string {
+		return []string{
+			"CREATE TABLE IF NOT EXISTS {{" + tableName + "}} ([[id]] TEXT PRIMARY KEY NOT NULL, [[list]] INTEGER NOT NULL)",
+			"CREATE INDEX IF NOT EXISTS {{idx_" + tableName + "_list}} ON {{" + tableName + "}} ([[list]])",
+		}
+	},
+	// the centroids are trained from the existing vectors before assigning them
+	backfill: func(app App, collection *Collection, field Field) error {
+		_, err := rebuildRecordVectorIndex(app, collection, field.(*VectorField))
+		return err
+	},
+	onDrop: func(app App, collection *Collection, field Field) error {
+		return commitRecordVectorIndex(app, collection, field, nil)
+	},
+	upsert: func(app App, record *Record, field Field) error {
+		return upsertRecordVectorIndexEntry(app, record, field.(*VectorField))
+	},
+	delete: deleteRecordVectorIndexEntry,
+	// note: changed is not set (aka. always upserted) because the
+	// record original state is not refreshed between consecutive saves
+}
+
+// recordVectorIndexTableName returns the name of the IVF index shadow table
+// used to prefilter the nearest lists of the specified collection field.
+func recordVectorIndexTableName(collection *Collection, field Field) string {
+	return recordShadowTableName(recordVectorIndexTablePrefix, collection, field)
+}
+
+// recordVectorIndexFilePath returns the path to the persisted IVF index centroids
+// of the specified collection field.
+func recordVectorIndexFilePath(app App, collection *Collection, field Field) string {
+	return filepath.Join(app.DataDir(), recordVectorIndexDir, collection.Id+"_"+field.GetId()+".ivf")
+}
+
+func recordVectorIndexStoreKey(collection *Collection, field Field) string {
+	return vectorIndexStoreKeyPrefix + collection.Id + "_" + field.GetId()
+}
+
+// recordVectorIndexTxStoreKey returns the app store key of the IVF index
+// rebuilt (or dropped) as part of the provided not yet completed transaction.
+func recordVectorIndexTxStoreKey(txInfo *TxAppInfo, collection *Collection, field Field) string {
+	return fmt.Sprintf("%s_tx%p", recordVectorIndexStoreKey(collection, field), txInfo)
+}
+
+// isVectorIndexedField reports whether the provided field is a vector field with enabled IVF index.
+func isVectorIndexedField(field Field) bool {
+	f, ok := field.(*VectorField)
+	return ok && f.Index
+}
+
+// loadRecordVectorIndex returns the IVF index of the provided collection field.
+//
+// The index is loaded from the app store cache or from the persisted
+// centroids file. If the file is missing, the index is rebuilt.
+func loadRecordVectorIndex(app App, collection *Collection, field *VectorField) (*recordVectorIndex, error) {
+	// rebuilt or dropped as part of the current transaction
+	if txInfo := app.TxInfo(); txInfo != nil {
+		if idx, ok := app.Store().Get(recordVectorIndexTxStoreKey(txInfo, collection, field)).(*recordVectorIndex); ok {
+			if idx == nil {
+				return rebuildRecordVectorIndex(app, collection, field)
+			}
+
+			return idx, nil
+		}
+	}
+
+	if idx, ok := app.Store().Get(recordVectorIndexStoreKey(collection, field)).(*recordVectorIndex); ok {
+		return idx, nil
+	}
+
+	data, err := os.ReadFile(recordVectorIndexFilePath(app, collection, field))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return rebuildRecordVectorIndex(app, collection, field)
+		}
+
+		return nil, err
+	}
+
+	idx, err := decodeRecordVectorIndex(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %q vector index: %w", field.Name, err)
+	}
+
+	app.Store().Set(recordVectorIndexStoreKey(collection, field), idx)
+
+	return idx, nil
+}
+
+// rebuildRecordVectorIndex trains new IVF index centroids from the current
+// collection records field values and reassigns all records to their nearest list.
+func rebuildRecordVectorIndex(app App, collection *Collection, field *VectorField) (*recordVectorIndex, error) {
+	idx, err := trainRecordVectorIndex(app, collection, field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to train %q vector index: %w", field.Name, err)
+	}
+
+	tableName := recordVectorIndexTableName(collection, field)
+
+	err = app.RunInTransaction(func(txApp App) error {
+		if _, err := txApp.DB().NewQuery("DELETE FROM {{" + tableName + "}}").Execute(); err != nil {
+			return err
+		}
+
+		if len(idx.centroids) == 0 {
+			return nil
+		}
+
+		return forEachRecordVector(txApp, collection, field, func(id string, v types.Vector) error {
+			_, err := txApp.DB().Insert(tableName, dbx.Params{"id": id, "list": idx.nearest(v)}).Execute()
+			return err
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rebuild %q vector index: %w", field.Name, err)
+	}
+
+	if err := commitRecordVectorIndex(app, collection, field, idx); err != nil {
+		return nil, err
+	}
+
+	return idx, nil
+}
+
+// commitRecordVectorIndex persists the provided IVF index centroids and
+// replaces the cached index of the collection field (nil idx deletes them).
+//
+// If app is part of a transaction, the index file and cache are updated
+// only after the transaction has completed successfully.
+// Until then the index is available only for the transaction itself.
+func commitRecordVectorIndex(app App, collection *Collection, field Field, idx *recordVectorIndex) error {
+	txInfo := app.TxInfo()
+	if txInfo == nil {
+		return writeRecordVectorIndex(app, collection, field, idx)
+	}
+
+	txKey := recordVectorIndexTxStoreKey(txInfo, collection, field)
+
+	// register the complete callback only once per transaction
+	isPending := app.Store().Has(txKey)
+
+	app.Store().Set(txKey, idx)
+
+	if !isPending {
+		txInfo.OnComplete(func(txErr error) error {
+			pending, _ := app.Store().Get(txKey).(*recordVectorIndex)
+			app.Store().Remove(txKey)
+
+			if txErr != nil {
+				return nil
+			}
+
+			return writeRecordVectorIndex(app, collection, field, pending)
+		})
+	}
+
+	return nil
+}
+
+// writeRecordVectorIndex saves the provided IVF index centroids file
+// and the app store cache (or deletes them if idx is nil).
+func writeRecordVectorIndex(app App, collection *Collection, field Field, idx *recordVectorIndex) error {
+	storeKey := recordVectorIndexStoreKey(collection, field)
+	path := recordVectorIndexFilePath(app, collection, field)
+
+	if idx == nil {
+		app.Store().Remove(storeKey)
+
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete %q vector index file: %w", field.GetName(), err)
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, idx.encode(), 0644); err != nil {
+		return fmt.Errorf("failed to save %q vector index: %w", field.GetName(), err)
+	}
+
+	app.Store().Set(storeKey, idx)
+
+	return nil
+}
+
+// trainRecordVectorIndex runs k-means over a sample of the collection
+// records field values and returns the resulting index centroids.
+//
+// The number of the index lists is sqrt(n) (up to [maxVectorIndexLists]).
+func trainRecordVectorIndex(app App, collection *Collection, field *VectorField) (*recordVectorIndex, error) {
+	var total int
+	err := app.DB().Select("count(*)").
+		From(collection.Name).
+		Where(dbx.NewExp("[[" + field.Name + "]] IS NOT NULL")).
+		Row(&total)
+	if err != nil {
+		return nil, err
+	}
+
+	stride := max(1, total/maxVectorIndexTrainSize)
+
+	samples := make([]types.Vector, 0, min(total, maxVectorIndexTrainSize))
+	var i int
+	err = forEachRecordVector(app, collection, field, func(id string, v types.Vector) error {
+		if i%stride == 0 && len(samples) < maxVectorIndexTrainSize {
+			samples = append(samples, v)
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &recordVectorIndex{trained: i}
+
+	if len(samples) == 0 {
+		return idx, nil
+	}
+
+	lists := min(max(int(math.Sqrt(float64(i))), 1), maxVectorIndexLists, len(samples))
+
+	idx.centroids = vectorKMeans(samples, lists, vectorIndexKMeansIters)
+
+	return idx, nil
+}
+
+// forEachRecordVector calls fn in batches for each non-empty
+// collection record field value with the expected dimensions.
+func forEachRecordVector(app App, collection *Collection, field *VectorField, fn func(id string, v types.Vector) error) error {
+	type row struct {
+		Id   string `db:"id"`
+		Data []byte `db:"data"`
+	}
+
+	rows := make([]row, 0, recordVectorIndexBatchSize)
+	var lastId string
+	for {
+		err := app.DB().NewQuery(
+			"SELECT [[id]], [[" + field.Name + "]] AS [[data]] FROM {{" + collection.Name + "}} " +
+				"WHERE [[id]] > {:lastId} AND [[" + field.Name + "]] IS NOT NULL ORDER BY [[id]] ASC LIMIT {:limit}",
+		).Bind(dbx.Params{
+			"lastId": lastId,
+			"limit":  recordVectorIndexBatchSize,
+		}).All(&rows)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rows {
+			v, err := types.ParseVectorBytes(r.Data)
+			if err != nil || len(v) != field.Dimensions {
+				continue // malformed value
+			}
+
+			if err := fn(r.Id, v); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < recordVectorIndexBatchSize {
+			return nil
+		}
+
+		lastId = rows[len(rows)-1].Id
+		rows = rows[:0]
+	}
+}
+
+// vectorKMeans partitions the vectors into k clusters and returns their centroids.
+//
+// The initial centroids are evenly picked from the provided vectors
+// and clusters that end up empty keep their previous centroid.
+func vectorKMeans(vectors []types.Vector, k int, iterations int) []types.Vector {
+	dims := len(vectors[0])
+
+	centroids := make([]types.Vector, k)
+	for i := range centroids {
+		centroids[i] = slices.Clone(vectors[i*len(vectors)/k])
+	}
+
+	idx := &recordVectorIndex{centroids: centroids}
+	assignments := make([]int, len(vectors))
+
+	for iter := 0; iter < iterations; iter++ {
+		changed := iter == 0
+
+		for i, v := range vectors {
+			list := idx.nearest(v)
+			if list != assignments[i] {
+				assignments[i] = list
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+
+		sums := make([][]float64, k)
+		counts := make([]int, k)
+		for i, v := range vectors {
+			list := assignments[i]
+			if sums[list] == nil {
+				sums[list] = make([]float64, dims)
+			}
+			for j, n := range v {
+				sums[list][j] += float64(n)
+			}
+			counts[list]++
+		}
+
+		for list, sum := range sums {
+			if counts[list] == 0 {
+				continue
+			}
+			for j := range sum {
+				centroids[list][j] = float32(sum[j] / float64(counts[list]))
+			}
+		}
+	}
+
+	return centroids
+}
+
+func vectorSquaredL2(a, b types.Vector) float64 {
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return sum
+}
+
+// encode serializes the index centroids in the following binary format:
+//
+//	magic | uint32 lists | uint32 dimensions | uint64 trained | float32 centroids data
+func (idx *recordVectorIndex) encode() []byte {
+	var dims int
+	if len(idx.centroids) > 0 {
+		dims = len(idx.centroids[0])
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(vectorIndexFileMagic)+16+len(idx.centroids)*dims*4))
+	buf.WriteString(vectorIndexFileMagic)
+	binary.Write(buf, binary.LittleEndian, uint32(len(idx.centroids)))
+	binary.Write(buf, binary.LittleEndian, uint32(dims))
+	binary.Write(buf, binary.LittleEndian, uint64(idx.trained))
+	for _, c := range idx.centroids {
+		buf.Write(c.Bytes())
+	}
+
+	return buf.Bytes()
+}
+
+// decodeRecordVectorIndex parses the result of [recordVectorIndex.encode].
+func decodeRecordVectorIndex(data []byte) (*recordVectorIndex, error) {
+	headerSize := len(vectorIndexFileMagic) + 16
+
+	if len(data) < headerSize || string(data[:len(vectorIndexFileMagic)]) != vectorIndexFileMagic {
+		return nil, errors.New("invalid vector index header")
+	}
+
+	data = data[len(vectorIndexFileMagic):]
+
+	lists := int(binary.LittleEndian.Uint32(data))
+	dims := int(binary.LittleEndian.Uint32(data[4:]))
+	trained := int(binary.LittleEndian.Uint64(data[8:]))
+	data = data[16:]
+
+	if len(data) != lists*dims*4 {
+		return nil, errors.New("invalid vector index data size")
+	}
+
+	idx := &recordVectorIndex{
+		centroids: make([]types.Vector, lists),
+		trained:   trained,
+	}
+
+	for i := range idx.centroids {
+		idx.centroids[i], _ = types.ParseVectorBytes(data[i*dims*4 : (i+1)*dims*4])
+	}
+
+	return idx, nil
+}
+
+// upsertRecordVectorIndexEntry replaces the IVF index list assignment of the provided record field.
+func upsertRecordVectorIndexEntry(app App, record *Record, field *VectorField) error {
+	v, _ := record.GetRaw(field.Name).(types.Vector)
+	if len(v) != field.Dimensions {
+		return deleteRecordVectorIndexEntry(app, record, field)
+	}
+
+	idx, err := loadRecordVectorIndex(app, record.Collection(), field)
+	if err != nil {
+		return err
+	}
+
+	// not trained yet (eg. the index was created for an empty collection)
+	// so train it with the current records field values including the new one
+	if len(idx.centroids) == 0 {
+		_, err := rebuildRecordVectorIndex(app, record.Collection(), field)
+		return err
+	}
+
+	list := idx.nearest(v)
+
+	_, err = app.DB().NewQuery(
+		"INSERT OR REPLACE INTO {{" + recordVectorIndexTableName(record.Collection(), field) + "}} ([[id]], [[list]]) VALUES ({:id}, {:list})",
+	).Bind(dbx.Params{
+		"id":   record.Id,
+		"list": list,
+	}).Execute()
+
+	return err
+}
+
+// deleteRecordVectorIndexEntry removes the IVF index entry of the provided record field (if any).
+func deleteRecordVectorIndexEntry(app App, record *Record, field Field) error {
+	_, err := app.DB().Delete(
+		recordVectorIndexTableName(record.Collection(), field),
+		dbx.HashExp{"id": record.Id},
+	).Execute()
+
+	return err
+}
+
+func (app *BaseApp) registerRecordVectorIndexHooks() {
+	// run on every hour to retrain the vector indexes that have grown significantly since their last training
+	app.Cron().Add("__pbVectorIndexes__", "0 * * * *", func() {
+		collections, err := app.FindAllCollections(CollectionTypeBase, CollectionTypeAuth)
+		if err != nil {
+			app.Logger().Warn("Failed to fetch the vector indexed collections", "error", err)
+			return
+		}
+
+		for _, collection := range collections {
+			for _, f := range collection.Fields {
+				field, ok := f.(*VectorField)
+				if !ok || !field.Index {
+					continue
+				}
+
+				if err := app.retrainRecordVectorIndexIfNeeded(collection, field); err != nil {
+					app.Logger().Warn(
+						"Failed to retrain the vector index",
+						"collection", collection.Name,
+						"field", field.Name,
+						"error", err,
+					)
+				}
+			}
+		}
+	})
+}
+
+// retrainRecordVectorIndexIfNeeded rebuilds the IVF index of the provided field
+// if the number of the indexed vectors has at least doubled since its last training.
+func (app *BaseApp) retrainRecordVectorIndexIfNeeded(collection *Collection, field *VectorField) error {
+	idx, err := loadRecordVectorIndex(app, collection, field)
+	if err != nil {
+		return err
+	}
+
+	var total int
+	err = app.DB().Select("count(*)").
+		From(collection.Name).
+		Where(dbx.NewExp("[[" + field.Name + "]] IS NOT NULL")).
+		Row(&total)
+	if err != nil {
+		return err
+	}
+
+	if total == 0 || total < 2*idx.trained {
+		return nil
+	}
+
+	_, err = rebuildRecordVectorIndex(app, collection, field)
+
+	return err
+}
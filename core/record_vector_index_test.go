@@ -0,0 +1,630 @@
+package core_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestRecordVectorIndexSync(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("vector_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.VectorField{Name: "embedding", Dimensions: 2},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	field := collection.Fields.GetByName("embedding").(*core.VectorField)
+	indexTable := "_vector_" + collection.Id + "_" + field.Id
+	indexFile := filepath.Join(app.DataDir(), "vector_indexes", collection.Id+"_"+field.Id+".ivf")
+
+	for i, name := range []string{"a", "b", "c", "d"} {
+		record := core.NewRecord(collection)
+		record.Set("name", name)
+		record.Set("embedding", types.Vector{float32(i), 1})
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// without vector
+	empty := core.NewRecord(collection)
+	empty.Set("name", "empty")
+	if err := app.Save(empty); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.HasTable(indexTable) {
+		t.Fatalf("Expected %q to not exist", indexTable)
+	}
+
+	// enable and index the existing records
+	field.Index = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if !app.HasTable(indexTable) {
+		t.Fatalf("Expected %q to be created", indexTable)
+	}
+	if _, err := os.Stat(indexFile); err != nil {
+		t.Fatalf("Expected %q to be created: %v", indexFile, err)
+	}
+	assertVectorIndexEntries(t, app, indexTable, 4)
+
+	// create
+	record := core.NewRecord(collection)
+	record.Set("name", "e")
+	record.Set("embedding", "[10, 10]")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	assertVectorIndexEntries(t, app, indexTable, 5)
+
+	// update (unset)
+	record.Set("embedding", nil)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+	assertVectorIndexEntries(t, app, indexTable, 4)
+
+	// update (set)
+	empty.Set("embedding", "[1, 1]")
+	if err := app.Save(empty); err != nil {
+		t.Fatal(err)
+	}
+	assertVectorIndexEntries(t, app, indexTable, 5)
+
+	// delete
+	if err := app.Delete(empty); err != nil {
+		t.Fatal(err)
+	}
+	assertVectorIndexEntries(t, app, indexTable, 4)
+
+	// disable
+	field.Index = false
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(indexTable) {
+		t.Fatalf("Expected %q to be deleted", indexTable)
+	}
+	if _, err := os.Stat(indexFile); err == nil {
+		t.Fatalf("Expected %q to be deleted", indexFile)
+	}
+
+	// reenable and delete the collection
+	field.Index = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	if !app.HasTable(indexTable) {
+		t.Fatalf("Expected %q to be recreated", indexTable)
+	}
+	assertVectorIndexEntries(t, app, indexTable, 4)
+
+	if err := app.Delete(collection); err != nil {
+		t.Fatal(err)
+	}
+	if app.HasTable(indexTable) {
+		t.Fatalf("Expected %q to be deleted after the collection removal", indexTable)
+	}
+	if _, err := os.Stat(indexFile); err == nil {
+		t.Fatalf("Expected %q to be deleted after the collection removal", indexFile)
+	}
+}
+
+func TestRecordVectorIndexMissingFile(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createVectorTestCollection(t, app)
+
+	field := collection.Fields.GetByName("indexed").(*core.VectorField)
+	indexTable := "_vector_" + collection.Id + "_" + field.Id
+	indexFile := filepath.Join(app.DataDir(), "vector_indexes", collection.Id+"_"+field.Id+".ivf")
+
+	// simulate fresh app start with missing index file
+	app.Store().Remove("pbVectorIndex_" + collection.Id + "_" + field.Id)
+	if err := os.Remove(indexFile); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("name", "new")
+	record.Set("indexed", "[0, 0, 1]")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(indexFile); err != nil {
+		t.Fatalf("Expected %q to be rebuilt: %v", indexFile, err)
+	}
+	assertVectorIndexEntries(t, app, indexTable, 5)
+}
+
+func TestRecordVectorIndexTransactionRollback(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createVectorTestCollection(t, app)
+
+	indexed := collection.Fields.GetByName("indexed").(*core.VectorField)
+	indexedFile := filepath.Join(app.DataDir(), "vector_indexes", collection.Id+"_"+indexed.Id+".ivf")
+	indexedStoreKey := "pbVectorIndex_" + collection.Id + "_" + indexed.Id
+
+	plain := collection.Fields.GetByName("plain").(*core.VectorField)
+	plainTable := "_vector_" + collection.Id + "_" + plain.Id
+	plainFile := filepath.Join(app.DataDir(), "vector_indexes", collection.Id+"_"+plain.Id+".ivf")
+	plainStoreKey := "pbVectorIndex_" + collection.Id + "_" + plain.Id
+
+	cachedIndexed := app.Store().Get(indexedStoreKey)
+	if cachedIndexed == nil {
+		t.Fatalf("Expected %q to be cached", indexedStoreKey)
+	}
+
+	rollbackErr := errors.New("rollback")
+
+	err := app.RunInTransaction(func(txApp core.App) error {
+		c, err := txApp.FindCollectionByNameOrId(collection.Id)
+		if err != nil {
+			return err
+		}
+
+		c.Fields.GetByName("indexed").(*core.VectorField).Index = false
+		c.Fields.GetByName("plain").(*core.VectorField).Index = true
+		if err := txApp.Save(c); err != nil {
+			return err
+		}
+
+		// the transaction sees its own changes
+		if !txApp.HasTable(plainTable) {
+			t.Fatalf("Expected %q to be created within the transaction", plainTable)
+		}
+
+		// but the file and cache are not changed until the transaction completes
+		if _, err := os.Stat(plainFile); err == nil {
+			t.Fatalf("Expected %q to not be created within the transaction", plainFile)
+		}
+		if _, err := os.Stat(indexedFile); err != nil {
+			t.Fatalf("Expected %q to not be deleted within the transaction", indexedFile)
+		}
+
+		return rollbackErr
+	})
+	if !errors.Is(err, rollbackErr) {
+		t.Fatalf("Expected the rollback error, got %v", err)
+	}
+
+	if app.HasTable(plainTable) {
+		t.Fatalf("Expected %q to not exist", plainTable)
+	}
+	if _, err := os.Stat(plainFile); err == nil {
+		t.Fatalf("Expected %q to not exist", plainFile)
+	}
+	if app.Store().Has(plainStoreKey) {
+		t.Fatalf("Expected %q to not be cached", plainStoreKey)
+	}
+
+	if _, err := os.Stat(indexedFile); err != nil {
+		t.Fatalf("Expected %q to still exist: %v", indexedFile, err)
+	}
+	if v := app.Store().Get(indexedStoreKey); v != cachedIndexed {
+		t.Fatalf("Expected %q to remain cached", indexedStoreKey)
+	}
+
+	for key := range app.Store().GetAll() {
+		if strings.Contains(key, "_tx") {
+			t.Fatalf("Expected the transaction store key %q to be removed", key)
+		}
+	}
+
+	// commit
+	err = app.RunInTransaction(func(txApp core.App) error {
+		c, err := txApp.FindCollectionByNameOrId(collection.Id)
+		if err != nil {
+			return err
+		}
+
+		c.Fields.GetByName("indexed").(*core.VectorField).Index = false
+		c.Fields.GetByName("plain").(*core.VectorField).Index = true
+
+		return txApp.Save(c)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(plainFile); err != nil {
+		t.Fatalf("Expected %q to be created: %v", plainFile, err)
+	}
+	if !app.Store().Has(plainStoreKey) {
+		t.Fatalf("Expected %q to be cached", plainStoreKey)
+	}
+	assertVectorIndexEntries(t, app, plainTable, 4)
+
+	if _, err := os.Stat(indexedFile); err == nil {
+		t.Fatalf("Expected %q to be deleted", indexedFile)
+	}
+	if app.Store().Has(indexedStoreKey) {
+		t.Fatalf("Expected %q to be removed from the cache", indexedStoreKey)
+	}
+}
+
+func TestRecordVectorIndexProbes(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("vector_test")
+	collection.Fields.Add(
+		&core.VectorField{Name: "indexed", Dimensions: 2, Index: true, Probes: 1},
+		&core.VectorField{Name: "plain", Dimensions: 2},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 well separated clusters
+	for i := 0; i < 16; i++ {
+		x := float32(10 + i%4)
+		if i >= 8 {
+			x = -x
+		}
+		v := types.Vector{x, float32(i % 3)}
+
+		record := core.NewRecord(collection)
+		record.Set("indexed", v)
+		record.Set("plain", v)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// retrain with all records
+	collection.Fields.GetByName("indexed").(*core.VectorField).Index = false
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+	collection.Fields.GetByName("indexed").(*core.VectorField).Index = true
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := core.NewRecordFieldResolver(app, collection, &core.RequestInfo{Query: map[string]string{"q": "[11, 1]"}}, false)
+	expr, err := search.FilterData("vectorDistance(indexed, @request.query.q, 'l2') < 100").BuildExpr(resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []*core.Record{}
+	if err := app.RecordQuery(collection).AndWhere(expr).All(&records); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the records from the single nearest list are expected
+	// (the list could contain the entire cluster depending on the centroids initialization)
+	if len(records) == 0 || len(records) > 8 {
+		t.Fatalf("Expected between 1 and 8 records from the nearest index list, got %d", len(records))
+	}
+	for _, r := range records {
+		v, _ := r.GetRaw("indexed").(types.Vector)
+		if len(v) != 2 || v[0] < 0 {
+			t.Fatalf("Expected only records from the nearest cluster, got %v", v)
+		}
+	}
+}
+
+func TestRecordVectorDistance(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createVectorTestCollection(t, app)
+
+	scenarios := []struct {
+		filter      string
+		sort        string
+		query       string
+		expectError bool
+		expected    []string
+	}{
+		// sort (note: NULLs are first for ASC)
+		{"", "vectorDistance(FIELD, @request.query.q),name", "[1, 0, 0]", false, []string{"null", "x", "xy", "y", "z"}},
+		{"", "-vectorDistance(FIELD, @request.query.q, 'cosine'),name", "[1, 0, 0]", false, []string{"y", "z", "xy", "x", "null"}},
+		{"", "vectorDistance(FIELD, @request.query.q, 'l2'),name", "0,2,0", false, []string{"null", "y", "xy", "x", "z"}},
+		{"", "vectorDistance(FIELD, @request.query.q, 'dot'),name", "[0, 2, 0]", false, []string{"null", "xy", "y", "x", "z"}},
+		// missing query vector
+		{"", "vectorDistance(FIELD, @request.query.missing),name", "", false, []string{"null", "x", "xy", "y", "z"}},
+		// filter
+		{"vectorDistance(FIELD, @request.query.q) < 0.5", "name", "[1, 0, 0]", false, []string{"x", "xy"}},
+		{"0.5 > vectorDistance(FIELD, @request.query.q, 'cosine')", "name", "[1, 0, 0]", false, []string{"x", "xy"}},
+		{"vectorDistance(FIELD, @request.query.q) >= 0.5", "name", "[1, 0, 0]", false, []string{"y", "z"}},
+		{"vectorDistance(FIELD, @request.query.q, 'l2') <= 1", "name", "[0, 0, 2]", false, []string{"z"}},
+		// field to field
+		{"vectorDistance(FIELD, plain, 'l2') = 0", "name", "", false, []string{"x", "xy", "y", "z"}},
+		// errors
+		{"", "vectorDistance(FIELD, @request.query.q)", "[1, 0]", true, nil},
+		{"", "vectorDistance(FIELD, @request.query.q)", "[1, 0, 'a']", true, nil},
+		{"", "vectorDistance(FIELD, @request.query.q, 'invalid')", "[1, 0, 0]", true, nil},
+		{"", "vectorDistance(FIELD)", "[1, 0, 0]", true, nil},
+		{"", "vectorDistance(name, @request.query.q)", "[1, 0, 0]", true, nil},
+		{"", "vectorDistance(FIELD, name)", "[1, 0, 0]", true, nil},
+		{"", "vectorDistance(@request.query.q, FIELD)", "[1, 0, 0]", true, nil},
+		{"vectorDistance(FIELD, '[1,0,0]') < 1", "", "", true, nil},
+	}
+
+	for _, s := range scenarios {
+		for _, field := range []string{"indexed", "plain"} {
+			filter := strings.ReplaceAll(s.filter, "FIELD", field)
+			sort := strings.ReplaceAll(s.sort, "FIELD", field)
+
+			t.Run(filter+"_"+sort, func(t *testing.T) {
+				requestInfo := &core.RequestInfo{Query: map[string]string{"q": s.query}}
+
+				resolver := core.NewRecordFieldResolver(app, collection, requestInfo, false)
+
+				query := app.RecordQuery(collection)
+
+				if filter != "" {
+					expr, err := search.FilterData(filter).BuildExpr(resolver)
+					hasErr := err != nil
+					if hasErr != s.expectError {
+						t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+					}
+					if hasErr {
+						return
+					}
+
+					rawSQL := expr.Build(app.ConcurrentDB().(*dbx.DB), dbx.Params{})
+					hasPrefilter := strings.Contains(rawSQL, "IN (SELECT")
+					expectPrefilter := field == "indexed" && strings.Contains(filter, "@request") && !strings.Contains(filter, ">=")
+					if hasPrefilter != expectPrefilter {
+						t.Fatalf("Expected hasPrefilter %v, got %v\n%s", expectPrefilter, hasPrefilter, rawSQL)
+					}
+
+					query.AndWhere(expr)
+				}
+
+				if sort != "" {
+					for _, sortField := range search.ParseSortFromString(sort) {
+						expr, err := sortField.BuildExpr(resolver)
+						hasErr := err != nil
+						if hasErr != s.expectError {
+							t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+						}
+						if hasErr {
+							return
+						}
+
+						query.AndOrderBy(expr)
+					}
+				}
+
+				if err := resolver.UpdateQuery(query); err != nil {
+					t.Fatal(err)
+				}
+
+				records := []*core.Record{}
+				if err := query.All(&records); err != nil {
+					t.Fatal(err)
+				}
+
+				names := make([]string, len(records))
+				for i, r := range records {
+					names[i] = r.GetString("name")
+				}
+
+				if !slices.Equal(names, s.expected) {
+					t.Fatalf("Expected names %v, got %v", s.expected, names)
+				}
+			})
+		}
+	}
+}
+
+func TestRecordVectorDistanceProvider(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createVectorTestCollection(t, app)
+
+	requestInfo := &core.RequestInfo{Query: map[string]string{"q": "[1, 0, 0]"}}
+
+	scenarios := []struct {
+		name     string
+		hidden   bool
+		params   string
+		expected []string
+	}{
+		{
+			"page 1",
+			false,
+			"page=1&perPage=2&sort=vectorDistance(indexed, @request.query.q, 'cosine'),name",
+			[]string{"x", "xy"},
+		},
+		{
+			"page 2",
+			false,
+			"page=2&perPage=2&sort=vectorDistance(indexed, @request.query.q, 'cosine'),name",
+			[]string{"y", "z"},
+		},
+		{
+			"with filter",
+			false,
+			"page=1&perPage=2&filter=vectorDistance(indexed, @request.query.q) < 1.5&sort=-vectorDistance(indexed, @request.query.q),-name",
+			[]string{"z", "y"},
+		},
+		{
+			"hidden field",
+			true,
+			"sort=vectorDistance(indexed, @request.query.q)",
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			c, err := app.FindCollectionByNameOrId(collection.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// emulate the list rule
+			rule := "name != 'null'"
+			c.ListRule = &rule
+			if s.hidden {
+				c.Fields.GetByName("indexed").SetHidden(true)
+			}
+
+			resolver := core.NewRecordFieldResolver(app, c, requestInfo, false)
+
+			records := []*core.Record{}
+
+			provider := search.NewProvider(resolver).
+				Query(app.RecordQuery(c)).
+				AddFilter(search.FilterData(*c.ListRule))
+
+			_, err = provider.ParseAndExec(s.params, &records)
+
+			hasErr := err != nil
+			if hasErr != (s.expected == nil) {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expected == nil, hasErr, err)
+			}
+
+			names := make([]string, len(records))
+			for i, r := range records {
+				names[i] = r.GetString("name")
+			}
+
+			if !hasErr && !slices.Equal(names, s.expected) {
+				t.Fatalf("Expected names %v, got %v", s.expected, names)
+			}
+		})
+	}
+}
+
+func TestRecordVectorDistanceProviderCursor(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := createVectorTestCollection(t, app)
+
+	requestInfo := &core.RequestInfo{Query: map[string]string{"q": "[1, 0, 0]"}}
+
+	fetch := func(cursor string) (*search.Result, []string) {
+		resolver := core.NewRecordFieldResolver(app, collection, requestInfo, false)
+
+		records := []*core.Record{}
+
+		result, err := search.NewProvider(resolver).
+			Query(app.RecordQuery(collection)).
+			ParseAndExec("perPage=2&cursor="+cursor+"&sort=-vectorDistance(indexed, @request.query.q),name", &records)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := make([]string, len(records))
+		for i, r := range records {
+			names[i] = r.GetString("name")
+		}
+
+		return result, names
+	}
+
+	expectedPages := [][]string{
+		{"y", "z"},
+		{"xy", "x"},
+		{"null"},
+	}
+
+	var cursor string
+	for i, expected := range expectedPages {
+		result, names := fetch(cursor)
+
+		if !slices.Equal(names, expected) {
+			t.Fatalf("[page %d] Expected names %v, got %v", i+1, expected, names)
+		}
+
+		cursor = result.NextCursor
+	}
+
+	if cursor != "" {
+		t.Fatalf("Expected no more pages, got cursor %q", cursor)
+	}
+}
+
+func createVectorTestCollection(t *testing.T, app core.App) *core.Collection {
+	t.Helper()
+
+	// "indexed" and "plain" hold the same values to ensure that
+	// the index prefilter doesn't change the filter results
+	collection := core.NewBaseCollection("vector_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "name"},
+		&core.VectorField{Name: "indexed", Dimensions: 3, Index: true},
+		&core.VectorField{Name: "plain", Dimensions: 3},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	vectors := map[string]types.Vector{
+		"x":    {1, 0, 0},
+		"xy":   {1, 1, 0},
+		"y":    {0, 1, 0},
+		"z":    {0, 0, 1},
+		"null": nil,
+	}
+	for name, v := range vectors {
+		record := core.NewRecord(collection)
+		record.Set("name", name)
+		record.Set("indexed", v)
+		record.Set("plain", v)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return collection
+}
+
+func assertVectorIndexEntries(t *testing.T, app core.App, table string, expected int) {
+	t.Helper()
+
+	var total int
+	err := app.DB().Select("count(*)").From(table).Row(&total)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != expected {
+		t.Fatalf("Expected %d %q entries, got %d", expected, table, total)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ganigeorgiev/fexpr"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/core/validators"
@@ -128,6 +129,9 @@ type settings struct {
 	TrustedProxy TrustedProxyConfig `form:"trustedProxy" json:"trustedProxy"`
 	Batch        BatchConfig        `form:"batch" json:"batch"`
 	Logs         LogsConfig         `form:"logs" json:"logs"`
+	Webhooks     WebhooksConfig     `form:"webhooks" json:"webhooks"`
+	Idempotency  IdempotencyConfig  `form:"idempotency" json:"idempotency"`
+	Realtime     RealtimeConfig     `form:"realtime" json:"realtime"`
 }
 
 // Settings defines the PocketBase app settings.
@@ -169,6 +173,19 @@ func newDefaultSettings() *Settings {
 				MaxRequests: 50,
 				Timeout:     3,
 			},
+			Webhooks: WebhooksConfig{
+				MaxAttempts:   5,
+				RetryInterval: 30,
+				Timeout:       10,
+				MaxDays:       7,
+			},
+			Idempotency: IdempotencyConfig{
+				Window: 86400,
+			},
+			Realtime: RealtimeConfig{
+				HistorySize:         100,
+				BackpressureTimeout: 5,
+			},
 			RateLimits: RateLimitsConfig{
 				Enabled: false, // @todo once tested enough enable by default for new installations
 				Rules: []RateLimitRule{
@@ -287,6 +304,9 @@ func (s *Settings) PostValidate(ctx context.Context, app App) error {
 		validation.Field(&s.Batch),
 		validation.Field(&s.RateLimits),
 		validation.Field(&s.TrustedProxy),
+		validation.Field(&s.Webhooks),
+		validation.Field(&s.Idempotency),
+		validation.Field(&s.Realtime),
 	)
 }
 
@@ -333,6 +353,12 @@ func (s *Settings) MarshalJSON() ([]byte, error) {
 		&copy.Backups.S3.Secret,
 	}
 
+	// clone to avoid modifying the original webhooks
+	copy.Webhooks.Hooks = slices.Clone(copy.Webhooks.Hooks)
+	for i := range copy.Webhooks.Hooks {
+		sensitiveFields = append(sensitiveFields, &copy.Webhooks.Hooks[i].Secret)
+	}
+
 	// mask all sensitive fields
 	for _, v := range sensitiveFields {
 		if v != nil && *v != "" {
@@ -443,6 +469,197 @@ func (c BatchConfig) Validate() error {
 
 // -------------------------------------------------------------------
 
+type IdempotencyConfig struct {
+	// Window is the duration in seconds for which the responses of the
+	// requests with "Idempotency-Key" header are stored and replayed on retry.
+	//
+	// Set it to 0 to disable the "Idempotency-Key" header handling.
+	Window int64 `form:"window" json:"window"`
+}
+
+// Validate makes IdempotencyConfig validatable by implementing [validation.Validatable] interface.
+func (c IdempotencyConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Window, validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// MaxRealtimeHistorySize is the max allowed RealtimeConfig.HistorySize value.
+const MaxRealtimeHistorySize = 10000
+
+// The supported RealtimeConfig.BackpressurePolicy values.
+//
+// The default empty policy waits for the slow clients indefinitely.
+const (
+	RealtimeBackpressureDrop       = "drop"
+	RealtimeBackpressureDisconnect = "disconnect"
+)
+
+type RealtimeConfig struct {
+	// HistorySize is the max number of the most recent record events
+	// per collection that are kept for replay to the reconnecting
+	// realtime clients (aka. the ones with "Last-Event-ID").
+	//
+	// Set it to 0 to disable the realtime events replay.
+	HistorySize int `form:"historySize" json:"historySize"`
+
+	// HistoryPersist stores the realtime events history in the
+	// auxiliary database instead of in memory so that it could
+	// be also replayed after app restart.
+	HistoryPersist bool `form:"historyPersist" json:"historyPersist"`
+
+	// Channels is a list with the custom client-to-client pub/sub channels.
+	Channels []RealtimeChannelConfig `form:"channels" json:"channels"`
+
+	// BackpressurePolicy specifies what to do with the record event
+	// messages of a slow client that hasn't accepted them within the
+	// BackpressureTimeout (either "drop" the message or "disconnect" the client).
+	//
+	// Leave it empty to wait for the slow clients without a timeout.
+	BackpressurePolicy string `form:"backpressurePolicy" json:"backpressurePolicy"`
+
+	// BackpressureTimeout is the max duration in seconds to wait
+	// for a client to accept a message before applying the BackpressurePolicy.
+	BackpressureTimeout int64 `form:"backpressureTimeout" json:"backpressureTimeout"`
+}
+
+// FindChannel returns the channel config matching the provided channel name.
+//
+// Exact name matches have priority over the wildcard ones.
+// If there are several matching wildcard configs, the one with the longest prefix is returned.
+func (c *RealtimeConfig) FindChannel(name string) (RealtimeChannelConfig, bool) {
+	var result RealtimeChannelConfig
+	var found bool
+
+	for _, channel := range c.Channels {
+		if channel.Name == name {
+			return channel, true
+		}
+
+		prefix, ok := strings.CutSuffix(channel.Name, "*")
+		if ok && strings.HasPrefix(name, prefix) && (!found || len(channel.Name) > len(result.Name)) {
+			result = channel
+			found = true
+		}
+	}
+
+	return result, found
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c RealtimeConfig) MarshalJSON() ([]byte, error) {
+	type alias RealtimeConfig
+
+	// serialize as empty array
+	if c.Channels == nil {
+		c.Channels = []RealtimeChannelConfig{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// Validate makes RealtimeConfig validatable by implementing [validation.Validatable] interface.
+func (c RealtimeConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.HistorySize, validation.Min(0), validation.Max(MaxRealtimeHistorySize)),
+		validation.Field(&c.Channels, validation.By(checkUniqueRealtimeChannelName)),
+		validation.Field(
+			&c.BackpressurePolicy,
+			validation.In(RealtimeBackpressureDrop, RealtimeBackpressureDisconnect),
+		),
+		validation.Field(
+			&c.BackpressureTimeout,
+			validation.When(c.BackpressurePolicy != "", validation.Required),
+			validation.Min(0),
+		),
+	)
+}
+
+func checkUniqueRealtimeChannelName(value any) error {
+	channels, ok := value.([]RealtimeChannelConfig)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	existing := make(map[string]struct{}, len(channels))
+
+	for i, channel := range channels {
+		if _, ok := existing[channel.Name]; ok {
+			return validation.Errors{
+				strconv.Itoa(i): validation.Errors{
+					"name": validation.NewError("validation_duplicated_realtime_channel_name", "Realtime channel with name {{.name}} already exists.").
+						SetParams(map[string]any{"name": channel.Name}),
+				},
+			}
+		}
+
+		existing[channel.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+var realtimeChannelNameRegex = regexp.MustCompile(`^([\w\-\.\:\/]+\*?|\*)$`)
+
+type RealtimeChannelConfig struct {
+	// Name is the channel identifier.
+	//
+	// It could end with "*" to match all channels with the same prefix
+	// (eg. "docs/*" matches "docs/abc" and "docs/abc/cursors").
+	Name string `form:"name" json:"name"`
+
+	// SubscribeRule is the filter expression that the subscribing
+	// (and listing the presence members) client must satisfy.
+	//
+	// Similar to the collection API rules, nil means superusers only
+	// and empty string means everyone.
+	//
+	// The rule could reference the @request.auth.*, @request.query.*, @request.headers.*
+	// (the subscription options) and @collection.* fields.
+	// The concrete channel name is available as @request.body.channel.
+	SubscribeRule *string `form:"subscribeRule" json:"subscribeRule"`
+
+	// PublishRule is the filter expression that the publishing client must satisfy.
+	//
+	// Similar to the collection API rules, nil means superusers only
+	// and empty string means everyone.
+	//
+	// In addition to the SubscribeRule fields, the published
+	// payload is available as @request.body.data.*.
+	PublishRule *string `form:"publishRule" json:"publishRule"`
+
+	// Presence enables the channel members tracking, aka. broadcasting
+	// "join" and "leave" events when a client subscribes or unsubscribes.
+	Presence bool `form:"presence" json:"presence"`
+}
+
+// Validate makes RealtimeChannelConfig validatable by implementing [validation.Validatable] interface.
+func (c RealtimeChannelConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 255), validation.Match(realtimeChannelNameRegex)),
+		validation.Field(&c.SubscribeRule, validation.By(checkRealtimeChannelRule)),
+		validation.Field(&c.PublishRule, validation.By(checkRealtimeChannelRule)),
+	)
+}
+
+func checkRealtimeChannelRule(value any) error {
+	rule, _ := value.(*string)
+	if rule == nil || *rule == "" {
+		return nil
+	}
+
+	if _, err := fexpr.Parse(*rule); err != nil {
+		return validation.NewError("validation_invalid_rule", "Invalid rule. Raw error: {{.error}}").
+			SetParams(map[string]any{"error": err.Error()})
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
 type BackupsConfig struct {
 	// Cron is a cron expression to schedule auto backups, eg. "* * * * *".
 	//
@@ -714,3 +931,183 @@ func (c RateLimitRule) String() string {
 
 	return string(raw)
 }
+
+// -------------------------------------------------------------------
+
+// The allowed WebhookConfig.Events values.
+const (
+	WebhookEventCreate = "create"
+	WebhookEventUpdate = "update"
+	WebhookEventDelete = "delete"
+)
+
+type WebhooksConfig struct {
+	// Hooks is the list of the configured outbound webhooks.
+	Hooks []WebhookConfig `form:"hooks" json:"hooks"`
+
+	// MaxAttempts is the max number of delivery attempts
+	// (including the first one) before marking a delivery as failed.
+	MaxAttempts int `form:"maxAttempts" json:"maxAttempts"`
+
+	// RetryInterval is the base delay (in seconds) before retrying a failed delivery.
+	//
+	// The delay is doubled after each failed attempt (exponential backoff).
+	RetryInterval int64 `form:"retryInterval" json:"retryInterval"`
+
+	// Timeout is the max duration (in seconds) to wait for a single delivery response.
+	Timeout int64 `form:"timeout" json:"timeout"`
+
+	// MaxDays is the number of days to keep the deliveries log (0 disables the cleanup).
+	MaxDays int `form:"maxDays" json:"maxDays"`
+}
+
+// FindWebhook returns the webhook with the specified name.
+func (c *WebhooksConfig) FindWebhook(name string) (WebhookConfig, bool) {
+	for _, hook := range c.Hooks {
+		if hook.Name == name {
+			return hook, true
+		}
+	}
+
+	return WebhookConfig{}, false
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c WebhooksConfig) MarshalJSON() ([]byte, error) {
+	type alias WebhooksConfig
+
+	// serialize as empty array
+	if c.Hooks == nil {
+		c.Hooks = []WebhookConfig{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// The submitted hooks replace the existing ones as a whole (instead of
+// being decoded over them by their position) and the secret of an
+// existing hook is preserved only if the submitted hook with the same
+// name has an empty secret (the secrets are masked in the settings response).
+func (c *WebhooksConfig) UnmarshalJSON(data []byte) error {
+	type alias WebhooksConfig
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	_, hasHooks := fields["hooks"]
+
+	oldHooks := c.Hooks
+
+	decoded := alias(*c)
+	if hasHooks {
+		decoded.Hooks = nil
+	}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if hasHooks {
+		for i, hook := range decoded.Hooks {
+			if hook.Secret != "" {
+				continue
+			}
+
+			for _, old := range oldHooks {
+				if old.Name == hook.Name {
+					decoded.Hooks[i].Secret = old.Secret
+					break
+				}
+			}
+		}
+	}
+
+	*c = WebhooksConfig(decoded)
+
+	return nil
+}
+
+// Validate makes WebhooksConfig validatable by implementing [validation.Validatable] interface.
+func (c WebhooksConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Hooks, validation.By(checkUniqueWebhookName)),
+		validation.Field(&c.MaxAttempts, validation.Min(0)),
+		validation.Field(&c.RetryInterval, validation.Min(0)),
+		validation.Field(&c.Timeout, validation.Min(0)),
+		validation.Field(&c.MaxDays, validation.Min(0)),
+	)
+}
+
+func checkUniqueWebhookName(value any) error {
+	hooks, ok := value.([]WebhookConfig)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	existing := make(map[string]struct{}, len(hooks))
+
+	for i, hook := range hooks {
+		if _, ok := existing[hook.Name]; ok {
+			return validation.Errors{
+				strconv.Itoa(i): validation.Errors{
+					"name": validation.NewError("validation_duplicated_webhook_name", "Webhook with name {{.name}} already exists.").
+						SetParams(map[string]any{"name": hook.Name}),
+				},
+			}
+		}
+
+		existing[hook.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+type WebhookConfig struct {
+	// Name is the unique identifier of the webhook.
+	Name string `form:"name" json:"name"`
+
+	// URL is the endpoint where the webhook payloads will be POST-ed.
+	URL string `form:"url" json:"url"`
+
+	// Secret is an optional key used to sign the payloads with HMAC-SHA256.
+	//
+	// The signature is sent as hex encoded "X-Webhook-Signature" header
+	// and it is calculated from "<X-Webhook-Timestamp header value>.<body>".
+	Secret string `form:"secret" json:"secret,omitempty"`
+
+	// Collection is the name or id of the collection whose record events to deliver.
+	Collection string `form:"collection" json:"collection"`
+
+	// Events specifies the record events to deliver ("create", "update", "delete").
+	Events []string `form:"events" json:"events"`
+
+	// Filter is an optional filter expression that the event record must satisfy
+	// (eg. "status = 'published' && total > 10").
+	Filter string `form:"filter" json:"filter"`
+
+	// Disabled allows temporary stopping the webhook deliveries.
+	Disabled bool `form:"disabled" json:"disabled"`
+}
+
+// Validate makes WebhookConfig validatable by implementing [validation.Validatable] interface.
+func (c WebhookConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&c.URL, validation.Required, is.URL),
+		validation.Field(&c.Collection, validation.Required),
+		validation.Field(
+			&c.Events,
+			validation.Required,
+			validation.Each(validation.In(WebhookEventCreate, WebhookEventUpdate, WebhookEventDelete)),
+		),
+	)
+}
+
+// HasEvent reports whether the webhook is configured for the specified event.
+func (c WebhookConfig) HasEvent(event string) bool {
+	return slices.Contains(c.Events, event)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestSettingsDelete(t *testing.T) {
@@ -99,6 +100,7 @@ func TestSettingsMarshalJSON(t *testing.T) {
 	settings.SMTP.Password = testSecret
 	settings.S3.Secret = testSecret
 	settings.Backups.S3.Secret = testSecret
+	settings.Webhooks.Hooks = []core.WebhookConfig{{Name: "test", Secret: testSecret}}
 
 	raw, err := json.Marshal(settings)
 	if err != nil {
@@ -106,11 +108,87 @@ func TestSettingsMarshalJSON(t *testing.T) {
 	}
 	rawStr := string(raw)
 
-	expected := `{"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false},"meta":{"appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false}}`
+	expected := `{"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false},"meta":{"appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false},"webhooks":{"hooks":[{"name":"test","url":"","collection":"","events":null,"filter":"","disabled":false}],"maxAttempts":0,"retryInterval":0,"timeout":0,"maxDays":0},"idempotency":{"window":0},"realtime":{"historySize":0,"historyPersist":false,"channels":[],"backpressurePolicy":"","backpressureTimeout":0}}`
 
 	if rawStr != expected {
 		t.Fatalf("Expected\n%v\ngot\n%v", expected, rawStr)
 	}
+
+	// the original secret should remain unchanged
+	if settings.Webhooks.Hooks[0].Secret != testSecret {
+		t.Fatalf("Expected the original webhook secret to be unchanged, got %q", settings.Webhooks.Hooks[0].Secret)
+	}
+}
+
+func TestWebhooksConfigUnmarshalJSONSecrets(t *testing.T) {
+	t.Parallel()
+
+	settings := &core.Settings{}
+	settings.Webhooks.MaxAttempts = 3
+	settings.Webhooks.Hooks = []core.WebhookConfig{
+		{Name: "a", URL: "https://a.example.com", Secret: "secret_a", Filter: "total > 1"},
+		{Name: "b", URL: "https://b.example.com", Secret: "secret_b"},
+		{Name: "c", URL: "https://c.example.com", Secret: "secret_c"},
+	}
+
+	// the masked settings response
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	masked := map[string]any{}
+	if err := json.Unmarshal(raw, &masked); err != nil {
+		t.Fatal(err)
+	}
+	hooks := masked["webhooks"].(map[string]any)["hooks"].([]any)
+
+	// reorder and delete hooks, add a new one and change a secret
+	newHook := map[string]any{"name": "d", "url": "https://d.example.com"}
+	hooks[1].(map[string]any)["secret"] = "secret_b_new"
+	masked["webhooks"].(map[string]any)["hooks"] = []any{hooks[2], newHook, hooks[1]}
+
+	body, err := json.Marshal(map[string]any{"webhooks": masked["webhooks"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal(body, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []core.WebhookConfig{
+		{Name: "c", URL: "https://c.example.com", Secret: "secret_c"},
+		{Name: "d", URL: "https://d.example.com"},
+		{Name: "b", URL: "https://b.example.com", Secret: "secret_b_new"},
+	}
+
+	if len(settings.Webhooks.Hooks) != len(expected) {
+		t.Fatalf("Expected %d hooks, got %d", len(expected), len(settings.Webhooks.Hooks))
+	}
+
+	for i, hook := range settings.Webhooks.Hooks {
+		if hook.Name != expected[i].Name || hook.URL != expected[i].URL || hook.Secret != expected[i].Secret || hook.Filter != expected[i].Filter {
+			t.Errorf("[%d] Expected hook %#v, got %#v", i, expected[i], hook)
+		}
+	}
+
+	if settings.Webhooks.MaxAttempts != 3 {
+		t.Fatalf("Expected maxAttempts 3, got %d", settings.Webhooks.MaxAttempts)
+	}
+
+	// update without hooks should keep the existing ones
+	if err := json.Unmarshal([]byte(`{"webhooks":{"maxAttempts":5}}`), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(settings.Webhooks.Hooks) != len(expected) || settings.Webhooks.Hooks[0].Secret != "secret_c" {
+		t.Fatalf("Expected the existing hooks to be preserved, got %#v", settings.Webhooks.Hooks)
+	}
+
+	if settings.Webhooks.MaxAttempts != 5 {
+		t.Fatalf("Expected maxAttempts 5, got %d", settings.Webhooks.MaxAttempts)
+	}
 }
 
 func TestSettingsValidate(t *testing.T) {
@@ -245,6 +323,108 @@ func TestLogsConfigValidate(t *testing.T) {
 	}
 }
 
+func TestWebhooksConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.WebhooksConfig
+		expectedErrors []string
+	}{
+		{
+			"zero values",
+			core.WebhooksConfig{},
+			[]string{},
+		},
+		{
+			"invalid data",
+			core.WebhooksConfig{
+				MaxAttempts:   -1,
+				RetryInterval: -1,
+				Timeout:       -1,
+				MaxDays:       -1,
+				Hooks: []core.WebhookConfig{
+					{},
+				},
+			},
+			[]string{"maxAttempts", "retryInterval", "timeout", "maxDays", "hooks"},
+		},
+		{
+			"duplicated hook names",
+			core.WebhooksConfig{
+				Hooks: []core.WebhookConfig{
+					{Name: "a", URL: "https://example.com", Collection: "demo1", Events: []string{"create"}},
+					{Name: "a", URL: "https://example.com", Collection: "demo2", Events: []string{"create"}},
+				},
+			},
+			[]string{"hooks"},
+		},
+		{
+			"valid data",
+			core.WebhooksConfig{
+				MaxAttempts:   3,
+				RetryInterval: 10,
+				Timeout:       5,
+				MaxDays:       1,
+				Hooks: []core.WebhookConfig{
+					{Name: "a", URL: "https://example.com", Collection: "demo1", Events: []string{"create"}},
+					{Name: "b", URL: "https://example.com", Collection: "demo2", Events: []string{"update", "delete"}},
+				},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestWebhookConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.WebhookConfig
+		expectedErrors []string
+	}{
+		{
+			"zero values",
+			core.WebhookConfig{},
+			[]string{"name", "url", "collection", "events"},
+		},
+		{
+			"invalid data",
+			core.WebhookConfig{
+				Name:       strings.Repeat("a", 101),
+				URL:        "invalid",
+				Collection: "demo1",
+				Events:     []string{"create", "invalid"},
+			},
+			[]string{"name", "url", "events"},
+		},
+		{
+			"valid data",
+			core.WebhookConfig{
+				Name:       "test",
+				URL:        "https://example.com/hook",
+				Collection: "demo1",
+				Events:     []string{"create", "update", "delete"},
+				Filter:     "title != ''",
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
 func TestSMTPConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -485,6 +665,185 @@ func TestBatchConfigValidate(t *testing.T) {
 	}
 }
 
+func TestIdempotencyConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.IdempotencyConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.IdempotencyConfig{},
+			[]string{},
+		},
+		{
+			"invalid data (negative window)",
+			core.IdempotencyConfig{Window: -1},
+			[]string{"window"},
+		},
+		{
+			"valid data",
+			core.IdempotencyConfig{Window: 3600},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestRealtimeConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.RealtimeConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.RealtimeConfig{},
+			[]string{},
+		},
+		{
+			"invalid data (negative history size)",
+			core.RealtimeConfig{HistorySize: -1},
+			[]string{"historySize"},
+		},
+		{
+			"invalid data (too large history size)",
+			core.RealtimeConfig{HistorySize: core.MaxRealtimeHistorySize + 1},
+			[]string{"historySize"},
+		},
+		{
+			"invalid channels",
+			core.RealtimeConfig{Channels: []core.RealtimeChannelConfig{
+				{Name: "a"},
+				{Name: "a"},
+			}},
+			[]string{"channels"},
+		},
+		{
+			"invalid backpressure policy",
+			core.RealtimeConfig{BackpressurePolicy: "invalid", BackpressureTimeout: 1},
+			[]string{"backpressurePolicy"},
+		},
+		{
+			"backpressure policy without timeout",
+			core.RealtimeConfig{BackpressurePolicy: core.RealtimeBackpressureDrop},
+			[]string{"backpressureTimeout"},
+		},
+		{
+			"negative backpressure timeout",
+			core.RealtimeConfig{BackpressureTimeout: -1},
+			[]string{"backpressureTimeout"},
+		},
+		{
+			"valid data",
+			core.RealtimeConfig{HistorySize: 100, HistoryPersist: true, Channels: []core.RealtimeChannelConfig{
+				{Name: "a"},
+				{Name: "a/*"},
+			}, BackpressurePolicy: core.RealtimeBackpressureDisconnect, BackpressureTimeout: 1},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestRealtimeConfigFindChannel(t *testing.T) {
+	config := core.RealtimeConfig{Channels: []core.RealtimeChannelConfig{
+		{Name: "*", Presence: true},
+		{Name: "docs/*", PublishRule: types.Pointer("1")},
+		{Name: "docs/abc/*", PublishRule: types.Pointer("2")},
+		{Name: "docs/abc", PublishRule: types.Pointer("3")},
+		{Name: "chat"},
+	}}
+
+	scenarios := []struct {
+		name     string
+		expected string
+	}{
+		{"", "*"},
+		{"missing", "*"},
+		{"chat", "chat"},
+		{"chat2", "*"},
+		{"docs/", "docs/*"},
+		{"docs/a", "docs/*"},
+		{"docs/abc", "docs/abc"},
+		{"docs/abc/", "docs/abc/*"},
+		{"docs/abc/cursors", "docs/abc/*"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			channel, ok := config.FindChannel(s.name)
+			if !ok {
+				t.Fatal("Expected matching channel")
+			}
+
+			if channel.Name != s.expected {
+				t.Fatalf("Expected channel %q, got %q", s.expected, channel.Name)
+			}
+		})
+	}
+
+	config.Channels = config.Channels[1:]
+	if channel, ok := config.FindChannel("missing"); ok {
+		t.Fatalf("Expected no matching channel, got %v", channel)
+	}
+}
+
+func TestRealtimeChannelConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.RealtimeChannelConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value",
+			core.RealtimeChannelConfig{},
+			[]string{"name"},
+		},
+		{
+			"invalid data",
+			core.RealtimeChannelConfig{
+				Name:          "a b*c",
+				SubscribeRule: types.Pointer("a ="),
+				PublishRule:   types.Pointer("(a = 1"),
+			},
+			[]string{"name", "subscribeRule", "publishRule"},
+		},
+		{
+			"valid data",
+			core.RealtimeChannelConfig{
+				Name:          "docs/abc-1.2:3/*",
+				SubscribeRule: types.Pointer(""),
+				PublishRule:   types.Pointer("@request.auth.id != '' && @request.body.data.x > 1"),
+				Presence:      true,
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
 func TestRateLimitsConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -0,0 +1,41 @@
+package core
+
+import "github.com/pocketbase/pocketbase/tools/types"
+
+var (
+	_ Model = (*WebhookDelivery)(nil)
+)
+
+const WebhookDeliveriesTableName = "_webhookDeliveries"
+
+// The available WebhookDelivery.Status values.
+const (
+	WebhookDeliveryStatusPending = "pending"
+	WebhookDeliveryStatusSending = "sending"
+	WebhookDeliveryStatusSuccess = "success"
+	WebhookDeliveryStatusFailed  = "failed"
+)
+
+// WebhookDelivery defines a single outbound webhook delivery log entry.
+type WebhookDelivery struct {
+	BaseModel
+
+	Created        types.DateTime `db:"created" json:"created"`
+	Updated        types.DateTime `db:"updated" json:"updated"`
+	NextAttempt    types.DateTime `db:"nextAttempt" json:"nextAttempt"`
+	Payload        types.JSONRaw  `db:"payload" json:"payload"`
+	Webhook        string         `db:"webhook" json:"webhook"`
+	Event          string         `db:"event" json:"event"`
+	Collection     string         `db:"collection" json:"collection"`
+	RecordId       string         `db:"recordId" json:"recordId"`
+	URL            string         `db:"url" json:"url"`
+	Status         string         `db:"status" json:"status"`
+	ResponseBody   string         `db:"responseBody" json:"responseBody"`
+	Error          string         `db:"error" json:"error"`
+	Attempts       int            `db:"attempts" json:"attempts"`
+	ResponseStatus int            `db:"responseStatus" json:"responseStatus"`
+}
+
+func (m *WebhookDelivery) TableName() string {
+	return WebhookDeliveriesTableName
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// WebhookDeliveryQuery returns a new WebhookDelivery select query.
+func (app *BaseApp) WebhookDeliveryQuery() *dbx.SelectQuery {
+	return app.AuxModelQuery(&WebhookDelivery{})
+}
+
+// FindWebhookDeliveryById finds a single WebhookDelivery entry by its id.
+func (app *BaseApp) FindWebhookDeliveryById(id string) (*WebhookDelivery, error) {
+	model := &WebhookDelivery{}
+
+	err := app.WebhookDeliveryQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// DeleteOldWebhookDeliveries deletes all webhook deliveries that are created before createdBefore.
+//
+// For better performance the delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteOldWebhookDeliveries(createdBefore time.Time) error {
+	formattedDate := createdBefore.UTC().Format(types.DefaultDateLayout)
+	expr := dbx.NewExp("[[created]] <= {:date}", dbx.Params{"date": formattedDate})
+
+	_, err := app.auxNonconcurrentDB.Delete((&WebhookDelivery{}).TableName(), expr).Execute()
+
+	return err
+}
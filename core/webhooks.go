@@ -0,0 +1,445 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const systemHookIdWebhooks = "__pbWebhooksSystemHook__"
+
+// The headers sent with each webhook delivery request.
+const (
+	WebhookHeaderId        = "X-Webhook-Id"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+// ErrWebhookDeliveryNotPending is returned when trying to send a webhook
+// delivery that is not pending or that is already being sent.
+var ErrWebhookDeliveryNotPending = errors.New("the webhook delivery is not pending or it is already being sent")
+
+// max number of response body bytes to store in the deliveries log
+const webhookMaxResponseBody = 2048
+
+// max number of due deliveries to process in a single retry run
+const webhookRetryBatchSize = 100
+
+func (app *BaseApp) registerWebhookHooks() {
+	app.OnRecordAfterCreateSuccess().Bind(&hook.Handler[*RecordEvent]{
+		Id: systemHookIdWebhooks,
+		Func: func(e *RecordEvent) error {
+			// note: use the outside scoped app instance since the
+			// event app could be an already completed transaction
+			app.dispatchWebhookDeliveries(app.prepareWebhookDeliveries(app, WebhookEventCreate, e.Record))
+
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
+	app.OnRecordAfterUpdateSuccess().Bind(&hook.Handler[*RecordEvent]{
+		Id: systemHookIdWebhooks,
+		Func: func(e *RecordEvent) error {
+			app.dispatchWebhookDeliveries(app.prepareWebhookDeliveries(app, WebhookEventUpdate, e.Record))
+
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
+	// delete: prepare the deliveries while the record still exists
+	// so that the webhook filters could be evaluated
+	app.OnRecordDelete().Bind(&hook.Handler[*RecordEvent]{
+		Id: systemHookIdWebhooks,
+		Func: func(e *RecordEvent) error {
+			deliveries := app.prepareWebhookDeliveries(e.App, WebhookEventDelete, e.Record)
+			if len(deliveries) > 0 {
+				app.Store().Set(webhookDeleteStoreKey(e.Record), deliveries)
+			}
+
+			return e.Next()
+		},
+		Priority: 99, // execute as later as possible
+	})
+
+	app.OnRecordAfterDeleteSuccess().Bind(&hook.Handler[*RecordEvent]{
+		Id: systemHookIdWebhooks,
+		Func: func(e *RecordEvent) error {
+			key := webhookDeleteStoreKey(e.Record)
+
+			deliveries, _ := app.Store().Get(key).([]*WebhookDelivery)
+			app.Store().Remove(key)
+
+			app.dispatchWebhookDeliveries(deliveries)
+
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
+	app.OnRecordAfterDeleteError().Bind(&hook.Handler[*RecordErrorEvent]{
+		Id: systemHookIdWebhooks,
+		Func: func(e *RecordErrorEvent) error {
+			app.Store().Remove(webhookDeleteStoreKey(e.Record))
+
+			return e.Next()
+		},
+		Priority: -99,
+	})
+
+	// retry the due failed deliveries
+	app.Cron().Add("__pbWebhooksRetry__", "* * * * *", func() {
+		if err := app.processDueWebhookDeliveries(context.Background()); err != nil {
+			app.Logger().Warn("Failed to process the pending webhook deliveries", slog.String("error", err.Error()))
+		}
+	})
+
+	// cleanup old deliveries
+	app.Cron().Add("__pbWebhooksCleanup__", "0 */6 * * *", func() {
+		maxDays := app.Settings().Webhooks.MaxDays
+		if maxDays <= 0 {
+			return
+		}
+
+		if err := app.DeleteOldWebhookDeliveries(time.Now().AddDate(0, 0, -1*maxDays)); err != nil {
+			app.Logger().Warn("Failed to delete old webhook deliveries", slog.String("error", err.Error()))
+		}
+	})
+}
+
+func webhookDeleteStoreKey(record *Record) string {
+	return "@webhooksDelete/" + record.Collection().Id + "/" + record.Id
+}
+
+// prepareWebhookDeliveries returns the new (unsaved) deliveries of all
+// enabled webhooks matching the specified record event.
+//
+// queryApp is used for the webhooks filter evaluation.
+func (app *BaseApp) prepareWebhookDeliveries(queryApp App, event string, record *Record) []*WebhookDelivery {
+	hooks := app.Settings().Webhooks.Hooks
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	collection := record.Collection()
+
+	var deliveries []*WebhookDelivery
+	var payload types.JSONRaw
+
+	for _, hook := range hooks {
+		if hook.Disabled ||
+			!hook.HasEvent(event) ||
+			(hook.Collection != collection.Id && hook.Collection != collection.Name) {
+			continue
+		}
+
+		if hook.Filter != "" {
+			match, err := webhookRecordMatchesFilter(queryApp, record, hook.Filter)
+			if err != nil {
+				app.Logger().Warn(
+					"Failed to evaluate the webhook filter",
+					slog.String("webhook", hook.Name),
+					slog.String("filter", hook.Filter),
+					slog.String("error", err.Error()),
+				)
+			}
+			if !match {
+				continue
+			}
+		}
+
+		if payload == nil {
+			var err error
+			payload, err = newWebhookPayload(event, record)
+			if err != nil {
+				app.Logger().Warn(
+					"Failed to serialize the webhook payload",
+					slog.String("webhook", hook.Name),
+					slog.String("error", err.Error()),
+				)
+				return nil
+			}
+		}
+
+		now := types.NowDateTime()
+
+		delivery := &WebhookDelivery{
+			Webhook:     hook.Name,
+			Event:       event,
+			Collection:  collection.Name,
+			RecordId:    record.Id,
+			URL:         hook.URL,
+			Payload:     payload,
+			Status:      WebhookDeliveryStatusPending,
+			NextAttempt: now,
+			Created:     now,
+			Updated:     now,
+		}
+		delivery.Id = GenerateDefaultRandomId()
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries
+}
+
+// dispatchWebhookDeliveries persists the provided deliveries
+// and sends them in the background.
+func (app *BaseApp) dispatchWebhookDeliveries(deliveries []*WebhookDelivery) {
+	for _, delivery := range deliveries {
+		if err := app.AuxSaveNoValidate(delivery); err != nil {
+			app.Logger().Error(
+				"Failed to save webhook delivery",
+				slog.String("webhook", delivery.Webhook),
+				slog.String("recordId", delivery.RecordId),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		routine.FireAndForget(func() {
+			err := app.SendWebhookDelivery(context.Background(), delivery)
+			if err != nil && !errors.Is(err, ErrWebhookDeliveryNotPending) {
+				app.Logger().Error(
+					"Failed to send webhook delivery",
+					slog.String("id", delivery.Id),
+					slog.String("webhook", delivery.Webhook),
+					slog.String("error", err.Error()),
+				)
+			}
+		})
+	}
+}
+
+// SendWebhookDelivery sends the provided pending webhook delivery
+// and updates its status, attempts and response details.
+//
+// If the delivery fails and there are remaining attempts, it is
+// scheduled for a retry with exponential backoff.
+//
+// Returns [ErrWebhookDeliveryNotPending] if the delivery is not pending
+// or it is already being sent by another process.
+// Note that a failed delivery request is not considered an error
+// and it is reported only with the updated delivery fields.
+func (app *BaseApp) SendWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	now := types.NowDateTime()
+
+	// "claim" the delivery to prevent concurrent sends (eg. by the retry cron)
+	result, err := app.AuxNonconcurrentDB().Update(
+		delivery.TableName(),
+		dbx.Params{"status": WebhookDeliveryStatusSending, "updated": now.String()},
+		dbx.HashExp{"id": delivery.Id, "status": WebhookDeliveryStatusPending},
+	).WithContext(ctx).Execute()
+	if err != nil {
+		return err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrWebhookDeliveryNotPending
+	}
+
+	config := app.Settings().Webhooks
+
+	maxAttempts := max(config.MaxAttempts, 1)
+
+	delivery.Attempts++
+
+	hook, ok := config.FindWebhook(delivery.Webhook)
+	if ok {
+		delivery.ResponseStatus, delivery.ResponseBody, err = sendWebhookRequest(ctx, hook, delivery, config.Timeout)
+	} else {
+		// the webhook was removed from the settings
+		err = errors.New("missing webhook " + strconv.Quote(delivery.Webhook) + " configuration")
+		maxAttempts = delivery.Attempts
+	}
+
+	delivery.Updated = types.NowDateTime()
+
+	switch {
+	case err == nil:
+		delivery.Status = WebhookDeliveryStatusSuccess
+		delivery.Error = ""
+		delivery.NextAttempt = types.DateTime{}
+	case delivery.Attempts < maxAttempts:
+		delivery.Status = WebhookDeliveryStatusPending
+		delivery.Error = err.Error()
+		delivery.NextAttempt = delivery.Updated.Add(WebhookRetryDelay(config.RetryInterval, delivery.Attempts))
+	default:
+		delivery.Status = WebhookDeliveryStatusFailed
+		delivery.Error = err.Error()
+		delivery.NextAttempt = types.DateTime{}
+	}
+
+	return app.AuxSaveNoValidateWithContext(ctx, delivery)
+}
+
+// WebhookRetryDelay returns the delay before the next delivery attempt
+// based on the base interval (in seconds) and the number of the already made attempts.
+//
+// The delay is doubled after each attempt and it is capped to 24h.
+func WebhookRetryDelay(interval int64, attempts int) time.Duration {
+	const maxDelay = 24 * time.Hour
+
+	delay := time.Duration(max(interval, 1)) * time.Second
+
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+
+	return delay
+}
+
+// processDueWebhookDeliveries sends all pending deliveries
+// whose next attempt date has passed.
+func (app *BaseApp) processDueWebhookDeliveries(ctx context.Context) error {
+	now := types.NowDateTime()
+
+	// release deliveries stuck in "sending" state (eg. because of an app crash)
+	timeout := time.Duration(max(app.Settings().Webhooks.Timeout, 10)) * time.Second
+	_, err := app.AuxNonconcurrentDB().Update(
+		WebhookDeliveriesTableName,
+		dbx.Params{"status": WebhookDeliveryStatusPending},
+		dbx.And(
+			dbx.HashExp{"status": WebhookDeliveryStatusSending},
+			dbx.NewExp("[[updated]] < {:stuck}", dbx.Params{"stuck": now.Add(-2 * timeout).String()}),
+		),
+	).WithContext(ctx).Execute()
+	if err != nil {
+		return err
+	}
+
+	deliveries := []*WebhookDelivery{}
+
+	err = app.WebhookDeliveryQuery().
+		AndWhere(dbx.HashExp{"status": WebhookDeliveryStatusPending}).
+		AndWhere(dbx.NewExp("[[nextAttempt]] <= {:now}", dbx.Params{"now": now.String()})).
+		OrderBy("nextAttempt ASC").
+		Limit(webhookRetryBatchSize).
+		WithContext(ctx).
+		All(&deliveries)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, delivery := range deliveries {
+		err := app.SendWebhookDelivery(ctx, delivery)
+		if err != nil && !errors.Is(err, ErrWebhookDeliveryNotPending) {
+			errs = append(errs, fmt.Errorf("%s: %w", delivery.Id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sendWebhookRequest sends the delivery payload to the delivery URL and
+// returns the response status and the (truncated) response body.
+func sendWebhookRequest(ctx context.Context, hook WebhookConfig, delivery *WebhookDelivery, timeout int64) (int, string, error) {
+	if timeout <= 0 {
+		timeout = 10
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderId, delivery.Id)
+	req.Header.Set(WebhookHeaderEvent, delivery.Event)
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	if hook.Secret != "" {
+		req.Header.Set(WebhookHeaderSignature, WebhookSignature(hook.Secret, timestamp, body))
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, webhookMaxResponseBody))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, string(resBody), fmt.Errorf("unexpected response status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, string(resBody), nil
+}
+
+// WebhookSignature returns the hex encoded HMAC-SHA256 signature
+// of the "<timestamp>.<body>" string.
+//
+// It could be used by the webhook receivers to verify the delivery authenticity.
+func WebhookSignature(secret string, timestamp string, body []byte) string {
+	return security.HS256(timestamp+"."+string(body), secret)
+}
+
+func newWebhookPayload(event string, record *Record) (types.JSONRaw, error) {
+	collection := record.Collection()
+
+	data := record.Fresh()
+	data.IgnoreEmailVisibility(true)
+
+	return json.Marshal(map[string]any{
+		"event": event,
+		"collection": map[string]any{
+			"id":   collection.Id,
+			"name": collection.Name,
+		},
+		"record":    data,
+		"timestamp": types.NowDateTime(),
+	})
+}
+
+// webhookRecordMatchesFilter checks whether the record satisfies the provided filter expression.
+func webhookRecordMatchesFilter(app App, record *Record, filter string) (bool, error) {
+	var exists int
+
+	query := app.RecordQuery(record.Collection()).
+		Select("(1)").
+		AndWhere(dbx.HashExp{record.Collection().Name + ".id": record.Id})
+
+	// the webhooks are configured by superusers so allow hidden fields
+	resolver := NewRecordFieldResolver(app, record.Collection(), nil, true)
+	expr, err := search.FilterData(filter).BuildExpr(resolver)
+	if err != nil {
+		return false, err
+	}
+	resolver.UpdateQuery(query)
+
+	err = query.AndWhere(expr).Limit(1).Row(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	return exists > 0, nil
+}
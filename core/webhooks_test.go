@@ -0,0 +1,343 @@
+package core_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type webhookTestRequest struct {
+	header http.Header
+	body   string
+}
+
+func newWebhookTestServer(t testing.TB, status int) (*httptest.Server, func() []webhookTestRequest) {
+	var mu sync.Mutex
+	var requests []webhookTestRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		requests = append(requests, webhookTestRequest{header: r.Header.Clone(), body: string(body)})
+		mu.Unlock()
+
+		w.WriteHeader(status)
+		w.Write([]byte("test_response"))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []webhookTestRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookTestRequest{}, requests...)
+	}
+}
+
+// waitWebhookDeliveries waits until there are total number of non-pending deliveries.
+func waitWebhookDeliveries(t testing.TB, app core.App, total int) []*core.WebhookDelivery {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		deliveries := []*core.WebhookDelivery{}
+
+		err := app.WebhookDeliveryQuery().
+			AndWhere(dbx.NotIn("status", core.WebhookDeliveryStatusSending)).
+			AndWhere(dbx.NewExp("attempts > 0 OR status != 'pending'")).
+			OrderBy("created ASC", "webhook ASC").
+			All(&deliveries)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(deliveries) >= total {
+			return deliveries
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d processed deliveries, got %d", total, len(deliveries))
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestWebhooksRecordEvents(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	server, requests := newWebhookTestServer(t, 200)
+
+	app.Settings().Webhooks.Hooks = []core.WebhookConfig{
+		{
+			Name:       "all",
+			URL:        server.URL + "/all",
+			Secret:     "test_secret",
+			Collection: "demo2",
+			Events:     []string{core.WebhookEventCreate, core.WebhookEventUpdate, core.WebhookEventDelete},
+		},
+		{
+			Name:       "filtered",
+			URL:        server.URL + "/filtered",
+			Collection: "demo2",
+			Events:     []string{core.WebhookEventCreate, core.WebhookEventUpdate, core.WebhookEventDelete},
+			Filter:     "active = true",
+		},
+		{
+			Name:       "disabled",
+			URL:        server.URL + "/disabled",
+			Collection: "demo2",
+			Events:     []string{core.WebhookEventCreate},
+			Disabled:   true,
+		},
+		{
+			Name:       "other",
+			URL:        server.URL + "/other",
+			Collection: "demo3",
+			Events:     []string{core.WebhookEventCreate},
+		},
+	}
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "webhook_test")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	waitWebhookDeliveries(t, app, 1) // all
+
+	record.Set("active", true)
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	waitWebhookDeliveries(t, app, 3) // +all +filtered
+
+	if err := app.Delete(record); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := waitWebhookDeliveries(t, app, 5) // +all +filtered
+
+	expected := []string{
+		"all:create",
+		"all:update",
+		"filtered:update",
+		"all:delete",
+		"filtered:delete",
+	}
+
+	if len(deliveries) != len(expected) {
+		t.Fatalf("Expected %d deliveries, got %d", len(expected), len(deliveries))
+	}
+
+	for i, d := range deliveries {
+		if str := d.Webhook + ":" + d.Event; str != expected[i] {
+			t.Errorf("[%d] Expected delivery %q, got %q", i, expected[i], str)
+		}
+
+		if d.Status != core.WebhookDeliveryStatusSuccess || d.Attempts != 1 || d.ResponseStatus != 200 || d.ResponseBody != "test_response" {
+			t.Errorf("[%d] Unexpected delivery state %#v", i, d)
+		}
+
+		if d.RecordId != record.Id || d.Collection != "demo2" {
+			t.Errorf("[%d] Unexpected delivery record %q (%q)", i, d.RecordId, d.Collection)
+		}
+	}
+
+	reqs := requests()
+	if len(reqs) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d", len(expected), len(reqs))
+	}
+
+	for _, req := range reqs {
+		payload := map[string]any{}
+		if err := json.Unmarshal([]byte(req.body), &payload); err != nil {
+			t.Fatal(err)
+		}
+
+		recordData, _ := payload["record"].(map[string]any)
+		if recordData["id"] != record.Id || recordData["title"] != "webhook_test" {
+			t.Fatalf("Unexpected payload record %v", recordData)
+		}
+
+		if req.header.Get(core.WebhookHeaderId) == "" {
+			t.Fatal("Missing delivery id header")
+		}
+
+		signature := req.header.Get(core.WebhookHeaderSignature)
+		if req.header.Get(core.WebhookHeaderEvent) == "" || req.header.Get(core.WebhookHeaderTimestamp) == "" {
+			t.Fatalf("Missing event or timestamp headers: %v", req.header)
+		}
+
+		if payload["event"] != req.header.Get(core.WebhookHeaderEvent) {
+			t.Fatalf("Expected payload event %v to match the header %q", payload["event"], req.header.Get(core.WebhookHeaderEvent))
+		}
+
+		// only the "all" webhook has a secret
+		isSigned := signature != ""
+		hasSecret := strings.Contains(reqURL(req, deliveries), "all")
+		if isSigned != hasSecret {
+			t.Fatalf("Expected signed %v, got %v", hasSecret, isSigned)
+		}
+
+		if isSigned {
+			expectedSignature := core.WebhookSignature("test_secret", req.header.Get(core.WebhookHeaderTimestamp), []byte(req.body))
+			if signature != expectedSignature {
+				t.Fatalf("Expected signature %q, got %q", expectedSignature, signature)
+			}
+		}
+	}
+}
+
+// reqURL returns the webhook URL of the delivery associated with the request.
+func reqURL(req webhookTestRequest, deliveries []*core.WebhookDelivery) string {
+	for _, d := range deliveries {
+		if d.Id == req.header.Get(core.WebhookHeaderId) {
+			return d.URL
+		}
+	}
+	return ""
+}
+
+func TestWebhooksRetries(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	server, requests := newWebhookTestServer(t, 500)
+
+	app.Settings().Webhooks.MaxAttempts = 2
+	app.Settings().Webhooks.RetryInterval = 60
+	app.Settings().Webhooks.Hooks = []core.WebhookConfig{
+		{
+			Name:       "test",
+			URL:        server.URL,
+			Collection: "demo2",
+			Events:     []string{core.WebhookEventCreate},
+		},
+	}
+
+	collection, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "webhook_test")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := waitWebhookDeliveries(t, app, 1)[0]
+
+	if delivery.Status != core.WebhookDeliveryStatusPending ||
+		delivery.Attempts != 1 ||
+		delivery.ResponseStatus != 500 ||
+		!strings.Contains(delivery.Error, "500") {
+		t.Fatalf("Expected pending delivery with 1 failed attempt, got %#v", delivery)
+	}
+
+	if delay := delivery.NextAttempt.Time().Sub(delivery.Updated.Time()); delay != 60*time.Second {
+		t.Fatalf("Expected next attempt after 60s, got %v", delay)
+	}
+
+	// last attempt
+	if err := app.SendWebhookDelivery(t.Context(), delivery); err != nil {
+		t.Fatal(err)
+	}
+
+	if delivery.Status != core.WebhookDeliveryStatusFailed || delivery.Attempts != 2 || !delivery.NextAttempt.IsZero() {
+		t.Fatalf("Expected failed delivery with 2 attempts, got %#v", delivery)
+	}
+
+	// already failed delivery
+	if err := app.SendWebhookDelivery(t.Context(), delivery); err != core.ErrWebhookDeliveryNotPending {
+		t.Fatalf("Expected ErrWebhookDeliveryNotPending, got %v", err)
+	}
+
+	if total := len(requests()); total != 2 {
+		t.Fatalf("Expected 2 requests, got %d", total)
+	}
+
+	persisted, err := app.FindWebhookDeliveryById(delivery.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if persisted.Status != core.WebhookDeliveryStatusFailed || persisted.Attempts != 2 {
+		t.Fatalf("Expected the failed state to be persisted, got %#v", persisted)
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		interval int64
+		attempts int
+		expected time.Duration
+	}{
+		{0, 1, 1 * time.Second},
+		{30, 0, 30 * time.Second},
+		{30, 1, 30 * time.Second},
+		{30, 2, 60 * time.Second},
+		{30, 4, 240 * time.Second},
+		{3600, 10, 24 * time.Hour},
+	}
+
+	for _, s := range scenarios {
+		if result := core.WebhookRetryDelay(s.interval, s.attempts); result != s.expected {
+			t.Errorf("(%d, %d) Expected %v, got %v", s.interval, s.attempts, s.expected, result)
+		}
+	}
+}
+
+func TestDeleteOldWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	now := time.Now()
+
+	for _, created := range []time.Time{now.AddDate(0, 0, -10), now.AddDate(0, 0, -5), now} {
+		delivery := &core.WebhookDelivery{Webhook: "test", Status: core.WebhookDeliveryStatusSuccess, Payload: types.JSONRaw("{}")}
+		delivery.Id = core.GenerateDefaultRandomId()
+		delivery.Created, _ = types.ParseDateTime(created)
+		delivery.Updated = delivery.Created
+		if err := app.AuxSaveNoValidate(delivery); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := app.DeleteOldWebhookDeliveries(now.AddDate(0, 0, -7)); err != nil {
+		t.Fatal(err)
+	}
+
+	var total int
+	if err := app.WebhookDeliveryQuery().Select("count(*)").Row(&total); err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 2 {
+		t.Fatalf("Expected 2 remaining deliveries, got %d", total)
+	}
+}
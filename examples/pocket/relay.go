@@ -85,7 +85,6 @@ func RelayHookFunc(se *core.ServeEvent) error {
 	proxyMiddleware := &hook.Handler[*core.RequestEvent]{
 		Id: "proxyMiddlewareId",
 		Func: func(re *core.RequestEvent) error {
-			re.TraceMiddleware("proxyMiddlewareId", -2000 + 1)
 			w, r := re.Event.Response, re.Event.Request
 
 			if proxy.IsProxy(r) {
@@ -101,7 +100,6 @@ func RelayHookFunc(se *core.ServeEvent) error {
 	upgradeMiddleware := &hook.Handler[*core.RequestEvent]{
 		Id: "upgradeMiddlewareId",
 		Func: func(re *core.RequestEvent) error {
-			re.TraceMiddleware("upgradeMiddlewareId", -2000 + 2)
 			w, r := re.Event.Response, re.Event.Request
 
 			if s.IsUpgrade(r) {
@@ -135,7 +133,6 @@ func RelayHookFunc(se *core.ServeEvent) error {
 	indexMiddleware := &hook.Handler[*core.RequestEvent]{
 		Id: "indexMiddlewareId",
 		Func: func(re *core.RequestEvent) error {
-			re.TraceMiddleware("indexMiddlewareId", -2000 + 3)
 			w, r := re.Event.Response, re.Event.Request
 
 			isUI := strings.HasPrefix(r.URL.Path, "/_/")
@@ -212,7 +209,6 @@ func RelayHookFunc(se *core.ServeEvent) error {
 	ingressMiddleware := &hook.Handler[*core.RequestEvent]{
 		Id: "ingressMiddlewareId",
 		Func: func(re *core.RequestEvent) error {
-			re.TraceMiddleware("ingressMiddlewareId", -2000 + 4)
 			w, r := re.Event.Response, re.Event.Request
 
 			if !s.IsRootExternal(r) {
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.AuxDB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_webhookDeliveries}} (
+					[[id]]             TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+					[[webhook]]        TEXT DEFAULT "" NOT NULL,
+					[[event]]          TEXT DEFAULT "" NOT NULL,
+					[[collection]]     TEXT DEFAULT "" NOT NULL,
+					[[recordId]]       TEXT DEFAULT "" NOT NULL,
+					[[url]]            TEXT DEFAULT "" NOT NULL,
+					[[payload]]        JSON DEFAULT "{}" NOT NULL,
+					[[status]]         TEXT DEFAULT "pending" NOT NULL,
+					[[attempts]]       INTEGER DEFAULT 0 NOT NULL,
+					[[responseStatus]] INTEGER DEFAULT 0 NOT NULL,
+					[[responseBody]]   TEXT DEFAULT "" NOT NULL,
+					[[error]]          TEXT DEFAULT "" NOT NULL,
+					[[nextAttempt]]    TEXT DEFAULT "" NOT NULL,
+					[[created]]        TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+					[[updated]]        TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_webhookDeliveries_webhook on {{_webhookDeliveries}} ([[webhook]]);
+				CREATE INDEX IF NOT EXISTS idx_webhookDeliveries_status_nextAttempt on {{_webhookDeliveries}} ([[status]], [[nextAttempt]]);
+				CREATE INDEX IF NOT EXISTS idx_webhookDeliveries_created on {{_webhookDeliveries}} ([[created]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.AuxDB().DropTable("_webhookDeliveries").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _webhookDeliveries table doesn't exist
+			exists := txApp.AuxHasTable("_webhookDeliveries")
+			return !exists, nil
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.AuxDB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_idempotencyKeys}} (
+					[[id]]                  TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+					[[key]]                 TEXT DEFAULT "" NOT NULL,
+					[[auth]]                TEXT DEFAULT "" NOT NULL,
+					[[fingerprint]]         TEXT DEFAULT "" NOT NULL,
+					[[status]]              TEXT DEFAULT "pending" NOT NULL,
+					[[responseStatus]]      INTEGER DEFAULT 0 NOT NULL,
+					[[responseHeaders]]     JSON DEFAULT "{}" NOT NULL,
+					[[responseBody]]        TEXT DEFAULT "" NOT NULL,
+					[[created]]             TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+					[[updated]]             TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotencyKeys_auth_key on {{_idempotencyKeys}} ([[auth]], [[key]]);
+				CREATE INDEX IF NOT EXISTS idx_idempotencyKeys_created on {{_idempotencyKeys}} ([[created]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.AuxDB().DropTable("_idempotencyKeys").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _idempotencyKeys table doesn't exist
+			exists := txApp.AuxHasTable("_idempotencyKeys")
+			return !exists, nil
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			// note: stored in the main db so that the history entries
+			// could be written in the same transaction as the record change
+			_, execErr := txApp.DB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_recordHistory}} (
+					[[id]]                TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+					[[collectionRef]]     TEXT DEFAULT "" NOT NULL,
+					[[recordRef]]         TEXT DEFAULT "" NOT NULL,
+					[[version]]           INTEGER DEFAULT 0 NOT NULL,
+					[[action]]            TEXT DEFAULT "" NOT NULL,
+					[[data]]              JSON DEFAULT "{}" NOT NULL,
+					[[changes]]           JSON DEFAULT "[]" NOT NULL,
+					[[authCollectionRef]] TEXT DEFAULT "" NOT NULL,
+					[[authRef]]           TEXT DEFAULT "" NOT NULL,
+					[[ip]]                TEXT DEFAULT "" NOT NULL,
+					[[created]]           TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_recordHistory_collectionRef_recordRef_version on {{_recordHistory}} ([[collectionRef]], [[recordRef]], [[version]]);
+				CREATE INDEX IF NOT EXISTS idx_recordHistory_authCollectionRef_authRef on {{_recordHistory}} ([[authCollectionRef]], [[authRef]]);
+				CREATE INDEX IF NOT EXISTS idx_recordHistory_created on {{_recordHistory}} ([[created]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.DB().DropTable("_recordHistory").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _recordHistory table doesn't exist
+			exists := txApp.HasTable("_recordHistory")
+			return !exists, nil
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.AuxDB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_realtimeEvents}} (
+					[[id]]         INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+					[[collection]] TEXT DEFAULT "" NOT NULL,
+					[[action]]     TEXT DEFAULT "" NOT NULL,
+					[[record]]     JSON DEFAULT "{}" NOT NULL,
+					[[created]]    TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_realtimeEvents_collection_id on {{_realtimeEvents}} ([[collection]], [[id]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.AuxDB().DropTable("_realtimeEvents").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _realtimeEvents table doesn't exist
+			exists := txApp.AuxHasTable("_realtimeEvents")
+			return !exists, nil
+		},
+	})
+}
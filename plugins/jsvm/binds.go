@@ -537,6 +537,18 @@ func baseBinds(vm *goja.Runtime) {
 		instance := &core.GeoPointField{}
 		return structConstructorUnmarshal(vm, call, instance)
 	})
+	vm.Set("GeoShapeField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.GeoShapeField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
+	vm.Set("EncryptedField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.EncryptedField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
+	vm.Set("VectorField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.VectorField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
 	// ---
 
 	vm.Set("MailerMessage", func(call goja.ConstructorCall) *goja.Object {
@@ -801,6 +813,9 @@ func apisBinds(vm *goja.Runtime) {
 	obj.Set("enrichRecord", apis.EnrichRecord)
 	obj.Set("enrichRecords", apis.EnrichRecords)
 
+	// realtime helpers
+	obj.Set("realtimePublish", apis.RealtimePublish)
+
 	// api errors
 	registerFactoryAsConstructor(vm, "ApiError", router.NewApiError)
 	registerFactoryAsConstructor(vm, "NotFoundError", router.NewNotFoundError)
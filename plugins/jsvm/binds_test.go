@@ -46,7 +46,7 @@ func TestBaseBindsCount(t *testing.T) {
 	vm := goja.New()
 	baseBinds(vm)
 
-	testBindsCount(vm, "this", 35, t)
+	testBindsCount(vm, "this", 38, t)
 }
 
 func TestBaseBindsSleep(t *testing.T) {
@@ -444,6 +444,18 @@ func TestBaseBindsNamedFields(t *testing.T) {
 			"new GeoPointField({name: 'test'})",
 			isType[*core.GeoPointField],
 		},
+		{
+			"new GeoShapeField({name: 'test'})",
+			isType[*core.GeoShapeField],
+		},
+		{
+			"new EncryptedField({name: 'test'})",
+			isType[*core.EncryptedField],
+		},
+		{
+			"new VectorField({name: 'test'})",
+			isType[*core.VectorField],
+		},
 	}
 
 	for _, s := range scenarios {
@@ -1089,7 +1101,7 @@ func TestApisBindsCount(t *testing.T) {
 	apisBinds(vm)
 
 	testBindsCount(vm, "this", 8, t)
-	testBindsCount(vm, "$apis", 11, t)
+	testBindsCount(vm, "$apis", 12, t)
 }
 
 func TestApisBindsApiError(t *testing.T) {
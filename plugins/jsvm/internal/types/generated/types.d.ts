@@ -510,6 +510,36 @@ declare class GeoPointField implements core.GeoPointField {
   constructor(data?: Partial<core.GeoPointField>)
 }
 
+interface GeoShapeField extends core.GeoShapeField{} // merge
+/**
+ * {@inheritDoc core.GeoShapeField}
+ *
+ * @group PocketBase
+ */
+declare class GeoShapeField implements core.GeoShapeField {
+  constructor(data?: Partial<core.GeoShapeField>)
+}
+
+interface EncryptedField extends core.EncryptedField{} // merge
+/**
+ * {@inheritDoc core.EncryptedField}
+ *
+ * @group PocketBase
+ */
+declare class EncryptedField implements core.EncryptedField {
+  constructor(data?: Partial<core.EncryptedField>)
+}
+
+interface VectorField extends core.VectorField{} // merge
+/**
+ * {@inheritDoc core.VectorField}
+ *
+ * @group PocketBase
+ */
+declare class VectorField implements core.VectorField {
+  constructor(data?: Partial<core.VectorField>)
+}
+
 interface MailerMessage extends mailer.Message{} // merge
 /**
  * MailerMessage defines a single email message.
@@ -1067,6 +1097,7 @@ declare namespace $apis {
   let bodyLimit:                     apis.bodyLimit
   let enrichRecord:                  apis.enrichRecord
   let enrichRecords:                 apis.enrichRecords
+  let realtimePublish:               apis.realtimePublish
 
   /**
    * RecordAuthResponse writes standardized json record auth response
@@ -10277,6 +10308,190 @@ namespace core {
    */
   validateSettings(ctx: context.Context, app: App, collection: Collection): void
  }
+ /**
+  * EncryptedField defines "encrypted" type field for storing text or
+  * JSON values encrypted at rest with AES-256-GCM.
+  * 
+  * The encryption key is derived from the secret stored in the env variable
+  * with the name of [BaseAppConfig.EncryptionEnv]. The values are decrypted
+  * transparently when accessed, for example:
+  * 
+  * ```
+  * 	record.Set("token", "abc")
+  * 	record.GetString("token") // "abc"
+  * ```
+  * 
+  * Encrypted fields can't be filtered or sorted. If BlindIndex is enabled,
+  * a keyed hash of the value is stored together with the encrypted value
+  * allowing "=" and "!=" comparisons against a single plain value.
+  * 
+  * The respective zero record field value is empty string (or the zero
+  * [types.JSONRaw] for the "json" format).
+  * 
+  * The following additional getter keys are available:
+  * 
+  *   - "fieldName:encrypted" - returns the raw stored (aka. encrypted) field value. For example:
+  *     record.GetString("token:encrypted")
+  */
+ interface EncryptedField {
+  /**
+   * Name (required) is the unique name of the field.
+   */
+  name: string
+  /**
+   * Id is the unique stable field identifier.
+   * 
+   * It is automatically generated from the name when adding to a collection FieldsList.
+   */
+  id: string
+  /**
+   * System prevents the renaming and removal of the field.
+   */
+  system: boolean
+  /**
+   * Hidden hides the field from the API response.
+   */
+  hidden: boolean
+  /**
+   * Presentable hints the Dashboard UI to use the underlying
+   * field record value in the relation preview label.
+   */
+  presentable: boolean
+  /**
+   * Format specifies the format of the plain field value.
+   * 
+   * Supported values are "text" and "json". If empty, fallback to "text".
+   */
+  format: string
+  /**
+   * MaxSize specifies the maximum size of the plain field value (in bytes).
+   * 
+   * If zero, a default limit of 64KB is applied.
+   */
+  maxSize: number
+  /**
+   * BlindIndex enables storing a keyed hash of the plain value
+   * allowing equality filters on the field.
+   * 
+   * Note that existing values are not reindexed automatically on change.
+   * To update them you could run the "encryption rotate" command with the same key.
+   */
+  blindIndex: boolean
+  /**
+   * Required will require the field value to be non-empty.
+   */
+  required: boolean
+ }
+ interface EncryptedField {
+  /**
+   * Type implements [Field.Type] interface method.
+   */
+  type(): string
+ }
+ interface EncryptedField {
+  /**
+   * GetId implements [Field.GetId] interface method.
+   */
+  getId(): string
+ }
+ interface EncryptedField {
+  /**
+   * SetId implements [Field.SetId] interface method.
+   */
+  setId(id: string): void
+ }
+ interface EncryptedField {
+  /**
+   * GetName implements [Field.GetName] interface method.
+   */
+  getName(): string
+ }
+ interface EncryptedField {
+  /**
+   * SetName implements [Field.SetName] interface method.
+   */
+  setName(name: string): void
+ }
+ interface EncryptedField {
+  /**
+   * GetSystem implements [Field.GetSystem] interface method.
+   */
+  getSystem(): boolean
+ }
+ interface EncryptedField {
+  /**
+   * SetSystem implements [Field.SetSystem] interface method.
+   */
+  setSystem(system: boolean): void
+ }
+ interface EncryptedField {
+  /**
+   * GetHidden implements [Field.GetHidden] interface method.
+   */
+  getHidden(): boolean
+ }
+ interface EncryptedField {
+  /**
+   * SetHidden implements [Field.SetHidden] interface method.
+   */
+  setHidden(hidden: boolean): void
+ }
+ interface EncryptedField {
+  /**
+   * ColumnType implements [Field.ColumnType] interface method.
+   */
+  columnType(app: App): string
+ }
+ interface EncryptedField {
+  /**
+   * IsJSON reports whether the field plain value is in JSON format.
+   */
+  isJSON(): boolean
+ }
+ interface EncryptedField {
+  /**
+   * PrepareValue implements [Field.PrepareValue] interface method.
+   * 
+   * The raw value is expected to be the stored encrypted field value.
+   */
+  prepareValue(record: Record, raw: any): any
+ }
+ interface EncryptedField {
+  /**
+   * DriverValue implements the [DriverValuer] interface.
+   */
+  driverValue(record: Record): any
+ }
+ interface EncryptedField {
+  /**
+   * ValidateValue implements [Field.ValidateValue] interface method.
+   */
+  validateValue(ctx: context.Context, app: App, record: Record): void
+ }
+ interface EncryptedField {
+  /**
+   * ValidateSettings implements [Field.ValidateSettings] interface method.
+   */
+  validateSettings(ctx: context.Context, app: App, collection: Collection): void
+ }
+ interface EncryptedField {
+  /**
+   * CalculateMaxBodySize implements the [MaxBodySizeCalculator] interface.
+   */
+  calculateMaxBodySize(): number
+ }
+ interface EncryptedField {
+  /**
+   * FindGetter implements the [GetterFinder] interface.
+   */
+  findGetter(key: string): GetterFunc
+ }
+ interface EncryptedField {
+  /**
+   * FindSetter implements the [SetterFinder] interface.
+   */
+  findSetter(key: string): SetterFunc
+ }
  /**
   * FileField defines "file" type field for managing record file(s).
   * 
@@ -10513,6 +10728,15 @@ namespace core {
   * 	record.Set("location", map[string]any{"lat":123, "lon":456})
   * 	record.Set("location", []byte(`{"lat":123, "lon":456}`)
   * ```
+  * 
+  * When SpatialIndex is enabled, the field values are also stored in a
+  * R*Tree index table that is used to prefilter the geoWithinBox() and
+  * geoDistance() filter expressions, eg.:
+  * 
+  * ```
+  * 	geoWithinBox(location, 23.2, 42.6, 23.4, 42.8) = true
+  * 	geoDistance(location.lon, location.lat, 23.32, 42.69) < 25
+  * ```
   */
  interface GeoPointField {
   /**
@@ -10542,6 +10766,11 @@ namespace core {
    * Required will require the field coordinates to be non-zero (aka. not "Null Island").
    */
   required: boolean
+  /**
+   * SpatialIndex enables the R*Tree index of the field values
+   * to speed up the bounding box and distance filters.
+   */
+  spatialIndex: boolean
  }
  interface GeoPointField {
   /**
@@ -10621,6 +10850,183 @@ namespace core {
    */
   validateSettings(ctx: context.Context, app: App, collection: Collection): void
  }
+ interface GeoPointField {
+  /**
+   * Intercept implements the [RecordInterceptor] interface.
+   */
+  intercept(ctx: context.Context, app: App, record: Record, actionName: string, actionFunc: () => void): void
+ }
+ interface GeoPointField {
+  /**
+   * IsSpatialIndexed implements the [SpatialIndexer] interface.
+   */
+  isSpatialIndexed(): boolean
+ }
+ interface GeoPointField {
+  /**
+   * SpatialBBox implements the [SpatialIndexer] interface.
+   * 
+   * Note that the zero "Null Island" points are also indexed so that the
+   * index prefilter doesn't change the result of the filter expressions.
+   */
+  spatialBBox(record: Record): [types.GeoBBox, boolean]
+ }
+ /**
+  * GeoShapeField defines "geoShape" type field for storing GeoJSON
+  * LineString, Polygon and MultiPolygon geometries (eg. delivery zones, routes).
+  * 
+  * You can set the record field value as [types.GeoShape], map or serialized GeoJSON geometry object.
+  * The stored value is always converted to [types.GeoShape] (with its bounding box cached in the "bbox" member).
+  * Nil, empty map, empty bytes slice, etc. results in zero [types.GeoShape].
+  * 
+  * Examples of updating a record's GeoShapeField value programmatically:
+  * 
+  * ```
+  * 	record.Set("zone", types.NewGeoPolygon([]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 10, Lat: 0}, {Lon: 10, Lat: 10}, {Lon: 0, Lat: 0}}))
+  * 	record.Set("zone", map[string]any{"type": "LineString", "coordinates": [][]float64{{0, 0}, {10, 10}}})
+  * 	record.Set("zone", []byte(`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]]}`))
+  * ```
+  * 
+  * The field values could be queried with the geoContains() and geoIntersects() filter functions, eg.:
+  * 
+  * ```
+  * 	geoContains(zone, @request.body.location) = true
+  * 	geoContains(zone, 23.32, 42.69) = true
+  * 	geoIntersects(zone, @request.body.route) = true
+  * ```
+  */
+ interface GeoShapeField {
+  /**
+   * Name (required) is the unique name of the field.
+   */
+  name: string
+  /**
+   * Id is the unique stable field identifier.
+   * 
+   * It is automatically generated from the name when adding to a collection FieldsList.
+   */
+  id: string
+  /**
+   * System prevents the renaming and removal of the field.
+   */
+  system: boolean
+  /**
+   * Hidden hides the field from the API response.
+   */
+  hidden: boolean
+  /**
+   * Presentable hints the Dashboard UI to use the underlying
+   * field record value in the relation preview label.
+   */
+  presentable: boolean
+  /**
+   * Required will require the field value to be non-zero shape.
+   */
+  required: boolean
+  /**
+   * SpatialIndex enables the R*Tree index of the field values bounding box
+   * to speed up the geoContains() and geoIntersects() filters.
+   */
+  spatialIndex: boolean
+ }
+ interface GeoShapeField {
+  /**
+   * Type implements [Field.Type] interface method.
+   */
+  type(): string
+ }
+ interface GeoShapeField {
+  /**
+   * GetId implements [Field.GetId] interface method.
+   */
+  getId(): string
+ }
+ interface GeoShapeField {
+  /**
+   * SetId implements [Field.SetId] interface method.
+   */
+  setId(id: string): void
+ }
+ interface GeoShapeField {
+  /**
+   * GetName implements [Field.GetName] interface method.
+   */
+  getName(): string
+ }
+ interface GeoShapeField {
+  /**
+   * SetName implements [Field.SetName] interface method.
+   */
+  setName(name: string): void
+ }
+ interface GeoShapeField {
+  /**
+   * GetSystem implements [Field.GetSystem] interface method.
+   */
+  getSystem(): boolean
+ }
+ interface GeoShapeField {
+  /**
+   * SetSystem implements [Field.SetSystem] interface method.
+   */
+  setSystem(system: boolean): void
+ }
+ interface GeoShapeField {
+  /**
+   * GetHidden implements [Field.GetHidden] interface method.
+   */
+  getHidden(): boolean
+ }
+ interface GeoShapeField {
+  /**
+   * SetHidden implements [Field.SetHidden] interface method.
+   */
+  setHidden(hidden: boolean): void
+ }
+ interface GeoShapeField {
+  /**
+   * ColumnType implements [Field.ColumnType] interface method.
+   */
+  columnType(app: App): string
+ }
+ interface GeoShapeField {
+  /**
+   * PrepareValue implements [Field.PrepareValue] interface method.
+   */
+  prepareValue(record: Record, raw: any): any
+ }
+ interface GeoShapeField {
+  /**
+   * ValidateValue implements [Field.ValidateValue] interface method.
+   */
+  validateValue(ctx: context.Context, app: App, record: Record): void
+ }
+ interface GeoShapeField {
+  /**
+   * ValidateSettings implements [Field.ValidateSettings] interface method.
+   */
+  validateSettings(ctx: context.Context, app: App, collection: Collection): void
+ }
+ interface GeoShapeField {
+  /**
+   * Intercept implements the [RecordInterceptor] interface.
+   */
+  intercept(ctx: context.Context, app: App, record: Record, actionName: string, actionFunc: () => void): void
+ }
+ interface GeoShapeField {
+  /**
+   * IsSpatialIndexed implements the [SpatialIndexer] interface.
+   */
+  isSpatialIndexed(): boolean
+ }
+ interface GeoShapeField {
+  /**
+   * SpatialBBox implements the [SpatialIndexer] interface.
+   * 
+   * The zero shapes are not indexed.
+   */
+  spatialBBox(record: Record): [types.GeoBBox, boolean]
+ }
  /**
   * JSONField defines "json" type field for storing any serialized JSON value.
   * 
@@ -11717,6 +12123,183 @@ namespace core {
    */
   validateSettings(ctx: context.Context, app: App, collection: Collection): void
  }
+ /**
+  * VectorField defines "vector" type field for storing fixed size
+  * float32 embedding vectors (eg. for semantic search).
+  * 
+  * The field value is stored in the db as compact little-endian float32 blob
+  * and it is serialized as json array of numbers.
+  * 
+  * You can set the record field value as [types.Vector], slice of numbers,
+  * json array string or comma separated list of numbers.
+  * Nil, empty string, empty slice, etc. results in zero [types.Vector] (stored as NULL).
+  * 
+  * Examples of updating a record's VectorField value programmatically:
+  * 
+  * ```
+  * 	record.Set("embedding", types.Vector{0.1, 0.2, 0.3})
+  * 	record.Set("embedding", []float64{0.1, 0.2, 0.3})
+  * 	record.Set("embedding", "[0.1, 0.2, 0.3]")
+  * ```
+  * 
+  * The records could be filtered and sorted by their distance to another
+  * vector with the vectorDistance() function, eg.:
+  * 
+  * ```
+  * 	sort=vectorDistance(embedding, @request.query.q_vec, 'cosine')
+  * 	filter=vectorDistance(embedding, @request.query.q_vec, 'l2') < 0.5
+  * ```
+  */
+ interface VectorField {
+  /**
+   * Name (required) is the unique name of the field.
+   */
+  name: string
+  /**
+   * Id is the unique stable field identifier.
+   * 
+   * It is automatically generated from the name when adding to a collection FieldsList.
+   */
+  id: string
+  /**
+   * System prevents the renaming and removal of the field.
+   */
+  system: boolean
+  /**
+   * Hidden hides the field from the API response.
+   */
+  hidden: boolean
+  /**
+   * Presentable hints the Dashboard UI to use the underlying
+   * field record value in the relation preview label.
+   */
+  presentable: boolean
+  /**
+   * Dimensions (required) specifies the exact number of the vector elements.
+   * 
+   * It can't be changed after the field creation.
+   */
+  dimensions: number
+  /**
+   * Required will require the field value to be non-zero vector.
+   */
+  required: boolean
+  /**
+   * Index enables the approximate nearest neighbour (IVF) index of the
+   * field values to speed up the vectorDistance() upper bound filters
+   * (eg. "vectorDistance(embedding, @request.query.q_vec) < 0.3").
+   * 
+   * The index trades accuracy for speed, aka. some of the matching
+   * records may be missing from the filtered results.
+   */
+  index: boolean
+  /**
+   * Probes specifies the number of the nearest index lists that are
+   * scanned when Index is enabled.
+   * 
+   * If zero, defaults to 1/4 of the index lists (but no less than 2).
+   */
+  probes: number
+ }
+ interface VectorField {
+  /**
+   * Type implements [Field.Type] interface method.
+   */
+  type(): string
+ }
+ interface VectorField {
+  /**
+   * GetId implements [Field.GetId] interface method.
+   */
+  getId(): string
+ }
+ interface VectorField {
+  /**
+   * SetId implements [Field.SetId] interface method.
+   */
+  setId(id: string): void
+ }
+ interface VectorField {
+  /**
+   * GetName implements [Field.GetName] interface method.
+   */
+  getName(): string
+ }
+ interface VectorField {
+  /**
+   * SetName implements [Field.SetName] interface method.
+   */
+  setName(name: string): void
+ }
+ interface VectorField {
+  /**
+   * GetSystem implements [Field.GetSystem] interface method.
+   */
+  getSystem(): boolean
+ }
+ interface VectorField {
+  /**
+   * SetSystem implements [Field.SetSystem] interface method.
+   */
+  setSystem(system: boolean): void
+ }
+ interface VectorField {
+  /**
+   * GetHidden implements [Field.GetHidden] interface method.
+   */
+  getHidden(): boolean
+ }
+ interface VectorField {
+  /**
+   * SetHidden implements [Field.SetHidden] interface method.
+   */
+  setHidden(hidden: boolean): void
+ }
+ interface VectorField {
+  /**
+   * ColumnType implements [Field.ColumnType] interface method.
+   */
+  columnType(app: App): string
+ }
+ interface VectorField {
+  /**
+   * PrepareValue implements [Field.PrepareValue] interface method.
+   * 
+   * Plain string and bytes values are treated as the raw db float32 blob.
+   * Malformed blobs result in zero vector.
+   */
+  prepareValue(record: Record, raw: any): any
+ }
+ interface VectorField {
+  /**
+   * DriverValue implements the [DriverValuer] interface.
+   */
+  driverValue(record: Record): any
+ }
+ interface VectorField {
+  /**
+   * ValidateValue implements [Field.ValidateValue] interface method.
+   */
+  validateValue(ctx: context.Context, app: App, record: Record): void
+ }
+ interface VectorField {
+  /**
+   * ValidateSettings implements [Field.ValidateSettings] interface method.
+   */
+  validateSettings(ctx: context.Context, app: App, collection: Collection): void
+ }
+ interface VectorField {
+  /**
+   * FindSetter implements the [SetterFinder] interface.
+   */
+  findSetter(key: string): SetterFunc
+ }
+ interface VectorField {
+  /**
+   * Intercept implements the [RecordInterceptor] interface.
+   */
+  intercept(ctx: context.Context, app: App, record: Record, actionName: string, actionFunc: () => void): void
+ }
  interface newFieldsList {
   /**
    * NewFieldsList creates a new FieldsList instance with the provided fields.
@@ -14280,6 +14863,26 @@ namespace apis {
    */
   (e: core.RequestEvent, records: Array<(core.Record | undefined)>, ...defaultExpands: string[]): void
  }
+ interface realtimePublish {
+  /**
+   * RealtimePublish publishes data to all clients subscribed
+   * to the specified custom realtime channel.
+   * 
+   * Note that the channel PublishRule is not checked and it is expected
+   * to be used only by trusted server-side code (eg. in hooks):
+   * 
+   * ```
+   * 	app.OnRecordAfterUpdateSuccess("docs").BindFunc(func(e *core.RecordEvent) error {
+   * 		err := apis.RealtimePublish(e.App, "docs/"+e.Record.Id, map[string]any{"saved": true})
+   * 		if err != nil {
+   * 			return err
+   * 		}
+   * 		return e.Next()
+   * 	})
+   * ```
+   */
+  (app: CoreApp, channel: string, data: any): void
+ }
  interface iterator<T> {
  }
  /**
@@ -17066,6 +17669,27 @@ namespace types {
    */
   scan(value: any): void
  }
+ /**
+  * GeoBBox defines a geo bounding box.
+  */
+ interface GeoBBox {
+  minLon: number
+  minLat: number
+  maxLon: number
+  maxLat: number
+ }
+ interface GeoBBox {
+  /**
+   * ContainsPoint reports whether the point is inside the current bounding box (including its edges).
+   */
+  containsPoint(p: GeoPoint): boolean
+ }
+ interface GeoBBox {
+  /**
+   * Intersects reports whether the current and the other bounding boxes have at least one common point.
+   */
+  intersects(other: GeoBBox): boolean
+ }
  /**
   * JSONArray defines a slice that is safe for json and db read/write.
   */
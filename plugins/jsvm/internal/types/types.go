@@ -526,6 +526,36 @@ declare class GeoPointField implements core.GeoPointField {
   constructor(data?: Partial<core.GeoPointField>)
 }
 
+interface GeoShapeField extends core.GeoShapeField{} // merge
+/**
+ * {@inheritDoc core.GeoShapeField}
+ *
+ * @group PocketBase
+ */
+declare class GeoShapeField implements core.GeoShapeField {
+  constructor(data?: Partial<core.GeoShapeField>)
+}
+
+interface EncryptedField extends core.EncryptedField{} // merge
+/**
+ * {@inheritDoc core.EncryptedField}
+ *
+ * @group PocketBase
+ */
+declare class EncryptedField implements core.EncryptedField {
+  constructor(data?: Partial<core.EncryptedField>)
+}
+
+interface VectorField extends core.VectorField{} // merge
+/**
+ * {@inheritDoc core.VectorField}
+ *
+ * @group PocketBase
+ */
+declare class VectorField implements core.VectorField {
+  constructor(data?: Partial<core.VectorField>)
+}
+
 interface MailerMessage extends mailer.Message{} // merge
 /**
  * MailerMessage defines a single email message.
@@ -1083,6 +1113,7 @@ declare namespace $apis {
   let bodyLimit:                     apis.bodyLimit
   let enrichRecord:                  apis.enrichRecord
   let enrichRecords:                 apis.enrichRecords
+  let realtimePublish:               apis.realtimePublish
 
   /**
    * RecordAuthResponse writes standardized json record auth response
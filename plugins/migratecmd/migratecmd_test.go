@@ -59,6 +59,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": true,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -86,6 +87,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": false,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -122,6 +124,8 @@ migrate((app) => {
     "fileToken": {
       "duration": 180
     },
+    "history": false,
+    "historyRule": null,
     "id": "@TEST_RANDOM",
     "indexes": [
       "create index test on new_name (id)",
@@ -167,7 +171,11 @@ migrate((app) => {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "softDelete": false,
+    "softDeleteRetention": 0,
     "system": true,
+    "ttl": 0,
+    "ttlField": "",
     "type": "auth",
     "updateRule": null,
     "verificationTemplate": {
@@ -235,6 +243,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": true,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -262,6 +271,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": false,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -298,6 +308,8 @@ func init() {
 			"fileToken": {
 				"duration": 180
 			},
+			"history": false,
+			"historyRule": null,
 			"id": "@TEST_RANDOM",
 			"indexes": [
 				"create index test on new_name (id)",
@@ -343,7 +355,11 @@ func init() {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"softDelete": false,
+			"softDeleteRetention": 0,
 			"system": true,
+			"ttl": 0,
+			"ttlField": "",
 			"type": "auth",
 			"updateRule": null,
 			"verificationTemplate": {
@@ -500,6 +516,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": true,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -527,6 +544,7 @@ migrate((app) => {
         "presentable": false,
         "primaryKey": false,
         "required": true,
+        "searchable": false,
         "system": true,
         "type": "text"
       },
@@ -563,6 +581,8 @@ migrate((app) => {
     "fileToken": {
       "duration": 180
     },
+    "history": false,
+    "historyRule": null,
     "id": "@TEST_RANDOM",
     "indexes": [
       "create index test on test123 (id)",
@@ -608,7 +628,11 @@ migrate((app) => {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "softDelete": false,
+    "softDeleteRetention": 0,
     "system": false,
+    "ttl": 0,
+    "ttlField": "",
     "type": "auth",
     "updateRule": null,
     "verificationTemplate": {
@@ -679,6 +703,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": true,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -706,6 +731,7 @@ func init() {
 					"presentable": false,
 					"primaryKey": false,
 					"required": true,
+					"searchable": false,
 					"system": true,
 					"type": "text"
 				},
@@ -742,6 +768,8 @@ func init() {
 			"fileToken": {
 				"duration": 180
 			},
+			"history": false,
+			"historyRule": null,
 			"id": "@TEST_RANDOM",
 			"indexes": [
 				"create index test on test123 (id)",
@@ -787,7 +815,11 @@ func init() {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"softDelete": false,
+			"softDeleteRetention": 0,
 			"system": false,
+			"ttl": 0,
+			"ttlField": "",
 			"type": "auth",
 			"updateRule": null,
 			"verificationTemplate": {
@@ -932,6 +964,7 @@ migrate((app) => {
     "presentable": false,
     "primaryKey": false,
     "required": false,
+    "searchable": false,
     "system": false,
     "type": "text"
   }))
@@ -1063,6 +1096,7 @@ func init() {
 			"presentable": false,
 			"primaryKey": false,
 			"required": false,
+			"searchable": false,
 			"system": false,
 			"type": "text"
 		}` + "`" + `)); err != nil {
@@ -161,11 +161,16 @@ func NewWithConfig(config Config) *PocketBase {
 }
 
 // Start starts the application, aka. registers the default system
-// commands (serve, superuser, version) and executes pb.RootCmd.
+// commands (serve, superuser, openapi, certs, export, import, encryption, version) and executes pb.RootCmd.
 func (pb *PocketBase) Start() error {
 	// register system commands
 	pb.RootCmd.AddCommand(cmd.NewSuperuserCommand(pb))
 	pb.RootCmd.AddCommand(cmd.NewServeCommand(pb, !pb.hideStartBanner))
+	pb.RootCmd.AddCommand(cmd.NewOpenAPICommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewCertsCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewExportCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewImportCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewEncryptionCommand(pb))
 
 	return pb.Execute()
 }
@@ -0,0 +1,494 @@
+// Package certstore implements a directory based TLS certificates store
+// with SNI (including wildcard) lookup, in-memory caching and hot reload.
+//
+// Each certificate is loaded from a "<host>.pem" file containing the
+// PEM encoded certificate chain and private key. The private key could be also
+// stored in a separate "<host>-key.pem" file (the default mkcert naming).
+//
+// Wildcard certificates could be stored as "_.example.com.pem" (or "*.example.com.pem").
+//
+// Optionally a default cert/key pair (see [Store.SetDefault]) could be used
+// as fallback when there is no certificate matching the requested server name.
+//
+// Example:
+//
+//	store := certstore.New("pb_data/.certs")
+//
+//	if err := store.Load(); err != nil {
+//		return err
+//	}
+//
+//	tlsConfig := &tls.Config{GetCertificate: store.GetCertificate}
+package certstore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	certExt       = ".pem"
+	keyFileSuffix = "-key.pem"
+)
+
+// ErrNoCertificate is returned by [Store.GetCertificate] when there is
+// no certificate matching the requested server name.
+var ErrNoCertificate = errors.New("no matching certificate")
+
+// Certificate describes a single loaded certificate file.
+type Certificate struct {
+	// File is the certificate file name (relative to the store directory).
+	File string `json:"file"`
+
+	// Hosts lists the host names (and IPs) that the certificate is indexed with.
+	Hosts []string `json:"hosts"`
+
+	// Issuer is the certificate issuer common name.
+	Issuer string `json:"issuer"`
+
+	// NotBefore is the certificate validity start date.
+	NotBefore time.Time `json:"notBefore"`
+
+	// NotAfter is the certificate expiry date.
+	NotAfter time.Time `json:"notAfter"`
+
+	keyPair *tls.Certificate
+}
+
+// Store is a concurrent safe directory based TLS certificates store.
+type Store struct {
+	watcher         *fsnotify.Watcher
+	debounceTimer   *time.Timer
+	hosts           map[string]*Certificate
+	certs           []*Certificate
+	defaultCert     *Certificate
+	defaultErr      error
+	dir             string
+	defaultCertFile string
+	defaultKeyFile  string
+	mu              sync.RWMutex
+	loadMu          sync.Mutex
+	watchMu         sync.Mutex
+}
+
+// New creates a new certificates store for the specified directory.
+//
+// The directory could be empty if only a default certificate is used (see [Store.SetDefault]).
+//
+// Note that the certificates are not loaded until [Store.Load] is called.
+func New(dir string) *Store {
+	return &Store{
+		dir:   dir,
+		hosts: map[string]*Certificate{},
+	}
+}
+
+// Dir returns the store certificates directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Load (re)loads all certificates from the store directory
+// and replaces the in-memory cache.
+//
+// A missing store directory is treated as empty.
+//
+// Invalid certificate files are skipped and their errors are returned
+// joined together after all valid certificates are loaded.
+func (s *Store) Load() error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	var entries []os.DirEntry
+	if s.dir != "" {
+		var err error
+		entries, err = os.ReadDir(s.dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	var errs []error
+
+	// note: the default files are changed only under loadMu
+	defaultCertFile, defaultKeyFile := s.defaultCertFile, s.defaultKeyFile
+
+	var defaultCert *Certificate
+	var defaultErr error
+	if defaultCertFile != "" {
+		defaultCert, defaultErr = loadCertificate(defaultCertFile, defaultCertFile, defaultKeyFile)
+		if defaultErr != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", defaultCertFile, defaultErr))
+		}
+	}
+
+	hosts := map[string]*Certificate{}
+	certs := make([]*Certificate, 0, len(entries))
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		if entry.IsDir() || !strings.HasSuffix(name, certExt) || strings.HasSuffix(name, keyFileSuffix) {
+			continue
+		}
+
+		cert, err := s.loadFile(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		certs = append(certs, cert)
+
+		for _, host := range cert.Hosts {
+			// on conflict prefer the certificate that expires later
+			if existing, ok := hosts[host]; !ok || existing.NotAfter.Before(cert.NotAfter) {
+				hosts[host] = cert
+			}
+		}
+	}
+
+	if defaultCert != nil {
+		certs = append(certs, defaultCert)
+	}
+
+	s.mu.Lock()
+	s.hosts = hosts
+	s.certs = certs
+	s.defaultCert = defaultCert
+	s.defaultErr = defaultErr
+	s.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// SetDefault loads the specified cert/key pair files and registers it as
+// the default certificate that is returned by [Store.GetCertificate]
+// when there is no certificate matching the requested server name.
+//
+// The files could be outside of the store directory. They are reloaded
+// together with the other certificates on [Store.Load] and on file change
+// if the store is watched (see [Store.Watch]).
+//
+// The default certificate is also included in the [Store.List] result
+// with File set to the provided certFile path.
+func (s *Store) SetDefault(certFile string, keyFile string) error {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	cert, err := loadCertificate(certFile, certFile, keyFile)
+
+	s.mu.Lock()
+	oldDefault := s.defaultCert
+	s.defaultCertFile = certFile
+	s.defaultKeyFile = keyFile
+	s.defaultCert = cert
+	s.defaultErr = err
+	s.certs = slices.DeleteFunc(slices.Clone(s.certs), func(c *Certificate) bool {
+		return c == oldDefault
+	})
+	if cert != nil {
+		s.certs = append(s.certs, cert)
+	}
+	s.mu.Unlock()
+
+	if s.watcher != nil {
+		for _, dir := range s.watchDirs() {
+			if watchErr := s.watcher.Add(dir); watchErr != nil {
+				err = errors.Join(err, watchErr)
+			}
+		}
+	}
+
+	return err
+}
+
+func (s *Store) loadFile(name string) (*Certificate, error) {
+	return loadCertificate(
+		name,
+		filepath.Join(s.dir, name),
+		filepath.Join(s.dir, strings.TrimSuffix(name, certExt)+keyFileSuffix),
+	)
+}
+
+// loadCertificate loads the certificate from the provided PEM files.
+//
+// The keyFile is read only if the certFile doesn't contain the private key.
+func loadCertificate(name string, certFile string, keyFile string) (*Certificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM := certPEM
+	if !strings.Contains(string(certPEM), "PRIVATE KEY-----") {
+		keyPEM, err = os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("missing private key: %w", err)
+		}
+	}
+
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := keyPair.Leaf
+	if leaf == nil {
+		leaf, err = x509.ParseCertificate(keyPair.Certificate[0])
+		if err != nil {
+			return nil, err
+		}
+		keyPair.Leaf = leaf
+	}
+
+	cert := &Certificate{
+		File:      name,
+		Issuer:    leaf.Issuer.CommonName,
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+		keyPair:   &keyPair,
+	}
+
+	for _, host := range leaf.DNSNames {
+		cert.Hosts = appendHost(cert.Hosts, host)
+	}
+	for _, ip := range leaf.IPAddresses {
+		cert.Hosts = appendHost(cert.Hosts, ip.String())
+	}
+
+	// fallback to the file name host
+	if len(cert.Hosts) == 0 {
+		host := strings.TrimSuffix(filepath.Base(name), certExt)
+		if strings.HasPrefix(host, "_.") {
+			host = "*" + host[1:]
+		}
+		cert.Hosts = appendHost(cert.Hosts, host)
+	}
+
+	return cert, nil
+}
+
+func appendHost(hosts []string, host string) []string {
+	host = normalizeHost(host)
+	if host == "" || slices.Contains(hosts, host) {
+		return hosts
+	}
+
+	return append(hosts, host)
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// Lookup returns the certificate matching the provided server name (aka. SNI).
+//
+// It first checks for an exact host match and then for a wildcard one
+// (eg. "*.example.com" matches "a.example.com" but not "a.b.example.com").
+//
+// Returns nil if there is no matching certificate.
+func (s *Store) Lookup(serverName string) *Certificate {
+	serverName = normalizeHost(serverName)
+	if serverName == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if cert, ok := s.hosts[serverName]; ok {
+		return cert
+	}
+
+	if _, rest, ok := strings.Cut(serverName, "."); ok && rest != "" {
+		if cert, ok := s.hosts["*."+rest]; ok {
+			return cert
+		}
+	}
+
+	return nil
+}
+
+// GetCertificate implements the [tls.Config.GetCertificate] callback.
+//
+// If there is no matching certificate, it fallbacks to the default one (if set).
+//
+// Returns [ErrNoCertificate] if there is neither matching nor default certificate.
+func (s *Store) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := s.Lookup(hello.ServerName); cert != nil {
+		return cert.keyPair, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.defaultCert != nil {
+		return s.defaultCert.keyPair, nil
+	}
+
+	if s.defaultErr != nil {
+		return nil, s.defaultErr
+	}
+
+	return nil, ErrNoCertificate
+}
+
+// List returns a list with the loaded certificates sorted by their file name.
+func (s *Store) List() []Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Certificate, len(s.certs))
+	for i, cert := range s.certs {
+		result[i] = *cert
+		result[i].Hosts = slices.Clone(cert.Hosts)
+	}
+
+	slices.SortFunc(result, func(a, b Certificate) int {
+		return strings.Compare(a.File, b.File)
+	})
+
+	return result
+}
+
+// Watch starts watching the store directory (and the default certificate files, if any)
+// for changes and automatically reloads the certificates on file create, update or delete.
+//
+// The optional onReload callback is called after each reload with
+// the [Store.Load] result.
+//
+// Call [Store.Close] to stop watching.
+func (s *Store) Watch(onReload func(err error)) error {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	if s.watcher != nil {
+		return errors.New("the store is already watched")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range s.watchDirs() {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	s.watcher = watcher
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if !s.isWatchedFile(event.Name) {
+					continue
+				}
+
+				// debounce the reload since a single file save
+				// could trigger multiple events
+				s.watchMu.Lock()
+				if s.debounceTimer != nil {
+					s.debounceTimer.Stop()
+				}
+				s.debounceTimer = time.AfterFunc(100*time.Millisecond, func() {
+					err := s.Load()
+					if onReload != nil {
+						onReload(err)
+					}
+				})
+				s.watchMu.Unlock()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if onReload != nil {
+					onReload(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close stops the store directory watcher (if any).
+func (s *Store) Close() error {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	if s.debounceTimer != nil {
+		s.debounceTimer.Stop()
+		s.debounceTimer = nil
+	}
+
+	if s.watcher == nil {
+		return nil
+	}
+
+	err := s.watcher.Close()
+	s.watcher = nil
+
+	return err
+}
+
+// watchDirs returns the directories of the store and its default certificate files.
+func (s *Store) watchDirs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dirs := make([]string, 0, 3)
+
+	if s.dir != "" {
+		dirs = append(dirs, filepath.Clean(s.dir))
+	}
+
+	for _, file := range []string{s.defaultCertFile, s.defaultKeyFile} {
+		if file == "" {
+			continue
+		}
+
+		if dir := filepath.Dir(file); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// isWatchedFile reports whether the provided changed file path
+// is a store certificate or one of the default certificate files.
+func (s *Store) isWatchedFile(path string) bool {
+	path = filepath.Clean(path)
+
+	if s.dir != "" && filepath.Dir(path) == filepath.Clean(s.dir) && strings.HasSuffix(path, certExt) {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return (s.defaultCertFile != "" && path == filepath.Clean(s.defaultCertFile)) ||
+		(s.defaultKeyFile != "" && path == filepath.Clean(s.defaultKeyFile))
+}
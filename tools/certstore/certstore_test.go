@@ -0,0 +1,322 @@
+package certstore_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/certstore"
+)
+
+func generateCert(t testing.TB, notAfter time.Time, hosts ...string) (certPEM []byte, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test"},
+		Issuer:       pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     hosts,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	return certPEM, keyPEM
+}
+
+func writeCert(t testing.TB, dir string, name string, notAfter time.Time, hosts ...string) {
+	certPEM, keyPEM := generateCert(t, notAfter, hosts...)
+
+	if err := os.WriteFile(filepath.Join(dir, name), append(certPEM, keyPEM...), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreLoadAndLookup(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	expiry := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+
+	writeCert(t, dir, "example.com.pem", expiry, "example.com", "www.example.com")
+	writeCert(t, dir, "_.example.com.pem", expiry.Add(time.Hour), "*.example.com")
+	writeCert(t, dir, "nohosts.test.pem", expiry)
+	writeCert(t, dir, "conflict.pem", expiry.Add(-time.Hour), "example.com")
+
+	// separate key file
+	certPEM, keyPEM := generateCert(t, expiry, "separate.test")
+	os.WriteFile(filepath.Join(dir, "separate.test.pem"), certPEM, 0644)
+	os.WriteFile(filepath.Join(dir, "separate.test-key.pem"), keyPEM, 0644)
+
+	// invalid files
+	os.WriteFile(filepath.Join(dir, "invalid.pem"), []byte("invalid"), 0644)
+	os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("invalid"), 0644)
+
+	store := certstore.New(dir)
+
+	err := store.Load()
+	if err == nil || !strings.Contains(err.Error(), "invalid.pem") {
+		t.Fatalf("Expected invalid.pem load error, got %v", err)
+	}
+
+	scenarios := []struct {
+		serverName string
+		expected   string
+	}{
+		{"", ""},
+		{"missing.test", ""},
+		{"example.com", "example.com.pem"},
+		{"EXAMPLE.com.", "example.com.pem"},
+		{"www.example.com", "example.com.pem"},
+		{"a.example.com", "_.example.com.pem"},
+		{"a.b.example.com", ""},
+		{"nohosts.test", "nohosts.test.pem"},
+		{"separate.test", "separate.test.pem"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.serverName, func(t *testing.T) {
+			cert := store.Lookup(s.serverName)
+
+			var file string
+			if cert != nil {
+				file = cert.File
+			}
+
+			if file != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, file)
+			}
+
+			tlsCert, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: s.serverName})
+			if s.expected == "" {
+				if !errors.Is(err, certstore.ErrNoCertificate) || tlsCert != nil {
+					t.Fatalf("Expected ErrNoCertificate, got %v (%v)", err, tlsCert)
+				}
+			} else if err != nil || tlsCert == nil {
+				t.Fatalf("Expected tls certificate, got %v (%v)", err, tlsCert)
+			}
+		})
+	}
+}
+
+func TestStoreLoadMissingDir(t *testing.T) {
+	t.Parallel()
+
+	store := certstore.New(filepath.Join(t.TempDir(), "missing"))
+
+	if err := store.Load(); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	if total := len(store.List()); total != 0 {
+		t.Fatalf("Expected no certificates, got %d", total)
+	}
+}
+
+func TestStoreList(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	expiry := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+
+	writeCert(t, dir, "b.test.pem", expiry, "b.test")
+	writeCert(t, dir, "a.test.pem", expiry, "a.test", "A.test", "www.a.test")
+
+	store := certstore.New(dir)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	list := store.List()
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 certificates, got %d", len(list))
+	}
+
+	if list[0].File != "a.test.pem" || list[1].File != "b.test.pem" {
+		t.Fatalf("Expected the certificates to be sorted by file, got %q, %q", list[0].File, list[1].File)
+	}
+
+	if hosts := strings.Join(list[0].Hosts, ","); hosts != "a.test,www.a.test" {
+		t.Fatalf("Expected hosts %q, got %q", "a.test,www.a.test", hosts)
+	}
+
+	if !list[0].NotAfter.Equal(expiry) {
+		t.Fatalf("Expected NotAfter %v, got %v", expiry, list[0].NotAfter)
+	}
+
+	if list[0].Issuer != "test" {
+		t.Fatalf("Expected issuer %q, got %q", "test", list[0].Issuer)
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	store := certstore.New(dir)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := make(chan error, 10)
+	if err := store.Watch(func(err error) { reloaded <- err }); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if err := store.Watch(nil); err == nil {
+		t.Fatal("Expected duplicated Watch call error")
+	}
+
+	writeCert(t, dir, "watch.test.pem", time.Now().Add(time.Hour), "watch.test")
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("Expected nil reload error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the store to be reloaded")
+	}
+
+	if store.Lookup("watch.test") == nil {
+		t.Fatal("Expected the new certificate to be loaded")
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStoreDefault(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	defaultDir := t.TempDir()
+
+	expiry := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+
+	writeCert(t, dir, "a.test.pem", expiry, "a.test")
+
+	certPEM, keyPEM := generateCert(t, expiry, "default.test")
+	certFile := filepath.Join(defaultDir, "cert.pem")
+	keyFile := filepath.Join(defaultDir, "key.pem")
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := certstore.New(dir)
+
+	if _, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: "missing.test"}); !errors.Is(err, certstore.ErrNoCertificate) {
+		t.Fatalf("Expected ErrNoCertificate without default certificate, got %v", err)
+	}
+
+	if err := store.SetDefault(filepath.Join(defaultDir, "missing.pem"), keyFile); err == nil {
+		t.Fatal("Expected SetDefault error for missing cert file")
+	}
+
+	if _, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: "missing.test"}); err == nil || errors.Is(err, certstore.ErrNoCertificate) {
+		t.Fatalf("Expected the default certificate load error, got %v", err)
+	}
+
+	if err := store.SetDefault(certFile, keyFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// SNI match
+	cert, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: "a.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Leaf.DNSNames[0] != "a.test" {
+		t.Fatalf("Expected the a.test certificate, got %v", cert.Leaf.DNSNames)
+	}
+
+	// default fallback
+	cert, err = store.GetCertificate(&tls.ClientHelloInfo{ServerName: "missing.test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Leaf.DNSNames[0] != "default.test" {
+		t.Fatalf("Expected the default certificate, got %v", cert.Leaf.DNSNames)
+	}
+
+	// the default certificate is not indexed by its hosts
+	if store.Lookup("default.test") != nil {
+		t.Fatal("Expected the default certificate to not be indexed")
+	}
+
+	list := store.List()
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 certificates, got %d", len(list))
+	}
+	if !slices.ContainsFunc(list, func(c certstore.Certificate) bool { return c.File == certFile }) {
+		t.Fatalf("Expected the default certificate file %q to be listed, got %v", certFile, list)
+	}
+
+	// reload on default file change
+	reloaded := make(chan error, 10)
+	if err := store.Watch(func(err error) { reloaded <- err }); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	certPEM, keyPEM = generateCert(t, expiry, "changed.test")
+	if err := os.WriteFile(keyFile, keyPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case <-reloaded:
+		case <-deadline:
+			t.Fatal("Expected the default certificate to be reloaded")
+		}
+
+		cert, err = store.GetCertificate(&tls.ClientHelloInfo{ServerName: "missing.test"})
+		if err == nil && cert.Leaf.DNSNames[0] == "changed.test" {
+			break
+		}
+	}
+}
@@ -0,0 +1,453 @@
+// Package dns01 implements an ACME (RFC 8555) certificates manager
+// that uses the DNS-01 challenge and therefore could issue wildcard certificates.
+//
+// It complements the [autocert.Manager] (which supports only the HTTP-01 and
+// TLS-ALPN-01 challenges) and could be used with any ACME CA, including
+// private ones (step-ca, pebble, etc.).
+//
+// Example:
+//
+//	m := &dns01.Manager{
+//		Client:  &acme.Client{DirectoryURL: "https://ca.internal/acme/acme/directory"},
+//		Solver:  &dns01.ExecSolver{Command: "/usr/local/bin/dns-hook"},
+//		Cache:   autocert.DirCache("certs"),
+//		Domains: []string{"example.com", "*.example.com"},
+//	}
+//
+//	tlsConfig := &tls.Config{GetCertificate: m.GetCertificate}
+package dns01
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	cacheKeyPrefix  = "dns01+"
+	accountCacheKey = cacheKeyPrefix + "account+key"
+
+	// DefaultRenewBefore is the default certificate renewal period before its expiry.
+	DefaultRenewBefore = 30 * 24 * time.Hour
+
+	// DefaultTimeout is the default max duration of a single certificate issue.
+	DefaultTimeout = 5 * time.Minute
+)
+
+// ErrUnmanagedDomain is returned when the requested server name
+// is not matching any of the [Manager.Domains].
+var ErrUnmanagedDomain = errors.New("dns01: unmanaged domain")
+
+// Manager is a DNS-01 based ACME certificates manager.
+//
+// All exported fields should be set before the first [Manager.GetCertificate] call.
+type Manager struct {
+	// Client is the ACME client used for the certificate requests.
+	//
+	// If Client.Key is nil, an account key will be loaded from (or stored in) the Cache.
+	// If Client.DirectoryURL is empty, Let's Encrypt production directory is used.
+	Client *acme.Client
+
+	// Solver manages the DNS-01 challenge TXT records.
+	Solver Solver
+
+	// Cache is an optional certificates and account key storage.
+	//
+	// The cache keys are prefixed with "dns01+" so it is safe to share
+	// the same cache with an [autocert.Manager].
+	Cache autocert.Cache
+
+	// Domains lists the domains the manager is allowed to issue certificates for.
+	//
+	// Wildcard domains (eg. "*.example.com") match a single subdomain label.
+	Domains []string
+
+	// Email is an optional ACME account contact email.
+	Email string
+
+	// ExternalAccountBinding is an optional EAB required by some CAs to register new accounts.
+	ExternalAccountBinding *acme.ExternalAccountBinding
+
+	// RenewBefore specifies how early the certificates should be renewed
+	// before they expire (default to [DefaultRenewBefore]).
+	RenewBefore time.Duration
+
+	// PropagationDelay is an optional duration to wait after the challenge
+	// TXT record creation before notifying the CA.
+	PropagationDelay time.Duration
+
+	group        singleflight.Group
+	certs        map[string]*tls.Certificate
+	mu           sync.RWMutex
+	registerOnce sync.Mutex
+	registered   bool
+}
+
+// Manages reports whether the provided server name is matching one of the manager domains.
+func (m *Manager) Manages(serverName string) bool {
+	_, ok := m.certDomain(serverName)
+	return ok
+}
+
+// certDomain returns the managed domain (exact or wildcard) matching the provided server name.
+func (m *Manager) certDomain(serverName string) (string, bool) {
+	serverName = strings.TrimSuffix(strings.ToLower(serverName), ".")
+	if serverName == "" {
+		return "", false
+	}
+
+	if slices.Contains(m.Domains, serverName) {
+		return serverName, true
+	}
+
+	if _, rest, ok := strings.Cut(serverName, "."); ok && rest != "" {
+		wildcard := "*." + rest
+		if slices.Contains(m.Domains, wildcard) {
+			return wildcard, true
+		}
+	}
+
+	return "", false
+}
+
+// GetCertificate implements the [tls.Config.GetCertificate] callback.
+//
+// It returns the cached certificate for the matching managed domain
+// or issues a new one. Certificates that are close to their expiry
+// are renewed in the background.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	domain, ok := m.certDomain(hello.ServerName)
+	if !ok {
+		return nil, ErrUnmanagedDomain
+	}
+
+	ctx := hello.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cert, err := m.cachedCert(ctx, domain)
+	if err == nil {
+		if m.needsRenewal(cert) {
+			go func() {
+				_, _, _ = m.group.Do(domain, func() (any, error) {
+					ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+					defer cancel()
+
+					return m.obtain(ctx, domain)
+				})
+			}()
+		}
+
+		return cert, nil
+	}
+
+	result, err, _ := m.group.Do(domain, func() (any, error) {
+		// detach from the handshake context since the result is shared
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), DefaultTimeout)
+		defer cancel()
+
+		return m.obtain(ctx, domain)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*tls.Certificate), nil
+}
+
+func (m *Manager) needsRenewal(cert *tls.Certificate) bool {
+	renewBefore := m.RenewBefore
+	if renewBefore <= 0 {
+		renewBefore = DefaultRenewBefore
+	}
+
+	return cert.Leaf == nil || time.Until(cert.Leaf.NotAfter) < renewBefore
+}
+
+// cachedCert returns the domain certificate from the memory or the persistent cache.
+func (m *Manager) cachedCert(ctx context.Context, domain string) (*tls.Certificate, error) {
+	m.mu.RLock()
+	cert, ok := m.certs[domain]
+	m.mu.RUnlock()
+	if ok {
+		return cert, nil
+	}
+
+	if m.Cache == nil {
+		return nil, autocert.ErrCacheMiss
+	}
+
+	data, err := m.Cache.Get(ctx, certCacheKey(domain))
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err = decodeCert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(cert.Leaf.NotAfter) {
+		return nil, autocert.ErrCacheMiss
+	}
+
+	m.storeInMemory(domain, cert)
+
+	return cert, nil
+}
+
+func (m *Manager) storeInMemory(domain string, cert *tls.Certificate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.certs == nil {
+		m.certs = map[string]*tls.Certificate{}
+	}
+	m.certs[domain] = cert
+}
+
+// obtain issues a new certificate for the specified domain.
+func (m *Manager) obtain(ctx context.Context, domain string) (*tls.Certificate, error) {
+	if m.Solver == nil {
+		return nil, errors.New("dns01: missing solver")
+	}
+
+	if err := m.register(ctx); err != nil {
+		return nil, err
+	}
+
+	order, err := m.Client.AuthorizeOrder(ctx, acme.DomainIDs(domain))
+	if err != nil {
+		return nil, fmt.Errorf("dns01: failed to create order: %w", err)
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		if err := m.authorize(ctx, authzURL); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err = m.Client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, fmt.Errorf("dns01: order failed: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: []string{domain},
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+
+	der, _, err := m.Client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("dns01: failed to finalize order: %w", err)
+	}
+
+	data, err := encodeCert(key, der)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := decodeCert(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Cache != nil {
+		if err := m.Cache.Put(ctx, certCacheKey(domain), data); err != nil {
+			return nil, err
+		}
+	}
+
+	m.storeInMemory(domain, cert)
+
+	return cert, nil
+}
+
+// authorize completes the DNS-01 challenge of a single order authorization.
+func (m *Manager) authorize(ctx context.Context, authzURL string) error {
+	authz, err := m.Client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "dns-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("dns01: no dns-01 challenge offered for %q", authz.Identifier.Value)
+	}
+
+	value, err := m.Client.DNS01ChallengeRecord(challenge.Token)
+	if err != nil {
+		return err
+	}
+
+	name := RecordName(authz.Identifier.Value)
+
+	if err := m.Solver.Present(ctx, name, value); err != nil {
+		return fmt.Errorf("dns01: failed to present %q: %w", name, err)
+	}
+	defer m.Solver.CleanUp(context.WithoutCancel(ctx), name, value)
+
+	if m.PropagationDelay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.PropagationDelay):
+		}
+	}
+
+	if _, err := m.Client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("dns01: failed to accept the challenge: %w", err)
+	}
+
+	if _, err := m.Client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("dns01: authorization failed: %w", err)
+	}
+
+	return nil
+}
+
+// register ensures that the ACME client has an account key and a registered account.
+func (m *Manager) register(ctx context.Context) error {
+	m.registerOnce.Lock()
+	defer m.registerOnce.Unlock()
+
+	if m.registered {
+		return nil
+	}
+
+	if m.Client.Key == nil {
+		key, err := m.accountKey(ctx)
+		if err != nil {
+			return err
+		}
+		m.Client.Key = key
+	}
+
+	account := &acme.Account{ExternalAccountBinding: m.ExternalAccountBinding}
+	if m.Email != "" {
+		account.Contact = []string{"mailto:" + m.Email}
+	}
+
+	_, err := m.Client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return fmt.Errorf("dns01: failed to register account: %w", err)
+	}
+
+	m.registered = true
+
+	return nil
+}
+
+// accountKey loads the account key from the cache or generates a new one.
+func (m *Manager) accountKey(ctx context.Context) (crypto.Signer, error) {
+	if m.Cache != nil {
+		if data, err := m.Cache.Get(ctx, accountCacheKey); err == nil {
+			block, _ := pem.Decode(data)
+			if block == nil {
+				return nil, errors.New("dns01: invalid cached account key")
+			}
+			return x509.ParseECPrivateKey(block.Bytes)
+		} else if !errors.Is(err, autocert.ErrCacheMiss) {
+			return nil, err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Cache != nil {
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		if err := m.Cache.Put(ctx, accountCacheKey, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+func certCacheKey(domain string) string {
+	// "*" is not a valid file name character on some systems
+	return cacheKeyPrefix + strings.ReplaceAll(domain, "*", "_")
+}
+
+// encodeCert encodes the private key and the certificate chain as PEM.
+func encodeCert(key *ecdsa.PrivateKey, chain [][]byte) ([]byte, error) {
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err := pem.Encode(&buf, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}); err != nil {
+		return nil, err
+	}
+
+	for _, der := range chain {
+		if err := pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeCert decodes a PEM encoded private key and certificate chain.
+func decodeCert(data []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(data, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert.Leaf == nil {
+		cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &cert, nil
+}
@@ -0,0 +1,208 @@
+package dns01_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/dns01"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func testCertPEM(t testing.TB, notAfter time.Time, hosts ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     hosts,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return append(
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...,
+	)
+}
+
+func TestRecordName(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		domain   string
+		expected string
+	}{
+		{"example.com", "_acme-challenge.example.com."},
+		{"example.com.", "_acme-challenge.example.com."},
+		{"*.example.com", "_acme-challenge.example.com."},
+		{"a.b.example.com", "_acme-challenge.a.b.example.com."},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.domain, func(t *testing.T) {
+			if result := dns01.RecordName(s.domain); result != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestManagerManages(t *testing.T) {
+	t.Parallel()
+
+	m := &dns01.Manager{Domains: []string{"example.com", "*.example.com", "test.org"}}
+
+	scenarios := []struct {
+		serverName string
+		expected   bool
+	}{
+		{"", false},
+		{"example.com", true},
+		{"EXAMPLE.COM.", true},
+		{"a.example.com", true},
+		{"a.b.example.com", false},
+		{"test.org", true},
+		{"a.test.org", false},
+		{"missing.com", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.serverName, func(t *testing.T) {
+			if result := m.Manages(s.serverName); result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestManagerGetCertificate(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	cache := autocert.DirCache(cacheDir)
+
+	// wildcard certificate cache
+	err := cache.Put(context.Background(), "dns01+_.example.com", testCertPEM(t, time.Now().Add(90*24*time.Hour), "*.example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// expired certificate cache
+	err = cache.Put(context.Background(), "dns01+expired.com", testCertPEM(t, time.Now().Add(-time.Hour), "expired.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &dns01.Manager{
+		Cache:   cache,
+		Domains: []string{"*.example.com", "expired.com"},
+	}
+
+	t.Run("unmanaged domain", func(t *testing.T) {
+		_, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "missing.com"})
+		if !errors.Is(err, dns01.ErrUnmanagedDomain) {
+			t.Fatalf("Expected ErrUnmanagedDomain, got %v", err)
+		}
+	})
+
+	t.Run("cached wildcard certificate", func(t *testing.T) {
+		cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "a.example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cert.Leaf == nil || cert.Leaf.DNSNames[0] != "*.example.com" {
+			t.Fatalf("Expected the *.example.com certificate, got %v", cert.Leaf)
+		}
+
+		// the certificate should be cached in memory
+		if err := os.RemoveAll(cacheDir); err != nil {
+			t.Fatal(err)
+		}
+
+		cert2, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "b.example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cert != cert2 {
+			t.Fatal("Expected the same in-memory certificate")
+		}
+	})
+
+	t.Run("expired certificate without solver", func(t *testing.T) {
+		_, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "expired.com"})
+		if err == nil || !strings.Contains(err.Error(), "missing solver") {
+			t.Fatalf("Expected missing solver error, got %v", err)
+		}
+	})
+}
+
+func TestExecSolver(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the test requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "log")
+	script := filepath.Join(dir, "hook.sh")
+
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$1 $2 $3\" >> "+logFile+"\n[ \"$3\" != \"fail\" ] || { echo oops; exit 1; }\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &dns01.ExecSolver{Command: script}
+
+	if err := s.Present(context.Background(), "_acme-challenge.example.com.", "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CleanUp(context.Background(), "_acme-challenge.example.com.", "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Present(context.Background(), "_acme-challenge.example.com.", "fail")
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Fatalf("Expected error with the command output, got %v", err)
+	}
+
+	raw, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "present _acme-challenge.example.com. abc\ncleanup _acme-challenge.example.com. abc\npresent _acme-challenge.example.com. fail\n"
+	if str := string(raw); str != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, str)
+	}
+}
@@ -0,0 +1,62 @@
+package dns01
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Solver defines the interface of a DNS-01 challenge solver
+// (usually implemented by a DNS provider client).
+type Solver interface {
+	// Present creates a TXT record with the provided name
+	// (eg. "_acme-challenge.example.com.") and value.
+	Present(ctx context.Context, name string, value string) error
+
+	// CleanUp removes the TXT record created by Present.
+	CleanUp(ctx context.Context, name string, value string) error
+}
+
+// RecordName returns the DNS-01 challenge TXT record FQDN for the specified domain.
+//
+// The wildcard prefix (if any) is trimmed since the challenge record
+// of "*.example.com" is the same as the one of "example.com".
+func RecordName(domain string) string {
+	domain = strings.TrimPrefix(domain, "*.")
+
+	return "_acme-challenge." + strings.TrimSuffix(domain, ".") + "."
+}
+
+var _ Solver = (*ExecSolver)(nil)
+
+// ExecSolver is a [Solver] that delegates the TXT records
+// management to an external program.
+//
+// The program is invoked as:
+//
+//	<command> present <name> <value>
+//	<command> cleanup <name> <value>
+type ExecSolver struct {
+	// Command is the path to the executable program.
+	Command string
+}
+
+// Present implements [Solver.Present].
+func (s *ExecSolver) Present(ctx context.Context, name string, value string) error {
+	return s.run(ctx, "present", name, value)
+}
+
+// CleanUp implements [Solver.CleanUp].
+func (s *ExecSolver) CleanUp(ctx context.Context, name string, value string) error {
+	return s.run(ctx, "cleanup", name, value)
+}
+
+func (s *ExecSolver) run(ctx context.Context, action string, name string, value string) error {
+	out, err := exec.CommandContext(ctx, s.Command, action, name, value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w (%s)", s.Command, action, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
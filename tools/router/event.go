@@ -34,7 +34,17 @@ type Event struct {
 
 	hook.Event
 
-	data store.Store[string, any]
+	data  store.Store[string, any]
+	trace *Trace
+}
+
+// Trace returns the current request middlewares and handler execution trace.
+func (e *Event) Trace() *Trace {
+	if e.trace == nil {
+		e.trace = &Trace{}
+	}
+
+	return e.trace
 }
 
 // RWUnwrapper specifies that an http.ResponseWriter could be "unwrapped"
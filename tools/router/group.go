@@ -1,6 +1,7 @@
 package router
 
 import (
+	"errors"
 	"net/http"
 	"regexp"
 	"strings"
@@ -229,6 +230,70 @@ func (group *RouterGroup[T]) hasRoute(pattern string, parents []*RouterGroup[T])
 	return false
 }
 
+// Walk iterates recursively over all routes registered in the current group
+// and its children (in the order of their registration) and calls fn for each of them.
+//
+// The path argument of fn is the full route path, aka. the concatenation of
+// all parent groups prefixes + the route path (without the method).
+//
+// The middlewares argument of fn contains the non-excluded parent groups
+// and route middlewares in the same order as they are bound to the route hook on [Router.BuildMux].
+func (group *RouterGroup[T]) Walk(fn func(route *Route[T], path string, middlewares []*hook.Handler[T])) error {
+	return group.walk(nil, fn)
+}
+
+func (group *RouterGroup[T]) walk(parents []*RouterGroup[T], fn func(route *Route[T], path string, middlewares []*hook.Handler[T])) error {
+	for _, child := range group.children {
+		switch v := child.(type) {
+		case *RouterGroup[T]:
+			if err := v.walk(append(parents, group), fn); err != nil {
+				return err
+			}
+		case *Route[T]:
+			var path string
+			var middlewares []*hook.Handler[T]
+
+			// add parent groups prefixes and middlewares
+			for _, p := range parents {
+				path += p.Prefix
+				for _, h := range p.Middlewares {
+					if _, ok := p.excludedMiddlewares[h.Id]; !ok {
+						if _, ok = group.excludedMiddlewares[h.Id]; !ok {
+							if _, ok = v.excludedMiddlewares[h.Id]; !ok {
+								middlewares = append(middlewares, h)
+							}
+						}
+					}
+				}
+			}
+
+			// add current group prefix and middlewares
+			path += group.Prefix
+			for _, h := range group.Middlewares {
+				if _, ok := group.excludedMiddlewares[h.Id]; !ok {
+					if _, ok = v.excludedMiddlewares[h.Id]; !ok {
+						middlewares = append(middlewares, h)
+					}
+				}
+			}
+
+			// add current route path and middlewares
+			path += v.Path
+			for _, h := range v.Middlewares {
+				if _, ok := v.excludedMiddlewares[h.Id]; !ok {
+					middlewares = append(middlewares, h)
+				}
+			}
+
+			fn(v, path, middlewares)
+		default:
+			return errors.New("invalid Group item type")
+		}
+	}
+
+	return nil
+}
+
 var wildcardPlaceholderRegex = regexp.MustCompile(`/{.+\.\.\.}$`)
 
 func stripWildcard(pattern string) string {
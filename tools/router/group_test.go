@@ -428,3 +428,53 @@ func TestRouterGroupHasRoute(t *testing.T) {
 		})
 	}
 }
+
+func TestRouterGroupWalk(t *testing.T) {
+	t.Parallel()
+
+	group := RouterGroup[*Event]{Prefix: "/root"}
+	group.Bind(&hook.Handler[*Event]{Id: "m1"}, &hook.Handler[*Event]{Id: "m2"})
+
+	group.Any("/any", nil)
+	group.GET("/base", nil).Unbind("m1")
+
+	sub := group.Group("/sub1")
+	sub.Bind(&hook.Handler[*Event]{Id: "m3"})
+	sub.POST("/a", nil)
+
+	sub2 := sub.Group("/sub2")
+	sub2.Unbind("m2")
+	sub2.GET("/b/{test}", nil).Bind(&hook.Handler[*Event]{Id: "m4"})
+
+	group.DELETE("/last", nil)
+
+	expected := []string{
+		" /root/any [m1 m2]",
+		"GET /root/base [m2]",
+		"POST /root/sub1/a [m1 m2 m3]",
+		"GET /root/sub1/sub2/b/{test} [m1 m3 m4]",
+		"DELETE /root/last [m1 m2]",
+	}
+
+	result := []string{}
+	err := group.Walk(func(route *Route[*Event], path string, middlewares []*hook.Handler[*Event]) {
+		ids := make([]string, len(middlewares))
+		for i, m := range middlewares {
+			ids[i] = m.Id
+		}
+		result = append(result, fmt.Sprintf("%s %s %v", route.Method, path, ids))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d routes, got %d: %v", len(expected), len(result), result)
+	}
+
+	for i, r := range result {
+		if r != expected[i] {
+			t.Errorf("[%d] Expected route %q, got %q", i, expected[i], r)
+		}
+	}
+}
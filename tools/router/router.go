@@ -84,86 +84,45 @@ func (r *Router[T]) PreBuildMux() {
 func (r *Router[T]) JustBuildMux() (http.Handler, error) {
 	mux := NewServeMux()
 
-	if err := r.loadMux(mux, r.RouterGroup, nil); err != nil {
+	if err := r.loadMux(mux, r.RouterGroup); err != nil {
 		return nil, err
 	}
 
 	return mux, nil
 }
 
-func (r *Router[T]) loadMux(mux *ServeMux, group *RouterGroup[T], parents []*RouterGroup[T]) error {
-	for _, child := range group.children {
-		switch v := child.(type) {
-		case *RouterGroup[T]:
-			if err := r.loadMux(mux, v, append(parents, group)); err != nil {
-				return err
-			}
-		case *Route[T]:
-			routeHook := &hook.Hook[T]{}
+func (r *Router[T]) loadMux(mux *ServeMux, group *RouterGroup[T]) error {
+	return group.walk(nil, func(route *Route[T], path string, middlewares []*hook.Handler[T]) {
+		routeHook := &hook.Hook[T]{}
+		for _, h := range middlewares {
+			routeHook.Bind(traceHandler(h))
+		}
 
-			var pattern string
+		pattern := path
+		if route.Method != "" {
+			pattern = route.Method + " " + path
+		}
 
-			if v.Method != "" {
-				pattern = v.Method + " "
-			}
+		mux.HandleFunc(pattern, func(resp http.ResponseWriter, req *http.Request) {
+			// wrap the response to add write and status tracking
+			resp = &ResponseWriter{ResponseWriter: resp}
 
-			// add parent groups middlewares
-			for _, p := range parents {
-				pattern += p.Prefix
-				for _, h := range p.Middlewares {
-					if _, ok := p.excludedMiddlewares[h.Id]; !ok {
-						if _, ok = group.excludedMiddlewares[h.Id]; !ok {
-							if _, ok = v.excludedMiddlewares[h.Id]; !ok {
-								routeHook.Bind(h)
-							}
-						}
-					}
-				}
-			}
+			// wrap the request body to allow multiple reads
+			req.Body = &RereadableReadCloser{ReadCloser: req.Body}
 
-			// add current groups middlewares
-			pattern += group.Prefix
-			for _, h := range group.Middlewares {
-				if _, ok := group.excludedMiddlewares[h.Id]; !ok {
-					if _, ok = v.excludedMiddlewares[h.Id]; !ok {
-						routeHook.Bind(h)
-					}
-				}
-			}
+			event, cleanupFunc := r.eventFactory(resp, req)
 
-			// add current route middlewares
-			pattern += v.Path
-			for _, h := range v.Middlewares {
-				if _, ok := v.excludedMiddlewares[h.Id]; !ok {
-					routeHook.Bind(h)
-				}
+			// trigger the handler hook chain
+			err := routeHook.Trigger(event, traceAction(route.Action))
+			if err != nil {
+				ErrorHandler(resp, req, err)
 			}
 
-			mux.HandleFunc(pattern, func(resp http.ResponseWriter, req *http.Request) {
-				// wrap the response to add write and status tracking
-				resp = &ResponseWriter{ResponseWriter: resp}
-
-				// wrap the request body to allow multiple reads
-				req.Body = &RereadableReadCloser{ReadCloser: req.Body}
-
-				event, cleanupFunc := r.eventFactory(resp, req)
-
-				// trigger the handler hook chain
-				err := routeHook.Trigger(event, v.Action)
-				if err != nil {
-					ErrorHandler(resp, req, err)
-				}
-
-				if cleanupFunc != nil {
-					cleanupFunc()
-				}
-			})
-		default:
-			return errors.New("invalid Group item type")
-		}
-	}
-
-	return nil
+			if cleanupFunc != nil {
+				cleanupFunc()
+			}
+		})
+	})
 }
 
 func ErrorHandler(resp http.ResponseWriter, req *http.Request, err error) {
@@ -0,0 +1,174 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+// TraceHandlerId is the span id of the final route handler (aka. the route action).
+const TraceHandlerId = "handler"
+
+// TraceSpan holds the execution timings of a single route middleware or handler.
+type TraceSpan struct {
+	// Id is the middleware id (or [TraceHandlerId] for the route handler).
+	Id string `json:"id"`
+
+	// Priority is the middleware exec priority.
+	Priority int `json:"priority"`
+
+	// Start is the time when the middleware execution has started.
+	Start time.Time `json:"start"`
+
+	// End is the time when the middleware execution has completed
+	// (it is zero if the middleware is still running).
+	End time.Time `json:"end"`
+}
+
+// Duration returns the total span execution time,
+// including the time spent in the nested middlewares and handler.
+//
+// If the span is still running, it returns the elapsed time since its start.
+func (s TraceSpan) Duration() time.Duration {
+	if s.End.IsZero() {
+		return time.Since(s.Start)
+	}
+
+	return s.End.Sub(s.Start)
+}
+
+// Trace collects the execution spans of a single request middlewares chain.
+//
+// The spans are recorded automatically for all middlewares and handlers
+// registered with the router.
+type Trace struct {
+	spans []TraceSpan
+	mu    sync.Mutex
+}
+
+// Begin registers a new span and returns a function to mark its end.
+func (t *Trace) Begin(id string, priority int) (end func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	i := len(t.spans)
+
+	t.spans = append(t.spans, TraceSpan{
+		Id:       id,
+		Priority: priority,
+		Start:    time.Now(),
+	})
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.spans[i].End = time.Now()
+	}
+}
+
+// Spans returns a shallow copy of the recorded trace spans
+// in the order of their execution.
+func (t *Trace) Spans() []TraceSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]TraceSpan, len(t.spans))
+	copy(result, t.spans)
+
+	return result
+}
+
+// ServerTiming returns the recorded trace spans formatted as
+// [Server-Timing] header value.
+//
+// The reported span duration excludes the time spent
+// in the nested middlewares and handler (aka. the "self" time).
+//
+// [Server-Timing]: https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Server-Timing
+func (t *Trace) ServerTiming() string {
+	spans := t.Spans()
+
+	var sb strings.Builder
+
+	for i, s := range spans {
+		dur := s.Duration()
+
+		// subtract the nested span duration (if any)
+		if i+1 < len(spans) {
+			next := spans[i+1]
+			if !next.Start.Before(s.Start) && (s.End.IsZero() || (!next.End.IsZero() && !next.End.After(s.End))) {
+				dur -= next.Duration()
+			}
+		}
+
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		// the metric name must be a valid token
+		sb.WriteString(serverTimingNameReplacer.Replace(s.Id))
+		sb.WriteString(fmt.Sprintf(`;desc="priority %d";dur=%.3f`, s.Priority, float64(dur)/float64(time.Millisecond)))
+	}
+
+	return sb.String()
+}
+
+var serverTimingNameReplacer = strings.NewReplacer(
+	" ", "_", "\t", "_", `"`, "_", "(", "_", ")", "_", ",", "_", "/", "_", ":", "_",
+	";", "_", "<", "_", "=", "_", ">", "_", "?", "_", "@", "_", "[", "_", `\`, "_",
+	"]", "_", "{", "_", "}", "_",
+)
+
+// Tracer defines an interface for events that collect a middlewares [Trace].
+type Tracer interface {
+	Trace() *Trace
+}
+
+// traceHandler wraps the provided middleware handler so that its
+// execution is recorded as a span in the event trace.
+func traceHandler[T hook.Resolver](h *hook.Handler[T]) *hook.Handler[T] {
+	traced := &hook.Handler[T]{
+		Id:       h.Id,
+		Priority: h.Priority,
+	}
+
+	traced.Func = func(e T) error {
+		tracer, ok := any(e).(Tracer)
+		if !ok {
+			return h.Func(e)
+		}
+
+		// note: use the traced handler id because for anonymous
+		// middlewares it is autogenerated on bind
+		end := tracer.Trace().Begin(traced.Id, traced.Priority)
+		defer end()
+
+		return h.Func(e)
+	}
+
+	return traced
+}
+
+// traceAction wraps the provided route action so that its
+// execution is recorded as a span in the event trace.
+func traceAction[T hook.Resolver](action func(e T) error) func(e T) error {
+	if action == nil {
+		return nil
+	}
+
+	return func(e T) error {
+		tracer, ok := any(e).(Tracer)
+		if !ok {
+			return action(e)
+		}
+
+		end := tracer.Trace().Begin(TraceHandlerId, 0)
+		defer end()
+
+		return action(e)
+	}
+}
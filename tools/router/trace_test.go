@@ -0,0 +1,131 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+func TestTraceBeginAndSpans(t *testing.T) {
+	t.Parallel()
+
+	trace := &router.Trace{}
+
+	end1 := trace.Begin("a", -10)
+	end2 := trace.Begin("b", 5)
+	end2()
+
+	spans := trace.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	if spans[0].Id != "a" || spans[0].Priority != -10 || !spans[0].End.IsZero() {
+		t.Fatalf("Unexpected first span %#v", spans[0])
+	}
+
+	if spans[1].Id != "b" || spans[1].Priority != 5 || spans[1].End.IsZero() {
+		t.Fatalf("Unexpected second span %#v", spans[1])
+	}
+
+	end1()
+
+	if spans := trace.Spans(); spans[0].End.IsZero() {
+		t.Fatal("Expected the first span to be completed")
+	}
+}
+
+func TestTraceServerTiming(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	trace := &router.Trace{}
+	if v := trace.ServerTiming(); v != "" {
+		t.Fatalf("Expected empty Server-Timing value, got %q", v)
+	}
+
+	end1 := trace.Begin("m 1", -1)
+	end2 := trace.Begin(router.TraceHandlerId, 0)
+	end2()
+	end1()
+
+	timing := trace.ServerTiming()
+
+	expectedRegex := regexp.MustCompile(`^m_1;desc="priority -1";dur=\d+\.\d{3}, handler;desc="priority 0";dur=\d+\.\d{3}$`)
+	if !expectedRegex.MatchString(timing) {
+		t.Fatalf("Unexpected Server-Timing value %q", timing)
+	}
+
+	if spans := trace.Spans(); spans[0].Start.Before(now) {
+		t.Fatalf("Expected span start after %v, got %v", now, spans[0].Start)
+	}
+}
+
+func TestRouterTrace(t *testing.T) {
+	t.Parallel()
+
+	var trace *router.Trace
+
+	r := router.NewRouter(func(w http.ResponseWriter, r *http.Request) (*router.Event, router.EventCleanupFunc) {
+		e := &router.Event{Response: w, Request: r}
+		trace = e.Trace()
+		return e, nil
+	})
+
+	r.Bind(&hook.Handler[*router.Event]{
+		Id:       "m2",
+		Priority: -1,
+		Func: func(e *router.Event) error {
+			return e.Next()
+		},
+	})
+
+	r.Bind(&hook.Handler[*router.Event]{
+		Id:       "m1",
+		Priority: -2,
+		Func: func(e *router.Event) error {
+			return e.Next()
+		},
+	})
+
+	r.GET("/test", func(e *router.Event) error {
+		return e.NoContent(204)
+	}).BindFunc(func(e *router.Event) error {
+		return e.Next()
+	})
+
+	mux, err := r.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	spans := trace.Spans()
+
+	ids := make([]string, len(spans))
+	for i, s := range spans {
+		ids[i] = s.Id
+
+		if s.End.IsZero() {
+			t.Errorf("Expected span %q to be completed", s.Id)
+		}
+	}
+
+	// the anonymous middleware id is autogenerated
+	if len(ids) != 4 || ids[0] != "m1" || ids[1] != "m2" || ids[2] == "" || ids[3] != router.TraceHandlerId {
+		t.Fatalf("Unexpected spans %v", ids)
+	}
+
+	if !strings.HasPrefix(trace.ServerTiming(), `m1;desc="priority -2"`) {
+		t.Fatalf("Unexpected Server-Timing value %q", trace.ServerTiming())
+	}
+}
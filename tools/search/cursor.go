@@ -0,0 +1,312 @@
+package search
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+)
+
+// ErrInvalidCursor is returned when the search cursor is malformed
+// or was generated for a different sort expression.
+var ErrInvalidCursor = errors.New("invalid search cursor")
+
+// cursorKey is a single keyset pagination sort key.
+type cursorKey struct {
+	identifier string
+	desc       bool
+}
+
+// cursorData is the decoded opaque search cursor.
+type cursorData struct {
+	// Signature is a checksum of the sort expression the cursor was generated for.
+	Signature string `json:"s"`
+
+	// Values are the sort keys values of the item the cursor points to.
+	Values []any `json:"v"`
+
+	// Backward indicates that the cursor points to the items before
+	// (instead of after) the one with the cursor values.
+	Backward bool `json:"b,omitempty"`
+}
+
+// cursorSignature returns a short checksum of the provided sort fields
+// that is used to invalidate cursors generated for a different sort.
+func cursorSignature(sort []SortField, cursorCol string) string {
+	var sb strings.Builder
+
+	for _, f := range sort {
+		sb.WriteString(f.Name)
+		sb.WriteString(" ")
+		sb.WriteString(strings.ToUpper(f.Direction))
+		sb.WriteString(",")
+	}
+	sb.WriteString(cursorCol)
+
+	return strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(sb.String()))), 36)
+}
+
+// defaultCursorSecret is the process wide fallback secret
+// of the providers without explicit cursor secret.
+var defaultCursorSecret = sync.OnceValue(func() string {
+	return rand.Text()
+})
+
+// cursorAEAD returns the AES-256-GCM cipher derived from the provided cursor secret.
+func cursorAEAD(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		secret = defaultCursorSecret()
+	}
+
+	key := sha256.Sum256([]byte("pb_search_cursor:" + secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encodeCursor seals the provided cursor data with the specified secret.
+func encodeCursor(secret string, data *cursorData) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := cursorAEAD(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, raw, nil)), nil
+}
+
+// decodeCursor opens the provided sealed cursor and validates
+// that it was generated for the specified sort signature.
+func decodeCursor(cursor string, secret string, signature string, totalKeys int) (*cursorData, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	aead, err := cursorAEAD(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCursor
+	}
+
+	raw, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	data := &cursorData{}
+	if err := decoder.Decode(data); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if data.Signature != signature || len(data.Values) != totalKeys {
+		return nil, ErrInvalidCursor
+	}
+
+	// normalize the json numbers to preserve the integers precision
+	for i, v := range data.Values {
+		switch n := v.(type) {
+		case json.Number:
+			if iv, err := n.Int64(); err == nil {
+				data.Values[i] = iv
+			} else if fv, err := n.Float64(); err == nil {
+				data.Values[i] = fv
+			} else {
+				return nil, ErrInvalidCursor
+			}
+		case nil, string, bool:
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return data, nil
+}
+
+// keysetExpr builds a db expression that matches the rows positioned
+// after the provided sort keys values in the keys sort order
+// (or before them if backward is set).
+//
+// NULL values are compared following the SQLite sort order,
+// aka. NULLs are considered smaller than any other value.
+func keysetExpr(keys []cursorKey, values []any, backward bool) dbx.Expression {
+	params := dbx.Params{}
+	ors := make([]string, 0, len(keys))
+
+	for i, key := range keys {
+		param := "__pbCursor" + strconv.Itoa(i)
+		params[param] = values[i]
+
+		var after string
+		if key.desc != backward {
+			if values[i] != nil {
+				after = fmt.Sprintf("(%s < {:%s} OR %s IS NULL)", key.identifier, param, key.identifier)
+			}
+		} else {
+			if values[i] == nil {
+				after = key.identifier + " IS NOT NULL"
+			} else {
+				after = fmt.Sprintf("%s > {:%s}", key.identifier, param)
+			}
+		}
+
+		if after == "" {
+			continue // nothing could be positioned after NULL in this direction
+		}
+
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s IS {:__pbCursor%d}", keys[j].identifier, j))
+		}
+		parts = append(parts, after)
+
+		ors = append(ors, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	if len(ors) == 0 {
+		return dbx.NewExp("0=1")
+	}
+
+	return dbx.NewExp(strings.Join(ors, " OR "), params)
+}
+
+// cursorItemsSlice returns the reflect value of the provided
+// pointer to a slice of search items.
+func cursorItemsSlice(items any) (reflect.Value, error) {
+	rv := reflect.ValueOf(items)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Slice {
+		return reflect.Value{}, errors.New("cursor pagination requires a pointer to a slice of items")
+	}
+
+	return rv.Elem(), nil
+}
+
+// cursorItemKey extracts the cursor column value of a single search item.
+//
+// The item could be a model with PK() method, a struct with a field
+// matching the column name (or its db tag) or a map with string keys.
+func cursorItemKey(item reflect.Value, col string) (any, error) {
+	if item.CanInterface() {
+		if m, ok := item.Interface().(interface{ PK() any }); ok {
+			return m.PK(), nil
+		}
+	}
+
+	for item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface {
+		if item.IsNil() {
+			return nil, errors.New("nil search item")
+		}
+		item = item.Elem()
+	}
+
+	var value reflect.Value
+
+	switch item.Kind() {
+	case reflect.Map:
+		if item.Type().Key().Kind() == reflect.String {
+			value = item.MapIndex(reflect.ValueOf(col).Convert(item.Type().Key()))
+		}
+	case reflect.Struct:
+		t := item.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag, _, _ := strings.Cut(f.Tag.Get("db"), ",")
+			if tag == col || (tag == "" && strings.EqualFold(f.Name, col)) {
+				value = item.Field(i)
+				break
+			}
+		}
+	}
+
+	if !value.IsValid() || !value.CanInterface() {
+		return nil, fmt.Errorf("failed to extract the search item %q cursor column value", col)
+	}
+
+	v := value.Interface()
+
+	if valuer, ok := v.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	return v, nil
+}
+
+// fetchCursorValues returns the sort keys values of the rows with the provided cursor column values.
+//
+// The returned map keys are the string representation of the provided ids.
+func fetchCursorValues(query dbx.SelectQuery, cursorCol string, keys []cursorKey, ids ...any) (map[string][]any, error) {
+	selects := make([]string, 0, len(keys)+1)
+	selects = append(selects, "[["+cursorCol+"]]")
+	for _, key := range keys {
+		selects = append(selects, key.identifier)
+	}
+
+	// note: query is shallow cloned and slice/map in-place modifications should be avoided
+	rows, err := query.Select(selects...).
+		AndWhere(dbx.In(cursorCol, ids...)).
+		OrderBy( /* reset */ ).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]any, len(ids))
+
+	for rows.Next() {
+		values := make([]any, len(selects))
+		ptrs := make([]any, len(selects))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+
+		id := fmt.Sprint(values[0])
+		if _, ok := result[id]; !ok {
+			result[id] = values[1:]
+		}
+	}
+
+	return result, rows.Err()
+}
@@ -172,6 +172,7 @@ func buildResolversExpr(
 	right *ResolverResult,
 ) (dbx.Expression, error) {
 	var expr dbx.Expression
+	var err error
 
 	switch op {
 	case fexpr.SignEq, fexpr.SignAnyEq:
@@ -238,6 +239,20 @@ func buildResolversExpr(
 		}
 	}
 
+	if left.AfterCompare != nil {
+		expr, err = left.AfterCompare(expr, op, right, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if right.AfterCompare != nil {
+		expr, err = right.AfterCompare(expr, op, left, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if left.AfterBuild != nil {
 		expr = left.AfterBuild(expr)
 	}
@@ -305,15 +320,26 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 			Params:     dbx.Params{placeholder: cast.ToFloat64(token.Literal)},
 		}, nil
 	case fexpr.TokenFunction:
+		args, _ := token.Meta.([]fexpr.Token)
+
+		argTokenResolverFunc := func(argToken fexpr.Token) (*ResolverResult, error) {
+			return resolveToken(argToken, fieldResolver)
+		}
+
+		// resolver specific functions
+		if fr, ok := fieldResolver.(FunctionResolver); ok {
+			result, handled, err := fr.ResolveFunction(token.Literal, argTokenResolverFunc, args...)
+			if handled {
+				return result, err
+			}
+		}
+
 		fn, ok := TokenFunctions[token.Literal]
 		if !ok {
 			return nil, fmt.Errorf("unknown function %q", token.Literal)
 		}
 
-		args, _ := token.Meta.([]fexpr.Token)
-		return fn(func(argToken fexpr.Token) (*ResolverResult, error) {
-			return resolveToken(argToken, fieldResolver)
-		}, args...)
+		return fn(argTokenResolverFunc, args...)
 	}
 
 	return nil, fmt.Errorf("unsupported token type %q", token.Type)
@@ -3,12 +3,14 @@ package search_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/search"
 )
@@ -246,6 +248,129 @@ func TestFilterDataBuildExprWithParams(t *testing.T) {
 	}
 }
 
+type testFunctionResolver struct {
+	*search.SimpleFieldResolver
+}
+
+func (r *testFunctionResolver) ResolveFunction(
+	name string,
+	argTokenResolverFunc func(fexpr.Token) (*search.ResolverResult, error),
+	args ...fexpr.Token,
+) (*search.ResolverResult, bool, error) {
+	if name == "bounded" {
+		arg, err := argTokenResolverFunc(args[0])
+		if err != nil {
+			return nil, true, err
+		}
+
+		return &search.ResolverResult{
+			NoCoalesce: true,
+			Identifier: "bounded(" + arg.Identifier + ")",
+			AfterCompare: func(expr dbx.Expression, op fexpr.SignOp, other *search.ResolverResult, isRight bool) (dbx.Expression, error) {
+				if op == fexpr.SignLike {
+					return nil, errors.New("unsupported operator")
+				}
+				return dbx.And(expr, dbx.NewExp(fmt.Sprintf("bound('%s', %s, %v)", op, other.Identifier, isRight), other.Params)), nil
+			},
+		}, true, nil
+	}
+
+	if name != "custom" {
+		return nil, false, nil
+	}
+
+	if len(args) != 1 {
+		return nil, true, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+
+	arg, err := argTokenResolverFunc(args[0])
+	if err != nil {
+		return nil, true, err
+	}
+
+	return &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: "custom(" + arg.Identifier + ")",
+		Params:     arg.Params,
+	}, true, nil
+}
+
+func TestFilterDataBuildExprWithFunctionResolver(t *testing.T) {
+	resolver := &testFunctionResolver{search.NewSimpleFieldResolver("test1")}
+
+	scenarios := []struct {
+		name          string
+		filterData    search.FilterData
+		expectError   bool
+		expectPattern string
+	}{
+		{
+			"unknown function",
+			"missing(test1) = 1",
+			true,
+			"",
+		},
+		{
+			"resolver function with invalid arguments",
+			"custom(test1, 2) = 1",
+			true,
+			"",
+		},
+		{
+			"resolver function",
+			"custom(test1) = 'a' && custom('b') > 1",
+			false,
+			"(custom([[test1]]) IS {:TEST} AND custom({:TEST}) > {:TEST})",
+		},
+		{
+			"resolver function with AfterCompare",
+			"bounded(test1) < 5 || 6 >= bounded(test1)",
+			false,
+			"((bounded([[test1]]) < {:TEST}) AND (bound('<', {:TEST}, false)) OR ({:TEST} >= bounded([[test1]])) AND (bound('>=', {:TEST}, true)))",
+		},
+		{
+			"resolver function with AfterCompare error",
+			"bounded(test1) ~ 5",
+			true,
+			"",
+		},
+		{
+			"fallback to the global token functions",
+			"geoDistance(1,2,3,4) < 567",
+			false,
+			"(6371 * acos(cos(radians({:TEST})) * cos(radians({:TEST})) * cos(radians({:TEST}) - radians({:TEST})) + sin(radians({:TEST})) * sin(radians({:TEST})))) < {:TEST}",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			expr, err := s.filterData.BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			rawSql := expr.Build(&dbx.DB{}, dbx.Params{})
+
+			expectPattern := strings.ReplaceAll(
+				"^"+regexp.QuoteMeta(s.expectPattern)+"$",
+				"TEST",
+				`\w+`,
+			)
+
+			pattern := regexp.MustCompile(expectPattern)
+			if !pattern.MatchString(rawSql) {
+				t.Fatalf("Pattern %v don't match with expression: \n%v", expectPattern, rawSql)
+			}
+		})
+	}
+}
+
 func TestFilterDataBuildExprWithLimit(t *testing.T) {
 	resolver := search.NewSimpleFieldResolver(`^\w+$`)
 
@@ -2,8 +2,10 @@ package search
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -48,6 +50,7 @@ const (
 	SortQueryParam      string = "sort"
 	FilterQueryParam    string = "filter"
 	SkipTotalQueryParam string = "skipTotal"
+	CursorQueryParam    string = "cursor"
 )
 
 // Result defines the returned search result structure.
@@ -57,6 +60,11 @@ type Result struct {
 	PerPage    int `json:"perPage"`
 	TotalItems int `json:"totalItems"`
 	TotalPages int `json:"totalPages"`
+
+	// NextCursor and PrevCursor are the opaque cursors of the adjacent
+	// result pages (available only with cursor pagination).
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
 }
 
 // Provider represents a single configured search provider instance.
@@ -64,6 +72,9 @@ type Provider struct {
 	fieldResolver      FieldResolver
 	query              *dbx.SelectQuery
 	countCol           string
+	cursorCol          string
+	cursor             *string
+	cursorSecret       string
 	sort               []SortField
 	filter             []FilterData
 	page               int
@@ -88,6 +99,7 @@ func NewProvider(fieldResolver FieldResolver) *Provider {
 	return &Provider{
 		fieldResolver:      fieldResolver,
 		countCol:           "id",
+		cursorCol:          "id",
 		page:               1,
 		perPage:            DefaultPerPage,
 		sort:               []SortField{},
@@ -132,6 +144,43 @@ func (s *Provider) CountCol(name string) *Provider {
 	return s
 }
 
+// Cursor enables the keyset (aka. cursor) pagination and sets the
+// opaque cursor of the page to fetch.
+//
+// An empty cursor fetches the first page. The cursors of the adjacent
+// pages are returned with the search [Result] NextCursor and PrevCursor.
+//
+// With cursor pagination the `page` field is not used for the query offset
+// and the search items are ordered by the provider `sort` fields
+// followed by the unique cursor column (replacing any base query ORDER BY).
+//
+// Note that the items passed to Exec must be a pointer to a slice and
+// each item must have a PK() method, a struct field or a map key
+// matching the cursor column.
+func (s *Provider) Cursor(cursor string) *Provider {
+	s.cursor = &cursor
+	return s
+}
+
+// CursorCol allows changing the default unique column (id) that is
+// used as last sort key and item identifier for the cursor pagination.
+func (s *Provider) CursorCol(name string) *Provider {
+	s.cursorCol = name
+	return s
+}
+
+// CursorSecret sets the secret used to seal the cursor pagination cursors.
+//
+// The cursors are encrypted so that the sort keys values of the items
+// they point to are not visible to the clients.
+//
+// If not set, a random secret that is valid only for the
+// current process is used.
+func (s *Provider) CursorSecret(secret string) *Provider {
+	s.cursorSecret = secret
+	return s
+}
+
 // Page sets the `page` field of the current search provider.
 //
 // Normalization on the `page` value is done during `Exec()`.
@@ -194,6 +243,10 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.SkipTotal(v)
 	}
 
+	if params.Has(CursorQueryParam) {
+		s.Cursor(params.Get(CursorQueryParam))
+	}
+
 	if raw := params.Get(PageQueryParam); raw != "" {
 		v, err := strconv.Atoi(raw)
 		if err != nil {
@@ -251,27 +304,87 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	if len(s.sort) > s.maxSortExprLimit {
 		return nil, ErrSortExprLimit
 	}
+	var cursorKeys []cursorKey
 	for _, sortField := range s.sort {
 		if len(sortField.Name) > MaxSortFieldLength {
 			return nil, ErrSortFieldLengthLimit
 		}
+
+		// collect the cursor sort keys to be applied later
+		if s.cursor != nil {
+			if sortField.Name == randomSortKey {
+				return nil, errors.New("cursor pagination doesn't support random sort")
+			}
+
+			resolved, err := sortField.resolve(s.fieldResolver)
+			if err != nil {
+				return nil, err
+			}
+
+			// the cursor values are compared directly with the column value
+			// and that will bypass any guard the resolver attaches to it
+			// (eg. a field that is accessible only under certain condition)
+			if resolved.AfterBuild != nil {
+				return nil, fmt.Errorf("cursor pagination doesn't support sort field %q", sortField.Name)
+			}
+
+			identifier := resolved.Identifier
+			if sortField.Name == rowidSortKey {
+				identifier = s.prefixWithFromTable(&modelsQuery, identifier)
+			}
+
+			cursorKeys = append(cursorKeys, cursorKey{
+				identifier: identifier,
+				desc:       strings.EqualFold(sortField.Direction, SortDesc),
+			})
+
+			continue
+		}
+
 		expr, err := sortField.BuildExpr(s.fieldResolver)
 		if err != nil {
 			return nil, err
 		}
 		if expr != "" {
 			// ensure that _rowid_ expressions are always prefixed with the first FROM table
-			if sortField.Name == rowidSortKey && !strings.Contains(expr, ".") {
-				queryInfo := modelsQuery.Info()
-				if len(queryInfo.From) > 0 {
-					expr = "[[" + inflector.Columnify(queryInfo.From[0]) + "]]." + expr
-				}
+			if sortField.Name == rowidSortKey {
+				expr = s.prefixWithFromTable(&modelsQuery, expr)
 			}
 
 			modelsQuery.AndOrderBy(expr)
 		}
 	}
 
+	// apply the cursor sort keys followed by the unique cursor column
+	var cursor *cursorData
+	var cursorCol string
+	if s.cursor != nil {
+		cursorCol = s.cursorCol
+		if queryInfo := modelsQuery.Info(); len(queryInfo.From) > 0 {
+			cursorCol = queryInfo.From[0] + "." + cursorCol
+		}
+
+		cursorKeys = append(cursorKeys, cursorKey{identifier: "[[" + cursorCol + "]]"})
+
+		if *s.cursor != "" {
+			var err error
+			cursor, err = decodeCursor(*s.cursor, s.cursorSecret, cursorSignature(s.sort, s.cursorCol), len(cursorKeys))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		modelsQuery.OrderBy( /* reset */ )
+		for _, key := range cursorKeys {
+			// reverse the order when fetching the previous page items
+			if key.desc != (cursor != nil && cursor.Backward) {
+				modelsQuery.AndOrderBy(key.identifier + " " + SortDesc)
+			} else {
+				modelsQuery.AndOrderBy(key.identifier + " " + SortAsc)
+			}
+		}
+	}
+
 	// apply field resolver query modifications (if any)
 	if err := s.fieldResolver.UpdateQuery(&modelsQuery); err != nil {
 		return nil, err
@@ -316,8 +429,19 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		return nil
 	}
 
+	// prepare a cursor values query from the base one
+	cursorValuesQuery := modelsQuery // shallow clone
+
+	var nextCursor, prevCursor string
+
 	// apply pagination to the original query and fetch the models
 	modelsExec := func() error {
+		if s.cursor != nil {
+			var err error
+			nextCursor, prevCursor, err = s.execWithCursor(modelsQuery, cursorValuesQuery, cursorCol, cursorKeys, cursor, items)
+			return err
+		}
+
 		modelsQuery.Limit(int64(s.perPage))
 		modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
 
@@ -345,11 +469,125 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		TotalItems: totalCount,
 		TotalPages: totalPages,
 		Items:      items,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}
 
 	return result, nil
 }
 
+// execWithCursor fetches the items positioned after (or before)
+// the provided cursor and returns the adjacent pages cursors.
+func (s *Provider) execWithCursor(
+	modelsQuery dbx.SelectQuery,
+	cursorValuesQuery dbx.SelectQuery,
+	cursorCol string,
+	keys []cursorKey,
+	cursor *cursorData,
+	items any,
+) (string, string, error) {
+	slice, err := cursorItemsSlice(items)
+	if err != nil {
+		return "", "", err
+	}
+
+	backward := cursor != nil && cursor.Backward
+
+	if cursor != nil {
+		modelsQuery.AndWhere(keysetExpr(keys, cursor.Values, backward))
+	}
+
+	// fetch 1 extra item to check whether there are more items
+	modelsQuery.Limit(int64(s.perPage + 1))
+
+	if err := modelsQuery.All(items); err != nil {
+		return "", "", err
+	}
+
+	hasMore := slice.Len() > s.perPage
+	if hasMore {
+		slice.Set(slice.Slice(0, s.perPage))
+	}
+
+	if backward {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	total := slice.Len()
+	if total == 0 {
+		return "", "", nil
+	}
+
+	plainCursorCol := s.cursorCol
+
+	firstId, err := cursorItemKey(slice.Index(0), plainCursorCol)
+	if err != nil {
+		return "", "", err
+	}
+
+	lastId, err := cursorItemKey(slice.Index(total-1), plainCursorCol)
+	if err != nil {
+		return "", "", err
+	}
+
+	values, err := fetchCursorValues(cursorValuesQuery, cursorCol, keys, firstId, lastId)
+	if err != nil {
+		return "", "", err
+	}
+
+	signature := cursorSignature(s.sort, s.cursorCol)
+
+	var nextCursor, prevCursor string
+
+	// there is a next page if there are more items after the current one
+	// or if the items were fetched backward (aka. from a later page)
+	if (hasMore && !backward) || backward {
+		lastValues, ok := values[fmt.Sprint(lastId)]
+		if !ok {
+			return "", "", errors.New("failed to resolve the next page cursor")
+		}
+
+		nextCursor, err = encodeCursor(s.cursorSecret, &cursorData{Signature: signature, Values: lastValues})
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	// there is a previous page if there are more items before the current one
+	// or if the items were fetched forward from a cursor (aka. from an earlier page)
+	if (hasMore && backward) || (!backward && cursor != nil) {
+		firstValues, ok := values[fmt.Sprint(firstId)]
+		if !ok {
+			return "", "", errors.New("failed to resolve the previous page cursor")
+		}
+
+		prevCursor, err = encodeCursor(s.cursorSecret, &cursorData{Signature: signature, Values: firstValues, Backward: true})
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return nextCursor, prevCursor, nil
+}
+
+// prefixWithFromTable prefixes the provided _rowid_ expression
+// with the first query FROM table (if not already).
+func (s *Provider) prefixWithFromTable(query *dbx.SelectQuery, expr string) string {
+	if strings.Contains(expr, ".") {
+		return expr
+	}
+
+	queryInfo := query.Info()
+	if len(queryInfo.From) > 0 {
+		return "[[" + inflector.Columnify(queryInfo.From[0]) + "]]." + expr
+	}
+
+	return expr
+}
+
 // ParseAndExec is a short convenient method to trigger both
 // `Parse()` and `Exec()` in a single call.
 func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, error) {
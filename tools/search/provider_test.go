@@ -3,6 +3,7 @@ package search
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -790,5 +791,220 @@ func (t *testFieldResolver) Resolve(field string) (*ResolverResult, error) {
 		return nil, errors.New("test error")
 	}
 
+	if field == "guarded" {
+		return &ResolverResult{Identifier: "test1", AfterBuild: dbx.Not}, nil
+	}
+
 	return &ResolverResult{Identifier: field}, nil
 }
+
+func TestProviderCursorPagination(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	// extra rows with duplicated and null sort values
+	testDB.Insert("test", dbx.Params{"id": 3, "test1": 2, "test2": "test2.3", "test3": nil}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 4, "test1": 1, "test2": "test2.4", "test3": "b"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 5, "test1": 3, "test2": "test2.5", "test3": nil}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 6, "test1": 2, "test2": "test2.6", "test3": "a"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 7, "test1": 1, "test2": "test2.7", "test3": "a"}).Execute()
+
+	query := testDB.Select("*").From("test").OrderBy("test2 DESC")
+
+	scenarios := []struct {
+		sort     string
+		expected []int
+	}{
+		{"", []int{1, 2, 3, 4, 5, 6, 7}},
+		{"-id", []int{7, 6, 5, 4, 3, 2, 1}},
+		{"test1", []int{1, 4, 7, 2, 3, 6, 5}},
+		{"-test1", []int{5, 2, 3, 6, 1, 4, 7}},
+		{"test3,-test1", []int{5, 3, 2, 1, 6, 7, 4}},
+		{"-test3,test1", []int{4, 7, 6, 1, 2, 3, 5}},
+		{"@rowid", []int{1, 2, 3, 4, 5, 6, 7}},
+	}
+
+	for _, s := range scenarios {
+		for _, perPage := range []int{1, 2, 3, 10} {
+			t.Run(fmt.Sprintf("%s_%d", s.sort, perPage), func(t *testing.T) {
+				var sort []SortField
+				if s.sort != "" {
+					sort = ParseSortFromString(s.sort)
+				}
+
+				exec := func(cursor string) ([]int, *Result) {
+					items := []testTableCursorStruct{}
+
+					result, err := NewProvider(&testFieldResolver{}).
+						Query(query).
+						Page(3). // should be ignored
+						PerPage(perPage).
+						Sort(sort).
+						Cursor(cursor).
+						Exec(&items)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					ids := make([]int, len(items))
+					for i, item := range items {
+						ids[i] = item.Id
+					}
+
+					return ids, result
+				}
+
+				// walk forward
+				var forward []int
+				var lastIds []int
+				var lastResult *Result
+				var cursor string
+				for i := 0; ; i++ {
+					ids, result := exec(cursor)
+					forward = append(forward, ids...)
+
+					if i == 0 && result.PrevCursor != "" {
+						t.Fatalf("Expected no prevCursor for the first page, got %q", result.PrevCursor)
+					}
+
+					if result.TotalItems != len(s.expected) {
+						t.Fatalf("Expected totalItems %d, got %d", len(s.expected), result.TotalItems)
+					}
+
+					lastIds = ids
+					lastResult = result
+					if result.NextCursor == "" || i > len(s.expected) {
+						break
+					}
+					cursor = result.NextCursor
+				}
+
+				if fmt.Sprint(forward) != fmt.Sprint(s.expected) {
+					t.Fatalf("Expected forward items %v, got %v", s.expected, forward)
+				}
+
+				// walk backward
+				backward := lastIds
+				cursor = lastResult.PrevCursor
+				for i := 0; cursor != "" && i <= len(s.expected); i++ {
+					ids, result := exec(cursor)
+					backward = append(ids, backward...)
+					cursor = result.PrevCursor
+
+					if result.NextCursor == "" {
+						t.Fatal("Expected nextCursor for the previous page")
+					}
+				}
+
+				if fmt.Sprint(backward) != fmt.Sprint(s.expected) {
+					t.Fatalf("Expected backward items %v, got %v", s.expected, backward)
+				}
+			})
+		}
+	}
+}
+
+func TestProviderCursorErrors(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	query := testDB.Select("*").From("test")
+
+	validData := &cursorData{
+		Signature: cursorSignature([]SortField{{"test1", SortDesc}}, "id"),
+		Values:    []any{1, 1},
+	}
+
+	validCursor, err := encodeCursor("test_secret", validData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherSecretCursor, err := encodeCursor("other_secret", validData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rawData, err := json.Marshal(validData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsealedCursor := base64.RawURLEncoding.EncodeToString(rawData)
+
+	scenarios := []struct {
+		name        string
+		sort        []SortField
+		cursor      string
+		items       any
+		expectError bool
+	}{
+		{"malformed cursor", []SortField{{"test1", SortDesc}}, "invalid", &[]testTableCursorStruct{}, true},
+		{"cursor for different sort", []SortField{{"test1", SortAsc}}, validCursor, &[]testTableCursorStruct{}, true},
+		{"cursor with different secret", []SortField{{"test1", SortDesc}}, otherSecretCursor, &[]testTableCursorStruct{}, true},
+		{"unsealed cursor", []SortField{{"test1", SortDesc}}, unsealedCursor, &[]testTableCursorStruct{}, true},
+		{"random sort", []SortField{{randomSortKey, SortAsc}}, "", &[]testTableCursorStruct{}, true},
+		{"guarded sort field", []SortField{{"guarded", SortDesc}}, "", &[]testTableCursorStruct{}, true},
+		{"non-slice pointer items", []SortField{{"test1", SortDesc}}, "", []testTableCursorStruct{}, true},
+		{"items without cursor column", []SortField{{"test1", SortDesc}}, "", &[]testTableStruct{}, true},
+		{"map items", []SortField{{"test1", SortDesc}}, validCursor, &[]dbx.NullStringMap{}, false},
+		{"valid cursor", []SortField{{"test1", SortDesc}}, validCursor, &[]testTableCursorStruct{}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := NewProvider(&testFieldResolver{}).
+				Query(query).
+				Sort(s.sort).
+				Cursor(s.cursor).
+				CursorSecret("test_secret").
+				Exec(s.items)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestProviderParseCursor(t *testing.T) {
+	scenarios := []struct {
+		query    string
+		expected *string
+	}{
+		{"", nil},
+		{"page=1", nil},
+		{"cursor=", new(string)},
+		{"cursor=abc", func() *string { v := "abc"; return &v }()},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.query, func(t *testing.T) {
+			p := NewProvider(&testFieldResolver{})
+
+			if err := p.Parse(s.query); err != nil {
+				t.Fatal(err)
+			}
+
+			if (p.cursor == nil) != (s.expected == nil) {
+				t.Fatalf("Expected cursor %v, got %v", s.expected, p.cursor)
+			}
+
+			if s.expected != nil && *p.cursor != *s.expected {
+				t.Fatalf("Expected cursor %q, got %q", *s.expected, *p.cursor)
+			}
+		})
+	}
+}
+
+type testTableCursorStruct struct {
+	Id    int    `db:"id" json:"id"`
+	Test1 int    `db:"test1" json:"test1"`
+	Test2 string `db:"test2" json:"test2"`
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/list"
@@ -31,6 +32,17 @@ type ResolverResult struct {
 	// AfterBuild is an optional function that will be called after building
 	// and combining the result of both resolved operands/sides in a single expression.
 	AfterBuild func(expr dbx.Expression) dbx.Expression
+
+	// AfterCompare is an optional function that will be called after building
+	// the comparison expression of both resolved operands/sides (before AfterBuild)
+	// with the expression operator and the other side operand.
+	//
+	// isRight reports whether the current ResolverResult is the right side operand.
+	//
+	// It could be used for example to add extra index friendly constraints
+	// based on the compared value (eg. a spatial index prefilter for a distance comparison)
+	// or to reject unsupported comparisons by returning an error.
+	AfterCompare func(expr dbx.Expression, op fexpr.SignOp, other *ResolverResult, isRight bool) (dbx.Expression, error)
 }
 
 // FieldResolver defines an interface for managing search fields.
@@ -46,6 +58,19 @@ type FieldResolver interface {
 	Resolve(field string) (*ResolverResult, error)
 }
 
+// FunctionResolver is an optional FieldResolver interface that allows
+// resolving resolver specific filter functions (eg. full-text search matches).
+//
+// It is checked before the global [TokenFunctions] and if the returned
+// handled flag is false the function resolution fallbacks to them.
+type FunctionResolver interface {
+	ResolveFunction(
+		name string,
+		argTokenResolverFunc func(fexpr.Token) (*ResolverResult, error),
+		args ...fexpr.Token,
+	) (result *ResolverResult, handled bool, err error)
+}
+
 // NewSimpleFieldResolver creates a new `SimpleFieldResolver` with the
 // provided `allowedFields`.
 //
@@ -3,6 +3,8 @@ package search
 import (
 	"fmt"
 	"strings"
+
+	"github.com/ganigeorgiev/fexpr"
 )
 
 const (
@@ -10,6 +12,13 @@ const (
 	rowidSortKey  string = "@rowid"
 )
 
+// RankSortKey is a special sort key for ordering the search results
+// by their full-text search relevance (lower is more relevant).
+//
+// It is resolved by the FieldResolver and it is up to the
+// resolver implementation whether it is supported or not.
+const RankSortKey string = "@rank"
+
 // sort field directions
 const (
 	SortAsc  string = "ASC"
@@ -29,19 +38,76 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 		return "RANDOM()", nil
 	}
 
+	identifier, err := s.resolveIdentifier(fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s", identifier, s.Direction), nil
+}
+
+// resolveIdentifier resolves the sort field name into a db identifier
+// (without the sort direction).
+func (s *SortField) resolveIdentifier(fieldResolver FieldResolver) (string, error) {
+	result, err := s.resolve(fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Identifier, nil
+}
+
+// resolve resolves the sort field name into a single column resolver result.
+func (s *SortField) resolve(fieldResolver FieldResolver) (*ResolverResult, error) {
 	// special case for the builtin SQLite rowid column
 	if s.Name == rowidSortKey {
-		return fmt.Sprintf("[[_rowid_]] %s", s.Direction), nil
+		return &ResolverResult{Identifier: "[[_rowid_]]"}, nil
 	}
 
-	result, err := fieldResolver.Resolve(s.Name)
+	var result *ResolverResult
+	var err error
+
+	if isSortFunction(s.Name) {
+		result, err = resolveSortFunction(s.Name, fieldResolver)
+	} else {
+		result, err = fieldResolver.Resolve(s.Name)
+	}
 
 	// invalidate empty fields and non-column identifiers
 	if err != nil || len(result.Params) > 0 || result.Identifier == "" || strings.ToLower(result.Identifier) == "null" {
-		return "", fmt.Errorf("invalid sort field %q", s.Name)
+		return nil, fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
-	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil
+	return result, nil
+}
+
+// isSortFunction reports whether the sort field name looks like
+// a function call expression (eg. "vectorDistance(embedding, @request.query.q)").
+func isSortFunction(name string) bool {
+	return strings.HasSuffix(name, ")") && strings.Contains(name, "(")
+}
+
+// resolveSortFunction resolves a single function call sort expression
+// using the same rules as the filter functions.
+func resolveSortFunction(name string, fieldResolver FieldResolver) (*ResolverResult, error) {
+	scanner := fexpr.NewScanner([]byte(name))
+
+	token, err := scanner.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Type != fexpr.TokenFunction {
+		return nil, fmt.Errorf("invalid sort function %q", name)
+	}
+
+	// ensure that there are no other trailing tokens
+	next, err := scanner.Scan()
+	if err != nil || next.Type != fexpr.TokenEOF {
+		return nil, fmt.Errorf("invalid sort function %q", name)
+	}
+
+	return resolveToken(token, fieldResolver)
 }
 
 // ParseSortFromString parses the provided string expression
@@ -50,8 +116,11 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 // Example:
 //
 //	fields := search.ParseSortFromString("-name,+created")
+//
+// Commas inside function call arguments and quoted strings are not
+// treated as separators, eg. "-vectorDistance(embedding, @request.query.q),id".
 func ParseSortFromString(str string) (fields []SortField) {
-	data := strings.Split(str, ",")
+	data := splitSortString(str)
 
 	for _, field := range data {
 		// trim whitespaces
@@ -65,3 +134,33 @@ func ParseSortFromString(str string) (fields []SortField) {
 
 	return
 }
+
+// splitSortString splits the sort string by its top level commas.
+func splitSortString(str string) []string {
+	var result []string
+	var depth int
+	var quote rune
+	var start int
+
+	for i, ch := range str {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			if depth > 0 {
+				depth--
+			}
+		case ch == ',' && depth == 0:
+			result = append(result, str[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(result, str[start:])
+}
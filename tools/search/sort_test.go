@@ -50,6 +50,46 @@ func TestSortFieldBuildExpr(t *testing.T) {
 	}
 }
 
+func TestSortFieldBuildExprWithFunction(t *testing.T) {
+	resolver := &testFunctionResolver{search.NewSimpleFieldResolver("test1", "test2")}
+
+	scenarios := []struct {
+		sortField        search.SortField
+		expectError      bool
+		expectExpression string
+	}{
+		// unknown function
+		{search.SortField{"missing(test1)", search.SortAsc}, true, ""},
+		// function with unknown field argument
+		{search.SortField{"custom(unknown)", search.SortAsc}, true, ""},
+		// function with bound params
+		{search.SortField{"custom('abc')", search.SortAsc}, true, ""},
+		// trailing tokens
+		{search.SortField{"custom(test1) || test2", search.SortAsc}, true, ""},
+		{search.SortField{"custom(test1) custom(test2)", search.SortAsc}, true, ""},
+		// non-function expression
+		{search.SortField{"(test1)", search.SortAsc}, true, ""},
+		// valid function
+		{search.SortField{"custom(test1)", search.SortDesc}, false, "custom([[test1]]) DESC"},
+		{search.SortField{"bounded(test2)", search.SortAsc}, false, "bounded([[test2]]) ASC"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.sortField.Name, func(t *testing.T) {
+			result, err := s.sortField.BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if result != s.expectExpression {
+				t.Fatalf("Expected expression %v, got %v", s.expectExpression, result)
+			}
+		})
+	}
+}
+
 func TestParseSortFromString(t *testing.T) {
 	scenarios := []struct {
 		value    string
@@ -62,6 +102,8 @@ func TestParseSortFromString(t *testing.T) {
 		{"test1,-test2,+test3", `[{"name":"test1","direction":"ASC"},{"name":"test2","direction":"DESC"},{"name":"test3","direction":"ASC"}]`},
 		{"@random,-test", `[{"name":"@random","direction":"ASC"},{"name":"test","direction":"DESC"}]`},
 		{"-@rowid,-test", `[{"name":"@rowid","direction":"DESC"},{"name":"test","direction":"DESC"}]`},
+		{"-fn(a, b, 'c,d'),test", `[{"name":"fn(a, b, 'c,d')","direction":"DESC"},{"name":"test","direction":"ASC"}]`},
+		{`fn(a, "(,"), +fn2(x(1,2))`, `[{"name":"fn(a, \"(,\")","direction":"ASC"},{"name":"fn2(x(1,2))","direction":"ASC"}]`},
 	}
 
 	for _, s := range scenarios {
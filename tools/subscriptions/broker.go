@@ -2,20 +2,35 @@ package subscriptions
 
 import (
 	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/routine"
 	"github.com/pocketbase/pocketbase/tools/store"
 )
 
+// ChannelTopicPrefix is the subscription topic prefix of the custom
+// client-to-client pub/sub channels (eg. "@channel/chat").
+const ChannelTopicPrefix = "@channel/"
+
+// ChannelTopic returns the subscription topic of the specified custom channel.
+func ChannelTopic(channel string) string {
+	return ChannelTopicPrefix + channel
+}
+
 // Broker defines a struct for managing subscriptions clients.
 type Broker struct {
-	store *store.Store[string, Client]
+	store    *store.Store[string, Client]
+	presence map[string]map[string]any // channel -> clientId -> member data
+	mux      sync.RWMutex
 }
 
 // NewBroker initializes and returns a new Broker instance.
 func NewBroker() *Broker {
 	return &Broker{
-		store: store.New[string, Client](nil),
+		store:    store.New[string, Client](nil),
+		presence: map[string]map[string]any{},
 	}
 }
 
@@ -63,3 +78,115 @@ func (b *Broker) Unregister(clientId string) {
 	client.Discard()
 	b.store.Remove(clientId)
 }
+
+// Publish sends the data to all clients subscribed to the specified custom channel
+// and returns the number of the sent messages.
+//
+// The message name is the client subscription (including its options, if any).
+func (b *Broker) Publish(channel string, data []byte) int {
+	prefix := ChannelTopic(channel) + "?"
+
+	var total int
+
+	for _, client := range b.store.Values() {
+		for sub := range client.Subscriptions(prefix) {
+			msg := Message{Name: sub, Data: data}
+
+			routine.FireAndForget(func() {
+				client.Send(msg)
+			})
+
+			total++
+		}
+	}
+
+	return total
+}
+
+// Join registers the client as presence member of the specified channel.
+//
+// Returns false if the client is already a member of the channel.
+func (b *Broker) Join(channel string, clientId string, data any) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if b.presence == nil {
+		b.presence = map[string]map[string]any{}
+	}
+
+	members, ok := b.presence[channel]
+	if !ok {
+		members = map[string]any{}
+		b.presence[channel] = members
+	}
+
+	if _, ok := members[clientId]; ok {
+		return false
+	}
+
+	members[clientId] = data
+
+	return true
+}
+
+// Leave removes the client from the presence members of the specified channel.
+//
+// Returns false if the client is not a member of the channel.
+func (b *Broker) Leave(channel string, clientId string) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return b.leave(channel, clientId)
+}
+
+// LeaveAll removes the client from the presence members
+// of all channels and returns the left channels (sorted).
+func (b *Broker) LeaveAll(clientId string) []string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	var result []string
+
+	for channel := range b.presence {
+		if b.leave(channel, clientId) {
+			result = append(result, channel)
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+func (b *Broker) leave(channel string, clientId string) bool {
+	members, ok := b.presence[channel]
+	if !ok {
+		return false
+	}
+
+	if _, ok := members[clientId]; !ok {
+		return false
+	}
+
+	delete(members, clientId)
+
+	if len(members) == 0 {
+		delete(b.presence, channel)
+	}
+
+	return true
+}
+
+// Members returns a shallow copy of the presence members of the
+// specified channel indexed with their client id.
+func (b *Broker) Members(channel string) map[string]any {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	result := make(map[string]any, len(b.presence[channel]))
+	for clientId, data := range b.presence[channel] {
+		result[clientId] = data
+	}
+
+	return result
+}
@@ -1,7 +1,9 @@
 package subscriptions_test
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
 )
@@ -132,3 +134,90 @@ func TestUnregister(t *testing.T) {
 		t.Fatalf("Expected client with id %s, got error %v", clientB.Id(), err)
 	}
 }
+
+func TestChannelTopic(t *testing.T) {
+	if topic := subscriptions.ChannelTopic("test"); topic != "@channel/test" {
+		t.Fatalf("Expected @channel/test topic, got %q", topic)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	b := subscriptions.NewBroker()
+
+	clientA := subscriptions.NewDefaultClient()
+	clientA.Subscribe("@channel/test", `@channel/test?options={"query":{"a":1}}`)
+	b.Register(clientA)
+
+	clientB := subscriptions.NewDefaultClient()
+	clientB.Subscribe("@channel/test2", "test")
+	b.Register(clientB)
+
+	if total := b.Publish("missing", []byte("{}")); total != 0 {
+		t.Fatalf("Expected 0 sent messages, got %d", total)
+	}
+
+	if total := b.Publish("test", []byte(`{"a":1}`)); total != 2 {
+		t.Fatalf("Expected 2 sent messages, got %d", total)
+	}
+
+	names := []string{}
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-clientA.Channel():
+			if string(msg.Data) != `{"a":1}` {
+				t.Fatalf("Expected message data %q, got %q", `{"a":1}`, msg.Data)
+			}
+			names = append(names, msg.Name)
+		case <-time.After(time.Second):
+			t.Fatal("Timeout while waiting for published message")
+		}
+	}
+
+	slices.Sort(names)
+	expectedNames := []string{"@channel/test", `@channel/test?options={"query":{"a":1}}`}
+	if !slices.Equal(names, expectedNames) {
+		t.Fatalf("Expected message names %v, got %v", expectedNames, names)
+	}
+}
+
+func TestPresence(t *testing.T) {
+	b := subscriptions.NewBroker()
+
+	if !b.Join("a", "client1", 1) {
+		t.Fatal("Expected client1 to join a")
+	}
+	if b.Join("a", "client1", 2) {
+		t.Fatal("Expected client1 to be already member of a")
+	}
+	if !b.Join("a", "client2", 3) || !b.Join("b", "client1", 4) {
+		t.Fatal("Expected successful joins")
+	}
+
+	members := b.Members("a")
+	if len(members) != 2 || members["client1"] != 1 || members["client2"] != 3 {
+		t.Fatalf("Unexpected a members %v", members)
+	}
+
+	// check if it is a shallow copy
+	delete(members, "client1")
+	if total := len(b.Members("a")); total != 2 {
+		t.Fatalf("Expected 2 a members, got %d", total)
+	}
+
+	if b.Leave("a", "missing") || b.Leave("missing", "client1") {
+		t.Fatal("Expected Leave to fail for nonexisting members")
+	}
+
+	if !b.Leave("a", "client2") {
+		t.Fatal("Expected client2 to leave a")
+	}
+
+	left := b.LeaveAll("client1")
+	if !slices.Equal(left, []string{"a", "b"}) {
+		t.Fatalf("Expected client1 to leave [a b], got %v", left)
+	}
+
+	if total := len(b.Members("a")) + len(b.Members("b")); total != 0 {
+		t.Fatalf("Expected no members, got %d", total)
+	}
+}
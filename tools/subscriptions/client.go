@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/security"
@@ -83,8 +84,10 @@ type DefaultClient struct {
 	store         map[string]any
 	subscriptions map[string]SubscriptionOptions
 	channel       chan Message
+	done          chan struct{}
 	id            string
 	mux           sync.RWMutex
+	sendMux       sync.RWMutex
 	isDiscarded   bool
 }
 
@@ -94,6 +97,7 @@ func NewDefaultClient() *DefaultClient {
 		id:            security.RandomString(40),
 		store:         map[string]any{},
 		channel:       make(chan Message),
+		done:          make(chan struct{}),
 		subscriptions: map[string]SubscriptionOptions{},
 	}
 }
@@ -160,39 +164,50 @@ func (c *DefaultClient) Subscribe(subs ...string) {
 			continue // skip empty
 		}
 
-		// extract subscription options (if any)
-		rawOptions := struct {
-			// note: any instead of string to minimize the breaking changes with earlier versions
-			Query   map[string]any `json:"query"`
-			Headers map[string]any `json:"headers"`
-		}{}
-		u, err := url.Parse(s)
-		if err == nil {
-			raw := u.Query().Get(optionsParam)
-			if raw != "" {
-				json.Unmarshal([]byte(raw), &rawOptions)
-			}
-		}
+		_, options := ParseSubscription(s)
 
-		options := SubscriptionOptions{
-			Query:   make(map[string]string, len(rawOptions.Query)),
-			Headers: make(map[string]string, len(rawOptions.Headers)),
-		}
+		c.subscriptions[s] = options
+	}
+}
 
-		// normalize query
-		// (currently only single string values are supported for consistency with the default routes handling)
-		for k, v := range rawOptions.Query {
-			options.Query[k] = cast.ToString(v)
+// ParseSubscription extracts the topic and the options of a single raw subscription, eg.:
+//
+//	ParseSubscription(`posts/*?options={"query":{"a":1}}`) // "posts/*", {Query:{"a":"1"}, Headers:{}}
+func ParseSubscription(sub string) (string, SubscriptionOptions) {
+	topic, _, _ := strings.Cut(sub, "?")
+
+	// extract subscription options (if any)
+	rawOptions := struct {
+		// note: any instead of string to minimize the breaking changes with earlier versions
+		Query   map[string]any `json:"query"`
+		Headers map[string]any `json:"headers"`
+	}{}
+	u, err := url.Parse(sub)
+	if err == nil {
+		raw := u.Query().Get(optionsParam)
+		if raw != "" {
+			json.Unmarshal([]byte(raw), &rawOptions)
 		}
+	}
 
-		// normalize headers name and values, eg. "X-Token" is converted to "x_token"
-		// (currently only single string values are supported for consistency with the default routes handling)
-		for k, v := range rawOptions.Headers {
-			options.Headers[inflector.Snakecase(k)] = cast.ToString(v)
-		}
+	options := SubscriptionOptions{
+		Query:   make(map[string]string, len(rawOptions.Query)),
+		Headers: make(map[string]string, len(rawOptions.Headers)),
+	}
 
-		c.subscriptions[s] = options
+	// normalize query
+	// (currently only single string values are supported for consistency with the default routes handling)
+	for k, v := range rawOptions.Query {
+		options.Query[k] = cast.ToString(v)
+	}
+
+	// normalize headers name and values, eg. "X-Token" is converted to "x_token"
+	// (currently only single string values are supported for consistency with the default routes handling)
+	for k, v := range rawOptions.Headers {
+		options.Headers[inflector.Snakecase(k)] = cast.ToString(v)
 	}
+
+	return topic, options
 }
 
 // Unsubscribe implements the [Client.Unsubscribe] interface method.
@@ -251,15 +266,24 @@ func (c *DefaultClient) Unset(key string) {
 // Discard implements the [Client.Discard] interface method.
 func (c *DefaultClient) Discard() {
 	c.mux.Lock()
-	defer c.mux.Unlock()
 
 	if c.isDiscarded {
+		c.mux.Unlock()
 		return
 	}
 
-	close(c.channel)
-
 	c.isDiscarded = true
+
+	// interrupt the pending sends (if any)
+	close(c.done)
+
+	c.mux.Unlock()
+
+	// wait for the pending sends to return before closing the channel
+	c.sendMux.Lock()
+	defer c.sendMux.Unlock()
+
+	close(c.channel)
 }
 
 // IsDiscarded implements the [Client.IsDiscarded] interface method.
@@ -272,9 +296,35 @@ func (c *DefaultClient) IsDiscarded() bool {
 
 // Send sends the specified message to the client's channel (if not discarded).
 func (c *DefaultClient) Send(m Message) {
+	c.send(m, nil)
+}
+
+// SendTimeout is similar to [DefaultClient.Send] but gives up if the
+// message wasn't accepted by the client's channel within the specified timeout.
+//
+// Returns false if the client is discarded or the timeout was reached.
+func (c *DefaultClient) SendTimeout(m Message, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	return c.send(m, timer.C)
+}
+
+// note: nil timeout channel blocks until the message is accepted or the client is discarded
+func (c *DefaultClient) send(m Message, timeout <-chan time.Time) bool {
+	c.sendMux.RLock()
+	defer c.sendMux.RUnlock()
+
 	if c.IsDiscarded() {
-		return
+		return false
 	}
 
-	c.Channel() <- m
+	select {
+	case c.channel <- m:
+		return true
+	case <-c.done:
+		return false
+	case <-timeout:
+		return false
+	}
 }
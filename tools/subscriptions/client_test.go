@@ -150,6 +150,38 @@ func TestSubscribeOptions(t *testing.T) {
 	}
 }
 
+func TestParseSubscription(t *testing.T) {
+	scenarios := []struct {
+		sub             string
+		expectedTopic   string
+		expectedOptions string
+	}{
+		{"", "", `{"query":{},"headers":{}}`},
+		{"test", "test", `{"query":{},"headers":{}}`},
+		{"test/*?a=1", "test/*", `{"query":{},"headers":{}}`},
+		{`@channel/test?options={"query":{"name":123},"headers":{"X-Token":456}}`, "@channel/test", `{"query":{"name":"123"},"headers":{"x_token":"456"}}`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.sub, func(t *testing.T) {
+			topic, options := subscriptions.ParseSubscription(s.sub)
+
+			if topic != s.expectedTopic {
+				t.Fatalf("Expected topic %q, got %q", s.expectedTopic, topic)
+			}
+
+			rawBytes, err := json.Marshal(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if str := string(rawBytes); str != s.expectedOptions {
+				t.Fatalf("Expected options\n%v\ngot\n%v", s.expectedOptions, str)
+			}
+		})
+	}
+}
+
 func TestUnsubscribe(t *testing.T) {
 	c := subscriptions.NewDefaultClient()
 
@@ -207,6 +239,43 @@ func TestDiscard(t *testing.T) {
 	}
 }
 
+func TestSendTimeout(t *testing.T) {
+	c := subscriptions.NewDefaultClient()
+
+	// no receiver
+	if c.SendTimeout(subscriptions.Message{Name: "m1"}, 10*time.Millisecond) {
+		t.Fatal("Expected m1 to timeout")
+	}
+
+	received := make(chan string, 1)
+	go func() {
+		m := <-c.Channel()
+		received <- m.Name
+	}()
+
+	if !c.SendTimeout(subscriptions.Message{Name: "m2"}, time.Second) {
+		t.Fatal("Expected m2 to be sent")
+	}
+
+	if name := <-received; name != "m2" {
+		t.Fatalf("Expected m2 to be received, got %q", name)
+	}
+
+	// discard while waiting
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.Discard()
+	}()
+
+	if c.SendTimeout(subscriptions.Message{Name: "m3"}, time.Second) {
+		t.Fatal("Expected m3 to fail because of the discarded client")
+	}
+
+	if c.SendTimeout(subscriptions.Message{Name: "m4"}, time.Second) {
+		t.Fatal("Expected m4 to fail because of the discarded client")
+	}
+}
+
 func TestSend(t *testing.T) {
 	c := subscriptions.NewDefaultClient()
 
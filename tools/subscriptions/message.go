@@ -8,23 +8,34 @@ import (
 type Message struct {
 	Name string `json:"name"`
 	Data []byte `json:"data"`
+
+	// Id is an optional message event id (eg. for resumable streams).
+	Id string `json:"id,omitempty"`
 }
 
 // WriteSSE writes the current message in a SSE format into the provided writer.
 //
+// The "id" line is omitted if eventId is empty so that the client
+// last event id remains unchanged.
+//
 // For example, writing to a router.Event:
 //
 //	m := Message{Name: "users/create", Data: []byte{...}}
 //	m.Write(e.Response, "yourEventId")
 //	e.Flush()
 func (m *Message) WriteSSE(w io.Writer, eventId string) error {
-	parts := [][]byte{
-		[]byte("id:" + eventId + "\n"),
-		[]byte("event:" + m.Name + "\n"),
+	parts := make([][]byte, 0, 5)
+
+	if eventId != "" {
+		parts = append(parts, []byte("id:"+eventId+"\n"))
+	}
+
+	parts = append(parts,
+		[]byte("event:"+m.Name+"\n"),
 		[]byte("data:"),
 		m.Data,
 		[]byte("\n\n"),
-	}
+	)
 
 	for _, part := range parts {
 		_, err := w.Write(part)
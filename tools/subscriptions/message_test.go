@@ -23,3 +23,20 @@ func TestMessageWrite(t *testing.T) {
 		t.Fatalf("Expected writer content\n%q\ngot\n%q", expected, v)
 	}
 }
+
+func TestMessageWriteWithoutId(t *testing.T) {
+	m := subscriptions.Message{
+		Name: "test_name",
+		Data: []byte("test_data"),
+	}
+
+	var sb strings.Builder
+
+	m.WriteSSE(&sb, "")
+
+	expected := "event:test_name\ndata:test_data\n\n"
+
+	if v := sb.String(); v != expected {
+		t.Fatalf("Expected writer content\n%q\ngot\n%q", expected, v)
+	}
+}
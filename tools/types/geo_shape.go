@@ -0,0 +1,526 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The supported GeoShape GeoJSON geometry types.
+const (
+	GeoShapeTypeLineString   = "LineString"
+	GeoShapeTypePolygon      = "Polygon"
+	GeoShapeTypeMultiPolygon = "MultiPolygon"
+)
+
+// geoShapeEpsilon is the max allowed planar deviation
+// when checking whether a point lies on a line segment.
+const geoShapeEpsilon = 1e-9
+
+// GeoBBox defines a geo bounding box.
+type GeoBBox struct {
+	MinLon float64 `form:"minLon" json:"minLon"`
+	MinLat float64 `form:"minLat" json:"minLat"`
+	MaxLon float64 `form:"maxLon" json:"maxLon"`
+	MaxLat float64 `form:"maxLat" json:"maxLat"`
+}
+
+// ContainsPoint reports whether the point is inside the current bounding box (including its edges).
+func (b GeoBBox) ContainsPoint(p GeoPoint) bool {
+	return p.Lon >= b.MinLon && p.Lon <= b.MaxLon && p.Lat >= b.MinLat && p.Lat <= b.MaxLat
+}
+
+// Intersects reports whether the current and the other bounding boxes have at least one common point.
+func (b GeoBBox) Intersects(other GeoBBox) bool {
+	return b.MaxLon >= other.MinLon && other.MaxLon >= b.MinLon && b.MaxLat >= other.MinLat && other.MaxLat >= b.MinLat
+}
+
+// GeoShape defines a struct for storing a GeoJSON LineString, Polygon or MultiPolygon
+// geometry (https://datatracker.ietf.org/doc/html/rfc7946#section-3.1) as serialized
+// json object with its cached bounding box, eg.:
+//
+//	{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]]],"bbox":[0,0,10,10]}
+//
+// The zero GeoShape (aka. without type) is serialized as json null.
+//
+// Note that the spatial predicates (ContainsPoint, Intersects) treat the
+// lon-lat coordinates as planar (aka. the shape edges are straight lines
+// in the lon-lat space and the shapes are not wrapped around the antimeridian).
+type GeoShape struct {
+	// Type is the GeoJSON geometry type (LineString, Polygon or MultiPolygon).
+	Type string
+
+	// Line holds the LineString positions.
+	Line []GeoPoint
+
+	// Polygons holds the Polygon (single item) and MultiPolygon positions
+	// as list of polygons, each one with its linear rings
+	// (the first ring is the exterior one and the rest are its holes).
+	Polygons [][][]GeoPoint
+}
+
+// NewGeoLineString creates a new LineString GeoShape from the provided points.
+func NewGeoLineString(points ...GeoPoint) GeoShape {
+	return GeoShape{Type: GeoShapeTypeLineString, Line: points}
+}
+
+// NewGeoPolygon creates a new Polygon GeoShape from the provided linear rings
+// (the first ring is the exterior one and the rest are its holes).
+func NewGeoPolygon(rings ...[]GeoPoint) GeoShape {
+	return GeoShape{Type: GeoShapeTypePolygon, Polygons: [][][]GeoPoint{rings}}
+}
+
+// NewGeoMultiPolygon creates a new MultiPolygon GeoShape from the provided polygons rings.
+func NewGeoMultiPolygon(polygons ...[][]GeoPoint) GeoShape {
+	return GeoShape{Type: GeoShapeTypeMultiPolygon, Polygons: polygons}
+}
+
+// IsZero reports whether the current GeoShape is the zero (aka. empty) shape.
+func (s GeoShape) IsZero() bool {
+	return s.Type == ""
+}
+
+// Points returns a flattened list with all shape positions.
+func (s GeoShape) Points() []GeoPoint {
+	if s.Type == GeoShapeTypeLineString {
+		return s.Line
+	}
+
+	var result []GeoPoint
+	for _, polygon := range s.Polygons {
+		for _, ring := range polygon {
+			result = append(result, ring...)
+		}
+	}
+
+	return result
+}
+
+// BBox returns the bounding box of the current shape.
+//
+// It returns zero GeoBBox for shape without positions.
+func (s GeoShape) BBox() GeoBBox {
+	points := s.Points()
+	if len(points) == 0 {
+		return GeoBBox{}
+	}
+
+	bbox := GeoBBox{
+		MinLon: points[0].Lon,
+		MinLat: points[0].Lat,
+		MaxLon: points[0].Lon,
+		MaxLat: points[0].Lat,
+	}
+
+	for _, p := range points[1:] {
+		bbox.MinLon = math.Min(bbox.MinLon, p.Lon)
+		bbox.MinLat = math.Min(bbox.MinLat, p.Lat)
+		bbox.MaxLon = math.Max(bbox.MaxLon, p.Lon)
+		bbox.MaxLat = math.Max(bbox.MaxLat, p.Lat)
+	}
+
+	return bbox
+}
+
+// Validate checks whether the current shape is a valid GeoJSON geometry.
+//
+// The zero GeoShape is considered valid.
+func (s GeoShape) Validate() error {
+	switch s.Type {
+	case "":
+		if len(s.Line) > 0 || len(s.Polygons) > 0 {
+			return errors.New("missing shape type")
+		}
+		return nil
+	case GeoShapeTypeLineString:
+		if len(s.Line) < 2 {
+			return errors.New("LineString must have at least 2 positions")
+		}
+	case GeoShapeTypePolygon, GeoShapeTypeMultiPolygon:
+		if len(s.Polygons) == 0 {
+			return fmt.Errorf("%s must have at least 1 polygon", s.Type)
+		}
+
+		if s.Type == GeoShapeTypePolygon && len(s.Polygons) > 1 {
+			return errors.New("Polygon must have exactly 1 polygon")
+		}
+
+		for _, polygon := range s.Polygons {
+			if len(polygon) == 0 {
+				return errors.New("polygon must have at least 1 linear ring")
+			}
+
+			for _, ring := range polygon {
+				if len(ring) < 4 {
+					return errors.New("polygon linear ring must have at least 4 positions")
+				}
+
+				if ring[0] != ring[len(ring)-1] {
+					return errors.New("polygon linear ring must be closed (aka. the first and last positions must be the same)")
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported shape type %q", s.Type)
+	}
+
+	for _, p := range s.Points() {
+		if p.Lon < -180 || p.Lon > 180 || p.Lat < -90 || p.Lat > 90 {
+			return fmt.Errorf("position [%v, %v] is out of the lon-lat range", p.Lon, p.Lat)
+		}
+	}
+
+	return nil
+}
+
+// ContainsPoint reports whether the point is inside the current shape.
+//
+// For LineString the point must lie on one of the line segments.
+// For Polygon and MultiPolygon the even-odd rule is used (aka. points inside
+// a hole are not contained) and points on the edges may or may not be contained.
+func (s GeoShape) ContainsPoint(p GeoPoint) bool {
+	if s.IsZero() || !s.BBox().ContainsPoint(p) {
+		return false
+	}
+
+	if s.Type == GeoShapeTypeLineString {
+		for _, seg := range s.segments() {
+			if seg.containsPoint(p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var crossings int
+	for _, seg := range s.segments() {
+		if seg.crossesRay(p) {
+			crossings++
+		}
+	}
+
+	return crossings%2 == 1
+}
+
+// Intersects reports whether the current and the other shapes have at least one common point.
+func (s GeoShape) Intersects(other GeoShape) bool {
+	if s.IsZero() || other.IsZero() || !s.BBox().Intersects(other.BBox()) {
+		return false
+	}
+
+	otherSegments := other.segments()
+	for _, a := range s.segments() {
+		for _, b := range otherSegments {
+			if a.intersects(b) {
+				return true
+			}
+		}
+	}
+
+	// no edges intersection -> check whether one of the shapes is inside the other
+	if s.Type != GeoShapeTypeLineString {
+		for _, p := range other.Points() {
+			if s.ContainsPoint(p) {
+				return true
+			}
+		}
+	}
+
+	if other.Type != GeoShapeTypeLineString {
+		for _, p := range s.Points() {
+			if other.ContainsPoint(p) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// String returns the string representation of the current GeoShape instance.
+func (s GeoShape) String() string {
+	raw, _ := json.Marshal(s)
+	return string(raw)
+}
+
+// AsMap implements [core.mapExtractor] and returns a value suitable
+// to be used in an API rule expression.
+func (s GeoShape) AsMap() map[string]any {
+	if s.IsZero() {
+		return nil
+	}
+
+	bbox := s.BBox()
+
+	return map[string]any{
+		"type":        s.Type,
+		"coordinates": s.coordinates(),
+		"bbox":        []float64{bbox.MinLon, bbox.MinLat, bbox.MaxLon, bbox.MaxLat},
+	}
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (s GeoShape) MarshalJSON() ([]byte, error) {
+	if s.IsZero() {
+		return []byte("null"), nil
+	}
+
+	bbox := s.BBox()
+
+	return json.Marshal(struct {
+		Type        string    `json:"type"`
+		Coordinates any       `json:"coordinates"`
+		BBox        []float64 `json:"bbox"`
+	}{
+		Type:        s.Type,
+		Coordinates: s.coordinates(),
+		BBox:        []float64{bbox.MinLon, bbox.MinLat, bbox.MaxLon, bbox.MaxLat},
+	})
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// The positions extra elements (eg. altitude) and the "bbox" member are ignored.
+func (s *GeoShape) UnmarshalJSON(data []byte) error {
+	*s = GeoShape{}
+
+	var raw struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil || raw.Type == "" {
+		return err // null or empty object
+	}
+
+	s.Type = raw.Type
+
+	switch raw.Type {
+	case GeoShapeTypeLineString:
+		var positions [][]float64
+		if err := json.Unmarshal(raw.Coordinates, &positions); err != nil {
+			return err
+		}
+		s.Line, err = toGeoPoints(positions)
+		return err
+	case GeoShapeTypePolygon:
+		var rings [][][]float64
+		if err := json.Unmarshal(raw.Coordinates, &rings); err != nil {
+			return err
+		}
+		polygon, err := toGeoRings(rings)
+		if err != nil {
+			return err
+		}
+		s.Polygons = [][][]GeoPoint{polygon}
+	case GeoShapeTypeMultiPolygon:
+		var polygons [][][][]float64
+		if err := json.Unmarshal(raw.Coordinates, &polygons); err != nil {
+			return err
+		}
+		s.Polygons = make([][][]GeoPoint, len(polygons))
+		for i, rings := range polygons {
+			s.Polygons[i], err = toGeoRings(rings)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported shape type %q", raw.Type)
+	}
+
+	return nil
+}
+
+// Value implements the [driver.Valuer] interface.
+//
+// The zero GeoShape is stored as NULL.
+func (s GeoShape) Value() (driver.Value, error) {
+	if s.IsZero() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(s)
+	return string(data), err
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current GeoShape instance.
+//
+// The value argument could be nil (zero shape), another GeoShape instance,
+// map or serialized GeoJSON geometry object.
+func (s *GeoShape) Scan(value any) error {
+	var err error
+
+	switch v := value.(type) {
+	case nil:
+		*s = GeoShape{}
+	case *GeoShape:
+		*s = *v
+	case GeoShape:
+		*s = v
+	case JSONRaw:
+		err = s.scanBytes(v)
+	case []byte:
+		err = s.scanBytes(v)
+	case string:
+		err = s.scanBytes([]byte(v))
+	default:
+		var raw []byte
+		raw, err = json.Marshal(v)
+		if err != nil {
+			err = fmt.Errorf("unable to marshalize value for scanning: %w", err)
+		} else {
+			err = s.scanBytes(raw)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("[GeoShape] unable to scan value %v: %w", value, err)
+	}
+
+	return nil
+}
+
+func (s *GeoShape) scanBytes(data []byte) error {
+	if len(data) == 0 {
+		*s = GeoShape{}
+		return nil
+	}
+
+	return json.Unmarshal(data, s)
+}
+
+// coordinates returns the GeoJSON coordinates representation of the shape positions.
+func (s GeoShape) coordinates() any {
+	switch s.Type {
+	case GeoShapeTypeLineString:
+		return fromGeoPoints(s.Line)
+	case GeoShapeTypePolygon:
+		if len(s.Polygons) == 0 {
+			return [][][]float64{}
+		}
+		return fromGeoRings(s.Polygons[0])
+	default:
+		result := make([][][][]float64, len(s.Polygons))
+		for i, rings := range s.Polygons {
+			result[i] = fromGeoRings(rings)
+		}
+		return result
+	}
+}
+
+// segments returns the line segments of the shape (aka. the polygon rings edges or the line parts).
+func (s GeoShape) segments() []geoSegment {
+	var result []geoSegment
+
+	appendSegments := func(points []GeoPoint) {
+		for i := 1; i < len(points); i++ {
+			result = append(result, geoSegment{points[i-1], points[i]})
+		}
+	}
+
+	if s.Type == GeoShapeTypeLineString {
+		appendSegments(s.Line)
+	} else {
+		for _, polygon := range s.Polygons {
+			for _, ring := range polygon {
+				appendSegments(ring)
+			}
+		}
+	}
+
+	return result
+}
+
+type geoSegment struct {
+	a GeoPoint
+	b GeoPoint
+}
+
+// containsPoint reports whether p lies on the segment.
+func (seg geoSegment) containsPoint(p GeoPoint) bool {
+	return math.Abs(geoOrientation(seg.a, seg.b, p)) <= geoShapeEpsilon &&
+		p.Lon >= math.Min(seg.a.Lon, seg.b.Lon) && p.Lon <= math.Max(seg.a.Lon, seg.b.Lon) &&
+		p.Lat >= math.Min(seg.a.Lat, seg.b.Lat) && p.Lat <= math.Max(seg.a.Lat, seg.b.Lat)
+}
+
+// crossesRay reports whether the segment crosses the horizontal
+// ray starting from p to +infinity longitude.
+func (seg geoSegment) crossesRay(p GeoPoint) bool {
+	if (seg.a.Lat > p.Lat) == (seg.b.Lat > p.Lat) {
+		return false
+	}
+
+	return p.Lon < (seg.b.Lon-seg.a.Lon)*(p.Lat-seg.a.Lat)/(seg.b.Lat-seg.a.Lat)+seg.a.Lon
+}
+
+// intersects reports whether the segment and the other segment have at least one common point.
+func (seg geoSegment) intersects(other geoSegment) bool {
+	d1 := geoOrientation(seg.a, seg.b, other.a)
+	d2 := geoOrientation(seg.a, seg.b, other.b)
+	d3 := geoOrientation(other.a, other.b, seg.a)
+	d4 := geoOrientation(other.a, other.b, seg.b)
+
+	return d1*d2 <= 0 && d3*d4 <= 0 &&
+		math.Max(seg.a.Lon, seg.b.Lon) >= math.Min(other.a.Lon, other.b.Lon) &&
+		math.Max(other.a.Lon, other.b.Lon) >= math.Min(seg.a.Lon, seg.b.Lon) &&
+		math.Max(seg.a.Lat, seg.b.Lat) >= math.Min(other.a.Lat, other.b.Lat) &&
+		math.Max(other.a.Lat, other.b.Lat) >= math.Min(seg.a.Lat, seg.b.Lat)
+}
+
+// geoOrientation returns the cross product of the (a, b) and (a, c) vectors
+// (positive for counter-clockwise, negative for clockwise and 0 for collinear points).
+func geoOrientation(a, b, c GeoPoint) float64 {
+	return (b.Lon-a.Lon)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lon-a.Lon)
+}
+
+func toGeoPoints(positions [][]float64) ([]GeoPoint, error) {
+	result := make([]GeoPoint, len(positions))
+
+	for i, pos := range positions {
+		if len(pos) < 2 {
+			return nil, errors.New("position must have at least 2 elements")
+		}
+		result[i] = GeoPoint{Lon: pos[0], Lat: pos[1]}
+	}
+
+	return result, nil
+}
+
+func toGeoRings(rings [][][]float64) ([][]GeoPoint, error) {
+	result := make([][]GeoPoint, len(rings))
+
+	for i, ring := range rings {
+		points, err := toGeoPoints(ring)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = points
+	}
+
+	return result, nil
+}
+
+func fromGeoPoints(points []GeoPoint) [][]float64 {
+	result := make([][]float64, len(points))
+
+	for i, p := range points {
+		result[i] = []float64{p.Lon, p.Lat}
+	}
+
+	return result
+}
+
+func fromGeoRings(rings [][]GeoPoint) [][][]float64 {
+	result := make([][][]float64, len(rings))
+
+	for i, ring := range rings {
+		result[i] = fromGeoPoints(ring)
+	}
+
+	return result
+}
@@ -0,0 +1,244 @@
+package types_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	testGeoSquare = types.NewGeoPolygon(
+		[]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 10, Lat: 0}, {Lon: 10, Lat: 10}, {Lon: 0, Lat: 10}, {Lon: 0, Lat: 0}},
+		[]types.GeoPoint{{Lon: 4, Lat: 4}, {Lon: 6, Lat: 4}, {Lon: 6, Lat: 6}, {Lon: 4, Lat: 6}, {Lon: 4, Lat: 4}}, // hole
+	)
+
+	testGeoTriangles = types.NewGeoMultiPolygon(
+		[][]types.GeoPoint{{{Lon: 20, Lat: 0}, {Lon: 30, Lat: 0}, {Lon: 20, Lat: 10}, {Lon: 20, Lat: 0}}},
+		[][]types.GeoPoint{{{Lon: -20, Lat: 0}, {Lon: -30, Lat: 0}, {Lon: -20, Lat: -10}, {Lon: -20, Lat: 0}}},
+	)
+
+	testGeoLine = types.NewGeoLineString(types.GeoPoint{Lon: -5, Lat: 5}, types.GeoPoint{Lon: 5, Lat: 5}, types.GeoPoint{Lon: 5, Lat: 15})
+)
+
+func TestGeoShapeStringAndValue(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		shape    types.GeoShape
+		expected string
+	}{
+		{"zero", types.GeoShape{}, `null`},
+		{"line", testGeoLine, `{"type":"LineString","coordinates":[[-5,5],[5,5],[5,15]],"bbox":[-5,5,5,15]}`},
+		{
+			"polygon",
+			testGeoSquare,
+			`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]],"bbox":[0,0,10,10]}`,
+		},
+		{
+			"multipolygon",
+			testGeoTriangles,
+			`{"type":"MultiPolygon","coordinates":[[[[20,0],[30,0],[20,10],[20,0]]],[[[-20,0],[-30,0],[-20,-10],[-20,0]]]],"bbox":[-30,-10,30,10]}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			str := s.shape.String()
+			if str != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, str)
+			}
+
+			val, err := s.shape.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if s.shape.IsZero() {
+				if val != nil {
+					t.Fatalf("Expected nil Value for zero shape, got %v", val)
+				}
+			} else if str != val {
+				t.Fatalf("Expected String and Value to return the same value")
+			}
+		})
+	}
+}
+
+func TestGeoShapeScan(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		value     any
+		expectErr bool
+		expectStr string
+	}{
+		{nil, false, `null`},
+		{"", false, `null`},
+		{[]byte{}, false, `null`},
+		{types.JSONRaw{}, false, `null`},
+		{`null`, false, `null`},
+		{`{}`, false, `null`},
+		{`[]`, true, `null`},
+		{0, true, `null`},
+		{`{"type":"Point","coordinates":[1,2]}`, true, `{"type":"Point","coordinates":[],"bbox":[0,0,0,0]}`},
+		{`{"type":"LineString","coordinates":[[1,2],["a",4]]}`, true, `{"type":"LineString","coordinates":[],"bbox":[0,0,0,0]}`},
+		{
+			`{"type":"LineString","coordinates":[[1,2,100],[3,4]],"bbox":[-100,-100,100,100]}`,
+			false,
+			`{"type":"LineString","coordinates":[[1,2],[3,4]],"bbox":[1,2,3,4]}`,
+		},
+		{
+			[]byte(`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`),
+			false,
+			`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]],"bbox":[0,0,1,1]}`,
+		},
+		{
+			map[string]any{"type": "LineString", "coordinates": [][]float64{{1, 2}, {3, 4}}},
+			false,
+			`{"type":"LineString","coordinates":[[1,2],[3,4]],"bbox":[1,2,3,4]}`,
+		},
+		{testGeoLine, false, testGeoLine.String()},
+		{&testGeoLine, false, testGeoLine.String()},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.value), func(t *testing.T) {
+			shape := testGeoSquare
+
+			err := shape.Scan(s.value)
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Errorf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+
+			if str := shape.String(); str != s.expectStr {
+				t.Errorf("Expected\n%s\ngot\n%s", s.expectStr, str)
+			}
+		})
+	}
+}
+
+func TestGeoShapeValidate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name      string
+		shape     types.GeoShape
+		expectErr bool
+	}{
+		{"zero", types.GeoShape{}, false},
+		{"missing type", types.GeoShape{Line: testGeoLine.Line}, true},
+		{"unsupported type", types.GeoShape{Type: "Point"}, true},
+		{"line with 1 position", types.NewGeoLineString(types.GeoPoint{Lon: 1, Lat: 1}), true},
+		{"line", testGeoLine, false},
+		{"polygon without rings", types.NewGeoPolygon(), true},
+		{"polygon with less than 4 positions", types.NewGeoPolygon([]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 1, Lat: 0}, {Lon: 0, Lat: 0}}), true},
+		{"unclosed polygon", types.NewGeoPolygon([]types.GeoPoint{{Lon: 0, Lat: 0}, {Lon: 1, Lat: 0}, {Lon: 1, Lat: 1}, {Lon: 0, Lat: 1}}), true},
+		{"polygon", testGeoSquare, false},
+		{"polygon with multiple polygons", types.GeoShape{Type: types.GeoShapeTypePolygon, Polygons: testGeoTriangles.Polygons}, true},
+		{"empty multipolygon", types.NewGeoMultiPolygon(), true},
+		{"multipolygon", testGeoTriangles, false},
+		{"lon out of range", types.NewGeoLineString(types.GeoPoint{Lon: 0, Lat: 0}, types.GeoPoint{Lon: 180.1, Lat: 0}), true},
+		{"lat out of range", types.NewGeoLineString(types.GeoPoint{Lon: 0, Lat: 0}, types.GeoPoint{Lon: 0, Lat: -90.1}), true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.shape.Validate()
+
+			hasErr := err != nil
+			if hasErr != s.expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectErr, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestGeoShapeBBox(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		shape    types.GeoShape
+		expected types.GeoBBox
+	}{
+		{"zero", types.GeoShape{}, types.GeoBBox{}},
+		{"line", testGeoLine, types.GeoBBox{MinLon: -5, MinLat: 5, MaxLon: 5, MaxLat: 15}},
+		{"polygon", testGeoSquare, types.GeoBBox{MinLon: 0, MinLat: 0, MaxLon: 10, MaxLat: 10}},
+		{"multipolygon", testGeoTriangles, types.GeoBBox{MinLon: -30, MinLat: -10, MaxLon: 30, MaxLat: 10}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if bbox := s.shape.BBox(); bbox != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, bbox)
+			}
+		})
+	}
+}
+
+func TestGeoShapeContainsPoint(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name     string
+		shape    types.GeoShape
+		point    types.GeoPoint
+		expected bool
+	}{
+		{"zero shape", types.GeoShape{}, types.GeoPoint{}, false},
+		{"polygon inside", testGeoSquare, types.GeoPoint{Lon: 2, Lat: 3}, true},
+		{"polygon inside hole", testGeoSquare, types.GeoPoint{Lon: 5, Lat: 5}, false},
+		{"polygon outside", testGeoSquare, types.GeoPoint{Lon: 11, Lat: 5}, false},
+		{"multipolygon inside first", testGeoTriangles, types.GeoPoint{Lon: 21, Lat: 1}, true},
+		{"multipolygon inside second", testGeoTriangles, types.GeoPoint{Lon: -21, Lat: -1}, true},
+		{"multipolygon between polygons", testGeoTriangles, types.GeoPoint{Lon: 0, Lat: 0}, false},
+		{"multipolygon outside in bbox", testGeoTriangles, types.GeoPoint{Lon: 29, Lat: 9}, false},
+		{"line vertex", testGeoLine, types.GeoPoint{Lon: 5, Lat: 5}, true},
+		{"line segment", testGeoLine, types.GeoPoint{Lon: 5, Lat: 10}, true},
+		{"line off segment", testGeoLine, types.GeoPoint{Lon: 4, Lat: 10}, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := s.shape.ContainsPoint(s.point); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestGeoShapeIntersects(t *testing.T) {
+	t.Parallel()
+
+	inner := types.NewGeoPolygon([]types.GeoPoint{{Lon: 1, Lat: 1}, {Lon: 2, Lat: 1}, {Lon: 2, Lat: 2}, {Lon: 1, Lat: 1}})
+
+	scenarios := []struct {
+		name     string
+		a        types.GeoShape
+		b        types.GeoShape
+		expected bool
+	}{
+		{"zero shapes", types.GeoShape{}, types.GeoShape{}, false},
+		{"zero and non-zero shapes", testGeoSquare, types.GeoShape{}, false},
+		{"crossing line and polygon", testGeoLine, testGeoSquare, true},
+		{"polygon inside polygon", testGeoSquare, inner, true},
+		{"polygon inside polygon (reversed)", inner, testGeoSquare, true},
+		{"polygon inside hole", testGeoSquare, types.NewGeoPolygon([]types.GeoPoint{{Lon: 4.5, Lat: 4.5}, {Lon: 5.5, Lat: 4.5}, {Lon: 5.5, Lat: 5.5}, {Lon: 4.5, Lat: 4.5}}), false},
+		{"line inside polygon", testGeoSquare, types.NewGeoLineString(types.GeoPoint{Lon: 1, Lat: 1}, types.GeoPoint{Lon: 2, Lat: 2}), true},
+		{"touching lines", testGeoLine, types.NewGeoLineString(types.GeoPoint{Lon: 5, Lat: 15}, types.GeoPoint{Lon: 6, Lat: 16}), true},
+		{"parallel lines", testGeoLine, types.NewGeoLineString(types.GeoPoint{Lon: -5, Lat: 6}, types.GeoPoint{Lon: 4, Lat: 6}), false},
+		{"disjoint polygons", testGeoSquare, testGeoTriangles, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if v := s.a.Intersects(s.b); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
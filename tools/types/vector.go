@@ -0,0 +1,275 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// List with the supported vector distance metrics.
+const (
+	// VectorMetricCosine is the cosine distance (1 - cosine similarity) in the range [0, 2].
+	VectorMetricCosine = "cosine"
+
+	// VectorMetricL2 is the euclidean distance.
+	VectorMetricL2 = "l2"
+
+	// VectorMetricDot is the negative inner product
+	// (negated so that smaller values are "closer" as with the other metrics).
+	VectorMetricDot = "dot"
+)
+
+// VectorMetrics is a list with all supported vector distance metrics.
+var VectorMetrics = []string{VectorMetricCosine, VectorMetricL2, VectorMetricDot}
+
+// Vector defines a float32 embedding vector that is stored in the db as
+// compact little-endian float32 blob and serialized as json array of numbers.
+//
+// The zero Vector is stored as NULL.
+type Vector []float32
+
+// ParseVector creates a new Vector from the provided value.
+//
+// The value could be another Vector, slice of numbers (or numeric strings),
+// json array string (eg. "[0.1,0.2]") or comma separated list of numbers (eg. "0.1,0.2").
+//
+// Nil, empty string, empty bytes slice, etc. results in zero Vector.
+func ParseVector(value any) (Vector, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case Vector:
+		return v, nil
+	case *Vector:
+		if v == nil {
+			return nil, nil
+		}
+		return *v, nil
+	case []float32:
+		return Vector(v), nil
+	case []float64:
+		result := make(Vector, len(v))
+		for i, n := range v {
+			result[i] = float32(n)
+		}
+		return result, nil
+	case []any:
+		result := make(Vector, len(v))
+		for i, n := range v {
+			f, err := cast.ToFloat64E(n)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vector element at index %d: %w", i, err)
+			}
+			result[i] = float32(f)
+		}
+		return result, nil
+	case []string:
+		result := make(Vector, len(v))
+		for i, n := range v {
+			f, err := strconv.ParseFloat(strings.TrimSpace(n), 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid vector element at index %d: %w", i, err)
+			}
+			result[i] = float32(f)
+		}
+		return result, nil
+	case []byte:
+		return parseVectorString(string(v))
+	case JSONRaw:
+		return parseVectorString(string(v))
+	case string:
+		return parseVectorString(v)
+	}
+
+	return nil, fmt.Errorf("unsupported vector value type %T", value)
+}
+
+func parseVectorString(str string) (Vector, error) {
+	str = strings.TrimSpace(str)
+	if str == "" || str == "null" {
+		return nil, nil
+	}
+
+	if str[0] == '[' {
+		var nums []float64
+		if err := json.Unmarshal([]byte(str), &nums); err != nil {
+			return nil, fmt.Errorf("invalid vector json array: %w", err)
+		}
+		return ParseVector(nums)
+	}
+
+	parts := strings.Split(str, ",")
+	result := make(Vector, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector element at index %d: %w", i, err)
+		}
+		result[i] = float32(f)
+	}
+
+	return result, nil
+}
+
+// ParseVectorBytes decodes a little-endian float32 blob (see [Vector.Bytes]).
+func ParseVectorBytes(data []byte) (Vector, error) {
+	if len(data)%4 != 0 {
+		return nil, errors.New("invalid vector blob size")
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	result := make(Vector, len(data)/4)
+	for i := range result {
+		result[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+
+	return result, nil
+}
+
+// Bytes returns the little-endian float32 blob representation of the vector.
+//
+// Returns nil for zero vector.
+func (v Vector) Bytes() []byte {
+	if len(v) == 0 {
+		return nil
+	}
+
+	result := make([]byte, len(v)*4)
+	for i, n := range v {
+		binary.LittleEndian.PutUint32(result[i*4:], math.Float32bits(n))
+	}
+
+	return result
+}
+
+// IsFinite reports whether all vector elements are finite numbers (aka. not NaN or ±Inf).
+func (v Vector) IsFinite() bool {
+	for _, n := range v {
+		f := float64(n)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the json array string representation of the vector.
+func (v Vector) String() string {
+	raw, _ := v.MarshalJSON()
+	return string(raw)
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+//
+// The zero vector is serialized as empty json array.
+func (v Vector) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]float32(v))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+func (v *Vector) UnmarshalJSON(data []byte) error {
+	parsed, err := parseVectorString(string(data))
+	if err != nil {
+		return err
+	}
+
+	*v = parsed
+
+	return nil
+}
+
+// Value implements the [driver.Valuer] interface.
+func (v Vector) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+
+	return v.Bytes(), nil
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided
+// db float32 blob value into the current Vector instance.
+func (v *Vector) Scan(value any) error {
+	var data []byte
+
+	switch val := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return fmt.Errorf("failed to unmarshal Vector value: %q", value)
+	}
+
+	parsed, err := ParseVectorBytes(data)
+	if err != nil {
+		return err
+	}
+
+	*v = parsed
+
+	return nil
+}
+
+// Distance calculates the distance between the current and the other
+// vector using the specified metric (see [VectorMetrics]).
+//
+// Returns an error if the vectors have different dimensions or
+// in case of cosine distance when one of the vectors has zero magnitude.
+func (v Vector) Distance(other Vector, metric string) (float64, error) {
+	if len(v) != len(other) {
+		return 0, fmt.Errorf("vector dimensions mismatch (%d vs %d)", len(v), len(other))
+	}
+
+	switch metric {
+	case VectorMetricCosine:
+		var dot, normA, normB float64
+		for i := range v {
+			a, b := float64(v[i]), float64(other[i])
+			dot += a * b
+			normA += a * a
+			normB += b * b
+		}
+
+		if normA == 0 || normB == 0 {
+			return 0, errors.New("cosine distance is not defined for zero magnitude vectors")
+		}
+
+		return 1 - dot/(math.Sqrt(normA)*math.Sqrt(normB)), nil
+	case VectorMetricL2:
+		var sum float64
+		for i := range v {
+			d := float64(v[i]) - float64(other[i])
+			sum += d * d
+		}
+
+		return math.Sqrt(sum), nil
+	case VectorMetricDot:
+		var dot float64
+		for i := range v {
+			dot += float64(v[i]) * float64(other[i])
+		}
+
+		return -dot, nil
+	}
+
+	return 0, fmt.Errorf("unsupported vector distance metric %q", metric)
+}
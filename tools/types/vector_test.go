@@ -0,0 +1,211 @@
+package types_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestParseVector(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		value       any
+		expected    string
+		expectError bool
+	}{
+		{nil, "[]", false},
+		{"", "[]", false},
+		{"null", "[]", false},
+		{[]byte{}, "[]", false},
+		{types.Vector{1, 2}, "[1,2]", false},
+		{&types.Vector{1, 2}, "[1,2]", false},
+		{[]float32{1, 2.5}, "[1,2.5]", false},
+		{[]float64{1, 2.5}, "[1,2.5]", false},
+		{[]any{1, "2.5", 3.0}, "[1,2.5,3]", false},
+		{[]any{1, "a"}, "", true},
+		{[]string{"1", " 2.5"}, "[1,2.5]", false},
+		{[]string{"1", "a"}, "", true},
+		{"[0.1, -0.2]", "[0.1,-0.2]", false},
+		{[]byte("[0.1,0.2]"), "[0.1,0.2]", false},
+		{types.JSONRaw("[1]"), "[1]", false},
+		{"0.1, 0.2,0.3", "[0.1,0.2,0.3]", false},
+		{"[1,", "", true},
+		{"1,a", "", true},
+		{123, "", true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.value), func(t *testing.T) {
+			v, err := types.ParseVector(s.value)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if str := v.String(); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
+	}
+}
+
+func TestVectorBytes(t *testing.T) {
+	t.Parallel()
+
+	if v := types.Vector(nil).Bytes(); v != nil {
+		t.Fatalf("Expected nil bytes for zero vector, got %v", v)
+	}
+
+	v := types.Vector{1, -2.5, 0.125}
+
+	data := v.Bytes()
+	if len(data) != 12 {
+		t.Fatalf("Expected 12 bytes, got %d", len(data))
+	}
+
+	parsed, err := types.ParseVectorBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.String() != v.String() {
+		t.Fatalf("Expected %s, got %s", v, parsed)
+	}
+
+	if _, err := types.ParseVectorBytes(data[:5]); err == nil {
+		t.Fatal("Expected invalid blob size error")
+	}
+}
+
+func TestVectorValueAndScan(t *testing.T) {
+	t.Parallel()
+
+	value, err := types.Vector(nil).Value()
+	if err != nil || value != nil {
+		t.Fatalf("Expected nil db value for zero vector, got %v (%v)", value, err)
+	}
+
+	original := types.Vector{0.5, 1}
+
+	value, err = original.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		value    any
+		expected string
+	}{
+		{nil, "[]"},
+		{value, "[0.5,1]"},
+		{string(value.([]byte)), "[0.5,1]"},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			v := types.Vector{1}
+			if err := v.Scan(s.value); err != nil {
+				t.Fatal(err)
+			}
+
+			if str := v.String(); str != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, str)
+			}
+		})
+	}
+
+	v := types.Vector{}
+	if err := v.Scan(123); err == nil {
+		t.Fatal("Expected scan error")
+	}
+}
+
+func TestVectorJSON(t *testing.T) {
+	t.Parallel()
+
+	raw, err := json.Marshal(map[string]any{"a": types.Vector(nil), "b": types.Vector{1, 0.25}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"a":[],"b":[1,0.25]}`
+	if string(raw) != expected {
+		t.Fatalf("Expected %s, got %s", expected, raw)
+	}
+
+	var v types.Vector
+	if err := json.Unmarshal([]byte("[3,4]"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "[3,4]" {
+		t.Fatalf("Expected [3,4], got %s", v)
+	}
+}
+
+func TestVectorIsFinite(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		vector   types.Vector
+		expected bool
+	}{
+		{nil, true},
+		{types.Vector{1, 2}, true},
+		{types.Vector{1, float32(math.NaN())}, false},
+		{types.Vector{float32(math.Inf(-1))}, false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			if v := s.vector.IsFinite(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestVectorDistance(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name        string
+		a           types.Vector
+		b           types.Vector
+		metric      string
+		expected    float64
+		expectError bool
+	}{
+		{"dimensions mismatch", types.Vector{1}, types.Vector{1, 2}, types.VectorMetricL2, 0, true},
+		{"unknown metric", types.Vector{1}, types.Vector{1}, "missing", 0, true},
+		{"cosine same direction", types.Vector{1, 1}, types.Vector{2, 2}, types.VectorMetricCosine, 0, false},
+		{"cosine orthogonal", types.Vector{1, 0}, types.Vector{0, 1}, types.VectorMetricCosine, 1, false},
+		{"cosine opposite", types.Vector{1, 0}, types.Vector{-1, 0}, types.VectorMetricCosine, 2, false},
+		{"cosine zero magnitude", types.Vector{0, 0}, types.Vector{1, 0}, types.VectorMetricCosine, 0, true},
+		{"l2", types.Vector{0, 0}, types.Vector{3, 4}, types.VectorMetricL2, 5, false},
+		{"dot", types.Vector{1, 2}, types.Vector{3, 4}, types.VectorMetricDot, -11, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := s.a.Distance(s.b, s.metric)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if math.Abs(result-s.expected) > 1e-9 {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
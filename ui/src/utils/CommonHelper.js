@@ -1113,7 +1113,7 @@ export default class CommonHelper {
                 val = "test@example.com";
             } else if (field.type == "url") {
                 val = "https://example.com";
-            } else if (field.type == "json") {
+            } else if (field.type == "json" || (field.type == "encrypted" && field.format == "json")) {
                 val = 'JSON';
             } else if (field.type == "file") {
                 if (forSubmit) {
@@ -1137,6 +1137,10 @@ export default class CommonHelper {
                 }
             } else if (field.type == "geoPoint") {
                 val = {"lon": 0, "lat": 0};
+            } else if (field.type == "geoShape") {
+                val = {"type": "LineString", "coordinates": [[0, 0], [1, 1]]};
+            } else if (field.type == "vector") {
+                val = Array(field.dimensions || 1).fill(0.1);
             } else {
                 val = "test";
             }
@@ -1202,6 +1206,12 @@ export default class CommonHelper {
                 return "ri-calendar-check-line";
             case "geoPoint":
                 return "ri-map-pin-2-line";
+            case "geoShape":
+                return "ri-shape-line";
+            case "encrypted":
+                return "ri-shield-keyhole-line";
+            case "vector":
+                return "ri-bubble-chart-line";
             default:
                 return "ri-star-s-line";
         }
@@ -1220,7 +1230,10 @@ export default class CommonHelper {
             case "number":
                 return "Number";
             case "geoPoint":
+            case "geoShape":
                 return "Object";
+            case "vector":
+                return "Array<Number>";
             case "file":
                 return "File";
             case "select":
@@ -1253,11 +1266,15 @@ export default class CommonHelper {
             return '{"lon":0,"lat":0}';
         }
 
-        if (field?.type === "json") {
+        if (field?.type === "json" || (field?.type === "encrypted" && field?.format === "json")) {
             return 'null, "", [], {}';
         }
 
         // arrayable fields
+        if (field?.type === "vector") {
+            return "[]";
+        }
+
         if (["select", "relation", "file"].includes(field?.type) && field?.maxSelect != 1) {
             return "[]";
         }